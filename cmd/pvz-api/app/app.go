@@ -1,99 +1,464 @@
 package app
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/Arzeeq/pvz-api/internal/config"
+	pb "github.com/Arzeeq/pvz-api/internal/grpc"
 	"github.com/Arzeeq/pvz-api/internal/logger"
 	"github.com/Arzeeq/pvz-api/internal/server"
+	"github.com/Arzeeq/pvz-api/internal/service"
 	"github.com/Arzeeq/pvz-api/internal/storage/pg"
+	"github.com/Arzeeq/pvz-api/internal/storage/sqlite"
+	"github.com/Arzeeq/pvz-api/internal/tracing"
 	"github.com/go-chi/chi"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
+const (
+	dbRetryInitialBackoff = 500 * time.Millisecond
+	dbRetryMaxBackoff     = 10 * time.Second
+)
+
+// Application owns servers and background jobs of the service.
+// It is started by Run and stopped by Shutdown, database is closed last.
 type Application struct {
-	cfg  *config.Config
-	l    *logger.MyLogger
-	http *server.HTTPServer
-	grpc *grpc.Server
+	cfg     *config.Config
+	l       *logger.MyLogger
+	http    *http.Server
+	metrics *http.Server
+	grpc    *grpc.Server
+	// grpcHealth serves grpc.health.v1 statuses of gRPC services
+	grpcHealth *health.Server
+	health     *service.HealthService
+	nodeSync   *service.NodeSyncService
+	expiry     *service.ExpiryService
+	closeDB    func()
+	// idempotency deletes expired idempotency keys, nil when keys are not stored
+	idempotency *service.IdempotencyService
+	// configPath is read again on reload, empty path reloads environment only
+	configPath string
+	// live holds fields of config changed by reload, they are read by servers, logger and services
+	live *config.Live
+	// httpServer checks route timeouts of reloaded config
+	httpServer *server.HTTPServer
+	// shutdownTracing flushes spans which are not exported yet
+	shutdownTracing func(context.Context) error
+	// errs receives errors of servers stopped not by Shutdown
+	errs     chan error
+	stopJobs context.CancelFunc
+	jobs     sync.WaitGroup
 }
 
-func NewApplication(cfg *config.Config, logger *logger.MyLogger) (*Application, func(), error) {
-	if cfg == nil || logger == nil {
-		return nil, nil, errors.New("cfg and logger must be non nil")
+// NewApplication creates application from cfg loaded from configPath,
+// live must be created from the same cfg and is shared with logger
+func NewApplication(cfg *config.Config, live *config.Live, configPath string, logger *logger.MyLogger) (*Application, error) {
+	if cfg == nil || live == nil || logger == nil {
+		return nil, errors.New("cfg, live and logger must be non nil")
+	}
+
+	var services *services
+	var handlers *Handlers
+	var closeDB func()
+	var err error
+	switch cfg.Storage {
+	case config.StorageSQLite:
+		services, handlers, closeDB, err = initSQLite(cfg, live, logger)
+	case config.StoragePostgres:
+		services, handlers, closeDB, err = initPostgres(cfg, live, logger)
+	default:
+		return nil, fmt.Errorf("unsupported storage %s", cfg.Storage)
+	}
+	if err != nil {
+		if closeDB != nil {
+			closeDB()
+		}
+		return nil, err
+	}
+
+	// metrics of current state are loaded from database, so they do not start from zero after restart
+	refreshCtx, cancel := context.WithTimeout(context.Background(), cfg.RequestTimeout)
+	if err := services.metrics.Refresh(refreshCtx); err != nil {
+		logger.WrapError("failed to load business metrics", err)
 	}
+	cancel()
 
-	pool, deferFn, err := pg.InitDB(cfg.ConnectionStr)
+	httpServer, err := server.NewHTTP(handlers.HTTPHandlers, logger, cfg, live)
 	if err != nil {
-		return nil, nil, err
+		closeDB()
+		return nil, err
+	}
+
+	grpcHealth := health.NewServer()
+	grpcServer, err := server.NewGRPC(handlers.GrpcPVZ, handlers.GrpcReception, handlers.GrpcProduct, grpcHealth, logger, live)
+	if err != nil {
+		closeDB()
+		return nil, err
+	}
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg)
+	if err != nil {
+		closeDB()
+		return nil, err
+	}
+
+	r := chi.NewRouter()
+	r.Handle("/metrics", promhttp.Handler())
+	r.Get("/healthz", handlers.Health.Healthz)
+	r.Get("/readyz", handlers.Health.Readyz)
+
+	app := Application{
+		cfg:             cfg,
+		configPath:      configPath,
+		live:            live,
+		l:               logger,
+		http:            &http.Server{Addr: fmt.Sprintf("0.0.0.0:%d", cfg.HTTPPort), Handler: httpServer},
+		httpServer:      httpServer,
+		metrics:         &http.Server{Addr: fmt.Sprintf("0.0.0.0:%d", cfg.PrometheusPort), Handler: r},
+		grpc:            grpcServer,
+		grpcHealth:      grpcHealth,
+		health:          services.health,
+		nodeSync:        services.nodeSync,
+		expiry:          services.expiry,
+		idempotency:     services.idempotency,
+		closeDB:         closeDB,
+		shutdownTracing: shutdownTracing,
+		errs:            make(chan error, 3),
+	}
+
+	return &app, nil
+}
+
+func initPostgres(cfg *config.Config, live *config.Live, logger *logger.MyLogger) (*services, *Handlers, func(), error) {
+	pool, deferFn, err := pg.InitDB(cfg.ConnectionStr, pg.PoolSettings{
+		MaxConns:          cfg.DBMaxConns,
+		MinConns:          cfg.DBMinConns,
+		MaxConnLifetime:   cfg.DBMaxConnLifetime,
+		MaxConnIdleTime:   cfg.DBMaxConnIdleTime,
+		HealthCheckPeriod: cfg.DBHealthCheckPeriod,
+		ConnectTimeout:    cfg.DBConnectTimeout,
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := waitForDatabase(pool.Ping, cfg.DBStartupTimeout, logger); err != nil {
+		return nil, nil, deferFn, err
+	}
+
+	poolCollector, err := pg.NewPoolCollector(pool)
+	if err != nil {
+		return nil, nil, deferFn, err
+	}
+	if err := prometheus.Register(poolCollector); err != nil {
+		return nil, nil, deferFn, err
 	}
 
 	migrator := pg.NewMigrator(cfg.MigrationDir, cfg.ConnectionStr)
 	if err := migrator.Up(); err != nil {
-		return nil, deferFn, err
+		return nil, nil, deferFn, err
 	}
 
-	handlers, err := InitializeHandlers(pool, cfg, logger)
+	storage, err := initStorages(pool)
 	if err != nil {
-		return nil, deferFn, err
+		return nil, nil, deferFn, err
 	}
 
-	http, err := server.NewHTTP(handlers.Auth, handlers.Pvz, handlers.Reception, handlers.Product, logger, cfg)
+	migrationVersion, err := migrator.LatestVersion()
 	if err != nil {
-		return nil, deferFn, err
+		return nil, nil, deferFn, err
 	}
 
-	grpc, err := server.NewGRPC(handlers.GrpcPVZ)
+	services, handlers, err := initialize(storage, cfg, live, logger, migrationVersion)
 	if err != nil {
-		return nil, deferFn, err
+		return nil, nil, deferFn, err
 	}
 
-	app := Application{
-		cfg:  cfg,
-		l:    logger,
-		http: http,
-		grpc: grpc,
+	return services, handlers, deferFn, nil
+}
+
+// waitForDatabase pings database with exponential backoff until it answers or timeout passes,
+// so application can be started together with database
+func waitForDatabase(ping func(context.Context) error, timeout time.Duration, logger *logger.MyLogger) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	backoff := dbRetryInitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := ping(ctx)
+		if err == nil {
+			return nil
+		}
+
+		logger.Warn("database is not available",
+			slog.Int("attempt", attempt),
+			slog.Duration("retry_in", backoff),
+			slog.String("error", err.Error()),
+		)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("database is not available after %s: %w", timeout, err)
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, dbRetryMaxBackoff)
+	}
+}
+
+func initSQLite(cfg *config.Config, live *config.Live, logger *logger.MyLogger) (*services, *Handlers, func(), error) {
+	migrator := sqlite.NewMigrator(cfg.MigrationDir, cfg.SQLitePath)
+	if err := migrator.Up(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	db, deferFn, err := sqlite.InitDB(cfg.SQLitePath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	storage, err := initSQLiteStorages(db)
+	if err != nil {
+		return nil, nil, deferFn, err
 	}
 
-	return &app, deferFn, nil
+	migrationVersion, err := migrator.LatestVersion()
+	if err != nil {
+		return nil, nil, deferFn, err
+	}
+
+	services, handlers, err := initialize(storage, cfg, live, logger, migrationVersion)
+	if err != nil {
+		return nil, nil, deferFn, err
+	}
+
+	return services, handlers, deferFn, nil
 }
 
+// Run binds ports and starts servers and background jobs. It returns an error when
+// any port can not be bound, later failures of servers are reported to Errors
 func (app *Application) Run() error {
 	app.l.Info("Running application")
 
+	httpLis, err := net.Listen("tcp", app.http.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen HTTP port: %w", err)
+	}
+	grpcLis, err := net.Listen("tcp", fmt.Sprintf(":%d", app.cfg.GRPCPort))
+	if err != nil {
+		httpLis.Close()
+		return fmt.Errorf("failed to listen gRPC port: %w", err)
+	}
+	metricsLis, err := net.Listen("tcp", app.metrics.Addr)
+	if err != nil {
+		httpLis.Close()
+		grpcLis.Close()
+		return fmt.Errorf("failed to listen Prometheus port: %w", err)
+	}
+
 	app.l.Info("Starting HTTP", slog.Int("port", app.cfg.HTTPPort))
 	go func() {
-		if err := app.http.Run(); err != nil {
-			app.l.WrapError("HTTP server has encountered an error", err)
+		if err := app.http.Serve(httpLis); !errors.Is(err, http.ErrServerClosed) {
+			app.errs <- fmt.Errorf("HTTP server: %w", err)
 		}
 	}()
 
 	app.l.Info("Starting gRPC", slog.Int("port", app.cfg.GRPCPort))
 	go func() {
-		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", app.cfg.GRPCPort))
-		if err != nil {
-			app.l.WrapError("failed to listen grpc port", err)
-			return
-		}
-
-		if err := app.grpc.Serve(lis); err != nil {
-			app.l.WrapError("failed to serve grpc port", err)
+		if err := app.grpc.Serve(grpcLis); err != nil {
+			app.errs <- fmt.Errorf("gRPC server: %w", err)
 		}
 	}()
 
-	r := chi.NewRouter()
-	r.Handle("/metrics", promhttp.Handler())
 	app.l.Info("Starting Prometheus", slog.Int("port", app.cfg.PrometheusPort))
 	go func() {
-		if err := http.ListenAndServe(fmt.Sprintf("0.0.0.0:%d", app.cfg.PrometheusPort), r); err != nil {
-			app.l.WrapError("failed to serve prometheus port", err)
+		if err := app.metrics.Serve(metricsLis); !errors.Is(err, http.ErrServerClosed) {
+			app.errs <- fmt.Errorf("Prometheus server: %w", err)
 		}
 	}()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	app.stopJobs = cancel
+
+	app.jobs.Add(1)
+	go app.runHealth(ctx)
+
+	if app.nodeSync != nil {
+		app.l.Info("Starting sync with central server", slog.String("url", app.cfg.SyncCentralURL))
+		app.jobs.Add(1)
+		go app.runNodeSync(ctx)
+	}
+
+	if app.expiry != nil {
+		app.l.Info("Starting storage period expiry", slog.Duration("interval", app.cfg.ExpiryInterval))
+		app.jobs.Add(1)
+		go app.runExpiry(ctx)
+	}
+
+	if app.idempotency != nil {
+		app.jobs.Add(1)
+		go app.runIdempotencyCleanup(ctx)
+	}
+
+	app.jobs.Add(1)
+	go app.runConfigReload(ctx)
+
 	return nil
 }
+
+// Errors reports servers which have stopped unexpectedly
+func (app *Application) Errors() <-chan error {
+	return app.errs
+}
+
+// Shutdown stops background jobs, drains in-flight HTTP and gRPC requests until ctx is done
+// and closes database. Requests still running after deadline are cut
+func (app *Application) Shutdown(ctx context.Context) error {
+	var errs []error
+
+	// readiness fails from now on, so new traffic goes to other instances
+	app.health.SetShuttingDown()
+	app.grpcHealth.Shutdown()
+
+	if app.stopJobs != nil {
+		app.stopJobs()
+	}
+	app.jobs.Wait()
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		app.grpc.GracefulStop()
+		close(grpcStopped)
+	}()
+
+	if err := app.http.Shutdown(ctx); err != nil {
+		app.http.Close()
+		errs = append(errs, fmt.Errorf("failed to shutdown HTTP server: %w", err))
+	}
+
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		app.grpc.Stop()
+		<-grpcStopped
+		errs = append(errs, fmt.Errorf("failed to shutdown gRPC server: %w", ctx.Err()))
+	}
+
+	// metrics are served until the end of draining
+	if err := app.metrics.Shutdown(ctx); err != nil {
+		app.metrics.Close()
+		errs = append(errs, fmt.Errorf("failed to shutdown Prometheus server: %w", err))
+	}
+
+	app.closeDB()
+
+	if err := app.shutdownTracing(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to flush traces: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// runHealth periodically reflects readiness of application in gRPC health statuses
+func (app *Application) runHealth(ctx context.Context) {
+	defer app.jobs.Done()
+	ticker := time.NewTicker(app.cfg.HealthInterval)
+	defer ticker.Stop()
+
+	for {
+		healthCtx, cancel := context.WithTimeout(ctx, app.cfg.HealthInterval)
+		status := healthpb.HealthCheckResponse_SERVING
+		if err := app.health.Ready(healthCtx); err != nil {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		cancel()
+
+		// statuses set after Shutdown of health server are ignored
+		app.grpcHealth.SetServingStatus("", status)
+		app.grpcHealth.SetServingStatus(pb.PVZService_ServiceDesc.ServiceName, status)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNodeSync periodically uploads local changes of offline node to the central server
+func (app *Application) runNodeSync(ctx context.Context) {
+	defer app.jobs.Done()
+	ticker := time.NewTicker(app.cfg.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		syncCtx, cancel := context.WithTimeout(ctx, app.cfg.SyncInterval)
+		if err := app.nodeSync.Sync(syncCtx); err != nil && ctx.Err() == nil {
+			app.l.WrapError("failed to sync with central server", err)
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runExpiry periodically moves products with expired storage period into return lists
+func (app *Application) runExpiry(ctx context.Context) {
+	defer app.jobs.Done()
+	ticker := time.NewTicker(app.cfg.ExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		expiryCtx, cancel := context.WithTimeout(ctx, app.cfg.ExpiryInterval)
+		expired, err := app.expiry.ExpireProducts(expiryCtx)
+		if err != nil && ctx.Err() == nil {
+			app.l.WrapError("failed to expire products", err)
+		} else if expired > 0 {
+			app.l.Info("products storage period expired", slog.Int("count", expired))
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runIdempotencyCleanup periodically deletes expired idempotency keys
+func (app *Application) runIdempotencyCleanup(ctx context.Context) {
+	defer app.jobs.Done()
+	ticker := time.NewTicker(app.cfg.IdempotencyCleanup)
+	defer ticker.Stop()
+
+	for {
+		cleanupCtx, cancel := context.WithTimeout(ctx, app.cfg.IdempotencyCleanup)
+		deleted, err := app.idempotency.DeleteExpired(cleanupCtx)
+		if err != nil && ctx.Err() == nil {
+			app.l.WrapError("failed to delete expired idempotency keys", err)
+		} else if deleted > 0 {
+			app.l.Debug("expired idempotency keys deleted", slog.Int("count", deleted))
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
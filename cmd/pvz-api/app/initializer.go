@@ -1,20 +1,26 @@
 package app
 
 import (
+	"database/sql"
 	"errors"
 	"time"
 
+	"github.com/Arzeeq/pvz-api/internal/client"
 	"github.com/Arzeeq/pvz-api/internal/config"
+	"github.com/Arzeeq/pvz-api/internal/dto"
 	grpc_handler "github.com/Arzeeq/pvz-api/internal/handler/grpc"
 	handler "github.com/Arzeeq/pvz-api/internal/handler/http"
 	"github.com/Arzeeq/pvz-api/internal/logger"
+	"github.com/Arzeeq/pvz-api/internal/middleware"
+	"github.com/Arzeeq/pvz-api/internal/server"
 	"github.com/Arzeeq/pvz-api/internal/service"
 	"github.com/Arzeeq/pvz-api/internal/storage/pg"
+	"github.com/Arzeeq/pvz-api/internal/storage/sqlite"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func InitializeHandlers(pool *pgxpool.Pool, cfg *config.Config, logger *logger.MyLogger) (*Handlers, error) {
-	if pool == nil || cfg == nil || logger == nil {
+func InitializeHandlers(pool *pgxpool.Pool, cfg *config.Config, live *config.Live, logger *logger.MyLogger) (*Handlers, error) {
+	if pool == nil || cfg == nil || live == nil || logger == nil {
 		return nil, errors.New("nil values in constructor")
 	}
 
@@ -23,40 +29,91 @@ func InitializeHandlers(pool *pgxpool.Pool, cfg *config.Config, logger *logger.M
 		return nil, err
 	}
 
-	services, err := initServices(storage, cfg.JWTSecret, cfg.JWTDuration)
+	migrationVersion, err := pg.NewMigrator(cfg.MigrationDir, cfg.ConnectionStr).LatestVersion()
 	if err != nil {
 		return nil, err
 	}
 
-	handlers, err := initHandlers(services, logger, cfg.RequestTimeout)
+	_, handlers, err := initialize(storage, cfg, live, logger, migrationVersion)
+	return handlers, err
+}
+
+func InitializeSQLiteHandlers(db *sql.DB, cfg *config.Config, live *config.Live, logger *logger.MyLogger) (*Handlers, error) {
+	if db == nil || cfg == nil || live == nil || logger == nil {
+		return nil, errors.New("nil values in constructor")
+	}
+
+	storage, err := initSQLiteStorages(db)
 	if err != nil {
 		return nil, err
 	}
 
-	return handlers, nil
+	migrationVersion, err := sqlite.NewMigrator(cfg.MigrationDir, cfg.SQLitePath).LatestVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	_, handlers, err := initialize(storage, cfg, live, logger, migrationVersion)
+	return handlers, err
 }
 
+// initialize creates services and handlers, migrationVersion is the version database is expected to have
+func initialize(storage *storages, cfg *config.Config, live *config.Live, logger *logger.MyLogger, migrationVersion uint) (*services, *Handlers, error) {
+	services, err := initServices(storage, cfg, live, migrationVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	handlers, err := initHandlers(services, logger, cfg.RequestTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return services, handlers, nil
+}
+
+// storages contains storage implementations, optional ones are nil
+// when chosen database does not support the feature
 type storages struct {
-	product   *pg.ProductStorage
-	pvz       *pg.PVZStorage
-	reception *pg.ReceptionStorage
-	user      *pg.UserStorage
+	product     service.ProductStorager
+	pvz         service.PVZStorager
+	reception   service.ReceptionStorager
+	user        service.UserStorager
+	health      service.HealthStorager
+	metrics     service.MetricsStorager
+	sync        service.SyncStorager
+	outbox      service.OutboxStorager
+	idempotency service.IdempotencyStorager
+	order       service.OrderStorager
+	cell        service.CellStorager
+	transfer    service.TransferStorager
+	expiry      service.ExpiryStorager
+	inventory   service.InventoryStorager
 }
 
 type services struct {
-	product   *service.ProductService
-	pvz       *service.PVZService
-	reception *service.ReceptionService
-	token     *service.TokenService
-	user      *service.UserService
+	product     *service.ProductService
+	pvz         *service.PVZService
+	reception   *service.ReceptionService
+	token       *service.TokenService
+	user        *service.UserService
+	health      *service.HealthService
+	metrics     *service.BusinessMetrics
+	sync        *service.SyncService
+	nodeSync    *service.NodeSyncService
+	idempotency *service.IdempotencyService
+	order       *service.OrderService
+	cell        *service.CellService
+	transfer    *service.TransferService
+	expiry      *service.ExpiryService
+	inventory   *service.InventoryService
 }
 
 type Handlers struct {
-	Auth      *handler.AuthHandler
-	Product   *handler.ProductHandler
-	Pvz       *handler.PVZHandler
-	Reception *handler.ReceptionHandler
-	GrpcPVZ   *grpc_handler.PVZHandler
+	server.HTTPHandlers
+	GrpcPVZ       *grpc_handler.PVZHandler
+	GrpcReception *grpc_handler.ReceptionHandler
+	GrpcProduct   *grpc_handler.ProductHandler
 }
 
 func initStorages(pool *pgxpool.Pool) (*storages, error) {
@@ -64,6 +121,15 @@ func initStorages(pool *pgxpool.Pool) (*storages, error) {
 	var pvzStorage *pg.PVZStorage
 	var receptionStorage *pg.ReceptionStorage
 	var userStorage *pg.UserStorage
+	var healthStorage *pg.HealthStorage
+	var metricsStorage *pg.MetricsStorage
+	var syncStorage *pg.SyncStorage
+	var idempotencyStorage *pg.IdempotencyStorage
+	var orderStorage *pg.OrderStorage
+	var cellStorage *pg.CellStorage
+	var transferStorage *pg.TransferStorage
+	var expiryStorage *pg.ExpiryStorage
+	var inventoryStorage *pg.InventoryStorage
 	var err error
 	if productStorage, err = pg.NewProductStorage(pool); err != nil {
 		return nil, err
@@ -77,42 +143,199 @@ func initStorages(pool *pgxpool.Pool) (*storages, error) {
 	if userStorage, err = pg.NewUserStorage(pool); err != nil {
 		return nil, err
 	}
+	if healthStorage, err = pg.NewHealthStorage(pool); err != nil {
+		return nil, err
+	}
+	if metricsStorage, err = pg.NewMetricsStorage(pool); err != nil {
+		return nil, err
+	}
+	if syncStorage, err = pg.NewSyncStorage(pool); err != nil {
+		return nil, err
+	}
+	if idempotencyStorage, err = pg.NewIdempotencyStorage(pool); err != nil {
+		return nil, err
+	}
+	if orderStorage, err = pg.NewOrderStorage(pool); err != nil {
+		return nil, err
+	}
+	if cellStorage, err = pg.NewCellStorage(pool); err != nil {
+		return nil, err
+	}
+	if transferStorage, err = pg.NewTransferStorage(pool); err != nil {
+		return nil, err
+	}
+	if expiryStorage, err = pg.NewExpiryStorage(pool); err != nil {
+		return nil, err
+	}
+	if inventoryStorage, err = pg.NewInventoryStorage(pool); err != nil {
+		return nil, err
+	}
+	return &storages{
+		product:     productStorage,
+		pvz:         pvzStorage,
+		reception:   receptionStorage,
+		user:        userStorage,
+		health:      healthStorage,
+		metrics:     metricsStorage,
+		sync:        syncStorage,
+		idempotency: idempotencyStorage,
+		order:       orderStorage,
+		cell:        cellStorage,
+		transfer:    transferStorage,
+		expiry:      expiryStorage,
+		inventory:   inventoryStorage,
+	}, nil
+}
+
+func initSQLiteStorages(db *sql.DB) (*storages, error) {
+	var productStorage *sqlite.ProductStorage
+	var pvzStorage *sqlite.PVZStorage
+	var receptionStorage *sqlite.ReceptionStorage
+	var userStorage *sqlite.UserStorage
+	var healthStorage *sqlite.HealthStorage
+	var metricsStorage *sqlite.MetricsStorage
+	var outboxStorage *sqlite.SyncStorage
+	var err error
+	if productStorage, err = sqlite.NewProductStorage(db); err != nil {
+		return nil, err
+	}
+	if pvzStorage, err = sqlite.NewPVZStorage(db); err != nil {
+		return nil, err
+	}
+	if receptionStorage, err = sqlite.NewReceptionStorage(db); err != nil {
+		return nil, err
+	}
+	if userStorage, err = sqlite.NewUserStorage(db); err != nil {
+		return nil, err
+	}
+	if healthStorage, err = sqlite.NewHealthStorage(db); err != nil {
+		return nil, err
+	}
+	if metricsStorage, err = sqlite.NewMetricsStorage(db); err != nil {
+		return nil, err
+	}
+	if outboxStorage, err = sqlite.NewSyncStorage(db); err != nil {
+		return nil, err
+	}
 	return &storages{
 		product:   productStorage,
 		pvz:       pvzStorage,
 		reception: receptionStorage,
 		user:      userStorage,
+		health:    healthStorage,
+		metrics:   metricsStorage,
+		outbox:    outboxStorage,
 	}, nil
 }
 
-func initServices(storage *storages, jwtSecret string, jwtDuration time.Duration) (*services, error) {
+func initServices(storage *storages, cfg *config.Config, live *config.Live, migrationVersion uint) (*services, error) {
 	var productService *service.ProductService
 	var pvzService *service.PVZService
 	var receptionService *service.ReceptionService
 	var tokenService *service.TokenService
 	var userService *service.UserService
+	var healthService *service.HealthService
+	var businessMetrics *service.BusinessMetrics
+	var syncService *service.SyncService
+	var nodeSyncService *service.NodeSyncService
+	var idempotencyService *service.IdempotencyService
+	var orderService *service.OrderService
+	var cellService *service.CellService
+	var transferService *service.TransferService
+	var expiryService *service.ExpiryService
+	var inventoryService *service.InventoryService
 	var err error
-	if productService, err = service.NewProductService(storage.product); err != nil {
+	if businessMetrics, err = service.NewBusinessMetrics(storage.metrics); err != nil {
+		return nil, err
+	}
+	if productService, err = service.NewProductService(storage.product, storage.cell, live.ProductPolicy, businessMetrics); err != nil {
 		return nil, err
 	}
-	if pvzService, err = service.NewPVZService(storage.pvz, storage.reception, storage.product); err != nil {
+	if pvzService, err = service.NewPVZService(storage.pvz, storage.reception, storage.product, businessMetrics); err != nil {
 		return nil, err
 	}
-	if receptionService, err = service.NewReceptionService(storage.reception); err != nil {
+	if receptionService, err = service.NewReceptionService(storage.reception, storage.product, businessMetrics); err != nil {
 		return nil, err
 	}
-	if tokenService, err = service.NewTokenService([]byte(jwtSecret), jwtDuration); err != nil {
+	if tokenService, err = service.NewTokenService([]byte(cfg.JWTSecret), cfg.JWTDuration); err != nil {
 		return nil, err
 	}
 	if userService, err = service.NewUserService(storage.user, tokenService); err != nil {
 		return nil, err
 	}
+	if healthService, err = service.NewHealthService(storage.health, migrationVersion); err != nil {
+		return nil, err
+	}
+	if storage.sync != nil {
+		if syncService, err = service.NewSyncService(storage.sync, businessMetrics); err != nil {
+			return nil, err
+		}
+	}
+	if storage.idempotency != nil {
+		if idempotencyService, err = service.NewIdempotencyService(storage.idempotency, cfg.IdempotencyTTL, cfg.IdempotencyLease); err != nil {
+			return nil, err
+		}
+	}
+	if storage.order != nil {
+		policy := service.PickupPolicy{
+			CodeTTL:     cfg.PickupCodeTTL,
+			MaxAttempts: cfg.PickupMaxAttempts,
+			Lockout:     cfg.PickupLockout,
+		}
+		if orderService, err = service.NewOrderService(storage.order, policy); err != nil {
+			return nil, err
+		}
+	}
+	if storage.cell != nil {
+		if cellService, err = service.NewCellService(storage.cell); err != nil {
+			return nil, err
+		}
+	}
+	if storage.transfer != nil {
+		if transferService, err = service.NewTransferService(storage.transfer, businessMetrics); err != nil {
+			return nil, err
+		}
+	}
+	if storage.expiry != nil {
+		periods := make(map[dto.ProductType]time.Duration, len(cfg.StoragePeriods))
+		for productType, period := range cfg.StoragePeriods {
+			periods[dto.ProductType(productType)] = period
+		}
+		if expiryService, err = service.NewExpiryService(storage.expiry, periods, cfg.StoragePeriodDefault); err != nil {
+			return nil, err
+		}
+	}
+	if storage.inventory != nil {
+		if inventoryService, err = service.NewInventoryService(storage.inventory); err != nil {
+			return nil, err
+		}
+	}
+	// offline node uploads its changes only when central server is configured
+	if storage.outbox != nil && cfg.SyncCentralURL != "" {
+		centralClient, err := client.NewCentralClient(cfg.SyncCentralURL, cfg.SyncToken, cfg.RequestTimeout)
+		if err != nil {
+			return nil, err
+		}
+		if nodeSyncService, err = service.NewNodeSyncService(storage.outbox, centralClient, cfg.SyncBatchSize); err != nil {
+			return nil, err
+		}
+	}
 	return &services{
-		product:   productService,
-		pvz:       pvzService,
-		reception: receptionService,
-		token:     tokenService,
-		user:      userService,
+		product:     productService,
+		pvz:         pvzService,
+		reception:   receptionService,
+		token:       tokenService,
+		user:        userService,
+		health:      healthService,
+		metrics:     businessMetrics,
+		sync:        syncService,
+		nodeSync:    nodeSyncService,
+		idempotency: idempotencyService,
+		order:       orderService,
+		cell:        cellService,
+		transfer:    transferService,
+		expiry:      expiryService,
+		inventory:   inventoryService,
 	}, nil
 }
 
@@ -121,7 +344,17 @@ func initHandlers(s *services, logger *logger.MyLogger, timeout time.Duration) (
 	var productHandler *handler.ProductHandler
 	var pvzHandler *handler.PVZHandler
 	var receptionHandler *handler.ReceptionHandler
+	var healthHandler *handler.HealthHandler
+	var syncHandler *handler.SyncHandler
+	var nodeSyncHandler *handler.NodeSyncHandler
+	var orderHandler *handler.OrderHandler
+	var cellHandler *handler.CellHandler
+	var transferHandler *handler.TransferHandler
+	var expiryHandler *handler.ExpiryHandler
+	var inventoryHandler *handler.InventoryHandler
 	var grpcPvzHandler *grpc_handler.PVZHandler
+	var grpcReceptionHandler *grpc_handler.ReceptionHandler
+	var grpcProductHandler *grpc_handler.ProductHandler
 	var err error
 	if authHandler, err = handler.NewAuthHandler(s.user, s.token, logger, timeout); err != nil {
 		return nil, err
@@ -135,14 +368,76 @@ func initHandlers(s *services, logger *logger.MyLogger, timeout time.Duration) (
 	if receptionHandler, err = handler.NewReceptionHandler(s.reception, logger, timeout); err != nil {
 		return nil, err
 	}
+	if healthHandler, err = handler.NewHealthHandler(s.health, logger, timeout); err != nil {
+		return nil, err
+	}
+	if s.sync != nil {
+		if syncHandler, err = handler.NewSyncHandler(s.sync, logger, timeout); err != nil {
+			return nil, err
+		}
+	}
+	if s.nodeSync != nil {
+		if nodeSyncHandler, err = handler.NewNodeSyncHandler(s.nodeSync, logger, timeout); err != nil {
+			return nil, err
+		}
+	}
+	if s.order != nil {
+		if orderHandler, err = handler.NewOrderHandler(s.order, logger, timeout); err != nil {
+			return nil, err
+		}
+	}
+	if s.cell != nil {
+		if cellHandler, err = handler.NewCellHandler(s.cell, logger, timeout); err != nil {
+			return nil, err
+		}
+	}
+	if s.transfer != nil {
+		if transferHandler, err = handler.NewTransferHandler(s.transfer, logger, timeout); err != nil {
+			return nil, err
+		}
+	}
+	if s.expiry != nil {
+		if expiryHandler, err = handler.NewExpiryHandler(s.expiry, logger, timeout); err != nil {
+			return nil, err
+		}
+	}
+	if s.inventory != nil {
+		if inventoryHandler, err = handler.NewInventoryHandler(s.inventory, logger, timeout); err != nil {
+			return nil, err
+		}
+	}
 	if grpcPvzHandler, err = grpc_handler.NewPVZHandler(s.pvz); err != nil {
 		return nil, err
 	}
+	if grpcReceptionHandler, err = grpc_handler.NewReceptionHandler(s.reception); err != nil {
+		return nil, err
+	}
+	if grpcProductHandler, err = grpc_handler.NewProductHandler(s.product); err != nil {
+		return nil, err
+	}
+	// idempotency is assigned only when available to keep interface value nil otherwise
+	var idempotency middleware.IdempotencyServicer
+	if s.idempotency != nil {
+		idempotency = s.idempotency
+	}
 	return &Handlers{
-		Auth:      authHandler,
-		Product:   productHandler,
-		Pvz:       pvzHandler,
-		Reception: receptionHandler,
-		GrpcPVZ:   grpcPvzHandler,
+		HTTPHandlers: server.HTTPHandlers{
+			Idempotency: idempotency,
+			Auth:        authHandler,
+			Product:     productHandler,
+			Pvz:         pvzHandler,
+			Reception:   receptionHandler,
+			Health:      healthHandler,
+			Sync:        syncHandler,
+			NodeSync:    nodeSyncHandler,
+			Order:       orderHandler,
+			Cell:        cellHandler,
+			Transfer:    transferHandler,
+			Expiry:      expiryHandler,
+			Inventory:   inventoryHandler,
+		},
+		GrpcPVZ:       grpcPvzHandler,
+		GrpcReception: grpcReceptionHandler,
+		GrpcProduct:   grpcProductHandler,
 	}, nil
 }
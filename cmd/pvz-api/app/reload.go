@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/config"
+	"github.com/fsnotify/fsnotify"
+)
+
+// configReloadDelay lets editors finish writing config file before it is read
+const configReloadDelay = 200 * time.Millisecond
+
+// runConfigReload reloads config on SIGHUP and on changes of config file
+func (app *Application) runConfigReload(ctx context.Context) {
+	defer app.jobs.Done()
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var events <-chan fsnotify.Event
+	var watchErrs <-chan error
+	if app.configPath != "" {
+		watcher, err := app.watchConfig()
+		if err != nil {
+			app.l.WrapError("failed to watch config file, reload is available by SIGHUP only", err)
+		} else {
+			defer watcher.Close()
+			events, watchErrs = watcher.Events, watcher.Errors
+		}
+	}
+
+	delay := time.NewTimer(configReloadDelay)
+	delay.Stop()
+	defer delay.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			app.reloadConfig("signal")
+		case event := <-events:
+			if filepath.Clean(event.Name) == filepath.Clean(app.configPath) && event.Has(fsnotify.Write|fsnotify.Create) {
+				delay.Reset(configReloadDelay)
+			}
+		case <-delay.C:
+			app.reloadConfig("file")
+		case err := <-watchErrs:
+			app.l.WrapError("config file watcher failed", err)
+		}
+	}
+}
+
+// watchConfig watches directory of config file, so file replaced by editor or deployment is still watched
+func (app *Application) watchConfig() (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(filepath.Dir(app.configPath)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	return watcher, nil
+}
+
+// reloadConfig reads config again and applies fields which can be changed without restart.
+// Invalid config is rejected as a whole and current config is kept, valid one replaces
+// all reloadable fields at once
+func (app *Application) reloadConfig(trigger string) {
+	cfg, err := config.Load(app.configPath)
+	if err != nil {
+		app.l.WrapError("config reload rejected, keeping current config", err, slog.String("trigger", trigger))
+		return
+	}
+
+	// route timeouts are the only fields which can be rejected after validation
+	if err := app.httpServer.CheckRouteTimeouts(cfg.RouteTimeouts); err != nil {
+		app.l.WrapError("config reload rejected, keeping current config", err, slog.String("trigger", trigger))
+		return
+	}
+	// env is not reloaded, so default log level chosen by it stays the same
+	next := *cfg
+	next.Env = app.cfg.Env
+	app.live.Store(&next)
+
+	if keys := app.cfg.RestartRequired(cfg); len(keys) > 0 {
+		app.l.Warn("config changes are ignored until restart", slog.Any("keys", keys))
+	}
+	app.l.Info("config reloaded", slog.String("trigger", trigger))
+}
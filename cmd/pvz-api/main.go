@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -12,26 +15,76 @@ import (
 )
 
 func main() {
+	printConfig := flag.Bool("print-config", false, "print effective config with secrets redacted and exit")
+	flag.Parse()
+
+	configPath := os.Getenv("CONFIG_PATH")
+	if *printConfig {
+		os.Exit(runPrintConfig(configPath))
+	}
+
 	// load config and create logger
-	cfg := config.MustLoad(os.Getenv("CONFIG_PATH"))
-	l := logger.New(cfg.Env, cfg.LoggerFormat)
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	live := config.NewLive(cfg)
+	l := logger.New(live)
+	slog.SetDefault(l.Logger)
 	l.Info("config loaded successfully", slog.String("env", cfg.Env))
 
-	app, deferFn, err := app.NewApplication(cfg, l)
+	app, err := app.NewApplication(cfg, live, configPath, l)
 	if err != nil {
 		l.WrapError("failed to create application instance", err)
+		os.Exit(1)
 	}
-	defer deferFn()
 
-	go func() {
-		if err := app.Run(); err != nil {
-			l.WrapError("application has encountered an error", err)
+	exitCode := 0
+	if err := app.Run(); err != nil {
+		l.WrapError("failed to run application", err)
+		exitCode = 1
+	} else {
+		// catching os signals SIGINT and SIGTERM or failure of any server
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		select {
+		case sig := <-quit:
+			l.Info("Gracefully shutting down application", slog.String("signal", sig.String()))
+		case err := <-app.Errors():
+			l.WrapError("application has encountered an error, shutting down", err)
+			exitCode = 1
 		}
-	}()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	if err := app.Shutdown(ctx); err != nil {
+		l.WrapError("failed to shutdown application gracefully", err)
+		exitCode = 1
+	}
+	cancel()
+	l.Info("Application stopped")
+
+	os.Exit(exitCode)
+}
+
+// runPrintConfig prints effective config to stdout and validation problems to stderr
+func runPrintConfig(configPath string) int {
+	cfg, err := config.Read(configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if err := cfg.WriteYAML(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid config:\n%v\n", err)
+		return 1
+	}
 
-	// catching os signals SIGINT and SIGTERM
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	l.Info("Gracefully shutting down application")
+	return 0
 }
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+)
+
+// CentralClient talks to the sync API of the central pvz-api server
+type CentralClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+func NewCentralClient(baseURL string, token string, timeout time.Duration) (*CentralClient, error) {
+	if baseURL == "" {
+		return nil, errors.New("empty central server url")
+	}
+
+	return &CentralClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (c *CentralClient) UploadBatch(ctx context.Context, batch dto.SyncBatch) (*dto.SyncBatchResult, error) {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode sync batch: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/sync/batches", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload sync batch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var respErr dto.Error
+		if err := dto.Parse(resp.Body, &respErr); err != nil {
+			return nil, fmt.Errorf("central server responded with status %d", resp.StatusCode)
+		}
+		return nil, fmt.Errorf("central server responded with status %d: %s", resp.StatusCode, respErr.Message)
+	}
+
+	var result dto.SyncBatchResult
+	if err := dto.Parse(resp.Body, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCentralClient(t *testing.T) {
+	client, err := NewCentralClient("", "token", time.Second)
+	require.Error(t, err)
+	require.Nil(t, client)
+
+	client, err = NewCentralClient("http://central/", "token", time.Second)
+	require.NoError(t, err)
+	require.Equal(t, "http://central", client.baseURL)
+}
+
+func TestCentralClient_UploadBatch(t *testing.T) {
+	batch := dto.SyncBatch{BatchId: uuid.New(), Items: []dto.SyncItem{}}
+
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/sync/batches", r.URL.Path)
+			require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+			var received dto.SyncBatch
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			require.Equal(t, batch.BatchId, received.BatchId)
+
+			_ = json.NewEncoder(w).Encode(dto.SyncBatchResult{BatchId: received.BatchId, Items: []dto.SyncItemResult{}})
+		}))
+		defer server.Close()
+
+		client, err := NewCentralClient(server.URL, "token", time.Second)
+		require.NoError(t, err)
+
+		result, err := client.UploadBatch(context.Background(), batch)
+		require.NoError(t, err)
+		require.Equal(t, batch.BatchId, result.BatchId)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(dto.Error{Message: "invalid token"})
+		}))
+		defer server.Close()
+
+		client, err := NewCentralClient(server.URL, "token", time.Second)
+		require.NoError(t, err)
+
+		_, err = client.UploadBatch(context.Background(), batch)
+		require.ErrorContains(t, err, "invalid token")
+	})
+}
@@ -1,13 +1,16 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"log"
-	"os"
-	"strconv"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
+	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -16,69 +19,281 @@ const (
 	EnvTest = "test"
 )
 
+const (
+	LoggerFormatText = "text"
+	LoggerFormatJSON = "json"
+)
+
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+const (
+	StoragePostgres = "postgres"
+	StorageSQLite   = "sqlite"
+)
+
+const (
+	TracingNone   = "none"
+	TracingOTLP   = "otlp"
+	TracingStdout = "stdout"
+	TracingFile   = "file"
+)
+
+const (
+	DuplicateBarcodeReject         = "reject"
+	DuplicateBarcodeReturnExisting = "return_existing"
+)
+
+// redacted replaces secrets in printed config
+const redacted = "[REDACTED]"
+
+// Config is read from YAML file and environment. Every field has a YAML key and an environment
+// variable named as upper cased key, environment overrides file and defaults fill fields set by neither
 type Config struct {
-	DBParam
-	Env            string        `yaml:"env" env-required:"true"`
-	JWTDuration    time.Duration `yaml:"jwt_duration" env-required:"true"`
-	LoggerFormat   string        `yaml:"logger_format"`
-	MigrationDir   string        `yaml:"migrations_dir"`
-	RequestTimeout time.Duration `yaml:"request_timeout" env-default:"5s"`
-	ConnectionStr  string        `yaml:"-"`
-	JWTSecret      string        `yaml:"-"`
-	HTTPPort       int           `yaml:"-"`
-	GRPCPort       int           `yaml:"-"`
-	PrometheusPort int           `yaml:"-"`
+	DBParam           `yaml:",inline"`
+	Env               string        `yaml:"env" env:"ENV" env-default:"prod"`
+	JWTDuration       time.Duration `yaml:"jwt_duration" env:"JWT_DURATION" env-default:"1h"`
+	LoggerFormat      string        `yaml:"logger_format" env:"LOGGER_FORMAT" env-default:"json"`
+	LogLevel          string        `yaml:"log_level" env:"LOG_LEVEL"`
+	MigrationDir      string        `yaml:"migrations_dir" env:"MIGRATIONS_DIR" env-default:"./migrations"`
+	RequestTimeout    time.Duration `yaml:"request_timeout" env:"REQUEST_TIMEOUT" env-default:"5s"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"15s"`
+	HealthInterval    time.Duration `yaml:"health_interval" env:"HEALTH_INTERVAL" env-default:"5s"`
+	Storage           string        `yaml:"storage" env:"STORAGE" env-default:"postgres"`
+	SQLitePath        string        `yaml:"sqlite_path" env:"SQLITE_PATH" env-default:"./pvz.db"`
+	SyncCentralURL    string        `yaml:"sync_central_url" env:"SYNC_CENTRAL_URL"`
+	SyncInterval      time.Duration `yaml:"sync_interval" env:"SYNC_INTERVAL" env-default:"30s"`
+	SyncBatchSize     int           `yaml:"sync_batch_size" env:"SYNC_BATCH_SIZE" env-default:"100"`
+	DuplicateBarcode  string        `yaml:"duplicate_barcode" env:"DUPLICATE_BARCODE" env-default:"reject"`
+	ProductBatchLimit int           `yaml:"product_batch_limit" env:"PRODUCT_BATCH_LIMIT" env-default:"100"`
+	IdempotencyTTL    time.Duration `yaml:"idempotency_ttl" env:"IDEMPOTENCY_TTL" env-default:"24h"`
+	// IdempotencyLease is how long key stays reserved by request in progress, e.g. after crash of the instance
+	IdempotencyLease  time.Duration `yaml:"idempotency_lease" env:"IDEMPOTENCY_LEASE" env-default:"1m"`
+	PickupCodeTTL     time.Duration `yaml:"pickup_code_ttl" env:"PICKUP_CODE_TTL" env-default:"72h"`
+	PickupMaxAttempts int           `yaml:"pickup_max_attempts" env:"PICKUP_MAX_ATTEMPTS" env-default:"5"`
+	PickupLockout     time.Duration `yaml:"pickup_lockout" env:"PICKUP_LOCKOUT" env-default:"15m"`
+	// RouteTimeouts overrides RequestTimeout for routes keyed by method and pattern, e.g. "POST /products/batch"
+	RouteTimeouts map[string]time.Duration `yaml:"route_timeouts" env:"ROUTE_TIMEOUTS"`
+	// StoragePeriods sets storage period by product type, other types use StoragePeriodDefault
+	StoragePeriods       map[string]time.Duration `yaml:"storage_periods" env:"STORAGE_PERIODS"`
+	StoragePeriodDefault time.Duration            `yaml:"storage_period_default" env:"STORAGE_PERIOD_DEFAULT" env-default:"336h"`
+	ExpiryInterval       time.Duration            `yaml:"expiry_interval" env:"EXPIRY_INTERVAL" env-default:"1h"`
+	// IdempotencyCleanup is interval of deleting expired idempotency keys
+	IdempotencyCleanup time.Duration `yaml:"idempotency_cleanup_interval" env:"IDEMPOTENCY_CLEANUP_INTERVAL" env-default:"1h"`
+	// DBStartupTimeout is how long application waits for database to become reachable on start
+	DBStartupTimeout    time.Duration `yaml:"db_startup_timeout" env:"DB_STARTUP_TIMEOUT" env-default:"1m"`
+	DBMaxConns          int32         `yaml:"db_max_conns" env:"DB_MAX_CONNS" env-default:"10"`
+	DBMinConns          int32         `yaml:"db_min_conns" env:"DB_MIN_CONNS" env-default:"0"`
+	DBMaxConnLifetime   time.Duration `yaml:"db_max_conn_lifetime" env:"DB_MAX_CONN_LIFETIME" env-default:"1h"`
+	DBMaxConnIdleTime   time.Duration `yaml:"db_max_conn_idle_time" env:"DB_MAX_CONN_IDLE_TIME" env-default:"30m"`
+	DBHealthCheckPeriod time.Duration `yaml:"db_health_check_period" env:"DB_HEALTH_CHECK_PERIOD" env-default:"1m"`
+	DBConnectTimeout    time.Duration `yaml:"db_connect_timeout" env:"DB_CONNECT_TIMEOUT" env-default:"5s"`
+	// TracingExporter sends spans to OTLP gRPC collector at TracingEndpoint or writes them to stdout or TracingFile
+	TracingExporter    string  `yaml:"tracing_exporter" env:"TRACING_EXPORTER" env-default:"none"`
+	TracingEndpoint    string  `yaml:"tracing_endpoint" env:"TRACING_ENDPOINT"`
+	TracingFile        string  `yaml:"tracing_file" env:"TRACING_FILE" env-default:"./traces.json"`
+	TracingSampleRatio float64 `yaml:"tracing_sample_ratio" env:"TRACING_SAMPLE_RATIO" env-default:"1"`
+	SyncToken          string  `yaml:"sync_token" env:"SYNC_TOKEN"`
+	JWTSecret          string  `yaml:"jwt_secret" env:"JWT_SECRET"`
+	HTTPPort           int     `yaml:"http_port" env:"HTTP_PORT" env-default:"8080"`
+	GRPCPort           int     `yaml:"grpc_port" env:"GRPC_PORT" env-default:"3000"`
+	PrometheusPort     int     `yaml:"prometheus_port" env:"PROMETHEUS_PORT" env-default:"9000"`
+	// ConnectionStr is built from DBParam
+	ConnectionStr string `yaml:"-"`
 }
 
 type DBParam struct {
-	DBUser     string
-	DBPassword string
-	DBHost     string
-	DBPort     string
-	DBName     string
+	DBUser     string `yaml:"database_user" env:"DATABASE_USER"`
+	DBPassword string `yaml:"database_password" env:"DATABASE_PASSWORD"`
+	DBHost     string `yaml:"database_host" env:"DATABASE_HOST" env-default:"localhost"`
+	DBPort     string `yaml:"database_port" env:"DATABASE_PORT" env-default:"5432"`
+	DBName     string `yaml:"database_name" env:"DATABASE_NAME"`
 }
 
 func (p *DBParam) GetConnStr() string {
 	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", p.DBUser, p.DBPassword, p.DBHost, p.DBPort, p.DBName)
 }
 
-func MustLoad(configPath string) *Config {
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		log.Fatalf("config file does not exist: %s", configPath)
+// Load reads config and validates it, all problems found are returned at once
+func Load(configPath string) (*Config, error) {
+	cfg, err := Read(configPath)
+	if err != nil {
+		return nil, err
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+
+	return cfg, nil
+}
+
+// Read reads config from YAML file and environment without validation.
+// Empty configPath reads environment only
+func Read(configPath string) (*Config, error) {
 	var cfg Config
-	if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
-		log.Fatalf("cannot read config: %s", err)
+	if configPath == "" {
+		if err := cleanenv.ReadEnv(&cfg); err != nil {
+			return nil, fmt.Errorf("cannot read config from environment: %w", err)
+		}
+	} else if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
+		return nil, fmt.Errorf("cannot read config %s: %w", configPath, err)
 	}
 
-	httpPort, err := strconv.Atoi(os.Getenv("HTTP_PORT"))
-	if err != nil {
-		log.Fatalf("failed to read HTTP port from env: %s", err)
+	cfg.ConnectionStr = cfg.GetConnStr()
+
+	return &cfg, nil
+}
+
+// Validate checks values which can not be checked by parsing
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key string, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+		}
+	}
+	oneOf := func(value string, key string, allowed ...string) {
+		for _, a := range allowed {
+			if value == a {
+				return
+			}
+		}
+		check(false, key, "must be one of %q, got %q", allowed, value)
+	}
+	positive := func(d time.Duration, key string) {
+		check(d > 0, key, "must be positive duration, got %s", d)
+	}
+	notNegative := func(d time.Duration, key string) {
+		check(d >= 0, key, "must not be negative, got %s", d)
 	}
-	cfg.HTTPPort = httpPort
 
-	grpcPort, err := strconv.Atoi(os.Getenv("GRPC_PORT"))
-	if err != nil {
-		log.Fatalf("failed to read gRPC port from env: %s", err)
+	oneOf(c.Env, "env", EnvDev, EnvProd, EnvTest)
+	oneOf(c.LoggerFormat, "logger_format", LoggerFormatText, LoggerFormatJSON)
+	oneOf(c.LogLevel, "log_level", "", LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError)
+	oneOf(c.Storage, "storage", StoragePostgres, StorageSQLite)
+	oneOf(c.DuplicateBarcode, "duplicate_barcode", DuplicateBarcodeReject, DuplicateBarcodeReturnExisting)
+	oneOf(c.TracingExporter, "tracing_exporter", TracingNone, TracingOTLP, TracingStdout, TracingFile)
+
+	check(c.JWTSecret != "" || c.Env != EnvProd, "jwt_secret", "must not be empty in %s", EnvProd)
+	check(c.MigrationDir != "", "migrations_dir", "must not be empty")
+	switch c.Storage {
+	case StoragePostgres:
+		check(c.DBHost != "", "database_host", "must not be empty for %s storage", StoragePostgres)
+		check(c.DBName != "", "database_name", "must not be empty for %s storage", StoragePostgres)
+		check(c.DBUser != "", "database_user", "must not be empty for %s storage", StoragePostgres)
+	case StorageSQLite:
+		check(c.SQLitePath != "", "sqlite_path", "must not be empty for %s storage", StorageSQLite)
 	}
-	cfg.GRPCPort = grpcPort
 
-	promPort, err := strconv.Atoi(os.Getenv("PROMETHEUS_PORT"))
-	if err != nil {
-		log.Fatalf("failed to read Prometheus port from env: %s", err)
+	ports := map[int]string{}
+	for _, port := range []struct {
+		key   string
+		value int
+	}{{"http_port", c.HTTPPort}, {"grpc_port", c.GRPCPort}, {"prometheus_port", c.PrometheusPort}} {
+		check(port.value > 0 && port.value <= 65535, port.key, "must be in range 1-65535, got %d", port.value)
+		if other, ok := ports[port.value]; ok {
+			check(false, port.key, "port %d is already used by %s", port.value, other)
+		}
+		ports[port.value] = port.key
 	}
-	cfg.PrometheusPort = promPort
 
-	cfg.JWTSecret = os.Getenv("JWT_SECRET")
+	positive(c.JWTDuration, "jwt_duration")
+	positive(c.RequestTimeout, "request_timeout")
+	positive(c.ShutdownTimeout, "shutdown_timeout")
+	positive(c.HealthInterval, "health_interval")
+	positive(c.SyncInterval, "sync_interval")
+	positive(c.IdempotencyTTL, "idempotency_ttl")
+	positive(c.IdempotencyLease, "idempotency_lease")
+	positive(c.IdempotencyCleanup, "idempotency_cleanup_interval")
+	positive(c.PickupCodeTTL, "pickup_code_ttl")
+	positive(c.PickupLockout, "pickup_lockout")
+	positive(c.StoragePeriodDefault, "storage_period_default")
+	positive(c.ExpiryInterval, "expiry_interval")
+	positive(c.DBStartupTimeout, "db_startup_timeout")
+	notNegative(c.DBMaxConnLifetime, "db_max_conn_lifetime")
+	notNegative(c.DBMaxConnIdleTime, "db_max_conn_idle_time")
+	notNegative(c.DBHealthCheckPeriod, "db_health_check_period")
+	notNegative(c.DBConnectTimeout, "db_connect_timeout")
+	for route, timeout := range c.RouteTimeouts {
+		positive(timeout, fmt.Sprintf("route_timeouts[%q]", route))
+	}
+	for productType, period := range c.StoragePeriods {
+		positive(period, fmt.Sprintf("storage_periods[%q]", productType))
+	}
 
-	// DBParams
-	cfg.DBPassword = os.Getenv("DATABASE_PASSWORD")
-	cfg.DBUser = os.Getenv("DATABASE_USER")
-	cfg.DBHost = os.Getenv("DATABASE_HOST")
-	cfg.DBPort = os.Getenv("DATABASE_PORT")
-	cfg.DBName = os.Getenv("DATABASE_NAME")
-	cfg.ConnectionStr = cfg.GetConnStr()
+	check(c.SyncBatchSize > 0, "sync_batch_size", "must be positive, got %d", c.SyncBatchSize)
+	check(c.ProductBatchLimit > 0, "product_batch_limit", "must be positive, got %d", c.ProductBatchLimit)
+	check(c.PickupMaxAttempts > 0, "pickup_max_attempts", "must be positive, got %d", c.PickupMaxAttempts)
+	check(c.DBMaxConns > 0, "db_max_conns", "must be positive, got %d", c.DBMaxConns)
+	check(c.DBMinConns >= 0 && c.DBMinConns <= c.DBMaxConns, "db_min_conns", "must be in range 0-%d, got %d", c.DBMaxConns, c.DBMinConns)
+	check(c.TracingSampleRatio >= 0 && c.TracingSampleRatio <= 1, "tracing_sample_ratio", "must be in range 0-1, got %v", c.TracingSampleRatio)
+	check(c.TracingExporter != TracingFile || c.TracingFile != "", "tracing_file", "must not be empty for %s exporter", TracingFile)
+
+	if c.SyncCentralURL != "" {
+		u, err := url.Parse(c.SyncCentralURL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"sync_central_url", "must be absolute http or https url, got %q", c.SyncCentralURL)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Redacted returns copy of config with secrets hidden, so it can be printed or logged
+func (c Config) Redacted() Config {
+	for _, secret := range []*string{&c.JWTSecret, &c.SyncToken, &c.DBPassword} {
+		if *secret != "" {
+			*secret = redacted
+		}
+	}
+	c.ConnectionStr = ""
+
+	return c
+}
+
+// WriteYAML writes config with secrets redacted in format of config file
+func (c *Config) WriteYAML(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// reloadable are keys of fields which are applied to running application on reload
+var reloadable = map[string]bool{
+	"log_level":           true,
+	"logger_format":       true,
+	"request_timeout":     true,
+	"route_timeouts":      true,
+	"duplicate_barcode":   true,
+	"product_batch_limit": true,
+}
+
+// RestartRequired returns keys of fields which differ in next config, but take effect only after restart
+func (c *Config) RestartRequired(next *Config) []string {
+	var keys []string
+	var compare func(current, next reflect.Value)
+	compare = func(current, next reflect.Value) {
+		for i := range current.NumField() {
+			field := current.Type().Field(i)
+			key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			switch {
+			case field.Anonymous:
+				compare(current.Field(i), next.Field(i))
+			case key == "" || key == "-" || reloadable[key]:
+			case !reflect.DeepEqual(current.Field(i).Interface(), next.Field(i).Interface()):
+				keys = append(keys, key)
+			}
+		}
+	}
+	compare(reflect.ValueOf(*c), reflect.ValueOf(*next))
 
-	return &cfg
+	return keys
 }
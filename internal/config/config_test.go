@@ -0,0 +1,250 @@
+package config
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func validConfig() *Config {
+	return &Config{
+		DBParam:              DBParam{DBUser: "user", DBHost: "localhost", DBPort: "5432", DBName: "pvz"},
+		Env:                  EnvProd,
+		JWTDuration:          time.Hour,
+		LoggerFormat:         LoggerFormatJSON,
+		MigrationDir:         "./migrations",
+		RequestTimeout:       5 * time.Second,
+		ShutdownTimeout:      15 * time.Second,
+		HealthInterval:       5 * time.Second,
+		Storage:              StoragePostgres,
+		SyncInterval:         30 * time.Second,
+		SyncBatchSize:        100,
+		DuplicateBarcode:     DuplicateBarcodeReject,
+		ProductBatchLimit:    100,
+		IdempotencyTTL:       24 * time.Hour,
+		IdempotencyLease:     time.Minute,
+		IdempotencyCleanup:   time.Hour,
+		PickupCodeTTL:        72 * time.Hour,
+		PickupMaxAttempts:    5,
+		PickupLockout:        15 * time.Minute,
+		StoragePeriodDefault: 336 * time.Hour,
+		ExpiryInterval:       time.Hour,
+		DBStartupTimeout:     time.Minute,
+		DBMaxConns:           10,
+		DBConnectTimeout:     5 * time.Second,
+		TracingExporter:      TracingNone,
+		TracingSampleRatio:   1,
+		JWTSecret:            "secret",
+		HTTPPort:             8080,
+		GRPCPort:             3000,
+		PrometheusPort:       9000,
+	}
+}
+
+func TestRead_Precedence(t *testing.T) {
+	// arrange
+	path := writeConfig(t, `
+env: dev
+request_timeout: 3s
+http_port: 8081
+grpc_port: 3001
+`)
+	t.Setenv("HTTP_PORT", "8082")
+
+	// act
+	cfg, err := Read(path)
+
+	// assert
+	require.NoError(t, err)
+	// environment overrides file
+	require.Equal(t, 8082, cfg.HTTPPort)
+	// file overrides default
+	require.Equal(t, 3001, cfg.GRPCPort)
+	require.Equal(t, 3*time.Second, cfg.RequestTimeout)
+	require.Equal(t, EnvDev, cfg.Env)
+	// default fills fields set by neither
+	require.Equal(t, 9000, cfg.PrometheusPort)
+	require.Equal(t, 15*time.Second, cfg.ShutdownTimeout)
+}
+
+func TestRead_EnvOnly(t *testing.T) {
+	// arrange
+	t.Setenv("ENV", EnvTest)
+	t.Setenv("STORAGE", StorageSQLite)
+	t.Setenv("SQLITE_PATH", "/tmp/pvz.db")
+	t.Setenv("DATABASE_USER", "user")
+	t.Setenv("DATABASE_NAME", "pvz")
+	t.Setenv("ROUTE_TIMEOUTS", "POST /products/batch:30s")
+
+	// act
+	cfg, err := Load("")
+
+	// assert
+	require.NoError(t, err)
+	require.Equal(t, EnvTest, cfg.Env)
+	require.Equal(t, StorageSQLite, cfg.Storage)
+	require.Equal(t, "/tmp/pvz.db", cfg.SQLitePath)
+	require.Equal(t, map[string]time.Duration{"POST /products/batch": 30 * time.Second}, cfg.RouteTimeouts)
+	require.Equal(t, "postgres://user:@localhost:5432/pvz?sslmode=disable", cfg.ConnectionStr)
+}
+
+func TestRead_InvalidDuration(t *testing.T) {
+	path := writeConfig(t, "request_timeout: soon\n")
+
+	_, err := Read(path)
+
+	require.Error(t, err)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		require.NoError(t, validConfig().Validate())
+	})
+
+	t.Run("all problems are reported", func(t *testing.T) {
+		// arrange
+		cfg := validConfig()
+		cfg.JWTSecret = ""
+		cfg.GRPCPort = cfg.HTTPPort
+		cfg.PrometheusPort = 70000
+		cfg.RequestTimeout = 0
+		cfg.RouteTimeouts = map[string]time.Duration{"GET /pvz": -time.Second}
+		cfg.DuplicateBarcode = "ignore"
+		cfg.DBMinConns = 20
+		cfg.SyncCentralURL = "central:8080"
+
+		// act
+		err := cfg.Validate()
+
+		// assert
+		require.Error(t, err)
+		for _, key := range []string{
+			"jwt_secret", "grpc_port", "prometheus_port", "request_timeout",
+			`route_timeouts["GET /pvz"]`, "duplicate_barcode", "db_min_conns", "sync_central_url",
+		} {
+			require.Contains(t, err.Error(), key+":")
+		}
+		require.Len(t, strings.Split(err.Error(), "\n"), 8)
+	})
+
+	t.Run("empty jwt secret is allowed outside prod", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Env = EnvDev
+		cfg.JWTSecret = ""
+
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("sqlite does not need database params", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Storage = StorageSQLite
+		cfg.SQLitePath = "./pvz.db"
+		cfg.DBParam = DBParam{}
+
+		require.NoError(t, cfg.Validate())
+	})
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	// arrange
+	cfg := validConfig()
+	cfg.JWTSecret = "jwtSecret"
+	cfg.DBPassword = "dbPassword"
+	cfg.SyncToken = "syncToken"
+	cfg.ConnectionStr = cfg.GetConnStr()
+
+	// act
+	var buf bytes.Buffer
+	err := cfg.WriteYAML(&buf)
+
+	// assert
+	require.NoError(t, err)
+	out := buf.String()
+	for _, secret := range []string{"jwtSecret", "dbPassword", "syncToken"} {
+		require.NotContains(t, out, secret)
+	}
+	require.Contains(t, out, "jwt_secret: '"+redacted+"'")
+	require.Contains(t, out, "http_port: 8080")
+	// original config is not changed
+	require.Equal(t, "jwtSecret", cfg.JWTSecret)
+}
+
+func TestConfig_RestartRequired(t *testing.T) {
+	// arrange
+	current := validConfig()
+	next := validConfig()
+	next.LogLevel = LogLevelDebug
+	next.RequestTimeout = time.Second
+	next.RouteTimeouts = map[string]time.Duration{"POST /products/batch": time.Minute}
+	next.ProductBatchLimit = 10
+	next.HTTPPort = 8081
+	next.DBHost = "db"
+	next.ConnectionStr = next.GetConnStr()
+
+	// act
+	keys := current.RestartRequired(next)
+
+	// assert
+	require.Equal(t, []string{"database_host", "http_port"}, keys)
+	require.Empty(t, current.RestartRequired(validConfig()))
+}
+
+func TestLive(t *testing.T) {
+	// arrange
+	current := validConfig()
+	next := validConfig()
+	next.LogLevel = LogLevelWarn
+	next.LoggerFormat = LoggerFormatText
+	next.RequestTimeout = time.Second
+	next.DuplicateBarcode = DuplicateBarcodeReturnExisting
+	next.ProductBatchLimit = 10
+	live := NewLive(current)
+	before := live.Load()
+
+	// act
+	live.Store(next)
+
+	// assert
+	// empty level is chosen by env
+	require.Equal(t, slog.LevelInfo, before.LogLevel)
+	require.True(t, before.LogJSON)
+	// snapshot loaded before reload is not changed by it
+	require.Equal(t, 5*time.Second, before.RequestTimeout)
+	after := live.Load()
+	require.Equal(t, slog.LevelWarn, after.LogLevel)
+	require.False(t, after.LogJSON)
+	require.Equal(t, time.Second, after.RequestTimeout)
+	returnExisting, batchLimit := live.ProductPolicy()
+	require.True(t, returnExisting)
+	require.Equal(t, 10, batchLimit)
+}
+
+func TestRepositoryConfigs(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("DATABASE_USER", "user")
+	t.Setenv("DATABASE_NAME", "pvz")
+
+	paths, err := filepath.Glob("../../configs/*.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			_, err := Load(path)
+			require.NoError(t, err)
+		})
+	}
+}
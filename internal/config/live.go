@@ -0,0 +1,70 @@
+package config
+
+import (
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// Reloadable holds fields of config which are applied without restart
+type Reloadable struct {
+	RequestTimeout time.Duration
+	// RouteTimeouts overrides RequestTimeout for http routes keyed by method and pattern
+	RouteTimeouts map[string]time.Duration
+	LogLevel      slog.Level
+	LogJSON       bool
+	// ReturnExisting makes repeated scan of barcode return already added product
+	ReturnExisting    bool
+	ProductBatchLimit int
+}
+
+// Live holds reloadable fields of running application. They are read from it on every use
+// and replaced as a whole, so a request never sees fields of two different configs
+type Live struct {
+	current atomic.Pointer[Reloadable]
+}
+
+func NewLive(cfg *Config) *Live {
+	var l Live
+	l.Store(cfg)
+
+	return &l
+}
+
+// Load returns current reloadable fields, they must not be modified
+func (l *Live) Load() *Reloadable {
+	return l.current.Load()
+}
+
+// Store replaces reloadable fields with fields of validated cfg
+func (l *Live) Store(cfg *Config) {
+	l.current.Store(&Reloadable{
+		RequestTimeout:    cfg.RequestTimeout,
+		RouteTimeouts:     cfg.RouteTimeouts,
+		LogLevel:          cfg.logLevel(),
+		LogJSON:           cfg.LoggerFormat == LoggerFormatJSON,
+		ReturnExisting:    cfg.DuplicateBarcode == DuplicateBarcodeReturnExisting,
+		ProductBatchLimit: cfg.ProductBatchLimit,
+	})
+}
+
+// ProductPolicy returns handling of duplicate barcodes and batch limit from one snapshot
+func (l *Live) ProductPolicy() (returnExisting bool, batchLimit int) {
+	current := l.Load()
+	return current.ReturnExisting, current.ProductBatchLimit
+}
+
+// logLevel returns configured level, empty level is chosen by env
+func (c *Config) logLevel() slog.Level {
+	level := slog.LevelInfo
+	switch c.Env {
+	case EnvTest, EnvDev:
+		level = slog.LevelDebug
+	}
+	if c.LogLevel != "" {
+		// level is checked by validation, default is kept for unchecked config
+		_ = level.UnmarshalText([]byte(c.LogLevel))
+	}
+
+	return level
+}
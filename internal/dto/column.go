@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Value stores manifest in JSON column
+func (m ReceptionManifest) Value() (driver.Value, error) {
+	return jsonValue(m)
+}
+
+func (m *ReceptionManifest) Scan(src any) error {
+	return scanJSON(src, m)
+}
+
+// Value stores discrepancy report in JSON column
+func (r DiscrepancyReport) Value() (driver.Value, error) {
+	return jsonValue(r)
+}
+
+func (r *DiscrepancyReport) Scan(src any) error {
+	return scanJSON(src, r)
+}
+
+// Value stores inventory report in JSON column
+func (r InventoryReport) Value() (driver.Value, error) {
+	return jsonValue(r)
+}
+
+func (r *InventoryReport) Scan(src any) error {
+	return scanJSON(src, r)
+}
+
+func jsonValue(v any) (driver.Value, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(raw), nil
+}
+
+func scanJSON(src any, dst any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, dst)
+	case string:
+		return json.Unmarshal([]byte(v), dst)
+	default:
+		return fmt.Errorf("unsupported type %T for JSON column", src)
+	}
+}
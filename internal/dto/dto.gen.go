@@ -13,6 +13,18 @@ const (
 	BearerAuthScopes = "bearerAuth.Scopes"
 )
 
+// Defines values for InventoryStatus.
+const (
+	InventoryCompleted  InventoryStatus = "completed"
+	InventoryInProgress InventoryStatus = "in_progress"
+)
+
+// Defines values for OrderStatus.
+const (
+	OrderCreated OrderStatus = "created"
+	OrderIssued  OrderStatus = "issued"
+)
+
 // Defines values for PVZCity.
 const (
 	Kazan           PVZCity = "Казань"
@@ -27,12 +39,67 @@ const (
 	ProductTypeShoes       ProductType = "обувь"
 )
 
+// Defines values for ProductBatchItemResultStatus.
+const (
+	BatchCreated  ProductBatchItemResultStatus = "created"
+	BatchExisting ProductBatchItemResultStatus = "existing"
+	BatchFailed   ProductBatchItemResultStatus = "failed"
+)
+
+// Defines values for ProductStatus.
+const (
+	ProductIssued           ProductStatus = "issued"
+	ProductReceived         ProductStatus = "received"
+	ProductReturnedToSender ProductStatus = "returned_to_sender"
+	ProductStored           ProductStatus = "stored"
+	ProductTransferred      ProductStatus = "transferred"
+)
+
+// Defines values for ProductTypeCountType.
+const (
+	CountClothes     ProductTypeCountType = "одежда"
+	CountElectronics ProductTypeCountType = "электроника"
+	CountShoes       ProductTypeCountType = "обувь"
+)
+
 // Defines values for ReceptionStatus.
 const (
 	Close      ReceptionStatus = "close"
 	InProgress ReceptionStatus = "in_progress"
 )
 
+// Defines values for SyncItemKind.
+const (
+	KindProductAdded         SyncItemKind = "product_added"
+	KindProductDeleted       SyncItemKind = "product_deleted"
+	KindProductStatusChanged SyncItemKind = "product_status_changed"
+	KindPvzCreated           SyncItemKind = "pvz_created"
+	KindReceptionClosed      SyncItemKind = "reception_closed"
+	KindReceptionCreated     SyncItemKind = "reception_created"
+)
+
+// Defines values for SyncItemResultStatus.
+const (
+	SyncApplied   SyncItemResultStatus = "applied"
+	SyncDuplicate SyncItemResultStatus = "duplicate"
+	SyncFailed    SyncItemResultStatus = "failed"
+	SyncResolved  SyncItemResultStatus = "resolved"
+)
+
+// Defines values for SyncOutboxItemStatus.
+const (
+	OutboxFailed  SyncOutboxItemStatus = "failed"
+	OutboxPending SyncOutboxItemStatus = "pending"
+	OutboxSynced  SyncOutboxItemStatus = "synced"
+)
+
+// Defines values for TransferStatus.
+const (
+	TransferCreated   TransferStatus = "created"
+	TransferInTransit TransferStatus = "in_transit"
+	TransferReceived  TransferStatus = "received"
+)
+
 // Defines values for UserRole.
 const (
 	UserRoleEmployee  UserRole = "employee"
@@ -52,17 +119,134 @@ const (
 	PostProductsJSONBodyTypeShoes       PostProductsJSONBodyType = "обувь"
 )
 
+// Defines values for GetPvzPvzIdReceptionsParamsStatus.
+const (
+	StatusFilterClose      GetPvzPvzIdReceptionsParamsStatus = "close"
+	StatusFilterInProgress GetPvzPvzIdReceptionsParamsStatus = "in_progress"
+)
+
+// Defines values for GetPvzPvzIdTransfersParamsDirection.
+const (
+	TransferIncoming GetPvzPvzIdTransfersParamsDirection = "incoming"
+	TransferOutgoing GetPvzPvzIdTransfersParamsDirection = "outgoing"
+)
+
 // Defines values for PostRegisterJSONBodyRole.
 const (
 	Employee  PostRegisterJSONBodyRole = "employee"
 	Moderator PostRegisterJSONBodyRole = "moderator"
 )
 
+// Cell defines model for Cell.
+type Cell struct {
+	Capacity int `json:"capacity"`
+
+	// Code Код стеллажа и ячейки, например A-01-03
+	Code string             `json:"code"`
+	Id   openapi_types.UUID `json:"id"`
+
+	// Occupied Количество товаров в статусах received и stored в ячейке
+	Occupied *int               `json:"occupied,omitempty"`
+	PvzId    openapi_types.UUID `json:"pvzId"`
+}
+
+// DiscrepancyItem defines model for DiscrepancyItem.
+type DiscrepancyItem struct {
+	Expected int         `json:"expected"`
+	Missing  int         `json:"missing"`
+	Received int         `json:"received"`
+	Surplus  int         `json:"surplus"`
+	Type     ProductType `json:"type"`
+}
+
+// DiscrepancyReport Расхождения между ожидаемым и принятым составом на момент закрытия приемки
+type DiscrepancyReport struct {
+	HasDiscrepancies bool              `json:"hasDiscrepancies"`
+	Items            []DiscrepancyItem `json:"items"`
+
+	// MissingBarcodes Штрихкоды ожидаемого состава, товары с которыми не были приняты
+	MissingBarcodes []string `json:"missingBarcodes,omitempty"`
+
+	// SurplusBarcodes Штрихкоды принятых товаров, которых нет в ожидаемом составе
+	SurplusBarcodes []string `json:"surplusBarcodes,omitempty"`
+
+	// SurplusWithoutBarcode Количество принятых товаров без штрихкода при ожидаемом составе из штрихкодов
+	SurplusWithoutBarcode int `json:"surplusWithoutBarcode,omitempty"`
+}
+
 // Error defines model for Error.
 type Error struct {
 	Message string `json:"message"`
 }
 
+// HealthStatus defines model for HealthStatus.
+type HealthStatus struct {
+	Status string `json:"status"`
+}
+
+// Inventory defines model for Inventory.
+type Inventory struct {
+	CompletedAt  *time.Time          `json:"completedAt,omitempty"`
+	CompletedBy  *openapi_types.UUID `json:"completedBy,omitempty"`
+	Id           openapi_types.UUID  `json:"id"`
+	PvzId        openapi_types.UUID  `json:"pvzId"`
+	Report       *InventoryReport    `json:"report,omitempty"`
+	ScannedCount int                 `json:"scannedCount"`
+	StartedAt    time.Time           `json:"startedAt"`
+	StartedBy    *openapi_types.UUID `json:"startedBy,omitempty"`
+	Status       InventoryStatus     `json:"status"`
+}
+
+// InventoryReport defines model for InventoryReport.
+type InventoryReport struct {
+	// ExpectedCount Количество товаров ПВЗ в статусах received и stored на момент завершения
+	ExpectedCount int `json:"expectedCount"`
+
+	// Missing Товары, которые числятся в ПВЗ, но не были отсканированы
+	Missing      []Product `json:"missing"`
+	ScannedCount int       `json:"scannedCount"`
+
+	// Unexpected Отсканированные товары, которые не числятся в ПВЗ
+	Unexpected []InventoryScan `json:"unexpected"`
+}
+
+// InventoryScan defines model for InventoryScan.
+type InventoryScan struct {
+	Barcode   *string             `json:"barcode,omitempty"`
+	ProductId *openapi_types.UUID `json:"productId,omitempty"`
+	ScannedAt time.Time           `json:"scannedAt"`
+}
+
+// InventoryStatus defines model for InventoryStatus.
+type InventoryStatus string
+
+// ManifestProgress Ход приемки относительно ожидаемого состава по типу товара
+type ManifestProgress struct {
+	Expected  int         `json:"expected"`
+	Received  int         `json:"received"`
+	Remaining int         `json:"remaining"`
+	Type      ProductType `json:"type"`
+}
+
+// Order defines model for Order.
+type Order struct {
+	CreatedAt time.Time           `json:"createdAt"`
+	CreatedBy *openapi_types.UUID `json:"createdBy,omitempty"`
+
+	// Customer Получатель заказа, например номер телефона
+	Customer            string               `json:"customer"`
+	Id                  openapi_types.UUID   `json:"id"`
+	IssuedAt            *time.Time           `json:"issuedAt,omitempty"`
+	IssuedBy            *openapi_types.UUID  `json:"issuedBy,omitempty"`
+	PickupCodeExpiresAt *time.Time           `json:"pickupCodeExpiresAt,omitempty"`
+	ProductIds          []openapi_types.UUID `json:"productIds"`
+	PvzId               openapi_types.UUID   `json:"pvzId"`
+	Status              OrderStatus          `json:"status"`
+}
+
+// OrderStatus defines model for Order.Status.
+type OrderStatus string
+
 // PVZ defines model for PVZ.
 type PVZ struct {
 	City             PVZCity             `json:"city" validate:"oneof=Москва Санкт-Петербург Казань"`
@@ -79,37 +263,240 @@ type PVZWithReceptions struct {
 	Receptions []ReceptionWithProducts `json:"receptions,omitempty"`
 }
 
+// PickupCode defines model for PickupCode.
+type PickupCode struct {
+	// Code Одноразовый код выдачи, возвращается только при генерации
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
 // Product defines model for Product.
 type Product struct {
-	DateTime    *time.Time          `json:"dateTime,omitempty"`
+	// Barcode Штрихкод товара
+	Barcode  *string    `json:"barcode,omitempty"`
+	DateTime *time.Time `json:"dateTime,omitempty"`
+
+	// ExpiredAt Время истечения срока хранения, товар попадает в список на возврат отправителю
+	ExpiredAt   *time.Time          `json:"expiredAt,omitempty"`
 	Id          *openapi_types.UUID `json:"id,omitempty"`
+	IssuedAt    *time.Time          `json:"issuedAt,omitempty"`
 	ReceptionId openapi_types.UUID  `json:"receptionId"`
-	Type        ProductType         `json:"type"`
+	ReturnedAt  *time.Time          `json:"returnedAt,omitempty"`
+
+	// Status Статус товара, допустимые переходы:
+	// received -> stored -> issued -> returned_to_sender.
+	// Статус transferred устанавливается при отправке товара в другой ПВЗ,
+	// товар с истекшим сроком хранения возвращается отправителю через список на возврат
+	Status        *ProductStatus `json:"status,omitempty"`
+	StoredAt      *time.Time     `json:"storedAt,omitempty"`
+	SuggestedCell *Cell          `json:"suggestedCell,omitempty"`
+	Type          ProductType    `json:"type"`
 }
 
 // ProductType defines model for Product.Type.
 type ProductType string
 
+// ProductBatchItem defines model for ProductBatchItem.
+type ProductBatchItem struct {
+	Barcode *string     `json:"barcode,omitempty"`
+	Type    ProductType `json:"type"`
+}
+
+// ProductBatchItemResult defines model for ProductBatchItemResult.
+type ProductBatchItemResult struct {
+	// Index Номер товара в запросе, начиная с 0
+	Index   int                          `json:"index"`
+	Message *string                      `json:"message,omitempty"`
+	Product *Product                     `json:"product,omitempty"`
+	Status  ProductBatchItemResultStatus `json:"status"`
+}
+
+// ProductBatchItemResultStatus defines model for ProductBatchItemResult.Status.
+type ProductBatchItemResultStatus string
+
+// ProductBatchResult defines model for ProductBatchResult.
+type ProductBatchResult struct {
+	Results []ProductBatchItemResult `json:"results"`
+}
+
+// ProductLocation defines model for ProductLocation.
+type ProductLocation struct {
+	Cell     *Cell              `json:"cell,omitempty"`
+	PlacedAt *time.Time         `json:"placedAt,omitempty"`
+	Product  Product            `json:"product"`
+	PvzId    openapi_types.UUID `json:"pvzId"`
+}
+
+// ProductPathStep defines model for ProductPathStep.
+type ProductPathStep struct {
+	Product Product            `json:"product"`
+	PvzId   openapi_types.UUID `json:"pvzId"`
+
+	// TransferId Перемещение, в которое включен товар
+	TransferId     *openapi_types.UUID `json:"transferId,omitempty"`
+	TransferStatus *TransferStatus     `json:"transferStatus,omitempty"`
+}
+
+// ProductStatus Статус товара, допустимые переходы:
+// received -> stored -> issued -> returned_to_sender.
+// Статус transferred устанавливается при отправке товара в другой ПВЗ,
+// товар с истекшим сроком хранения возвращается отправителю через список на возврат
+type ProductStatus string
+
+// ProductTypeCount defines model for ProductTypeCount.
+type ProductTypeCount struct {
+	Count int                  `json:"count"`
+	Type  ProductTypeCountType `json:"type"`
+}
+
+// ProductTypeCountType defines model for ProductTypeCount.Type.
+type ProductTypeCountType string
+
 // Reception defines model for Reception.
 type Reception struct {
-	DateTime time.Time          `json:"dateTime"`
-	Id       openapi_types.UUID `json:"id,omitempty"`
-	PvzId    openapi_types.UUID `json:"pvzId"`
-	Status   ReceptionStatus    `json:"status"`
+	// ClosedAt Время закрытия приемки
+	ClosedAt *time.Time `json:"closedAt,omitempty"`
+
+	// ClosedBy Пользователь, закрывший приемку
+	ClosedBy *openapi_types.UUID `json:"closedBy,omitempty"`
+	DateTime time.Time           `json:"dateTime"`
+
+	// DiscrepancyReport Расхождения между ожидаемым и принятым составом на момент закрытия приемки
+	DiscrepancyReport *DiscrepancyReport `json:"discrepancyReport,omitempty"`
+	Id                openapi_types.UUID `json:"id,omitempty"`
+
+	// Manifest Ожидаемый состав поставки - количество товаров по типам и/или список штрихкодов
+	Manifest *ReceptionManifest `json:"manifest,omitempty"`
+
+	// OpenedBy Пользователь, открывший приемку
+	OpenedBy *openapi_types.UUID `json:"openedBy,omitempty"`
+	PvzId    openapi_types.UUID  `json:"pvzId"`
+	Status   ReceptionStatus     `json:"status"`
 }
 
 // ReceptionStatus defines model for Reception.Status.
 type ReceptionStatus string
 
+// ReceptionDetails defines model for ReceptionDetails.
+type ReceptionDetails struct {
+	// MissingBarcodes Штрихкоды ожидаемого состава, товары с которыми еще не приняты
+	MissingBarcodes *[]string          `json:"missingBarcodes,omitempty"`
+	ProductCounts   []ProductTypeCount `json:"productCounts"`
+	Products        []Product          `json:"products"`
+
+	// Progress Ход приемки, заполняется для приемок с ожидаемым составом
+	Progress  *[]ManifestProgress `json:"progress,omitempty"`
+	Reception Reception           `json:"reception"`
+}
+
+// ReceptionManifest Ожидаемый состав поставки - количество товаров по типам и/или список штрихкодов
+type ReceptionManifest struct {
+	// Barcodes Штрихкоды ожидаемых товаров
+	Barcodes []string           `json:"barcodes,omitempty"`
+	Items    []ProductTypeCount `json:"items,omitempty"`
+}
+
 // ReceptionWithProducts defines model for ReceptionWithProducts.
 type ReceptionWithProducts struct {
 	Products  []Product `json:"products"`
 	Reception Reception `json:"reception"`
 }
 
+// SyncBatch defines model for SyncBatch.
+type SyncBatch struct {
+	BatchId openapi_types.UUID `json:"batchId"`
+	Items   []SyncItem         `json:"items"`
+}
+
+// SyncBatchResult defines model for SyncBatchResult.
+type SyncBatchResult struct {
+	BatchId openapi_types.UUID `json:"batchId"`
+	Items   []SyncItemResult   `json:"items"`
+}
+
+// SyncItem defines model for SyncItem.
+type SyncItem struct {
+	CreatedAt time.Time          `json:"createdAt"`
+	Id        openapi_types.UUID `json:"id"`
+	Kind      SyncItemKind       `json:"kind"`
+	Product   *Product           `json:"product,omitempty"`
+	Pvz       *PVZ               `json:"pvz,omitempty"`
+	Reception *Reception         `json:"reception,omitempty"`
+}
+
+// SyncItemKind defines model for SyncItem.Kind.
+type SyncItemKind string
+
+// SyncItemResult defines model for SyncItemResult.
+type SyncItemResult struct {
+	Id      openapi_types.UUID   `json:"id"`
+	Message *string              `json:"message,omitempty"`
+	Status  SyncItemResultStatus `json:"status"`
+}
+
+// SyncItemResultStatus defines model for SyncItemResult.Status.
+type SyncItemResultStatus string
+
+// SyncOutboxItem defines model for SyncOutboxItem.
+type SyncOutboxItem struct {
+	Attempts  int                  `json:"attempts"`
+	CreatedAt time.Time            `json:"createdAt"`
+	Id        openapi_types.UUID   `json:"id"`
+	Kind      string               `json:"kind"`
+	LastError *string              `json:"lastError,omitempty"`
+	Status    SyncOutboxItemStatus `json:"status"`
+}
+
+// SyncOutboxItemStatus defines model for SyncOutboxItem.Status.
+type SyncOutboxItemStatus string
+
+// SyncStatus defines model for SyncStatus.
+type SyncStatus struct {
+	Failed     int              `json:"failed"`
+	Items      []SyncOutboxItem `json:"items"`
+	LastError  *string          `json:"lastError,omitempty"`
+	LastSyncAt *time.Time       `json:"lastSyncAt,omitempty"`
+	Pending    int              `json:"pending"`
+	Synced     int              `json:"synced"`
+}
+
 // Token defines model for Token.
 type Token = string
 
+// Transfer defines model for Transfer.
+type Transfer struct {
+	CreatedAt  time.Time           `json:"createdAt"`
+	CreatedBy  *openapi_types.UUID `json:"createdBy,omitempty"`
+	FromPvzId  openapi_types.UUID  `json:"fromPvzId"`
+	Id         openapi_types.UUID  `json:"id"`
+	Items      []TransferItem      `json:"items"`
+	ReceivedAt *time.Time          `json:"receivedAt,omitempty"`
+	ReceivedBy *openapi_types.UUID `json:"receivedBy,omitempty"`
+
+	// ReceptionId Приемка ПВЗ назначения, в которую поступили товары
+	ReceptionId *openapi_types.UUID `json:"receptionId,omitempty"`
+	ShippedAt   *time.Time          `json:"shippedAt,omitempty"`
+	ShippedBy   *openapi_types.UUID `json:"shippedBy,omitempty"`
+	Status      TransferStatus      `json:"status"`
+	ToPvzId     openapi_types.UUID  `json:"toPvzId"`
+}
+
+// TransferItem defines model for TransferItem.
+type TransferItem struct {
+	// ConflictProductId Товар с тем же штрихкодом в незакрытой приемке ПВЗ назначения или ранее в этом перемещении,
+	// из-за него перемещение не принято
+	ConflictProductId *openapi_types.UUID `json:"conflictProductId,omitempty"`
+
+	// ProductId Товар в ПВЗ отправления
+	ProductId openapi_types.UUID `json:"productId"`
+
+	// ReceivedProductId Товар, созданный в приемке ПВЗ назначения
+	ReceivedProductId *openapi_types.UUID `json:"receivedProductId,omitempty"`
+}
+
+// TransferStatus defines model for TransferStatus.
+type TransferStatus string
+
 // User defines model for User.
 type User struct {
 	Email openapi_types.Email `json:"email"`
@@ -128,21 +515,67 @@ type PostDummyLoginJSONBody struct {
 // PostDummyLoginJSONBodyRole defines parameters for PostDummyLogin.
 type PostDummyLoginJSONBodyRole string
 
+// PostInventoriesInventoryIdScansJSONBody defines parameters for PostInventoriesInventoryIdScans.
+type PostInventoriesInventoryIdScansJSONBody struct {
+	Barcode   *string             `json:"barcode,omitempty"`
+	ProductId *openapi_types.UUID `json:"productId,omitempty"`
+}
+
 // PostLoginJSONBody defines parameters for PostLogin.
 type PostLoginJSONBody struct {
 	Email    openapi_types.Email `json:"email"`
 	Password string              `json:"password"`
 }
 
+// PostOrdersJSONBody defines parameters for PostOrders.
+type PostOrdersJSONBody struct {
+	Customer   string               `json:"customer"`
+	ProductIds []openapi_types.UUID `json:"productIds"`
+	PvzId      openapi_types.UUID   `json:"pvzId"`
+}
+
+// GetProductsParams defines parameters for GetProducts.
+type GetProductsParams struct {
+	Barcode *string             `form:"barcode,omitempty" json:"barcode,omitempty"`
+	PvzId   *openapi_types.UUID `form:"pvzId,omitempty" json:"pvzId,omitempty"`
+	Status  *ProductStatus      `form:"status,omitempty" json:"status,omitempty"`
+	Page    *int                `form:"page,omitempty" json:"page,omitempty"`
+	Limit   *int                `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
 // PostProductsJSONBody defines parameters for PostProducts.
 type PostProductsJSONBody struct {
-	PvzId openapi_types.UUID       `json:"pvzId"`
-	Type  PostProductsJSONBodyType `json:"type"`
+	// Barcode Штрихкод товара, уникален в пределах незакрытой приемки
+	Barcode *string                  `json:"barcode,omitempty"`
+	PvzId   openapi_types.UUID       `json:"pvzId"`
+	Type    PostProductsJSONBodyType `json:"type"`
 }
 
 // PostProductsJSONBodyType defines parameters for PostProducts.
 type PostProductsJSONBodyType string
 
+// PostProductsBatchJSONBody defines parameters for PostProductsBatch.
+type PostProductsBatchJSONBody struct {
+	Products []ProductBatchItem `json:"products"`
+	PvzId    openapi_types.UUID `json:"pvzId"`
+}
+
+// GetProductsLocationParams defines parameters for GetProductsLocation.
+type GetProductsLocationParams struct {
+	ProductId *openapi_types.UUID `form:"productId,omitempty" json:"productId,omitempty"`
+	Barcode   *string             `form:"barcode,omitempty" json:"barcode,omitempty"`
+}
+
+// PostProductsProductIdMoveJSONBody defines parameters for PostProductsProductIdMove.
+type PostProductsProductIdMoveJSONBody struct {
+	CellId openapi_types.UUID `json:"cellId"`
+}
+
+// PostProductsProductIdPlaceJSONBody defines parameters for PostProductsProductIdPlace.
+type PostProductsProductIdPlaceJSONBody struct {
+	CellId *openapi_types.UUID `json:"cellId,omitempty"`
+}
+
 // GetPvzParams defines parameters for GetPvz.
 type GetPvzParams struct {
 	// StartDate Начальная дата диапазона
@@ -158,9 +591,58 @@ type GetPvzParams struct {
 	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
 }
 
+// PostPvzPvzIdCellsJSONBody defines parameters for PostPvzPvzIdCells.
+type PostPvzPvzIdCellsJSONBody struct {
+	Capacity int    `json:"capacity"`
+	Code     string `json:"code"`
+}
+
+// PostPvzPvzIdPickupJSONBody defines parameters for PostPvzPvzIdPickup.
+type PostPvzPvzIdPickupJSONBody struct {
+	Code    string             `json:"code"`
+	OrderId openapi_types.UUID `json:"orderId"`
+}
+
+// GetPvzPvzIdReceptionsParams defines parameters for GetPvzPvzIdReceptions.
+type GetPvzPvzIdReceptionsParams struct {
+	// Status Статус приемки
+	Status *GetPvzPvzIdReceptionsParamsStatus `form:"status,omitempty" json:"status,omitempty"`
+
+	// StartDate Начальная дата диапазона
+	StartDate *time.Time `form:"startDate,omitempty" json:"startDate,omitempty"`
+
+	// EndDate Конечная дата диапазона
+	EndDate *time.Time `form:"endDate,omitempty" json:"endDate,omitempty"`
+
+	// Page Номер страницы
+	Page *int `form:"page,omitempty" json:"page,omitempty"`
+
+	// Limit Количество элементов на странице
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// GetPvzPvzIdReceptionsParamsStatus defines parameters for GetPvzPvzIdReceptions.
+type GetPvzPvzIdReceptionsParamsStatus string
+
+// PostPvzPvzIdReturnsConfirmJSONBody defines parameters for PostPvzPvzIdReturnsConfirm.
+type PostPvzPvzIdReturnsConfirmJSONBody struct {
+	ProductIds []openapi_types.UUID `json:"productIds"`
+}
+
+// GetPvzPvzIdTransfersParams defines parameters for GetPvzPvzIdTransfers.
+type GetPvzPvzIdTransfersParams struct {
+	Direction *GetPvzPvzIdTransfersParamsDirection `form:"direction,omitempty" json:"direction,omitempty"`
+	Status    *TransferStatus                      `form:"status,omitempty" json:"status,omitempty"`
+}
+
+// GetPvzPvzIdTransfersParamsDirection defines parameters for GetPvzPvzIdTransfers.
+type GetPvzPvzIdTransfersParamsDirection string
+
 // PostReceptionsJSONBody defines parameters for PostReceptions.
 type PostReceptionsJSONBody struct {
-	PvzId openapi_types.UUID `json:"pvzId"`
+	// Manifest Ожидаемый состав поставки - количество товаров по типам и/или список штрихкодов
+	Manifest *ReceptionManifest `json:"manifest,omitempty"`
+	PvzId    openapi_types.UUID `json:"pvzId"`
 }
 
 // PostRegisterJSONBody defines parameters for PostRegister.
@@ -173,20 +655,57 @@ type PostRegisterJSONBody struct {
 // PostRegisterJSONBodyRole defines parameters for PostRegister.
 type PostRegisterJSONBodyRole string
 
+// PostTransfersJSONBody defines parameters for PostTransfers.
+type PostTransfersJSONBody struct {
+	FromPvzId  openapi_types.UUID   `json:"fromPvzId"`
+	ProductIds []openapi_types.UUID `json:"productIds"`
+	ToPvzId    openapi_types.UUID   `json:"toPvzId"`
+}
+
 // PostDummyLoginJSONRequestBody defines body for PostDummyLogin for application/json ContentType.
 type PostDummyLoginJSONRequestBody PostDummyLoginJSONBody
 
+// PostInventoriesInventoryIdScansJSONRequestBody defines body for PostInventoriesInventoryIdScans for application/json ContentType.
+type PostInventoriesInventoryIdScansJSONRequestBody PostInventoriesInventoryIdScansJSONBody
+
 // PostLoginJSONRequestBody defines body for PostLogin for application/json ContentType.
 type PostLoginJSONRequestBody PostLoginJSONBody
 
+// PostOrdersJSONRequestBody defines body for PostOrders for application/json ContentType.
+type PostOrdersJSONRequestBody PostOrdersJSONBody
+
 // PostProductsJSONRequestBody defines body for PostProducts for application/json ContentType.
 type PostProductsJSONRequestBody PostProductsJSONBody
 
+// PostProductsBatchJSONRequestBody defines body for PostProductsBatch for application/json ContentType.
+type PostProductsBatchJSONRequestBody PostProductsBatchJSONBody
+
+// PostProductsProductIdMoveJSONRequestBody defines body for PostProductsProductIdMove for application/json ContentType.
+type PostProductsProductIdMoveJSONRequestBody PostProductsProductIdMoveJSONBody
+
+// PostProductsProductIdPlaceJSONRequestBody defines body for PostProductsProductIdPlace for application/json ContentType.
+type PostProductsProductIdPlaceJSONRequestBody PostProductsProductIdPlaceJSONBody
+
 // PostPvzJSONRequestBody defines body for PostPvz for application/json ContentType.
 type PostPvzJSONRequestBody = PVZ
 
+// PostPvzPvzIdCellsJSONRequestBody defines body for PostPvzPvzIdCells for application/json ContentType.
+type PostPvzPvzIdCellsJSONRequestBody PostPvzPvzIdCellsJSONBody
+
+// PostPvzPvzIdPickupJSONRequestBody defines body for PostPvzPvzIdPickup for application/json ContentType.
+type PostPvzPvzIdPickupJSONRequestBody PostPvzPvzIdPickupJSONBody
+
+// PostPvzPvzIdReturnsConfirmJSONRequestBody defines body for PostPvzPvzIdReturnsConfirm for application/json ContentType.
+type PostPvzPvzIdReturnsConfirmJSONRequestBody PostPvzPvzIdReturnsConfirmJSONBody
+
 // PostReceptionsJSONRequestBody defines body for PostReceptions for application/json ContentType.
 type PostReceptionsJSONRequestBody PostReceptionsJSONBody
 
 // PostRegisterJSONRequestBody defines body for PostRegister for application/json ContentType.
 type PostRegisterJSONRequestBody PostRegisterJSONBody
+
+// PostSyncBatchesJSONRequestBody defines body for PostSyncBatches for application/json ContentType.
+type PostSyncBatchesJSONRequestBody = SyncBatch
+
+// PostTransfersJSONRequestBody defines body for PostTransfers for application/json ContentType.
+type PostTransfersJSONRequestBody PostTransfersJSONBody
@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+// IdempotencyRecord is a response stored for Idempotency-Key of POST request.
+// StatusCode is zero while the first request with the key is still processed.
+type IdempotencyRecord struct {
+	Key         string
+	Fingerprint string
+	StatusCode  int
+	Body        []byte
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,12 @@
+package dto
+
+import "time"
+
+// PickupCodeState is a pickup code of order with its verification state,
+// it is never returned to clients
+type PickupCodeState struct {
+	CodeHash       *string
+	ExpiresAt      *time.Time
+	FailedAttempts int
+	LockedUntil    *time.Time
+}
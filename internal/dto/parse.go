@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"strconv"
 	"time"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 var ErrParsingDTO = errors.New("failed to parse dto")
@@ -99,3 +101,172 @@ func CorrectParams(p *GetPvzParams) {
 		*p.Limit = limitMax
 	}
 }
+
+func (p *GetPvzPvzIdReceptionsParams) FromParams(r *http.Request) error {
+	query := r.URL.Query()
+
+	if statusStr := query.Get("status"); statusStr != "" {
+		status := GetPvzPvzIdReceptionsParamsStatus(statusStr)
+		if status != StatusFilterInProgress && status != StatusFilterClose {
+			return fmt.Errorf("unknown reception status %s", statusStr)
+		}
+		p.Status = &status
+	}
+
+	if startDateStr := query.Get("startDate"); startDateStr != "" {
+		t, err := time.Parse(time.RFC3339, startDateStr)
+		if err != nil {
+			return err
+		}
+		p.StartDate = &t
+	}
+
+	if endDateStr := query.Get("endDate"); endDateStr != "" {
+		t, err := time.Parse(time.RFC3339, endDateStr)
+		if err != nil {
+			return err
+		}
+		p.EndDate = &t
+	}
+
+	if pageStr := query.Get("page"); pageStr != "" {
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			return err
+		}
+		p.Page = &page
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return err
+		}
+		p.Limit = &limit
+	}
+
+	return nil
+}
+
+func CorrectReceptionsParams(p *GetPvzPvzIdReceptionsParams) {
+	if p == nil {
+		return
+	}
+
+	pvzParams := GetPvzParams{
+		StartDate: p.StartDate,
+		EndDate:   p.EndDate,
+		Page:      p.Page,
+		Limit:     p.Limit,
+	}
+	CorrectParams(&pvzParams)
+
+	p.StartDate = pvzParams.StartDate
+	p.EndDate = pvzParams.EndDate
+	p.Page = pvzParams.Page
+	p.Limit = pvzParams.Limit
+}
+
+func (p *GetProductsParams) FromParams(r *http.Request) error {
+	query := r.URL.Query()
+
+	if query.Has("barcode") {
+		barcode := query.Get("barcode")
+		p.Barcode = &barcode
+	}
+
+	if pvzIDStr := query.Get("pvzId"); pvzIDStr != "" {
+		var pvzID openapi_types.UUID
+		if err := pvzID.UnmarshalText([]byte(pvzIDStr)); err != nil {
+			return err
+		}
+		p.PvzId = &pvzID
+	}
+
+	if statusStr := query.Get("status"); statusStr != "" {
+		status := ProductStatus(statusStr)
+		switch status {
+		case ProductReceived, ProductStored, ProductIssued, ProductReturnedToSender, ProductTransferred:
+		default:
+			return fmt.Errorf("unknown product status %s", statusStr)
+		}
+		p.Status = &status
+	}
+
+	if pageStr := query.Get("page"); pageStr != "" {
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			return err
+		}
+		p.Page = &page
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return err
+		}
+		p.Limit = &limit
+	}
+
+	return nil
+}
+
+func CorrectProductsParams(p *GetProductsParams) {
+	if p == nil {
+		return
+	}
+
+	pvzParams := GetPvzParams{
+		Page:  p.Page,
+		Limit: p.Limit,
+	}
+	CorrectParams(&pvzParams)
+
+	p.Page = pvzParams.Page
+	p.Limit = pvzParams.Limit
+}
+
+func (p *GetProductsLocationParams) FromParams(r *http.Request) error {
+	query := r.URL.Query()
+
+	if productIDStr := query.Get("productId"); productIDStr != "" {
+		var productID openapi_types.UUID
+		if err := productID.UnmarshalText([]byte(productIDStr)); err != nil {
+			return err
+		}
+		p.ProductId = &productID
+	}
+
+	if query.Has("barcode") {
+		barcode := query.Get("barcode")
+		p.Barcode = &barcode
+	}
+
+	return nil
+}
+
+func (p *GetPvzPvzIdTransfersParams) FromParams(r *http.Request) error {
+	query := r.URL.Query()
+
+	direction := TransferIncoming
+	if directionStr := query.Get("direction"); directionStr != "" {
+		direction = GetPvzPvzIdTransfersParamsDirection(directionStr)
+		if direction != TransferIncoming && direction != TransferOutgoing {
+			return fmt.Errorf("unknown transfer direction %s", directionStr)
+		}
+	}
+	p.Direction = &direction
+
+	if statusStr := query.Get("status"); statusStr != "" {
+		status := TransferStatus(statusStr)
+		switch status {
+		case TransferCreated, TransferInTransit, TransferReceived:
+		default:
+			return fmt.Errorf("unknown transfer status %s", statusStr)
+		}
+		p.Status = &status
+	}
+
+	return nil
+}
@@ -68,6 +68,55 @@ func (ReceptionStatus) EnumDescriptor() ([]byte, []int) {
 	return file_api_pvz_proto_rawDescGZIP(), []int{0}
 }
 
+type AddProductStatus int32
+
+const (
+	AddProductStatus_ADD_PRODUCT_STATUS_CREATED  AddProductStatus = 0
+	AddProductStatus_ADD_PRODUCT_STATUS_EXISTING AddProductStatus = 1
+	AddProductStatus_ADD_PRODUCT_STATUS_FAILED   AddProductStatus = 2
+)
+
+// Enum value maps for AddProductStatus.
+var (
+	AddProductStatus_name = map[int32]string{
+		0: "ADD_PRODUCT_STATUS_CREATED",
+		1: "ADD_PRODUCT_STATUS_EXISTING",
+		2: "ADD_PRODUCT_STATUS_FAILED",
+	}
+	AddProductStatus_value = map[string]int32{
+		"ADD_PRODUCT_STATUS_CREATED":  0,
+		"ADD_PRODUCT_STATUS_EXISTING": 1,
+		"ADD_PRODUCT_STATUS_FAILED":   2,
+	}
+)
+
+func (x AddProductStatus) Enum() *AddProductStatus {
+	p := new(AddProductStatus)
+	*p = x
+	return p
+}
+
+func (x AddProductStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AddProductStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_pvz_proto_enumTypes[1].Descriptor()
+}
+
+func (AddProductStatus) Type() protoreflect.EnumType {
+	return &file_api_pvz_proto_enumTypes[1]
+}
+
+func (x AddProductStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AddProductStatus.Descriptor instead.
+func (AddProductStatus) EnumDescriptor() ([]byte, []int) {
+	return file_api_pvz_proto_rawDescGZIP(), []int{1}
+}
+
 type PVZ struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -208,6 +257,706 @@ func (x *GetPVZListResponse) GetPvzs() []*PVZ {
 	return nil
 }
 
+type Reception struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DateTime      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=date_time,json=dateTime,proto3" json:"date_time,omitempty"`
+	PvzId         string                 `protobuf:"bytes,3,opt,name=pvz_id,json=pvzId,proto3" json:"pvz_id,omitempty"`
+	Status        ReceptionStatus        `protobuf:"varint,4,opt,name=status,proto3,enum=pvz.v1.ReceptionStatus" json:"status,omitempty"`
+	ClosedAt      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=closed_at,json=closedAt,proto3" json:"closed_at,omitempty"`
+	OpenedBy      string                 `protobuf:"bytes,6,opt,name=opened_by,json=openedBy,proto3" json:"opened_by,omitempty"`
+	ClosedBy      string                 `protobuf:"bytes,7,opt,name=closed_by,json=closedBy,proto3" json:"closed_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reception) Reset() {
+	*x = Reception{}
+	mi := &file_api_pvz_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reception) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reception) ProtoMessage() {}
+
+func (x *Reception) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pvz_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reception.ProtoReflect.Descriptor instead.
+func (*Reception) Descriptor() ([]byte, []int) {
+	return file_api_pvz_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Reception) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Reception) GetDateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DateTime
+	}
+	return nil
+}
+
+func (x *Reception) GetPvzId() string {
+	if x != nil {
+		return x.PvzId
+	}
+	return ""
+}
+
+func (x *Reception) GetStatus() ReceptionStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ReceptionStatus_RECEPTION_STATUS_IN_PROGRESS
+}
+
+func (x *Reception) GetClosedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ClosedAt
+	}
+	return nil
+}
+
+func (x *Reception) GetOpenedBy() string {
+	if x != nil {
+		return x.OpenedBy
+	}
+	return ""
+}
+
+func (x *Reception) GetClosedBy() string {
+	if x != nil {
+		return x.ClosedBy
+	}
+	return ""
+}
+
+type Product struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DateTime      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=date_time,json=dateTime,proto3" json:"date_time,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	ReceptionId   string                 `protobuf:"bytes,4,opt,name=reception_id,json=receptionId,proto3" json:"reception_id,omitempty"`
+	Barcode       *string                `protobuf:"bytes,5,opt,name=barcode,proto3,oneof" json:"barcode,omitempty"`
+	Status        string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	StoredAt      *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=stored_at,json=storedAt,proto3" json:"stored_at,omitempty"`
+	IssuedAt      *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	ReturnedAt    *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=returned_at,json=returnedAt,proto3" json:"returned_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Product) Reset() {
+	*x = Product{}
+	mi := &file_api_pvz_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Product) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Product) ProtoMessage() {}
+
+func (x *Product) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pvz_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Product.ProtoReflect.Descriptor instead.
+func (*Product) Descriptor() ([]byte, []int) {
+	return file_api_pvz_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Product) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Product) GetDateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DateTime
+	}
+	return nil
+}
+
+func (x *Product) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Product) GetReceptionId() string {
+	if x != nil {
+		return x.ReceptionId
+	}
+	return ""
+}
+
+func (x *Product) GetBarcode() string {
+	if x != nil && x.Barcode != nil {
+		return *x.Barcode
+	}
+	return ""
+}
+
+func (x *Product) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Product) GetStoredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StoredAt
+	}
+	return nil
+}
+
+func (x *Product) GetIssuedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.IssuedAt
+	}
+	return nil
+}
+
+func (x *Product) GetReturnedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ReturnedAt
+	}
+	return nil
+}
+
+type ProductTypeCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductTypeCount) Reset() {
+	*x = ProductTypeCount{}
+	mi := &file_api_pvz_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductTypeCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductTypeCount) ProtoMessage() {}
+
+func (x *ProductTypeCount) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pvz_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductTypeCount.ProtoReflect.Descriptor instead.
+func (*ProductTypeCount) Descriptor() ([]byte, []int) {
+	return file_api_pvz_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ProductTypeCount) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ProductTypeCount) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type ReceptionDetails struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reception     *Reception             `protobuf:"bytes,1,opt,name=reception,proto3" json:"reception,omitempty"`
+	Products      []*Product             `protobuf:"bytes,2,rep,name=products,proto3" json:"products,omitempty"`
+	ProductCounts []*ProductTypeCount    `protobuf:"bytes,3,rep,name=product_counts,json=productCounts,proto3" json:"product_counts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReceptionDetails) Reset() {
+	*x = ReceptionDetails{}
+	mi := &file_api_pvz_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReceptionDetails) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReceptionDetails) ProtoMessage() {}
+
+func (x *ReceptionDetails) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pvz_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReceptionDetails.ProtoReflect.Descriptor instead.
+func (*ReceptionDetails) Descriptor() ([]byte, []int) {
+	return file_api_pvz_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ReceptionDetails) GetReception() *Reception {
+	if x != nil {
+		return x.Reception
+	}
+	return nil
+}
+
+func (x *ReceptionDetails) GetProducts() []*Product {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+func (x *ReceptionDetails) GetProductCounts() []*ProductTypeCount {
+	if x != nil {
+		return x.ProductCounts
+	}
+	return nil
+}
+
+type GetReceptionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetReceptionRequest) Reset() {
+	*x = GetReceptionRequest{}
+	mi := &file_api_pvz_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReceptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReceptionRequest) ProtoMessage() {}
+
+func (x *GetReceptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pvz_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReceptionRequest.ProtoReflect.Descriptor instead.
+func (*GetReceptionRequest) Descriptor() ([]byte, []int) {
+	return file_api_pvz_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetReceptionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListReceptionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PvzId         string                 `protobuf:"bytes,1,opt,name=pvz_id,json=pvzId,proto3" json:"pvz_id,omitempty"`
+	Status        *ReceptionStatus       `protobuf:"varint,2,opt,name=status,proto3,enum=pvz.v1.ReceptionStatus,oneof" json:"status,omitempty"`
+	StartDate     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	Page          int32                  `protobuf:"varint,5,opt,name=page,proto3" json:"page,omitempty"`
+	Limit         int32                  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReceptionsRequest) Reset() {
+	*x = ListReceptionsRequest{}
+	mi := &file_api_pvz_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReceptionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReceptionsRequest) ProtoMessage() {}
+
+func (x *ListReceptionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pvz_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReceptionsRequest.ProtoReflect.Descriptor instead.
+func (*ListReceptionsRequest) Descriptor() ([]byte, []int) {
+	return file_api_pvz_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListReceptionsRequest) GetPvzId() string {
+	if x != nil {
+		return x.PvzId
+	}
+	return ""
+}
+
+func (x *ListReceptionsRequest) GetStatus() ReceptionStatus {
+	if x != nil && x.Status != nil {
+		return *x.Status
+	}
+	return ReceptionStatus_RECEPTION_STATUS_IN_PROGRESS
+}
+
+func (x *ListReceptionsRequest) GetStartDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartDate
+	}
+	return nil
+}
+
+func (x *ListReceptionsRequest) GetEndDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndDate
+	}
+	return nil
+}
+
+func (x *ListReceptionsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListReceptionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListReceptionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Receptions    []*Reception           `protobuf:"bytes,1,rep,name=receptions,proto3" json:"receptions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReceptionsResponse) Reset() {
+	*x = ListReceptionsResponse{}
+	mi := &file_api_pvz_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReceptionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReceptionsResponse) ProtoMessage() {}
+
+func (x *ListReceptionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pvz_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReceptionsResponse.ProtoReflect.Descriptor instead.
+func (*ListReceptionsResponse) Descriptor() ([]byte, []int) {
+	return file_api_pvz_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListReceptionsResponse) GetReceptions() []*Reception {
+	if x != nil {
+		return x.Receptions
+	}
+	return nil
+}
+
+type GetActiveReceptionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PvzId         string                 `protobuf:"bytes,1,opt,name=pvz_id,json=pvzId,proto3" json:"pvz_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetActiveReceptionRequest) Reset() {
+	*x = GetActiveReceptionRequest{}
+	mi := &file_api_pvz_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetActiveReceptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetActiveReceptionRequest) ProtoMessage() {}
+
+func (x *GetActiveReceptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pvz_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetActiveReceptionRequest.ProtoReflect.Descriptor instead.
+func (*GetActiveReceptionRequest) Descriptor() ([]byte, []int) {
+	return file_api_pvz_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetActiveReceptionRequest) GetPvzId() string {
+	if x != nil {
+		return x.PvzId
+	}
+	return ""
+}
+
+type AddProductRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PvzId         string                 `protobuf:"bytes,1,opt,name=pvz_id,json=pvzId,proto3" json:"pvz_id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Barcode       *string                `protobuf:"bytes,3,opt,name=barcode,proto3,oneof" json:"barcode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddProductRequest) Reset() {
+	*x = AddProductRequest{}
+	mi := &file_api_pvz_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddProductRequest) ProtoMessage() {}
+
+func (x *AddProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pvz_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddProductRequest.ProtoReflect.Descriptor instead.
+func (*AddProductRequest) Descriptor() ([]byte, []int) {
+	return file_api_pvz_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AddProductRequest) GetPvzId() string {
+	if x != nil {
+		return x.PvzId
+	}
+	return ""
+}
+
+func (x *AddProductRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *AddProductRequest) GetBarcode() string {
+	if x != nil && x.Barcode != nil {
+		return *x.Barcode
+	}
+	return ""
+}
+
+type AddProductResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Status        AddProductStatus       `protobuf:"varint,2,opt,name=status,proto3,enum=pvz.v1.AddProductStatus" json:"status,omitempty"`
+	Product       *Product               `protobuf:"bytes,3,opt,name=product,proto3" json:"product,omitempty"`
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddProductResult) Reset() {
+	*x = AddProductResult{}
+	mi := &file_api_pvz_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddProductResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddProductResult) ProtoMessage() {}
+
+func (x *AddProductResult) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pvz_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddProductResult.ProtoReflect.Descriptor instead.
+func (*AddProductResult) Descriptor() ([]byte, []int) {
+	return file_api_pvz_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *AddProductResult) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *AddProductResult) GetStatus() AddProductStatus {
+	if x != nil {
+		return x.Status
+	}
+	return AddProductStatus_ADD_PRODUCT_STATUS_CREATED
+}
+
+func (x *AddProductResult) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+func (x *AddProductResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type AddProductsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*AddProductResult    `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddProductsResponse) Reset() {
+	*x = AddProductsResponse{}
+	mi := &file_api_pvz_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddProductsResponse) ProtoMessage() {}
+
+func (x *AddProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pvz_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddProductsResponse.ProtoReflect.Descriptor instead.
+func (*AddProductsResponse) Descriptor() ([]byte, []int) {
+	return file_api_pvz_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *AddProductsResponse) GetResults() []*AddProductResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
 var File_api_pvz_proto protoreflect.FileDescriptor
 
 const file_api_pvz_proto_rawDesc = "" +
@@ -219,14 +968,80 @@ const file_api_pvz_proto_rawDesc = "" +
 	"\x04city\x18\x03 \x01(\tR\x04city\"\x13\n" +
 	"\x11GetPVZListRequest\"5\n" +
 	"\x12GetPVZListResponse\x12\x1f\n" +
-	"\x04pvzs\x18\x01 \x03(\v2\v.pvz.v1.PVZR\x04pvzs*P\n" +
+	"\x04pvzs\x18\x01 \x03(\v2\v.pvz.v1.PVZR\x04pvzs\"\x8f\x02\n" +
+	"\tReception\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x127\n" +
+	"\tdate_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\bdateTime\x12\x15\n" +
+	"\x06pvz_id\x18\x03 \x01(\tR\x05pvzId\x12/\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x17.pvz.v1.ReceptionStatusR\x06status\x127\n" +
+	"\tclosed_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\bclosedAt\x12\x1b\n" +
+	"\topened_by\x18\x06 \x01(\tR\bopenedBy\x12\x1b\n" +
+	"\tclosed_by\x18\a \x01(\tR\bclosedBy\"\xfb\x02\n" +
+	"\aProduct\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x127\n" +
+	"\tdate_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\bdateTime\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12!\n" +
+	"\freception_id\x18\x04 \x01(\tR\vreceptionId\x12\x1d\n" +
+	"\abarcode\x18\x05 \x01(\tH\x00R\abarcode\x88\x01\x01\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x127\n" +
+	"\tstored_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\bstoredAt\x127\n" +
+	"\tissued_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\bissuedAt\x12;\n" +
+	"\vreturned_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"returnedAtB\n" +
+	"\n" +
+	"\b_barcode\"<\n" +
+	"\x10ProductTypeCount\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\"\xb1\x01\n" +
+	"\x10ReceptionDetails\x12/\n" +
+	"\treception\x18\x01 \x01(\v2\x11.pvz.v1.ReceptionR\treception\x12+\n" +
+	"\bproducts\x18\x02 \x03(\v2\x0f.pvz.v1.ProductR\bproducts\x12?\n" +
+	"\x0eproduct_counts\x18\x03 \x03(\v2\x18.pvz.v1.ProductTypeCountR\rproductCounts\"%\n" +
+	"\x13GetReceptionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x8b\x02\n" +
+	"\x15ListReceptionsRequest\x12\x15\n" +
+	"\x06pvz_id\x18\x01 \x01(\tR\x05pvzId\x124\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x17.pvz.v1.ReceptionStatusH\x00R\x06status\x88\x01\x01\x129\n" +
+	"\n" +
+	"start_date\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tstartDate\x125\n" +
+	"\bend_date\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\aendDate\x12\x12\n" +
+	"\x04page\x18\x05 \x01(\x05R\x04page\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\x05R\x05limitB\t\n" +
+	"\a_status\"K\n" +
+	"\x16ListReceptionsResponse\x121\n" +
+	"\n" +
+	"receptions\x18\x01 \x03(\v2\x11.pvz.v1.ReceptionR\n" +
+	"receptions\"2\n" +
+	"\x19GetActiveReceptionRequest\x12\x15\n" +
+	"\x06pvz_id\x18\x01 \x01(\tR\x05pvzId\"i\n" +
+	"\x11AddProductRequest\x12\x15\n" +
+	"\x06pvz_id\x18\x01 \x01(\tR\x05pvzId\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1d\n" +
+	"\abarcode\x18\x03 \x01(\tH\x00R\abarcode\x88\x01\x01B\n" +
+	"\n" +
+	"\b_barcode\"\x9f\x01\n" +
+	"\x10AddProductResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x120\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x18.pvz.v1.AddProductStatusR\x06status\x12)\n" +
+	"\aproduct\x18\x03 \x01(\v2\x0f.pvz.v1.ProductR\aproduct\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\"I\n" +
+	"\x13AddProductsResponse\x122\n" +
+	"\aresults\x18\x01 \x03(\v2\x18.pvz.v1.AddProductResultR\aresults*P\n" +
 	"\x0fReceptionStatus\x12 \n" +
 	"\x1cRECEPTION_STATUS_IN_PROGRESS\x10\x00\x12\x1b\n" +
-	"\x17RECEPTION_STATUS_CLOSED\x10\x012Q\n" +
+	"\x17RECEPTION_STATUS_CLOSED\x10\x01*r\n" +
+	"\x10AddProductStatus\x12\x1e\n" +
+	"\x1aADD_PRODUCT_STATUS_CREATED\x10\x00\x12\x1f\n" +
+	"\x1bADD_PRODUCT_STATUS_EXISTING\x10\x01\x12\x1d\n" +
+	"\x19ADD_PRODUCT_STATUS_FAILED\x10\x022\xfe\x02\n" +
 	"\n" +
 	"PVZService\x12C\n" +
 	"\n" +
-	"GetPVZList\x12\x19.pvz.v1.GetPVZListRequest\x1a\x1a.pvz.v1.GetPVZListResponseB0Z.github.com/Arzeeq/pvz-api/internal/grpc;pvz_v1b\x06proto3"
+	"GetPVZList\x12\x19.pvz.v1.GetPVZListRequest\x1a\x1a.pvz.v1.GetPVZListResponse\x12E\n" +
+	"\fGetReception\x12\x1b.pvz.v1.GetReceptionRequest\x1a\x18.pvz.v1.ReceptionDetails\x12O\n" +
+	"\x0eListReceptions\x12\x1d.pvz.v1.ListReceptionsRequest\x1a\x1e.pvz.v1.ListReceptionsResponse\x12J\n" +
+	"\x12GetActiveReception\x12!.pvz.v1.GetActiveReceptionRequest\x1a\x11.pvz.v1.Reception\x12G\n" +
+	"\vAddProducts\x12\x19.pvz.v1.AddProductRequest\x1a\x1b.pvz.v1.AddProductsResponse(\x01B0Z.github.com/Arzeeq/pvz-api/internal/grpc;pvz_v1b\x06proto3"
 
 var (
 	file_api_pvz_proto_rawDescOnce sync.Once
@@ -240,25 +1055,62 @@ func file_api_pvz_proto_rawDescGZIP() []byte {
 	return file_api_pvz_proto_rawDescData
 }
 
-var file_api_pvz_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_api_pvz_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_api_pvz_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_api_pvz_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_api_pvz_proto_goTypes = []any{
-	(ReceptionStatus)(0),          // 0: pvz.v1.ReceptionStatus
-	(*PVZ)(nil),                   // 1: pvz.v1.PVZ
-	(*GetPVZListRequest)(nil),     // 2: pvz.v1.GetPVZListRequest
-	(*GetPVZListResponse)(nil),    // 3: pvz.v1.GetPVZListResponse
-	(*timestamppb.Timestamp)(nil), // 4: google.protobuf.Timestamp
+	(ReceptionStatus)(0),              // 0: pvz.v1.ReceptionStatus
+	(AddProductStatus)(0),             // 1: pvz.v1.AddProductStatus
+	(*PVZ)(nil),                       // 2: pvz.v1.PVZ
+	(*GetPVZListRequest)(nil),         // 3: pvz.v1.GetPVZListRequest
+	(*GetPVZListResponse)(nil),        // 4: pvz.v1.GetPVZListResponse
+	(*Reception)(nil),                 // 5: pvz.v1.Reception
+	(*Product)(nil),                   // 6: pvz.v1.Product
+	(*ProductTypeCount)(nil),          // 7: pvz.v1.ProductTypeCount
+	(*ReceptionDetails)(nil),          // 8: pvz.v1.ReceptionDetails
+	(*GetReceptionRequest)(nil),       // 9: pvz.v1.GetReceptionRequest
+	(*ListReceptionsRequest)(nil),     // 10: pvz.v1.ListReceptionsRequest
+	(*ListReceptionsResponse)(nil),    // 11: pvz.v1.ListReceptionsResponse
+	(*GetActiveReceptionRequest)(nil), // 12: pvz.v1.GetActiveReceptionRequest
+	(*AddProductRequest)(nil),         // 13: pvz.v1.AddProductRequest
+	(*AddProductResult)(nil),          // 14: pvz.v1.AddProductResult
+	(*AddProductsResponse)(nil),       // 15: pvz.v1.AddProductsResponse
+	(*timestamppb.Timestamp)(nil),     // 16: google.protobuf.Timestamp
 }
 var file_api_pvz_proto_depIdxs = []int32{
-	4, // 0: pvz.v1.PVZ.registration_date:type_name -> google.protobuf.Timestamp
-	1, // 1: pvz.v1.GetPVZListResponse.pvzs:type_name -> pvz.v1.PVZ
-	2, // 2: pvz.v1.PVZService.GetPVZList:input_type -> pvz.v1.GetPVZListRequest
-	3, // 3: pvz.v1.PVZService.GetPVZList:output_type -> pvz.v1.GetPVZListResponse
-	3, // [3:4] is the sub-list for method output_type
-	2, // [2:3] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	16, // 0: pvz.v1.PVZ.registration_date:type_name -> google.protobuf.Timestamp
+	2,  // 1: pvz.v1.GetPVZListResponse.pvzs:type_name -> pvz.v1.PVZ
+	16, // 2: pvz.v1.Reception.date_time:type_name -> google.protobuf.Timestamp
+	0,  // 3: pvz.v1.Reception.status:type_name -> pvz.v1.ReceptionStatus
+	16, // 4: pvz.v1.Reception.closed_at:type_name -> google.protobuf.Timestamp
+	16, // 5: pvz.v1.Product.date_time:type_name -> google.protobuf.Timestamp
+	16, // 6: pvz.v1.Product.stored_at:type_name -> google.protobuf.Timestamp
+	16, // 7: pvz.v1.Product.issued_at:type_name -> google.protobuf.Timestamp
+	16, // 8: pvz.v1.Product.returned_at:type_name -> google.protobuf.Timestamp
+	5,  // 9: pvz.v1.ReceptionDetails.reception:type_name -> pvz.v1.Reception
+	6,  // 10: pvz.v1.ReceptionDetails.products:type_name -> pvz.v1.Product
+	7,  // 11: pvz.v1.ReceptionDetails.product_counts:type_name -> pvz.v1.ProductTypeCount
+	0,  // 12: pvz.v1.ListReceptionsRequest.status:type_name -> pvz.v1.ReceptionStatus
+	16, // 13: pvz.v1.ListReceptionsRequest.start_date:type_name -> google.protobuf.Timestamp
+	16, // 14: pvz.v1.ListReceptionsRequest.end_date:type_name -> google.protobuf.Timestamp
+	5,  // 15: pvz.v1.ListReceptionsResponse.receptions:type_name -> pvz.v1.Reception
+	1,  // 16: pvz.v1.AddProductResult.status:type_name -> pvz.v1.AddProductStatus
+	6,  // 17: pvz.v1.AddProductResult.product:type_name -> pvz.v1.Product
+	14, // 18: pvz.v1.AddProductsResponse.results:type_name -> pvz.v1.AddProductResult
+	3,  // 19: pvz.v1.PVZService.GetPVZList:input_type -> pvz.v1.GetPVZListRequest
+	9,  // 20: pvz.v1.PVZService.GetReception:input_type -> pvz.v1.GetReceptionRequest
+	10, // 21: pvz.v1.PVZService.ListReceptions:input_type -> pvz.v1.ListReceptionsRequest
+	12, // 22: pvz.v1.PVZService.GetActiveReception:input_type -> pvz.v1.GetActiveReceptionRequest
+	13, // 23: pvz.v1.PVZService.AddProducts:input_type -> pvz.v1.AddProductRequest
+	4,  // 24: pvz.v1.PVZService.GetPVZList:output_type -> pvz.v1.GetPVZListResponse
+	8,  // 25: pvz.v1.PVZService.GetReception:output_type -> pvz.v1.ReceptionDetails
+	11, // 26: pvz.v1.PVZService.ListReceptions:output_type -> pvz.v1.ListReceptionsResponse
+	5,  // 27: pvz.v1.PVZService.GetActiveReception:output_type -> pvz.v1.Reception
+	15, // 28: pvz.v1.PVZService.AddProducts:output_type -> pvz.v1.AddProductsResponse
+	24, // [24:29] is the sub-list for method output_type
+	19, // [19:24] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
 }
 
 func init() { file_api_pvz_proto_init() }
@@ -266,13 +1118,16 @@ func file_api_pvz_proto_init() {
 	if File_api_pvz_proto != nil {
 		return
 	}
+	file_api_pvz_proto_msgTypes[4].OneofWrappers = []any{}
+	file_api_pvz_proto_msgTypes[8].OneofWrappers = []any{}
+	file_api_pvz_proto_msgTypes[11].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_pvz_proto_rawDesc), len(file_api_pvz_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   3,
+			NumEnums:      2,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,7 +19,11 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	PVZService_GetPVZList_FullMethodName = "/pvz.v1.PVZService/GetPVZList"
+	PVZService_GetPVZList_FullMethodName         = "/pvz.v1.PVZService/GetPVZList"
+	PVZService_GetReception_FullMethodName       = "/pvz.v1.PVZService/GetReception"
+	PVZService_ListReceptions_FullMethodName     = "/pvz.v1.PVZService/ListReceptions"
+	PVZService_GetActiveReception_FullMethodName = "/pvz.v1.PVZService/GetActiveReception"
+	PVZService_AddProducts_FullMethodName        = "/pvz.v1.PVZService/AddProducts"
 )
 
 // PVZServiceClient is the client API for PVZService service.
@@ -27,6 +31,10 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type PVZServiceClient interface {
 	GetPVZList(ctx context.Context, in *GetPVZListRequest, opts ...grpc.CallOption) (*GetPVZListResponse, error)
+	GetReception(ctx context.Context, in *GetReceptionRequest, opts ...grpc.CallOption) (*ReceptionDetails, error)
+	ListReceptions(ctx context.Context, in *ListReceptionsRequest, opts ...grpc.CallOption) (*ListReceptionsResponse, error)
+	GetActiveReception(ctx context.Context, in *GetActiveReceptionRequest, opts ...grpc.CallOption) (*Reception, error)
+	AddProducts(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[AddProductRequest, AddProductsResponse], error)
 }
 
 type pVZServiceClient struct {
@@ -47,11 +55,58 @@ func (c *pVZServiceClient) GetPVZList(ctx context.Context, in *GetPVZListRequest
 	return out, nil
 }
 
+func (c *pVZServiceClient) GetReception(ctx context.Context, in *GetReceptionRequest, opts ...grpc.CallOption) (*ReceptionDetails, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReceptionDetails)
+	err := c.cc.Invoke(ctx, PVZService_GetReception_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pVZServiceClient) ListReceptions(ctx context.Context, in *ListReceptionsRequest, opts ...grpc.CallOption) (*ListReceptionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListReceptionsResponse)
+	err := c.cc.Invoke(ctx, PVZService_ListReceptions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pVZServiceClient) GetActiveReception(ctx context.Context, in *GetActiveReceptionRequest, opts ...grpc.CallOption) (*Reception, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Reception)
+	err := c.cc.Invoke(ctx, PVZService_GetActiveReception_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pVZServiceClient) AddProducts(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[AddProductRequest, AddProductsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PVZService_ServiceDesc.Streams[0], PVZService_AddProducts_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AddProductRequest, AddProductsResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PVZService_AddProductsClient = grpc.ClientStreamingClient[AddProductRequest, AddProductsResponse]
+
 // PVZServiceServer is the server API for PVZService service.
 // All implementations must embed UnimplementedPVZServiceServer
 // for forward compatibility.
 type PVZServiceServer interface {
 	GetPVZList(context.Context, *GetPVZListRequest) (*GetPVZListResponse, error)
+	GetReception(context.Context, *GetReceptionRequest) (*ReceptionDetails, error)
+	ListReceptions(context.Context, *ListReceptionsRequest) (*ListReceptionsResponse, error)
+	GetActiveReception(context.Context, *GetActiveReceptionRequest) (*Reception, error)
+	AddProducts(grpc.ClientStreamingServer[AddProductRequest, AddProductsResponse]) error
 	mustEmbedUnimplementedPVZServiceServer()
 }
 
@@ -65,6 +120,18 @@ type UnimplementedPVZServiceServer struct{}
 func (UnimplementedPVZServiceServer) GetPVZList(context.Context, *GetPVZListRequest) (*GetPVZListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPVZList not implemented")
 }
+func (UnimplementedPVZServiceServer) GetReception(context.Context, *GetReceptionRequest) (*ReceptionDetails, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReception not implemented")
+}
+func (UnimplementedPVZServiceServer) ListReceptions(context.Context, *ListReceptionsRequest) (*ListReceptionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReceptions not implemented")
+}
+func (UnimplementedPVZServiceServer) GetActiveReception(context.Context, *GetActiveReceptionRequest) (*Reception, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetActiveReception not implemented")
+}
+func (UnimplementedPVZServiceServer) AddProducts(grpc.ClientStreamingServer[AddProductRequest, AddProductsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method AddProducts not implemented")
+}
 func (UnimplementedPVZServiceServer) mustEmbedUnimplementedPVZServiceServer() {}
 func (UnimplementedPVZServiceServer) testEmbeddedByValue()                    {}
 
@@ -104,6 +171,67 @@ func _PVZService_GetPVZList_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PVZService_GetReception_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReceptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PVZServiceServer).GetReception(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PVZService_GetReception_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PVZServiceServer).GetReception(ctx, req.(*GetReceptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PVZService_ListReceptions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReceptionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PVZServiceServer).ListReceptions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PVZService_ListReceptions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PVZServiceServer).ListReceptions(ctx, req.(*ListReceptionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PVZService_GetActiveReception_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetActiveReceptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PVZServiceServer).GetActiveReception(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PVZService_GetActiveReception_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PVZServiceServer).GetActiveReception(ctx, req.(*GetActiveReceptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PVZService_AddProducts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PVZServiceServer).AddProducts(&grpc.GenericServerStream[AddProductRequest, AddProductsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PVZService_AddProductsServer = grpc.ClientStreamingServer[AddProductRequest, AddProductsResponse]
+
 // PVZService_ServiceDesc is the grpc.ServiceDesc for PVZService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,7 +243,25 @@ var PVZService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetPVZList",
 			Handler:    _PVZService_GetPVZList_Handler,
 		},
+		{
+			MethodName: "GetReception",
+			Handler:    _PVZService_GetReception_Handler,
+		},
+		{
+			MethodName: "ListReceptions",
+			Handler:    _PVZService_ListReceptions_Handler,
+		},
+		{
+			MethodName: "GetActiveReception",
+			Handler:    _PVZService_GetActiveReception_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "AddProducts",
+			Handler:       _PVZService_AddProducts_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/pvz.proto",
 }
@@ -0,0 +1,105 @@
+package grpc_handler
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	pb "github.com/Arzeeq/pvz-api/internal/grpc"
+	"github.com/Arzeeq/pvz-api/internal/service"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type ProductServicer interface {
+	CreateProducts(ctx context.Context, pvzID openapi_types.UUID, items []dto.ProductBatchItem) (*dto.ProductBatchResult, error)
+	BatchLimit() int
+}
+
+type ProductHandler struct {
+	service ProductServicer
+}
+
+func NewProductHandler(service ProductServicer) (*ProductHandler, error) {
+	if service == nil {
+		return nil, errors.New("nil value in constructor")
+	}
+
+	return &ProductHandler{service: service}, nil
+}
+
+// AddProducts reads products of one pvz from client stream and adds them as a single batch,
+// stream longer than batch limit is stopped without reading the rest of it
+func (h *ProductHandler) AddProducts(stream pb.PVZService_AddProductsServer) error {
+	limit := h.service.BatchLimit()
+	var pvzID openapi_types.UUID
+	var items []dto.ProductBatchItem
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		var itemPvzID openapi_types.UUID
+		if err := itemPvzID.UnmarshalText([]byte(req.GetPvzId())); err != nil {
+			return status.Error(codes.InvalidArgument, "invalid pvz id")
+		}
+		if len(items) == 0 {
+			pvzID = itemPvzID
+		} else if itemPvzID != pvzID {
+			return status.Error(codes.InvalidArgument, "all products must belong to one pvz")
+		}
+
+		items = append(items, dto.ProductBatchItem{
+			Type:    dto.ProductType(req.GetType()),
+			Barcode: req.Barcode,
+		})
+		if len(items) > limit {
+			return status.Error(codes.ResourceExhausted, service.ErrBatchSize.Error())
+		}
+	}
+
+	result, err := h.service.CreateProducts(stream.Context(), pvzID, items)
+	if errors.Is(err, service.ErrBatchSize) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if errors.Is(err, service.ErrNoActiveReception) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	results := make([]*pb.AddProductResult, 0, len(result.Results))
+	for _, r := range result.Results {
+		results = append(results, convertProductResultToProto(r))
+	}
+
+	return stream.SendAndClose(&pb.AddProductsResponse{Results: results})
+}
+
+func convertProductResultToProto(r dto.ProductBatchItemResult) *pb.AddProductResult {
+	result := &pb.AddProductResult{Index: int32(r.Index)}
+
+	switch r.Status {
+	case dto.BatchCreated:
+		result.Status = pb.AddProductStatus_ADD_PRODUCT_STATUS_CREATED
+	case dto.BatchExisting:
+		result.Status = pb.AddProductStatus_ADD_PRODUCT_STATUS_EXISTING
+	default:
+		result.Status = pb.AddProductStatus_ADD_PRODUCT_STATUS_FAILED
+	}
+	if r.Product != nil {
+		result.Product = convertProductToProto(*r.Product)
+	}
+	if r.Message != nil {
+		result.Message = *r.Message
+	}
+
+	return result
+}
@@ -0,0 +1,194 @@
+package grpc_handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	pb "github.com/Arzeeq/pvz-api/internal/grpc"
+	"github.com/Arzeeq/pvz-api/internal/service"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type ReceptionServicer interface {
+	GetReception(ctx context.Context, receptionID openapi_types.UUID) (*dto.ReceptionDetails, error)
+	GetReceptions(ctx context.Context, pvzID openapi_types.UUID, params dto.GetPvzPvzIdReceptionsParams) ([]dto.Reception, error)
+	GetActiveReception(ctx context.Context, pvzID openapi_types.UUID) (*dto.Reception, error)
+}
+
+type ReceptionHandler struct {
+	service ReceptionServicer
+}
+
+func NewReceptionHandler(service ReceptionServicer) (*ReceptionHandler, error) {
+	if service == nil {
+		return nil, errors.New("nil value in constructor")
+	}
+
+	return &ReceptionHandler{service: service}, nil
+}
+
+func (h *ReceptionHandler) GetReception(ctx context.Context, req *pb.GetReceptionRequest) (*pb.ReceptionDetails, error) {
+	var receptionID openapi_types.UUID
+	if err := receptionID.UnmarshalText([]byte(req.GetId())); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid reception id")
+	}
+
+	details, err := h.service.GetReception(ctx, receptionID)
+	if err != nil {
+		return nil, receptionError(err)
+	}
+
+	products := make([]*pb.Product, 0, len(details.Products))
+	for _, p := range details.Products {
+		products = append(products, convertProductToProto(p))
+	}
+
+	counts := make([]*pb.ProductTypeCount, 0, len(details.ProductCounts))
+	for _, c := range details.ProductCounts {
+		counts = append(counts, &pb.ProductTypeCount{
+			Type:  string(c.Type),
+			Count: int32(c.Count),
+		})
+	}
+
+	return &pb.ReceptionDetails{
+		Reception:     convertReceptionToProto(details.Reception),
+		Products:      products,
+		ProductCounts: counts,
+	}, nil
+}
+
+func (h *ReceptionHandler) ListReceptions(ctx context.Context, req *pb.ListReceptionsRequest) (*pb.ListReceptionsResponse, error) {
+	var pvzID openapi_types.UUID
+	if err := pvzID.UnmarshalText([]byte(req.GetPvzId())); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid pvz id")
+	}
+
+	var params dto.GetPvzPvzIdReceptionsParams
+	// status is filtered only when it is set, unknown status is not treated as in progress
+	if req.Status != nil {
+		var receptionStatus dto.GetPvzPvzIdReceptionsParamsStatus
+		switch req.GetStatus() {
+		case pb.ReceptionStatus_RECEPTION_STATUS_IN_PROGRESS:
+			receptionStatus = dto.StatusFilterInProgress
+		case pb.ReceptionStatus_RECEPTION_STATUS_CLOSED:
+			receptionStatus = dto.StatusFilterClose
+		default:
+			return nil, status.Error(codes.InvalidArgument, "invalid reception status")
+		}
+		params.Status = &receptionStatus
+	}
+	if req.StartDate != nil {
+		startDate := req.GetStartDate().AsTime()
+		params.StartDate = &startDate
+	}
+	if req.EndDate != nil {
+		endDate := req.GetEndDate().AsTime()
+		params.EndDate = &endDate
+	}
+	if req.GetPage() != 0 {
+		page := int(req.GetPage())
+		params.Page = &page
+	}
+	if req.GetLimit() != 0 {
+		limit := int(req.GetLimit())
+		params.Limit = &limit
+	}
+	dto.CorrectReceptionsParams(&params)
+
+	receptions, err := h.service.GetReceptions(ctx, pvzID, params)
+	if err != nil {
+		return nil, receptionError(err)
+	}
+
+	receptionProtos := make([]*pb.Reception, 0, len(receptions))
+	for _, r := range receptions {
+		receptionProtos = append(receptionProtos, convertReceptionToProto(r))
+	}
+
+	return &pb.ListReceptionsResponse{Receptions: receptionProtos}, nil
+}
+
+func (h *ReceptionHandler) GetActiveReception(ctx context.Context, req *pb.GetActiveReceptionRequest) (*pb.Reception, error) {
+	var pvzID openapi_types.UUID
+	if err := pvzID.UnmarshalText([]byte(req.GetPvzId())); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid pvz id")
+	}
+
+	reception, err := h.service.GetActiveReception(ctx, pvzID)
+	if err != nil {
+		return nil, receptionError(err)
+	}
+
+	return convertReceptionToProto(*reception), nil
+}
+
+func receptionError(err error) error {
+	if errors.Is(err, service.ErrReceptionNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func convertReceptionToProto(r dto.Reception) *pb.Reception {
+	receptionStatus := pb.ReceptionStatus_RECEPTION_STATUS_IN_PROGRESS
+	if r.Status == dto.Close {
+		receptionStatus = pb.ReceptionStatus_RECEPTION_STATUS_CLOSED
+	}
+
+	reception := &pb.Reception{
+		Id:       r.Id.String(),
+		DateTime: timestamppb.New(r.DateTime),
+		PvzId:    r.PvzId.String(),
+		Status:   receptionStatus,
+	}
+	if r.ClosedAt != nil {
+		reception.ClosedAt = timestamppb.New(*r.ClosedAt)
+	}
+	if r.OpenedBy != nil {
+		reception.OpenedBy = r.OpenedBy.String()
+	}
+	if r.ClosedBy != nil {
+		reception.ClosedBy = r.ClosedBy.String()
+	}
+
+	return reception
+}
+
+func convertProductToProto(p dto.Product) *pb.Product {
+	var id string
+	if p.Id != nil {
+		id = p.Id.String()
+	}
+
+	var dateTime *timestamppb.Timestamp
+	if p.DateTime != nil {
+		dateTime = timestamppb.New(*p.DateTime)
+	}
+
+	product := &pb.Product{
+		Id:          id,
+		DateTime:    dateTime,
+		Type:        string(p.Type),
+		ReceptionId: p.ReceptionId.String(),
+		Barcode:     p.Barcode,
+	}
+	if p.Status != nil {
+		product.Status = string(*p.Status)
+	}
+	if p.StoredAt != nil {
+		product.StoredAt = timestamppb.New(*p.StoredAt)
+	}
+	if p.IssuedAt != nil {
+		product.IssuedAt = timestamppb.New(*p.IssuedAt)
+	}
+	if p.ReturnedAt != nil {
+		product.ReturnedAt = timestamppb.New(*p.ReturnedAt)
+	}
+
+	return product
+}
@@ -19,7 +19,7 @@ type UserServicer interface {
 }
 
 type TokenServicer interface {
-	Gen(role string) (dto.Token, error)
+	Gen(role, userID string) (dto.Token, error)
 }
 
 type AuthHandler struct {
@@ -60,7 +60,7 @@ func (h *AuthHandler) DummyLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.tokenService.Gen(string(roleDto.Role))
+	token, err := h.tokenService.Gen(string(roleDto.Role), "")
 	if err != nil {
 		h.log.HTTPError(w, http.StatusBadRequest, err)
 		return
@@ -80,7 +80,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	ctx, cancel := requestContext(r, h.timeout)
 	defer cancel()
 
 	user, err := h.userService.RegisterUser(ctx, userDto)
@@ -99,7 +99,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	ctx, cancel := requestContext(r, h.timeout)
 	defer cancel()
 
 	token, err := h.userService.LoginUser(ctx, userDto)
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Arzeeq/pvz-api/internal/logger"
+	"github.com/Arzeeq/pvz-api/internal/middleware"
+	"github.com/Arzeeq/pvz-api/internal/service"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type CellServicer interface {
+	CreateCell(ctx context.Context, pvzID openapi_types.UUID, payload dto.PostPvzPvzIdCellsJSONBody) (*dto.Cell, error)
+	GetCells(ctx context.Context, pvzID openapi_types.UUID) ([]dto.Cell, error)
+	SuggestCell(ctx context.Context, productID openapi_types.UUID) (*dto.Cell, error)
+	PlaceProduct(
+		ctx context.Context,
+		productID openapi_types.UUID,
+		cellID *openapi_types.UUID,
+		userID *openapi_types.UUID,
+	) (*dto.ProductLocation, error)
+	MoveProduct(
+		ctx context.Context,
+		productID openapi_types.UUID,
+		cellID openapi_types.UUID,
+		userID *openapi_types.UUID,
+	) (*dto.ProductLocation, error)
+	GetProductLocations(ctx context.Context, params dto.GetProductsLocationParams) ([]dto.ProductLocation, error)
+}
+
+type CellHandler struct {
+	cellService CellServicer
+	log         *logger.MyLogger
+	timeout     time.Duration
+}
+
+func NewCellHandler(cellService CellServicer, logger *logger.MyLogger, timeout time.Duration) (*CellHandler, error) {
+	if cellService == nil || logger == nil {
+		return nil, errors.New("nil values in NewCellHandler constructor")
+	}
+
+	return &CellHandler{
+		cellService: cellService,
+		log:         logger,
+		timeout:     timeout,
+	}, nil
+}
+
+func (h *CellHandler) CreateCell(w http.ResponseWriter, r *http.Request) {
+	var pvzId openapi_types.UUID
+	if err := pvzId.UnmarshalText([]byte(r.PathValue("pvzId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var cellDto dto.PostPvzPvzIdCellsJSONBody
+	if err := dto.Parse(r.Body, &cellDto); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	cell, err := h.cellService.CreateCell(ctx, pvzId, cellDto)
+	if err != nil {
+		h.log.HTTPError(w, cellErrorStatus(err), err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusCreated, cell)
+}
+
+func (h *CellHandler) GetCells(w http.ResponseWriter, r *http.Request) {
+	var pvzId openapi_types.UUID
+	if err := pvzId.UnmarshalText([]byte(r.PathValue("pvzId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	cells, err := h.cellService.GetCells(ctx, pvzId)
+	if err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, cells)
+}
+
+func (h *CellHandler) SuggestCell(w http.ResponseWriter, r *http.Request) {
+	var productId openapi_types.UUID
+	if err := productId.UnmarshalText([]byte(r.PathValue("productId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	cell, err := h.cellService.SuggestCell(ctx, productId)
+	if err != nil {
+		h.log.HTTPError(w, cellErrorStatus(err), err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, cell)
+}
+
+func (h *CellHandler) PlaceProduct(w http.ResponseWriter, r *http.Request) {
+	var productId openapi_types.UUID
+	if err := productId.UnmarshalText([]byte(r.PathValue("productId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// body is optional, without it product is placed into suggested cell
+	var placeDto dto.PostProductsProductIdPlaceJSONBody
+	if r.ContentLength != 0 {
+		if err := dto.Parse(r.Body, &placeDto); err != nil {
+			h.log.HTTPError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	location, err := h.cellService.PlaceProduct(ctx, productId, placeDto.CellId, middleware.UserIDFromContext(r.Context()))
+	if err != nil {
+		h.log.HTTPError(w, cellErrorStatus(err), err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, location)
+}
+
+func (h *CellHandler) MoveProduct(w http.ResponseWriter, r *http.Request) {
+	var productId openapi_types.UUID
+	if err := productId.UnmarshalText([]byte(r.PathValue("productId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var moveDto dto.PostProductsProductIdMoveJSONBody
+	if err := dto.Parse(r.Body, &moveDto); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	location, err := h.cellService.MoveProduct(ctx, productId, moveDto.CellId, middleware.UserIDFromContext(r.Context()))
+	if err != nil {
+		h.log.HTTPError(w, cellErrorStatus(err), err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, location)
+}
+
+func (h *CellHandler) GetProductLocations(w http.ResponseWriter, r *http.Request) {
+	var params dto.GetProductsLocationParams
+	if err := params.FromParams(r); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	locations, err := h.cellService.GetProductLocations(ctx, params)
+	if err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, locations)
+}
+
+func cellErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrProductNotFound), errors.Is(err, service.ErrCellNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrCellExists),
+		errors.Is(err, service.ErrCellFull),
+		errors.Is(err, service.ErrNoFreeCell),
+		errors.Is(err, service.ErrProductPlaced),
+		errors.Is(err, service.ErrProductNotPlaced),
+		errors.Is(err, service.ErrProductNotOnShelf):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
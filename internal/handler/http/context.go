@@ -0,0 +1,15 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/middleware"
+)
+
+// requestContext derives context for handling request from the request itself, so it is
+// cancelled when client disconnects, and limits it by timeout of the route
+func requestContext(r *http.Request, defaultTimeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), middleware.TimeoutFromContext(r.Context(), defaultTimeout))
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Arzeeq/pvz-api/internal/logger"
+	"github.com/Arzeeq/pvz-api/internal/service"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type ExpiryServicer interface {
+	GetReturnList(ctx context.Context, pvzID openapi_types.UUID) ([]dto.Product, error)
+	ConfirmReturns(ctx context.Context, pvzID openapi_types.UUID, payload dto.PostPvzPvzIdReturnsConfirmJSONBody) ([]dto.Product, error)
+}
+
+type ExpiryHandler struct {
+	expiryService ExpiryServicer
+	log           *logger.MyLogger
+	timeout       time.Duration
+}
+
+func NewExpiryHandler(expiryService ExpiryServicer, logger *logger.MyLogger, timeout time.Duration) (*ExpiryHandler, error) {
+	if expiryService == nil || logger == nil {
+		return nil, errors.New("nil values in NewExpiryHandler constructor")
+	}
+
+	return &ExpiryHandler{
+		expiryService: expiryService,
+		log:           logger,
+		timeout:       timeout,
+	}, nil
+}
+
+func (h *ExpiryHandler) GetReturnList(w http.ResponseWriter, r *http.Request) {
+	var pvzId openapi_types.UUID
+	if err := pvzId.UnmarshalText([]byte(r.PathValue("pvzId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	products, err := h.expiryService.GetReturnList(ctx, pvzId)
+	if err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, products)
+}
+
+func (h *ExpiryHandler) ConfirmReturns(w http.ResponseWriter, r *http.Request) {
+	var pvzId openapi_types.UUID
+	if err := pvzId.UnmarshalText([]byte(r.PathValue("pvzId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var returnsDto dto.PostPvzPvzIdReturnsConfirmJSONBody
+	if err := dto.Parse(r.Body, &returnsDto); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	products, err := h.expiryService.ConfirmReturns(ctx, pvzId, returnsDto)
+	if errors.Is(err, service.ErrProductsNotInList) {
+		h.log.HTTPError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, products)
+}
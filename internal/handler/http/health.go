@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Arzeeq/pvz-api/internal/logger"
+)
+
+const healthStatusOK = "ok"
+
+type HealthServicer interface {
+	Ready(ctx context.Context) error
+}
+
+type HealthHandler struct {
+	healthService HealthServicer
+	log           *logger.MyLogger
+	timeout       time.Duration
+}
+
+func NewHealthHandler(healthService HealthServicer, logger *logger.MyLogger, timeout time.Duration) (*HealthHandler, error) {
+	if healthService == nil || logger == nil {
+		return nil, errors.New("nil values in NewHealthHandler constructor")
+	}
+
+	return &HealthHandler{
+		healthService: healthService,
+		log:           logger,
+		timeout:       timeout,
+	}, nil
+}
+
+// Healthz reports that process is alive, it does not check dependencies
+func (h *HealthHandler) Healthz(w http.ResponseWriter, r *http.Request) {
+	h.log.HTTPResponse(w, http.StatusOK, dto.HealthStatus{Status: healthStatusOK})
+}
+
+// Readyz reports whether application can serve requests
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	if err := h.healthService.Ready(ctx); err != nil {
+		h.log.HTTPError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, dto.HealthStatus{Status: healthStatusOK})
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Arzeeq/pvz-api/internal/logger"
+	"github.com/Arzeeq/pvz-api/internal/middleware"
+	"github.com/Arzeeq/pvz-api/internal/service"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type InventoryServicer interface {
+	StartInventory(ctx context.Context, pvzID openapi_types.UUID, userID *openapi_types.UUID) (*dto.Inventory, error)
+	GetInventory(ctx context.Context, inventoryID openapi_types.UUID) (*dto.Inventory, error)
+	GetPVZInventories(ctx context.Context, pvzID openapi_types.UUID) ([]dto.Inventory, error)
+	Scan(ctx context.Context, inventoryID openapi_types.UUID, scan dto.PostInventoriesInventoryIdScansJSONBody, userID *openapi_types.UUID) (*dto.Inventory, error)
+	CompleteInventory(ctx context.Context, inventoryID openapi_types.UUID, userID *openapi_types.UUID) (*dto.Inventory, error)
+}
+
+type InventoryHandler struct {
+	inventoryService InventoryServicer
+	log              *logger.MyLogger
+	timeout          time.Duration
+}
+
+func NewInventoryHandler(inventoryService InventoryServicer, logger *logger.MyLogger, timeout time.Duration) (*InventoryHandler, error) {
+	if inventoryService == nil || logger == nil {
+		return nil, errors.New("nil values in NewInventoryHandler constructor")
+	}
+
+	return &InventoryHandler{
+		inventoryService: inventoryService,
+		log:              logger,
+		timeout:          timeout,
+	}, nil
+}
+
+func (h *InventoryHandler) StartInventory(w http.ResponseWriter, r *http.Request) {
+	var pvzId openapi_types.UUID
+	if err := pvzId.UnmarshalText([]byte(r.PathValue("pvzId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	inventory, err := h.inventoryService.StartInventory(ctx, pvzId, middleware.UserIDFromContext(r.Context()))
+	if err != nil {
+		h.log.HTTPError(w, inventoryErrorStatus(err), err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusCreated, inventory)
+}
+
+func (h *InventoryHandler) GetPVZInventories(w http.ResponseWriter, r *http.Request) {
+	var pvzId openapi_types.UUID
+	if err := pvzId.UnmarshalText([]byte(r.PathValue("pvzId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	inventories, err := h.inventoryService.GetPVZInventories(ctx, pvzId)
+	if err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, inventories)
+}
+
+func (h *InventoryHandler) GetInventory(w http.ResponseWriter, r *http.Request) {
+	var inventoryId openapi_types.UUID
+	if err := inventoryId.UnmarshalText([]byte(r.PathValue("inventoryId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	inventory, err := h.inventoryService.GetInventory(ctx, inventoryId)
+	if err != nil {
+		h.log.HTTPError(w, inventoryErrorStatus(err), err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, inventory)
+}
+
+func (h *InventoryHandler) Scan(w http.ResponseWriter, r *http.Request) {
+	var inventoryId openapi_types.UUID
+	if err := inventoryId.UnmarshalText([]byte(r.PathValue("inventoryId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var scanDto dto.PostInventoriesInventoryIdScansJSONBody
+	if err := dto.Parse(r.Body, &scanDto); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	inventory, err := h.inventoryService.Scan(ctx, inventoryId, scanDto, middleware.UserIDFromContext(r.Context()))
+	if err != nil {
+		h.log.HTTPError(w, inventoryErrorStatus(err), err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, inventory)
+}
+
+func (h *InventoryHandler) CompleteInventory(w http.ResponseWriter, r *http.Request) {
+	var inventoryId openapi_types.UUID
+	if err := inventoryId.UnmarshalText([]byte(r.PathValue("inventoryId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	inventory, err := h.inventoryService.CompleteInventory(ctx, inventoryId, middleware.UserIDFromContext(r.Context()))
+	if err != nil {
+		h.log.HTTPError(w, inventoryErrorStatus(err), err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, inventory)
+}
+
+func inventoryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInventoryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInventoryActive), errors.Is(err, service.ErrInventoryCompleted):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Arzeeq/pvz-api/internal/logger"
+	"github.com/Arzeeq/pvz-api/internal/middleware"
+	"github.com/Arzeeq/pvz-api/internal/service"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type OrderServicer interface {
+	CreateOrder(ctx context.Context, payload dto.PostOrdersJSONBody, userID *openapi_types.UUID) (*dto.Order, error)
+	GetOrder(ctx context.Context, orderID openapi_types.UUID) (*dto.Order, error)
+	GeneratePickupCode(ctx context.Context, orderID openapi_types.UUID) (*dto.PickupCode, error)
+	Pickup(
+		ctx context.Context,
+		pvzID openapi_types.UUID,
+		payload dto.PostPvzPvzIdPickupJSONBody,
+		userID *openapi_types.UUID,
+	) (*dto.Order, error)
+}
+
+type OrderHandler struct {
+	orderService OrderServicer
+	log          *logger.MyLogger
+	timeout      time.Duration
+}
+
+func NewOrderHandler(orderService OrderServicer, logger *logger.MyLogger, timeout time.Duration) (*OrderHandler, error) {
+	if orderService == nil || logger == nil {
+		return nil, errors.New("nil values in NewOrderHandler constructor")
+	}
+
+	return &OrderHandler{
+		orderService: orderService,
+		log:          logger,
+		timeout:      timeout,
+	}, nil
+}
+
+func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
+	var orderDto dto.PostOrdersJSONBody
+	if err := dto.Parse(r.Body, &orderDto); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	order, err := h.orderService.CreateOrder(ctx, orderDto, middleware.UserIDFromContext(r.Context()))
+	if err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusCreated, order)
+}
+
+func (h *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	var orderId openapi_types.UUID
+	if err := orderId.UnmarshalText([]byte(r.PathValue("orderId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	order, err := h.orderService.GetOrder(ctx, orderId)
+	if err != nil {
+		h.log.HTTPError(w, orderErrorStatus(err), err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, order)
+}
+
+func (h *OrderHandler) GeneratePickupCode(w http.ResponseWriter, r *http.Request) {
+	var orderId openapi_types.UUID
+	if err := orderId.UnmarshalText([]byte(r.PathValue("orderId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	code, err := h.orderService.GeneratePickupCode(ctx, orderId)
+	if err != nil {
+		h.log.HTTPError(w, orderErrorStatus(err), err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusCreated, code)
+}
+
+func (h *OrderHandler) Pickup(w http.ResponseWriter, r *http.Request) {
+	var pvzId openapi_types.UUID
+	if err := pvzId.UnmarshalText([]byte(r.PathValue("pvzId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var pickupDto dto.PostPvzPvzIdPickupJSONBody
+	if err := dto.Parse(r.Body, &pickupDto); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	order, err := h.orderService.Pickup(ctx, pvzId, pickupDto, middleware.UserIDFromContext(r.Context()))
+	if err != nil {
+		h.log.HTTPError(w, orderErrorStatus(err), err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, order)
+}
+
+func orderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrOrderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidPickupCode):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrPickupLocked):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusBadRequest
+	}
+}
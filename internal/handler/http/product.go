@@ -8,13 +8,17 @@ import (
 
 	"github.com/Arzeeq/pvz-api/internal/dto"
 	"github.com/Arzeeq/pvz-api/internal/logger"
+	"github.com/Arzeeq/pvz-api/internal/service"
 	"github.com/go-playground/validator/v10"
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 type ProductServicer interface {
-	CreateProduct(ctx context.Context, productDto dto.PostProductsJSONBody) (*dto.Product, error)
-	DeleteLastProduct(ctx context.Context, pvzID openapi_types.UUID) error
+	CreateProduct(ctx context.Context, productDto dto.PostProductsJSONBody) (*dto.Product, bool, error)
+	CreateProducts(ctx context.Context, pvzID openapi_types.UUID, items []dto.ProductBatchItem) (*dto.ProductBatchResult, error)
+	DeleteLastProduct(ctx context.Context, pvzID openapi_types.UUID, userID *openapi_types.UUID) error
+	GetProducts(ctx context.Context, params dto.GetProductsParams) ([]dto.Product, error)
+	ChangeProductStatus(ctx context.Context, productID openapi_types.UUID, status dto.ProductStatus) (*dto.Product, error)
 }
 
 type ProductHandler struct {
@@ -48,14 +52,99 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	ctx, cancel := requestContext(r, h.timeout)
 	defer cancel()
 
-	product, err := h.productService.CreateProduct(ctx, productDto)
+	product, created, err := h.productService.CreateProduct(ctx, productDto)
+	if errors.Is(err, service.ErrDuplicateBarcode) {
+		h.log.HTTPError(w, http.StatusConflict, err)
+		return
+	}
 	if err != nil {
 		h.log.HTTPError(w, http.StatusBadRequest, err)
 		return
 	}
 
+	if !created {
+		h.log.HTTPResponse(w, http.StatusOK, product)
+		return
+	}
 	h.log.HTTPResponse(w, http.StatusCreated, product)
 }
+
+func (h *ProductHandler) CreateProducts(w http.ResponseWriter, r *http.Request) {
+	var batch dto.PostProductsBatchJSONBody
+	if err := dto.Parse(r.Body, &batch); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	result, err := h.productService.CreateProducts(ctx, batch.PvzId, batch.Products)
+	if err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, result)
+}
+
+func (h *ProductHandler) GetProducts(w http.ResponseWriter, r *http.Request) {
+	var params dto.GetProductsParams
+	if err := params.FromParams(r); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	products, err := h.productService.GetProducts(ctx, params)
+	if err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, products)
+}
+
+func (h *ProductHandler) StoreProduct(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, dto.ProductStored)
+}
+
+func (h *ProductHandler) IssueProduct(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, dto.ProductIssued)
+}
+
+func (h *ProductHandler) ReturnProduct(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, dto.ProductReturnedToSender)
+}
+
+func (h *ProductHandler) changeStatus(w http.ResponseWriter, r *http.Request, status dto.ProductStatus) {
+	var productId openapi_types.UUID
+	if err := productId.UnmarshalText([]byte(r.PathValue("productId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	product, err := h.productService.ChangeProductStatus(ctx, productId, status)
+	if errors.Is(err, service.ErrProductNotFound) {
+		h.log.HTTPError(w, http.StatusNotFound, err)
+		return
+	}
+	if errors.Is(err, service.ErrInvalidTransition) || errors.Is(err, service.ErrProductExpired) {
+		h.log.HTTPError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, product)
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/Arzeeq/pvz-api/internal/dto"
 	"github.com/Arzeeq/pvz-api/internal/logger"
+	"github.com/Arzeeq/pvz-api/internal/middleware"
+	"github.com/Arzeeq/pvz-api/internal/service"
 	"github.com/go-playground/validator/v10"
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
@@ -58,7 +60,7 @@ func (h *PVZHandler) CreatePvz(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	ctx, cancel := requestContext(r, h.timeout)
 	defer cancel()
 
 	pvz, err := h.pvzService.CreatePVZ(ctx, pvzDto)
@@ -78,7 +80,7 @@ func (h *PVZHandler) GetPVZ(w http.ResponseWriter, r *http.Request) {
 	}
 	dto.CorrectParams(&pvzDto)
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	ctx, cancel := requestContext(r, h.timeout)
 	defer cancel()
 
 	pvzs := h.pvzService.GetPVZWithReceptionsFiltered(ctx, pvzDto)
@@ -94,10 +96,63 @@ func (h *PVZHandler) CloseReception(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	ctx, cancel := requestContext(r, h.timeout)
 	defer cancel()
 
-	reception, err := h.receptionService.CloseReception(ctx, pvzId)
+	reception, err := h.receptionService.CloseReception(ctx, pvzId, middleware.UserIDFromContext(r.Context()))
+	if err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, reception)
+}
+
+func (h *PVZHandler) GetReceptions(w http.ResponseWriter, r *http.Request) {
+	pathValue := r.PathValue("pvzId")
+	var pvzId openapi_types.UUID
+	err := pvzId.UnmarshalText([]byte(pathValue))
+	if err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var params dto.GetPvzPvzIdReceptionsParams
+	if err := params.FromParams(r); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+	dto.CorrectReceptionsParams(&params)
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	receptions, err := h.receptionService.GetReceptions(ctx, pvzId, params)
+	if err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, receptions)
+}
+
+func (h *PVZHandler) GetActiveReception(w http.ResponseWriter, r *http.Request) {
+	pathValue := r.PathValue("pvzId")
+	var pvzId openapi_types.UUID
+	err := pvzId.UnmarshalText([]byte(pathValue))
+	if err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	reception, err := h.receptionService.GetActiveReception(ctx, pvzId)
+	if errors.Is(err, service.ErrReceptionNotFound) {
+		h.log.HTTPError(w, http.StatusNotFound, err)
+		return
+	}
 	if err != nil {
 		h.log.HTTPError(w, http.StatusBadRequest, err)
 		return
@@ -115,10 +170,10 @@ func (h *PVZHandler) DeleteLastProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	ctx, cancel := requestContext(r, h.timeout)
 	defer cancel()
 
-	err = h.productService.DeleteLastProduct(ctx, pvzId)
+	err = h.productService.DeleteLastProduct(ctx, pvzId, middleware.UserIDFromContext(r.Context()))
 	if err != nil {
 		h.log.HTTPError(w, http.StatusBadRequest, err)
 		return
@@ -8,13 +8,24 @@ import (
 
 	"github.com/Arzeeq/pvz-api/internal/dto"
 	"github.com/Arzeeq/pvz-api/internal/logger"
+	"github.com/Arzeeq/pvz-api/internal/middleware"
+	"github.com/Arzeeq/pvz-api/internal/service"
 	"github.com/go-playground/validator/v10"
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 type ReceptionServicer interface {
-	CreateReception(ctx context.Context, pvzID openapi_types.UUID) (*dto.Reception, error)
-	CloseReception(ctx context.Context, pvzID openapi_types.UUID) (*dto.Reception, error)
+	CreateReception(
+		ctx context.Context,
+		pvzID openapi_types.UUID,
+		manifest *dto.ReceptionManifest,
+		userID *openapi_types.UUID,
+	) (*dto.Reception, error)
+	CloseReception(ctx context.Context, pvzID openapi_types.UUID, userID *openapi_types.UUID) (*dto.Reception, error)
+	GetReception(ctx context.Context, receptionID openapi_types.UUID) (*dto.ReceptionDetails, error)
+	GetReceptions(ctx context.Context, pvzID openapi_types.UUID, params dto.GetPvzPvzIdReceptionsParams) ([]dto.Reception, error)
+	GetActiveReception(ctx context.Context, pvzID openapi_types.UUID) (*dto.Reception, error)
+	DeleteProduct(ctx context.Context, receptionID, productID openapi_types.UUID, userID *openapi_types.UUID) error
 }
 
 type ReceptionHandler struct {
@@ -44,10 +55,10 @@ func (h *ReceptionHandler) CreateReception(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	ctx, cancel := requestContext(r, h.timeout)
 	defer cancel()
 
-	user, err := h.receptionService.CreateReception(ctx, receptionDto.PvzId)
+	user, err := h.receptionService.CreateReception(ctx, receptionDto.PvzId, receptionDto.Manifest, middleware.UserIDFromContext(r.Context()))
 	if err != nil {
 		h.log.HTTPError(w, http.StatusBadRequest, err)
 		return
@@ -55,3 +66,53 @@ func (h *ReceptionHandler) CreateReception(w http.ResponseWriter, r *http.Reques
 
 	h.log.HTTPResponse(w, http.StatusCreated, user)
 }
+
+func (h *ReceptionHandler) GetReception(w http.ResponseWriter, r *http.Request) {
+	pathValue := r.PathValue("receptionId")
+	var receptionId openapi_types.UUID
+	err := receptionId.UnmarshalText([]byte(pathValue))
+	if err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	details, err := h.receptionService.GetReception(ctx, receptionId)
+	if errors.Is(err, service.ErrReceptionNotFound) {
+		h.log.HTTPError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, details)
+}
+
+func (h *ReceptionHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
+	var receptionId, productId openapi_types.UUID
+	if err := receptionId.UnmarshalText([]byte(r.PathValue("receptionId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := productId.UnmarshalText([]byte(r.PathValue("productId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	err := h.receptionService.DeleteProduct(ctx, receptionId, productId, middleware.UserIDFromContext(r.Context()))
+	if errors.Is(err, service.ErrReceptionNotFound) || errors.Is(err, service.ErrProductNotFound) {
+		h.log.HTTPError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Arzeeq/pvz-api/internal/logger"
+	"github.com/Arzeeq/pvz-api/internal/middleware"
+)
+
+type SyncServicer interface {
+	ApplyBatch(ctx context.Context, batch dto.SyncBatch, role dto.UserRole) (*dto.SyncBatchResult, error)
+}
+
+type NodeSyncServicer interface {
+	GetStatus(ctx context.Context) (*dto.SyncStatus, error)
+}
+
+// SyncHandler accepts changes uploaded by offline PVZ nodes on the central server
+type SyncHandler struct {
+	syncService SyncServicer
+	log         *logger.MyLogger
+	timeout     time.Duration
+}
+
+func NewSyncHandler(syncService SyncServicer, logger *logger.MyLogger, timeout time.Duration) (*SyncHandler, error) {
+	if syncService == nil || logger == nil {
+		return nil, errors.New("nil values in NewSyncHandler constructor")
+	}
+
+	return &SyncHandler{
+		syncService: syncService,
+		log:         logger,
+		timeout:     timeout,
+	}, nil
+}
+
+func (h *SyncHandler) UploadBatch(w http.ResponseWriter, r *http.Request) {
+	var batch dto.PostSyncBatchesJSONRequestBody
+	if err := dto.Parse(r.Body, &batch); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	result, err := h.syncService.ApplyBatch(ctx, batch, middleware.RoleFromContext(r.Context()))
+	if err != nil {
+		// batch is rolled back as a whole, node uploads it again
+		h.log.HTTPError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, result)
+}
+
+// NodeSyncHandler shows state of uploading local changes on offline PVZ node
+type NodeSyncHandler struct {
+	nodeSyncService NodeSyncServicer
+	log             *logger.MyLogger
+	timeout         time.Duration
+}
+
+func NewNodeSyncHandler(nodeSyncService NodeSyncServicer, logger *logger.MyLogger, timeout time.Duration) (*NodeSyncHandler, error) {
+	if nodeSyncService == nil || logger == nil {
+		return nil, errors.New("nil values in NewNodeSyncHandler constructor")
+	}
+
+	return &NodeSyncHandler{
+		nodeSyncService: nodeSyncService,
+		log:             logger,
+		timeout:         timeout,
+	}, nil
+}
+
+func (h *NodeSyncHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	status, err := h.nodeSyncService.GetStatus(ctx)
+	if err != nil {
+		h.log.HTTPError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, status)
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Arzeeq/pvz-api/internal/logger"
+	"github.com/Arzeeq/pvz-api/internal/middleware"
+	"github.com/Arzeeq/pvz-api/internal/service"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type TransferServicer interface {
+	CreateTransfer(ctx context.Context, payload dto.PostTransfersJSONBody, userID *openapi_types.UUID) (*dto.Transfer, error)
+	GetTransfer(ctx context.Context, transferID openapi_types.UUID) (*dto.Transfer, error)
+	GetPVZTransfers(ctx context.Context, pvzID openapi_types.UUID, params dto.GetPvzPvzIdTransfersParams) ([]dto.Transfer, error)
+	ShipTransfer(ctx context.Context, transferID openapi_types.UUID, userID *openapi_types.UUID) (*dto.Transfer, error)
+	ReceiveTransfer(ctx context.Context, transferID openapi_types.UUID, userID *openapi_types.UUID) (*dto.Transfer, error)
+	GetProductPath(ctx context.Context, productID openapi_types.UUID) ([]dto.ProductPathStep, error)
+}
+
+type TransferHandler struct {
+	transferService TransferServicer
+	log             *logger.MyLogger
+	timeout         time.Duration
+}
+
+func NewTransferHandler(transferService TransferServicer, logger *logger.MyLogger, timeout time.Duration) (*TransferHandler, error) {
+	if transferService == nil || logger == nil {
+		return nil, errors.New("nil values in NewTransferHandler constructor")
+	}
+
+	return &TransferHandler{
+		transferService: transferService,
+		log:             logger,
+		timeout:         timeout,
+	}, nil
+}
+
+func (h *TransferHandler) CreateTransfer(w http.ResponseWriter, r *http.Request) {
+	var transferDto dto.PostTransfersJSONBody
+	if err := dto.Parse(r.Body, &transferDto); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	transfer, err := h.transferService.CreateTransfer(ctx, transferDto, middleware.UserIDFromContext(r.Context()))
+	if err != nil {
+		h.log.HTTPError(w, transferErrorStatus(err), err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusCreated, transfer)
+}
+
+func (h *TransferHandler) GetTransfer(w http.ResponseWriter, r *http.Request) {
+	var transferId openapi_types.UUID
+	if err := transferId.UnmarshalText([]byte(r.PathValue("transferId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	transfer, err := h.transferService.GetTransfer(ctx, transferId)
+	if err != nil {
+		h.log.HTTPError(w, transferErrorStatus(err), err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, transfer)
+}
+
+func (h *TransferHandler) GetPVZTransfers(w http.ResponseWriter, r *http.Request) {
+	var pvzId openapi_types.UUID
+	if err := pvzId.UnmarshalText([]byte(r.PathValue("pvzId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var params dto.GetPvzPvzIdTransfersParams
+	if err := params.FromParams(r); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	transfers, err := h.transferService.GetPVZTransfers(ctx, pvzId, params)
+	if err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, transfers)
+}
+
+func (h *TransferHandler) ShipTransfer(w http.ResponseWriter, r *http.Request) {
+	var transferId openapi_types.UUID
+	if err := transferId.UnmarshalText([]byte(r.PathValue("transferId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	transfer, err := h.transferService.ShipTransfer(ctx, transferId, middleware.UserIDFromContext(r.Context()))
+	if err != nil {
+		h.log.HTTPError(w, transferErrorStatus(err), err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, transfer)
+}
+
+func (h *TransferHandler) ReceiveTransfer(w http.ResponseWriter, r *http.Request) {
+	var transferId openapi_types.UUID
+	if err := transferId.UnmarshalText([]byte(r.PathValue("transferId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	transfer, err := h.transferService.ReceiveTransfer(ctx, transferId, middleware.UserIDFromContext(r.Context()))
+	if errors.Is(err, service.ErrTransferBarcodeConflict) {
+		// clashing products are marked in items of transfer
+		h.log.HTTPResponse(w, http.StatusConflict, transfer)
+		return
+	}
+	if err != nil {
+		h.log.HTTPError(w, transferErrorStatus(err), err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, transfer)
+}
+
+func (h *TransferHandler) GetProductPath(w http.ResponseWriter, r *http.Request) {
+	var productId openapi_types.UUID
+	if err := productId.UnmarshalText([]byte(r.PathValue("productId"))); err != nil {
+		h.log.HTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := requestContext(r, h.timeout)
+	defer cancel()
+
+	path, err := h.transferService.GetProductPath(ctx, productId)
+	if err != nil {
+		h.log.HTTPError(w, transferErrorStatus(err), err)
+		return
+	}
+
+	h.log.HTTPResponse(w, http.StatusOK, path)
+}
+
+func transferErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrTransferNotFound), errors.Is(err, service.ErrProductNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrTransferStatus),
+		errors.Is(err, service.ErrTransferProductsUnavailable):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Arzeeq/pvz-api/internal/config"
+)
+
+// formatHandler writes records with text or json handler chosen at the moment of writing,
+// so format of logger and loggers derived from it can be changed while they are used
+type formatHandler struct {
+	text slog.Handler
+	json slog.Handler
+	// live is shared by all handlers derived from the same logger
+	live *config.Live
+}
+
+func (h formatHandler) current() slog.Handler {
+	if h.live.Load().LogJSON {
+		return h.json
+	}
+
+	return h.text
+}
+
+func (h formatHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.current().Enabled(ctx, level)
+}
+
+func (h formatHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.current().Handle(ctx, record)
+}
+
+func (h formatHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return formatHandler{text: h.text.WithAttrs(attrs), json: h.json.WithAttrs(attrs), live: h.live}
+}
+
+func (h formatHandler) WithGroup(name string) slog.Handler {
+	return formatHandler{text: h.text.WithGroup(name), json: h.json.WithGroup(name), live: h.live}
+}
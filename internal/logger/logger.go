@@ -1,8 +1,8 @@
 package logger
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
@@ -12,38 +12,33 @@ import (
 )
 
 const (
-	LogFormatText = "text"
-	LogFormatJson = "json"
+	LogFormatText = config.LoggerFormatText
+	LogFormatJson = config.LoggerFormatJSON
 )
 
 type MyLogger struct {
 	*slog.Logger
 }
 
-func New(env string, format string) *MyLogger {
-	var loggerLevel slog.Level
+// New creates logger writing to stdout, level and format are read from live config on every record,
+// so reload changes them for logger and all loggers derived from it
+func New(live *config.Live) *MyLogger {
+	options := &slog.HandlerOptions{Level: liveLevel{live: live}}
 
-	switch env {
-	case config.EnvTest:
-		loggerLevel = slog.LevelDebug
-	case config.EnvDev:
-		loggerLevel = slog.LevelDebug
-	case config.EnvProd:
-		loggerLevel = slog.LevelInfo
-	}
+	return &MyLogger{Logger: slog.New(contextHandler{formatHandler{
+		text: slog.NewTextHandler(os.Stdout, options),
+		json: slog.NewJSONHandler(os.Stdout, options),
+		live: live,
+	}})}
+}
 
-	var logger *slog.Logger
-	switch format {
-	case LogFormatJson:
-		logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: loggerLevel}))
-	case LogFormatText:
-		logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: loggerLevel}))
-	default:
-		logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: loggerLevel}))
-		logger.Warn(fmt.Sprintf("unsupported logging format %s, using default format instead", format))
-	}
+// liveLevel is level of current live config
+type liveLevel struct {
+	live *config.Live
+}
 
-	return &MyLogger{logger}
+func (l liveLevel) Level() slog.Level {
+	return l.live.Load().LogLevel
 }
 
 func (l *MyLogger) WrapError(msg string, err error, args ...any) {
@@ -51,6 +46,12 @@ func (l *MyLogger) WrapError(msg string, err error, args ...any) {
 	l.Error(msg, args...)
 }
 
+// WrapErrorContext logs error with request id from ctx
+func (l *MyLogger) WrapErrorContext(ctx context.Context, msg string, err error, args ...any) {
+	args = append(args, slog.String("error", err.Error()))
+	l.ErrorContext(ctx, msg, args...)
+}
+
 func (l *MyLogger) HTTPResponse(w http.ResponseWriter, status int, payload any) {
 	w.Header().Add("Content-Type", "application/json")
 	w.WriteHeader(status)
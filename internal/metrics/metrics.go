@@ -10,27 +10,76 @@ var (
 	HttpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "http_requests_total",
 		Help: "Total number of HTTP requests",
-	}, []string{"method", "path"})
+	}, []string{"method", "route", "status"})
 
 	HttpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
 		Name:    "http_request_duration_in_seconds",
 		Help:    "Duration of HTTP requests",
 		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
-	}, []string{"method", "path"})
+	}, []string{"method", "route"})
+
+	HttpRequestsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "Number of HTTP requests being served",
+	})
+
+	HttpResponseSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_response_size_bytes",
+		Help:    "Size of HTTP response bodies",
+		Buckets: prometheus.ExponentialBuckets(64, 4, 8),
+	}, []string{"method", "route"})
+
+	GrpcRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "Total number of gRPC calls completed on the server",
+	}, []string{"method", "code"})
+
+	GrpcRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Duration of gRPC calls",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"method"})
+
+	GrpcRequestsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "grpc_server_in_flight",
+		Help: "Number of gRPC calls being served",
+	})
 
 	// bussiness metrics
-	PvzCreatedTotal = promauto.NewCounter(prometheus.CounterOpts{
+	PvzCreatedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "pvz_created_total",
 		Help: "Total number of PVZ created",
-	})
+	}, []string{"city"})
 
-	ReceptionsCreatedTotal = promauto.NewCounter(prometheus.CounterOpts{
+	ReceptionsCreatedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "receipts_created_total",
 		Help: "Total number of receipts created",
-	})
+	}, []string{"city"})
 
-	ProductsAddedTotal = promauto.NewCounter(prometheus.CounterOpts{
+	ProductsAddedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "products_added_total",
 		Help: "Total number of products added",
+	}, []string{"city", "type"})
+
+	ReceptionsOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "receptions_open",
+		Help: "Number of receptions in progress",
+	}, []string{"city"})
+
+	ReceptionDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "reception_duration_in_seconds",
+		Help:    "Time between opening and closing of reception",
+		Buckets: []float64{60, 300, 900, 1800, 3600, 7200, 14400, 28800, 86400},
 	})
+
+	ReceptionProducts = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "reception_products",
+		Help:    "Number of products in closed reception",
+		Buckets: []float64{0, 1, 5, 10, 25, 50, 100, 250, 500, 1000},
+	})
+
+	ProductsExpiredTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "products_expired_total",
+		Help: "Total number of products with expired storage period",
+	}, []string{"city"})
 )
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/logger"
+	"github.com/go-chi/chi/v5"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type accessLogKey struct{}
+
+// accessLogEntry collects request details known only to inner middlewares
+type accessLogEntry struct {
+	userID *openapi_types.UUID
+}
+
+// AccessLog writes one record per request with its route, result and duration
+func AccessLog(log *logger.MyLogger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			entry := &accessLogEntry{}
+			sw := newStatusWriter(w)
+
+			next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), accessLogKey{}, entry)))
+
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("route", routePattern(r)),
+				slog.Int("status", sw.status),
+				slog.Duration("duration", time.Since(start)),
+				slog.Int("bytes", sw.bytes),
+			}
+			if entry.userID != nil {
+				attrs = append(attrs, slog.String("user_id", entry.userID.String()))
+			}
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.URLParam("pvzId") != "" {
+				attrs = append(attrs, slog.String("pvz_id", rctx.URLParam("pvzId")))
+			}
+			log.LogAttrs(r.Context(), slog.LevelInfo, "request handled", attrs...)
+		})
+	}
+}
+
+// routePattern returns pattern of matched route, path is not used to keep records groupable
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		return rctx.RoutePattern()
+	}
+
+	return ""
+}
+
+// recordUserID makes authorized user visible to access log
+func recordUserID(ctx context.Context) {
+	if entry, ok := ctx.Value(accessLogKey{}).(*accessLogEntry); ok {
+		entry.userID = UserIDFromContext(ctx)
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"github.com/Arzeeq/pvz-api/internal/dto"
 	"github.com/Arzeeq/pvz-api/internal/logger"
 	"github.com/Arzeeq/pvz-api/pkg/auth"
+	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 var (
@@ -20,6 +22,9 @@ var (
 	ErrNoExpProvided   = errors.New("no exp provided")
 )
 
+type userIDKey struct{}
+type roleKey struct{}
+
 func AuthRoles(log *logger.MyLogger, jwtSecret []byte, roles ...dto.UserRole) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -53,7 +58,10 @@ func AuthRoles(log *logger.MyLogger, jwtSecret []byte, roles ...dto.UserRole) fu
 				return
 			}
 
-			next.ServeHTTP(w, r)
+			ctx := withUserID(r.Context(), claims)
+			ctx = context.WithValue(ctx, roleKey{}, dto.UserRole(claims["role"].(string)))
+			recordUserID(ctx)
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
@@ -86,3 +94,34 @@ func validateExp(claims map[string]interface{}) error {
 
 	return nil
 }
+
+// UserIDFromContext returns id of authorized user or nil
+// when token was issued without user, e.g. by dummy login
+func UserIDFromContext(ctx context.Context) *openapi_types.UUID {
+	userID, ok := ctx.Value(userIDKey{}).(openapi_types.UUID)
+	if !ok {
+		return nil
+	}
+
+	return &userID
+}
+
+// RoleFromContext returns role of authorized user or empty role when request was not authorized
+func RoleFromContext(ctx context.Context) dto.UserRole {
+	role, _ := ctx.Value(roleKey{}).(dto.UserRole)
+	return role
+}
+
+func withUserID(ctx context.Context, claims map[string]interface{}) context.Context {
+	sub, ok := claims["sub"].(string)
+	if !ok {
+		return ctx
+	}
+
+	var userID openapi_types.UUID
+	if err := userID.UnmarshalText([]byte(sub)); err != nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
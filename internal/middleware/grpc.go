@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/logger"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDMetadata is metadata key of request id, grpc keys are lower case
+var requestIDMetadata = strings.ToLower(RequestIDHeader)
+
+// GRPCUnaryRequestID takes request id from incoming metadata or generates a new one,
+// puts it into context, returns it in header metadata and logs the call
+func GRPCUnaryRequestID(log *logger.MyLogger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		ctx = grpcRequestContext(ctx)
+
+		resp, err := handler(ctx, req)
+		logGRPCCall(ctx, log, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// GRPCStreamRequestID is GRPCUnaryRequestID for streaming calls
+func GRPCStreamRequestID(log *logger.MyLogger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx := grpcRequestContext(ss.Context())
+
+		err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+		logGRPCCall(ctx, log, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func grpcRequestContext(ctx context.Context) context.Context {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDMetadata); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if !validRequestID(requestID) {
+		requestID = uuid.NewString()
+	}
+
+	// header is sent with the first response, error only means it was already sent
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, requestID))
+
+	return logger.WithRequestID(ctx, requestID)
+}
+
+func logGRPCCall(ctx context.Context, log *logger.MyLogger, method string, start time.Time, err error) {
+	log.LogAttrs(ctx, slog.LevelInfo, "grpc call handled",
+		slog.String("method", method),
+		slog.String("code", status.Code(err).String()),
+		slog.Duration("duration", time.Since(start)),
+	)
+}
+
+// contextStream replaces context of server stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Arzeeq/pvz-api/internal/logger"
+	"github.com/Arzeeq/pvz-api/internal/service"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+	// maxIdempotentBodySize limits body read into memory to compute fingerprint of request
+	maxIdempotentBodySize = 1 << 20
+)
+
+var ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+
+type IdempotencyServicer interface {
+	Begin(ctx context.Context, scope string, key string, fingerprint string) (*dto.IdempotencyRecord, error)
+	Finish(ctx context.Context, scope string, key string, statusCode int, body []byte) error
+}
+
+// Idempotency replays stored response for POST request repeated by the same user with the same
+// Idempotency-Key header. Key reused for another request is rejected with 409.
+// It must follow AuthRoles, keys are scoped by authorized user
+func Idempotency(log *logger.MyLogger, idempotency IdempotencyServicer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				log.HTTPError(w, http.StatusBadRequest, ErrInvalidIdempotencyKey)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodySize))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				log.HTTPError(w, http.StatusRequestEntityTooLarge, err)
+				return
+			}
+			if err != nil {
+				log.HTTPError(w, http.StatusBadRequest, err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			scope := idempotencyScope(r.Context())
+			record, err := idempotency.Begin(r.Context(), scope, key, fingerprint(r, body))
+			if errors.Is(err, service.ErrIdempotencyKeyReused) || errors.Is(err, service.ErrIdempotencyInProgress) {
+				log.HTTPError(w, http.StatusConflict, err)
+				return
+			}
+			if err != nil {
+				log.HTTPError(w, http.StatusInternalServerError, err)
+				return
+			}
+			if record != nil {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set(IdempotentReplayedHeader, "true")
+				w.WriteHeader(record.StatusCode)
+				if _, err := w.Write(record.Body); err != nil {
+					log.WrapErrorContext(r.Context(), "failed to write response", err)
+				}
+				return
+			}
+
+			// response is stored even if client has already gone
+			ctx := context.WithoutCancel(r.Context())
+			defer func() {
+				if p := recover(); p != nil {
+					// key is released, so the request can be retried
+					if err := idempotency.Finish(ctx, scope, key, http.StatusInternalServerError, nil); err != nil {
+						log.WrapErrorContext(ctx, "failed to release idempotency key", err)
+					}
+					panic(p)
+				}
+			}()
+
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			if err := idempotency.Finish(ctx, scope, key, recorder.status, recorder.body.Bytes()); err != nil {
+				log.WrapErrorContext(ctx, "failed to store idempotent response", err)
+			}
+		})
+	}
+}
+
+// idempotencyScope is id of authorized user, tokens without user issued by dummy login share keys of their role
+func idempotencyScope(ctx context.Context) string {
+	if userID := UserIDFromContext(ctx); userID != nil {
+		return userID.String()
+	}
+
+	return string(RoleFromContext(ctx))
+}
+
+// fingerprint identifies request by method, path and body. Credentials are not part of it,
+// so retry with refreshed token still gets stored response
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	for _, part := range []string{r.Method, r.URL.Path} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder passes response to client and keeps a copy of it
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -1,28 +1,84 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/Arzeeq/pvz-api/internal/metrics"
 	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
 )
 
+// unmatchedRoute labels requests which did not match any route, so random paths do not create new series
+const unmatchedRoute = "unmatched"
+
+// PrometheusMiddleware records HTTP metrics labeled by route pattern instead of path,
+// e.g. /pvz/{pvzId}/close_last_reception, to keep number of series bounded
 func PrometheusMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		metrics.HttpRequestsInFlight.Inc()
+		defer metrics.HttpRequestsInFlight.Dec()
+
 		start := time.Now()
-		next.ServeHTTP(w, r)
+		sw := newStatusWriter(w)
+		next.ServeHTTP(sw, r)
 
 		duration := time.Since(start).Seconds()
+		route := routePattern(r)
+		if route == "" {
+			route = unmatchedRoute
+		}
 
 		metrics.HttpRequestsTotal.With(prometheus.Labels{
 			"method": r.Method,
-			"path":   r.URL.Path,
+			"route":  route,
+			"status": strconv.Itoa(sw.status),
 		}).Inc()
 
 		metrics.HttpRequestDuration.With(prometheus.Labels{
 			"method": r.Method,
-			"path":   r.URL.Path,
+			"route":  route,
 		}).Observe(duration)
+
+		metrics.HttpResponseSize.With(prometheus.Labels{
+			"method": r.Method,
+			"route":  route,
+		}).Observe(float64(sw.bytes))
 	})
 }
+
+// GRPCUnaryMetrics records gRPC metrics labeled by full method name and status code
+func GRPCUnaryMetrics(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	metrics.GrpcRequestsInFlight.Inc()
+	defer metrics.GrpcRequestsInFlight.Dec()
+
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	observeGRPCCall(info.FullMethod, start, err)
+	return resp, err
+}
+
+// GRPCStreamMetrics is GRPCUnaryMetrics for streaming calls, stream is measured until it is closed
+func GRPCStreamMetrics(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	metrics.GrpcRequestsInFlight.Inc()
+	defer metrics.GrpcRequestsInFlight.Dec()
+
+	start := time.Now()
+	err := handler(srv, ss)
+	observeGRPCCall(info.FullMethod, start, err)
+	return err
+}
+
+func observeGRPCCall(method string, start time.Time, err error) {
+	metrics.GrpcRequestsTotal.With(prometheus.Labels{
+		"method": method,
+		"code":   status.Code(err).String(),
+	}).Inc()
+
+	metrics.GrpcRequestDuration.With(prometheus.Labels{
+		"method": method,
+	}).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/config"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc"
+)
+
+type routeTimeoutKey struct{}
+
+// RouteTimeout puts timeout configured for the route matching request into its context,
+// requests to routes without own timeout get request timeout.
+// Timeouts are keyed by method and route pattern, e.g. "POST /products/batch"
+func RouteTimeout(routes chi.Routes, live *config.Live) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current := live.Load()
+			timeout := current.RequestTimeout
+			if len(current.RouteTimeouts) != 0 {
+				pattern := routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path)
+				if routeTimeout, ok := current.RouteTimeouts[r.Method+" "+pattern]; ok && pattern != "" {
+					timeout = routeTimeout
+				}
+			}
+			r = r.WithContext(context.WithValue(r.Context(), routeTimeoutKey{}, timeout))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// TimeoutFromContext returns timeout of request or defaultTimeout when it was not set by RouteTimeout
+func TimeoutFromContext(ctx context.Context, defaultTimeout time.Duration) time.Duration {
+	if timeout, ok := ctx.Value(routeTimeoutKey{}).(time.Duration); ok {
+		return timeout
+	}
+
+	return defaultTimeout
+}
+
+// GRPCUnaryTimeout limits call by request timeout of live config
+func GRPCUnaryTimeout(live *config.Live) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, cancel := context.WithTimeout(ctx, live.Load().RequestTimeout)
+		defer cancel()
+
+		return handler(ctx, req)
+	}
+}
+
+// GRPCStreamTimeout limits whole streaming call by request timeout of live config
+func GRPCStreamTimeout(live *config.Live) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, cancel := context.WithTimeout(ss.Context(), live.Load().RequestTimeout)
+		defer cancel()
+
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
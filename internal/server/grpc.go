@@ -4,29 +4,82 @@ import (
 	"context"
 	"errors"
 
+	"github.com/Arzeeq/pvz-api/internal/config"
 	pb "github.com/Arzeeq/pvz-api/internal/grpc"
+	"github.com/Arzeeq/pvz-api/internal/logger"
+	"github.com/Arzeeq/pvz-api/internal/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 type GrpcHandler interface {
 	GetPVZList(ctx context.Context, req *pb.GetPVZListRequest) (*pb.GetPVZListResponse, error)
 }
 
+type GrpcReceptionHandler interface {
+	GetReception(ctx context.Context, req *pb.GetReceptionRequest) (*pb.ReceptionDetails, error)
+	ListReceptions(ctx context.Context, req *pb.ListReceptionsRequest) (*pb.ListReceptionsResponse, error)
+	GetActiveReception(ctx context.Context, req *pb.GetActiveReceptionRequest) (*pb.Reception, error)
+}
+
+type GrpcProductHandler interface {
+	AddProducts(stream pb.PVZService_AddProductsServer) error
+}
+
 type GRPCServer struct {
 	pb.UnimplementedPVZServiceServer
-	handler GrpcHandler
+	handler          GrpcHandler
+	receptionHandler GrpcReceptionHandler
+	productHandler   GrpcProductHandler
 }
 
 func (s *GRPCServer) GetPVZList(ctx context.Context, req *pb.GetPVZListRequest) (*pb.GetPVZListResponse, error) {
 	return s.handler.GetPVZList(ctx, req)
 }
 
-func NewGRPC(handler GrpcHandler) (*grpc.Server, error) {
-	if handler == nil {
+func (s *GRPCServer) GetReception(ctx context.Context, req *pb.GetReceptionRequest) (*pb.ReceptionDetails, error) {
+	return s.receptionHandler.GetReception(ctx, req)
+}
+
+func (s *GRPCServer) ListReceptions(ctx context.Context, req *pb.ListReceptionsRequest) (*pb.ListReceptionsResponse, error) {
+	return s.receptionHandler.ListReceptions(ctx, req)
+}
+
+func (s *GRPCServer) GetActiveReception(ctx context.Context, req *pb.GetActiveReceptionRequest) (*pb.Reception, error) {
+	return s.receptionHandler.GetActiveReception(ctx, req)
+}
+
+func (s *GRPCServer) AddProducts(stream pb.PVZService_AddProductsServer) error {
+	return s.productHandler.AddProducts(stream)
+}
+
+func NewGRPC(
+	handler GrpcHandler,
+	receptionHandler GrpcReceptionHandler,
+	productHandler GrpcProductHandler,
+	healthServer *health.Server,
+	log *logger.MyLogger,
+	live *config.Live,
+) (*grpc.Server, error) {
+	if handler == nil || receptionHandler == nil || productHandler == nil || healthServer == nil || log == nil || live == nil {
 		return nil, errors.New("nil values in constructor")
 	}
 
-	s := grpc.NewServer()
-	pb.RegisterPVZServiceServer(s, &GRPCServer{handler: handler})
+	s := grpc.NewServer(
+		// continues trace from incoming metadata, health checks are not traced
+		grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))),
+		// calls are limited by request timeout of live config, like http requests
+		grpc.ChainUnaryInterceptor(middleware.GRPCUnaryRequestID(log), middleware.GRPCUnaryMetrics, middleware.GRPCUnaryTimeout(live)),
+		grpc.ChainStreamInterceptor(middleware.GRPCStreamRequestID(log), middleware.GRPCStreamMetrics, middleware.GRPCStreamTimeout(live)),
+	)
+	pb.RegisterPVZServiceServer(s, &GRPCServer{
+		handler:          handler,
+		receptionHandler: receptionHandler,
+		productHandler:   productHandler,
+	})
+	healthpb.RegisterHealthServer(s, healthServer)
 	return s, nil
 }
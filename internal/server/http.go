@@ -1,8 +1,10 @@
 package server
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/Arzeeq/pvz-api/internal/config"
 	"github.com/Arzeeq/pvz-api/internal/dto"
@@ -19,14 +21,31 @@ type HTTPServer struct {
 	router chi.Router
 }
 
-func NewHTTP(
-	auth *handler.AuthHandler,
-	pvz *handler.PVZHandler,
-	reception *handler.ReceptionHandler,
-	product *handler.ProductHandler,
-	logger *logger.MyLogger,
-	cfg *config.Config,
-) (*HTTPServer, error) {
+// HTTPHandlers groups handlers served by HTTP server.
+// Optional handlers are nil when the feature is not available for chosen storage.
+type HTTPHandlers struct {
+	Auth      *handler.AuthHandler
+	Pvz       *handler.PVZHandler
+	Reception *handler.ReceptionHandler
+	Product   *handler.ProductHandler
+	Health    *handler.HealthHandler
+	Sync      *handler.SyncHandler
+	NodeSync  *handler.NodeSyncHandler
+	Order     *handler.OrderHandler
+	Cell      *handler.CellHandler
+	Transfer  *handler.TransferHandler
+	Expiry    *handler.ExpiryHandler
+	Inventory *handler.InventoryHandler
+	// Idempotency enables Idempotency-Key support for POST requests
+	Idempotency middleware.IdempotencyServicer
+}
+
+// NewHTTP creates server, request timeouts are read from live config on every request
+func NewHTTP(h HTTPHandlers, logger *logger.MyLogger, cfg *config.Config, live *config.Live) (*HTTPServer, error) {
+	if h.Auth == nil || h.Pvz == nil || h.Reception == nil || h.Product == nil || h.Health == nil || logger == nil || cfg == nil || live == nil {
+		return nil, errors.New("nil values in NewHTTP constructor")
+	}
+
 	r := chi.NewRouter()
 	s := HTTPServer{
 		cfg:    cfg,
@@ -35,38 +54,136 @@ func NewHTTP(
 	}
 
 	// without authorization
+	r.Use(middleware.RequestID)
+	r.Use(middleware.Tracing)
+	r.Use(middleware.AccessLog(logger))
 	r.Use(middleware.PrometheusMiddleware)
+	r.Use(middleware.RouteTimeout(r, live))
 	r.Handle("/metrics", promhttp.Handler())
-	r.Post("/dummyLogin", auth.DummyLogin)
-	r.Post("/register", auth.Register)
-	r.Post("/login", auth.Login)
+	r.Get("/healthz", h.Health.Healthz)
+	r.Get("/readyz", h.Health.Readyz)
+	r.Post("/dummyLogin", h.Auth.DummyLogin)
+	r.Post("/register", h.Auth.Register)
+	r.Post("/login", h.Auth.Login)
+
+	// idempotency keys are scoped by authorized user, so the middleware follows AuthRoles
+	useIdempotency := func(r chi.Router) {
+		if h.Idempotency != nil {
+			r.Use(middleware.Idempotency(logger, h.Idempotency))
+		}
+	}
 
 	// moderator only
 	r.Group(func(r chi.Router) {
 		r.Use(middleware.AuthRoles(logger, []byte(cfg.JWTSecret), dto.UserRoleModerator))
-		r.Post("/pvz", pvz.CreatePvz)
+		useIdempotency(r)
+		r.Post("/pvz", h.Pvz.CreatePvz)
 	})
 
 	// employee only
 	r.Group(func(r chi.Router) {
 		r.Use(middleware.AuthRoles(logger, []byte(cfg.JWTSecret), dto.UserRoleEmployee))
-		r.Post("/receptions", reception.CreateReception)
-		r.Post("/products", product.CreateProduct)
-		r.Post("/pvz/{pvzId}/delete_last_product", pvz.DeleteLastProduct)
+		useIdempotency(r)
+		r.Post("/receptions", h.Reception.CreateReception)
+		r.Post("/products", h.Product.CreateProduct)
+		r.Post("/products/batch", h.Product.CreateProducts)
+		r.Post("/products/{productId}/store", h.Product.StoreProduct)
+		r.Post("/products/{productId}/issue", h.Product.IssueProduct)
+		r.Post("/products/{productId}/return", h.Product.ReturnProduct)
+		r.Post("/pvz/{pvzId}/delete_last_product", h.Pvz.DeleteLastProduct)
+		r.Delete("/receptions/{receptionId}/products/{productId}", h.Reception.DeleteProduct)
+		if h.Order != nil {
+			r.Post("/orders", h.Order.CreateOrder)
+			r.Post("/orders/{orderId}/pickup_code", h.Order.GeneratePickupCode)
+			r.Post("/pvz/{pvzId}/pickup", h.Order.Pickup)
+		}
+		if h.Cell != nil {
+			r.Get("/products/{productId}/cell_suggestion", h.Cell.SuggestCell)
+			r.Post("/products/{productId}/place", h.Cell.PlaceProduct)
+			r.Post("/products/{productId}/move", h.Cell.MoveProduct)
+		}
+		if h.Transfer != nil {
+			r.Post("/transfers", h.Transfer.CreateTransfer)
+			r.Post("/transfers/{transferId}/ship", h.Transfer.ShipTransfer)
+			r.Post("/transfers/{transferId}/receive", h.Transfer.ReceiveTransfer)
+		}
+		if h.Expiry != nil {
+			r.Get("/pvz/{pvzId}/returns", h.Expiry.GetReturnList)
+			r.Post("/pvz/{pvzId}/returns/confirm", h.Expiry.ConfirmReturns)
+		}
+		if h.Inventory != nil {
+			r.Post("/pvz/{pvzId}/inventories", h.Inventory.StartInventory)
+			r.Post("/inventories/{inventoryId}/scans", h.Inventory.Scan)
+			r.Post("/inventories/{inventoryId}/complete", h.Inventory.CompleteInventory)
+		}
 	})
 
 	// moderator and employee
 	r.Group(func(r chi.Router) {
 		r.Use(middleware.AuthRoles(logger, []byte(cfg.JWTSecret), dto.UserRoleEmployee, dto.UserRoleModerator))
-		r.Get("/pvz", pvz.GetPVZ)
-		r.Post("/pvz/{pvzId}/close_last_reception", pvz.CloseReception)
+		useIdempotency(r)
+		r.Get("/pvz", h.Pvz.GetPVZ)
+		r.Post("/pvz/{pvzId}/close_last_reception", h.Pvz.CloseReception)
+		r.Get("/pvz/{pvzId}/receptions", h.Pvz.GetReceptions)
+		r.Get("/pvz/{pvzId}/receptions/active", h.Pvz.GetActiveReception)
+		r.Get("/receptions/{receptionId}", h.Reception.GetReception)
+		r.Get("/products", h.Product.GetProducts)
+		// pvz items of the batch are applied only for moderator
+		if h.Sync != nil {
+			r.Post("/sync/batches", h.Sync.UploadBatch)
+		}
+		if h.NodeSync != nil {
+			r.Get("/sync/status", h.NodeSync.GetStatus)
+		}
+		if h.Order != nil {
+			r.Get("/orders/{orderId}", h.Order.GetOrder)
+		}
+		if h.Cell != nil {
+			r.Post("/pvz/{pvzId}/cells", h.Cell.CreateCell)
+			r.Get("/pvz/{pvzId}/cells", h.Cell.GetCells)
+			r.Get("/products/location", h.Cell.GetProductLocations)
+		}
+		if h.Transfer != nil {
+			r.Get("/transfers/{transferId}", h.Transfer.GetTransfer)
+			r.Get("/pvz/{pvzId}/transfers", h.Transfer.GetPVZTransfers)
+			r.Get("/products/{productId}/path", h.Transfer.GetProductPath)
+		}
+		if h.Inventory != nil {
+			r.Get("/pvz/{pvzId}/inventories", h.Inventory.GetPVZInventories)
+			r.Get("/inventories/{inventoryId}", h.Inventory.GetInventory)
+		}
 	})
 
+	if err := checkRouteTimeouts(r, cfg.RouteTimeouts); err != nil {
+		return nil, err
+	}
+
 	return &s, nil
 }
 
-func (s *HTTPServer) Run() error {
-	return http.ListenAndServe(fmt.Sprintf("0.0.0.0:%d", s.cfg.HTTPPort), s.router)
+// checkRouteTimeouts makes sure every configured route timeout belongs to a registered route
+func checkRouteTimeouts(routes chi.Routes, timeouts map[string]time.Duration) error {
+	registered := make(map[string]struct{})
+	err := chi.Walk(routes, func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		registered[method+" "+route] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for route := range timeouts {
+		if _, ok := registered[route]; !ok {
+			return fmt.Errorf("timeout is configured for unknown route %q", route)
+		}
+	}
+
+	return nil
+}
+
+// CheckRouteTimeouts checks route timeouts of reloaded config before it replaces live config
+func (s *HTTPServer) CheckRouteTimeouts(routes map[string]time.Duration) error {
+	return checkRouteTimeouts(s.router, routes)
 }
 
 func (s *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
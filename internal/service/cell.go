@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+var (
+	ErrInvalidCell       = errors.New("cell must have code and positive capacity")
+	ErrCellExists        = errors.New("cell with this code already exists in pvz")
+	ErrCellCreate        = errors.New("failed to create cell")
+	ErrCellGet           = errors.New("failed to get cells")
+	ErrCellNotFound      = errors.New("cell not found in pvz of product")
+	ErrCellFull          = errors.New("cell is full")
+	ErrNoFreeCell        = errors.New("pvz has no free cells")
+	ErrProductPlaced     = errors.New("product is already placed, move it instead")
+	ErrProductNotPlaced  = errors.New("product is not placed in any cell")
+	ErrProductNotOnShelf = errors.New("product is not kept in pvz")
+	ErrProductPlace      = errors.New("failed to place product")
+	ErrInvalidLocation   = errors.New("product id or barcode must be specified")
+)
+
+type CellStorager interface {
+	CreateCell(ctx context.Context, pvzID openapi_types.UUID, payload dto.PostPvzPvzIdCellsJSONBody) (*dto.Cell, error)
+	GetCells(ctx context.Context, pvzID openapi_types.UUID) ([]dto.Cell, error)
+	GetCell(ctx context.Context, cellID openapi_types.UUID) (*dto.Cell, error)
+	SuggestCell(ctx context.Context, pvzID openapi_types.UUID) (*dto.Cell, error)
+	GetProductLocations(ctx context.Context, params dto.GetProductsLocationParams) ([]dto.ProductLocation, error)
+	PlaceProduct(ctx context.Context, productID openapi_types.UUID, cellID openapi_types.UUID, placedBy *openapi_types.UUID) (bool, error)
+}
+
+type CellService struct {
+	storage CellStorager
+}
+
+func NewCellService(storage CellStorager) (*CellService, error) {
+	if storage == nil {
+		return nil, ErrNilInConstruct
+	}
+
+	return &CellService{storage: storage}, nil
+}
+
+func (s *CellService) CreateCell(ctx context.Context, pvzID openapi_types.UUID, payload dto.PostPvzPvzIdCellsJSONBody) (*dto.Cell, error) {
+	ctx, span := startSpan(ctx, "CellService.CreateCell")
+	defer span.End()
+
+	if payload.Code == "" || payload.Capacity < 1 {
+		return nil, ErrInvalidCell
+	}
+
+	cell, err := s.storage.CreateCell(ctx, pvzID, payload)
+	if err != nil {
+		return nil, ErrCellCreate
+	}
+	if cell == nil {
+		return nil, ErrCellExists
+	}
+
+	return cell, nil
+}
+
+func (s *CellService) GetCells(ctx context.Context, pvzID openapi_types.UUID) ([]dto.Cell, error) {
+	ctx, span := startSpan(ctx, "CellService.GetCells")
+	defer span.End()
+
+	cells, err := s.storage.GetCells(ctx, pvzID)
+	if err != nil {
+		return nil, ErrCellGet
+	}
+
+	return cells, nil
+}
+
+// SuggestCell picks cell with the most free capacity in pvz of product
+func (s *CellService) SuggestCell(ctx context.Context, productID openapi_types.UUID) (*dto.Cell, error) {
+	ctx, span := startSpan(ctx, "CellService.SuggestCell")
+	defer span.End()
+
+	location, err := s.getShelvedProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.suggestCell(ctx, location.PvzId)
+}
+
+// PlaceProduct puts product into cell, suggested cell is used when cellID is nil
+func (s *CellService) PlaceProduct(
+	ctx context.Context,
+	productID openapi_types.UUID,
+	cellID *openapi_types.UUID,
+	userID *openapi_types.UUID,
+) (*dto.ProductLocation, error) {
+	ctx, span := startSpan(ctx, "CellService.PlaceProduct")
+	defer span.End()
+
+	location, err := s.getShelvedProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if location.Cell != nil {
+		return nil, ErrProductPlaced
+	}
+
+	var cell *dto.Cell
+	if cellID == nil {
+		cell, err = s.suggestCell(ctx, location.PvzId)
+	} else {
+		cell, err = s.getPVZCell(ctx, location.PvzId, *cellID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.place(ctx, productID, cell.Id, userID)
+}
+
+// MoveProduct moves placed product into another cell of the same pvz
+func (s *CellService) MoveProduct(
+	ctx context.Context,
+	productID openapi_types.UUID,
+	cellID openapi_types.UUID,
+	userID *openapi_types.UUID,
+) (*dto.ProductLocation, error) {
+	ctx, span := startSpan(ctx, "CellService.MoveProduct")
+	defer span.End()
+
+	location, err := s.getShelvedProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if location.Cell == nil {
+		return nil, ErrProductNotPlaced
+	}
+	if location.Cell.Id == cellID {
+		return location, nil
+	}
+
+	if _, err := s.getPVZCell(ctx, location.PvzId, cellID); err != nil {
+		return nil, err
+	}
+
+	return s.place(ctx, productID, cellID, userID)
+}
+
+// GetProductLocations finds pvz and cell of products by id or barcode,
+// cell is returned only while product is kept in pvz
+func (s *CellService) GetProductLocations(ctx context.Context, params dto.GetProductsLocationParams) ([]dto.ProductLocation, error) {
+	ctx, span := startSpan(ctx, "CellService.GetProductLocations")
+	defer span.End()
+
+	if params.ProductId == nil && params.Barcode == nil {
+		return nil, ErrInvalidLocation
+	}
+
+	locations, err := s.storage.GetProductLocations(ctx, params)
+	if err != nil {
+		return nil, ErrProductGet
+	}
+	for i := range locations {
+		if !onShelf(locations[i].Product) {
+			locations[i].Cell = nil
+			locations[i].PlacedAt = nil
+		}
+	}
+
+	return locations, nil
+}
+
+func (s *CellService) place(
+	ctx context.Context,
+	productID openapi_types.UUID,
+	cellID openapi_types.UUID,
+	userID *openapi_types.UUID,
+) (*dto.ProductLocation, error) {
+	placed, err := s.storage.PlaceProduct(ctx, productID, cellID, userID)
+	if err != nil {
+		return nil, ErrProductPlace
+	}
+	if !placed {
+		return nil, ErrCellFull
+	}
+
+	return s.getShelvedProduct(ctx, productID)
+}
+
+// getShelvedProduct returns location of product which is kept in pvz
+func (s *CellService) getShelvedProduct(ctx context.Context, productID openapi_types.UUID) (*dto.ProductLocation, error) {
+	locations, err := s.storage.GetProductLocations(ctx, dto.GetProductsLocationParams{ProductId: &productID})
+	if err != nil {
+		return nil, ErrProductGet
+	}
+	if len(locations) == 0 {
+		return nil, ErrProductNotFound
+	}
+	if !onShelf(locations[0].Product) {
+		return nil, ErrProductNotOnShelf
+	}
+
+	return &locations[0], nil
+}
+
+func (s *CellService) suggestCell(ctx context.Context, pvzID openapi_types.UUID) (*dto.Cell, error) {
+	cell, err := s.storage.SuggestCell(ctx, pvzID)
+	if err != nil {
+		return nil, ErrCellGet
+	}
+	if cell == nil {
+		return nil, ErrNoFreeCell
+	}
+
+	return cell, nil
+}
+
+func (s *CellService) getPVZCell(ctx context.Context, pvzID openapi_types.UUID, cellID openapi_types.UUID) (*dto.Cell, error) {
+	cell, err := s.storage.GetCell(ctx, cellID)
+	if err != nil {
+		return nil, ErrCellGet
+	}
+	if cell == nil || cell.PvzId != pvzID {
+		return nil, ErrCellNotFound
+	}
+
+	return cell, nil
+}
+
+// onShelf reports whether product is physically kept in pvz
+func onShelf(product dto.Product) bool {
+	return product.Status == nil || *product.Status == dto.ProductReceived || *product.Status == dto.ProductStored
+}
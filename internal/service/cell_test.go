@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCellStorage struct {
+	mock.Mock
+}
+
+func (m *mockCellStorage) CreateCell(ctx context.Context, pvzID openapi_types.UUID, payload dto.PostPvzPvzIdCellsJSONBody) (*dto.Cell, error) {
+	args := m.Called(ctx, pvzID, payload)
+	return args.Get(0).(*dto.Cell), args.Error(1)
+}
+
+func (m *mockCellStorage) GetCells(ctx context.Context, pvzID openapi_types.UUID) ([]dto.Cell, error) {
+	args := m.Called(ctx, pvzID)
+	return args.Get(0).([]dto.Cell), args.Error(1)
+}
+
+func (m *mockCellStorage) GetCell(ctx context.Context, cellID openapi_types.UUID) (*dto.Cell, error) {
+	args := m.Called(ctx, cellID)
+	return args.Get(0).(*dto.Cell), args.Error(1)
+}
+
+func (m *mockCellStorage) SuggestCell(ctx context.Context, pvzID openapi_types.UUID) (*dto.Cell, error) {
+	args := m.Called(ctx, pvzID)
+	return args.Get(0).(*dto.Cell), args.Error(1)
+}
+
+func (m *mockCellStorage) GetProductLocations(ctx context.Context, params dto.GetProductsLocationParams) ([]dto.ProductLocation, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]dto.ProductLocation), args.Error(1)
+}
+
+func (m *mockCellStorage) PlaceProduct(
+	ctx context.Context,
+	productID openapi_types.UUID,
+	cellID openapi_types.UUID,
+	placedBy *openapi_types.UUID,
+) (bool, error) {
+	args := m.Called(ctx, productID, cellID, placedBy)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestNewCellService(t *testing.T) {
+	service, err := NewCellService(nil)
+	require.ErrorIs(t, err, ErrNilInConstruct)
+	require.Nil(t, service)
+
+	service, err = NewCellService(new(mockCellStorage))
+	require.NoError(t, err)
+	require.NotNil(t, service)
+}
+
+func TestCellService_CreateCell(t *testing.T) {
+	ctx := context.Background()
+	pvzID := openapi_types.UUID{1}
+	payload := dto.PostPvzPvzIdCellsJSONBody{Code: "A-01", Capacity: 10}
+	cell := &dto.Cell{Id: openapi_types.UUID{2}, PvzId: pvzID, Code: payload.Code, Capacity: payload.Capacity}
+
+	testcases := []struct {
+		name      string
+		payload   dto.PostPvzPvzIdCellsJSONBody
+		mockSetup func(*mockCellStorage)
+		expected  *dto.Cell
+		err       error
+	}{
+		{
+			name:    "cell created",
+			payload: payload,
+			mockSetup: func(m *mockCellStorage) {
+				m.On("CreateCell", ctx, pvzID, payload).Return(cell, nil)
+			},
+			expected: cell,
+		},
+		{
+			name:      "zero capacity",
+			payload:   dto.PostPvzPvzIdCellsJSONBody{Code: "A-01"},
+			mockSetup: func(m *mockCellStorage) {},
+			err:       ErrInvalidCell,
+		},
+		{
+			name:    "code already exists",
+			payload: payload,
+			mockSetup: func(m *mockCellStorage) {
+				m.On("CreateCell", ctx, pvzID, payload).Return((*dto.Cell)(nil), nil)
+			},
+			err: ErrCellExists,
+		},
+		{
+			name:    "storage error",
+			payload: payload,
+			mockSetup: func(m *mockCellStorage) {
+				m.On("CreateCell", ctx, pvzID, payload).Return((*dto.Cell)(nil), errors.New("error"))
+			},
+			err: ErrCellCreate,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			storage := new(mockCellStorage)
+			testcase.mockSetup(storage)
+			service, err := NewCellService(storage)
+			require.NoError(t, err)
+
+			// act
+			cell, err := service.CreateCell(ctx, pvzID, testcase.payload)
+
+			// assert
+			require.ErrorIs(t, err, testcase.err)
+			require.Equal(t, testcase.expected, cell)
+			storage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCellService_PlaceProduct(t *testing.T) {
+	ctx := context.Background()
+	pvzID := openapi_types.UUID{1}
+	productID := openapi_types.UUID{2}
+	cellID := openapi_types.UUID{3}
+	params := dto.GetProductsLocationParams{ProductId: &productID}
+	stored, issued := dto.ProductStored, dto.ProductIssued
+	placedAt := time.Now()
+
+	cell := &dto.Cell{Id: cellID, PvzId: pvzID, Code: "A-01", Capacity: 2}
+	unplaced := dto.ProductLocation{Product: dto.Product{Id: &productID, Status: &stored}, PvzId: pvzID}
+	placed := dto.ProductLocation{Product: unplaced.Product, PvzId: pvzID, Cell: cell, PlacedAt: &placedAt}
+
+	testcases := []struct {
+		name      string
+		cellID    *openapi_types.UUID
+		mockSetup func(*mockCellStorage)
+		expected  *dto.ProductLocation
+		err       error
+	}{
+		{
+			name: "placed into suggested cell",
+			mockSetup: func(m *mockCellStorage) {
+				m.On("GetProductLocations", ctx, params).Return([]dto.ProductLocation{unplaced}, nil).Once()
+				m.On("SuggestCell", ctx, pvzID).Return(cell, nil)
+				m.On("PlaceProduct", ctx, productID, cellID, (*openapi_types.UUID)(nil)).Return(true, nil)
+				m.On("GetProductLocations", ctx, params).Return([]dto.ProductLocation{placed}, nil).Once()
+			},
+			expected: &placed,
+		},
+		{
+			name:   "placed into chosen cell",
+			cellID: &cellID,
+			mockSetup: func(m *mockCellStorage) {
+				m.On("GetProductLocations", ctx, params).Return([]dto.ProductLocation{unplaced}, nil).Once()
+				m.On("GetCell", ctx, cellID).Return(cell, nil)
+				m.On("PlaceProduct", ctx, productID, cellID, (*openapi_types.UUID)(nil)).Return(true, nil)
+				m.On("GetProductLocations", ctx, params).Return([]dto.ProductLocation{placed}, nil).Once()
+			},
+			expected: &placed,
+		},
+		{
+			name:   "cell of another pvz",
+			cellID: &cellID,
+			mockSetup: func(m *mockCellStorage) {
+				m.On("GetProductLocations", ctx, params).Return([]dto.ProductLocation{unplaced}, nil)
+				m.On("GetCell", ctx, cellID).Return(&dto.Cell{Id: cellID, PvzId: openapi_types.UUID{9}}, nil)
+			},
+			err: ErrCellNotFound,
+		},
+		{
+			name:   "cell is full",
+			cellID: &cellID,
+			mockSetup: func(m *mockCellStorage) {
+				m.On("GetProductLocations", ctx, params).Return([]dto.ProductLocation{unplaced}, nil)
+				m.On("GetCell", ctx, cellID).Return(cell, nil)
+				m.On("PlaceProduct", ctx, productID, cellID, (*openapi_types.UUID)(nil)).Return(false, nil)
+			},
+			err: ErrCellFull,
+		},
+		{
+			name: "suggested cell filled concurrently",
+			mockSetup: func(m *mockCellStorage) {
+				m.On("GetProductLocations", ctx, params).Return([]dto.ProductLocation{unplaced}, nil)
+				m.On("SuggestCell", ctx, pvzID).Return(cell, nil)
+				m.On("PlaceProduct", ctx, productID, cellID, (*openapi_types.UUID)(nil)).Return(false, nil)
+			},
+			err: ErrCellFull,
+		},
+		{
+			name: "storage error on placing",
+			mockSetup: func(m *mockCellStorage) {
+				m.On("GetProductLocations", ctx, params).Return([]dto.ProductLocation{unplaced}, nil)
+				m.On("SuggestCell", ctx, pvzID).Return(cell, nil)
+				m.On("PlaceProduct", ctx, productID, cellID, (*openapi_types.UUID)(nil)).Return(false, errors.New("error"))
+			},
+			err: ErrProductPlace,
+		},
+		{
+			name: "no free cells",
+			mockSetup: func(m *mockCellStorage) {
+				m.On("GetProductLocations", ctx, params).Return([]dto.ProductLocation{unplaced}, nil)
+				m.On("SuggestCell", ctx, pvzID).Return((*dto.Cell)(nil), nil)
+			},
+			err: ErrNoFreeCell,
+		},
+		{
+			name: "already placed",
+			mockSetup: func(m *mockCellStorage) {
+				m.On("GetProductLocations", ctx, params).Return([]dto.ProductLocation{placed}, nil)
+			},
+			err: ErrProductPlaced,
+		},
+		{
+			name: "issued product",
+			mockSetup: func(m *mockCellStorage) {
+				location := unplaced
+				location.Product.Status = &issued
+				m.On("GetProductLocations", ctx, params).Return([]dto.ProductLocation{location}, nil)
+			},
+			err: ErrProductNotOnShelf,
+		},
+		{
+			name: "product not found",
+			mockSetup: func(m *mockCellStorage) {
+				m.On("GetProductLocations", ctx, params).Return([]dto.ProductLocation{}, nil)
+			},
+			err: ErrProductNotFound,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			storage := new(mockCellStorage)
+			testcase.mockSetup(storage)
+			service, err := NewCellService(storage)
+			require.NoError(t, err)
+
+			// act
+			location, err := service.PlaceProduct(ctx, productID, testcase.cellID, nil)
+
+			// assert
+			require.ErrorIs(t, err, testcase.err)
+			require.Equal(t, testcase.expected, location)
+			storage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCellService_MoveProduct(t *testing.T) {
+	ctx := context.Background()
+	pvzID := openapi_types.UUID{1}
+	productID := openapi_types.UUID{2}
+	fromID, toID := openapi_types.UUID{3}, openapi_types.UUID{4}
+	params := dto.GetProductsLocationParams{ProductId: &productID}
+	stored := dto.ProductStored
+
+	from := &dto.Cell{Id: fromID, PvzId: pvzID, Code: "A-01", Capacity: 1}
+	to := &dto.Cell{Id: toID, PvzId: pvzID, Code: "A-02", Capacity: 1}
+	product := dto.Product{Id: &productID, Status: &stored}
+	before := dto.ProductLocation{Product: product, PvzId: pvzID, Cell: from}
+	after := dto.ProductLocation{Product: product, PvzId: pvzID, Cell: to}
+
+	t.Run("moved", func(t *testing.T) {
+		storage := new(mockCellStorage)
+		storage.On("GetProductLocations", ctx, params).Return([]dto.ProductLocation{before}, nil).Once()
+		storage.On("GetCell", ctx, toID).Return(to, nil)
+		storage.On("PlaceProduct", ctx, productID, toID, (*openapi_types.UUID)(nil)).Return(true, nil)
+		storage.On("GetProductLocations", ctx, params).Return([]dto.ProductLocation{after}, nil).Once()
+		service, err := NewCellService(storage)
+		require.NoError(t, err)
+
+		location, err := service.MoveProduct(ctx, productID, toID, nil)
+
+		require.NoError(t, err)
+		require.Equal(t, &after, location)
+		storage.AssertExpectations(t)
+	})
+
+	t.Run("same cell", func(t *testing.T) {
+		storage := new(mockCellStorage)
+		storage.On("GetProductLocations", ctx, params).Return([]dto.ProductLocation{before}, nil)
+		service, err := NewCellService(storage)
+		require.NoError(t, err)
+
+		location, err := service.MoveProduct(ctx, productID, fromID, nil)
+
+		require.NoError(t, err)
+		require.Equal(t, &before, location)
+		storage.AssertExpectations(t)
+	})
+
+	t.Run("target cell is full", func(t *testing.T) {
+		storage := new(mockCellStorage)
+		storage.On("GetProductLocations", ctx, params).Return([]dto.ProductLocation{before}, nil)
+		storage.On("GetCell", ctx, toID).Return(to, nil)
+		storage.On("PlaceProduct", ctx, productID, toID, (*openapi_types.UUID)(nil)).Return(false, nil)
+		service, err := NewCellService(storage)
+		require.NoError(t, err)
+
+		_, err = service.MoveProduct(ctx, productID, toID, nil)
+
+		require.ErrorIs(t, err, ErrCellFull)
+		storage.AssertExpectations(t)
+	})
+
+	t.Run("not placed", func(t *testing.T) {
+		storage := new(mockCellStorage)
+		storage.On("GetProductLocations", ctx, params).Return([]dto.ProductLocation{{Product: product, PvzId: pvzID}}, nil)
+		service, err := NewCellService(storage)
+		require.NoError(t, err)
+
+		_, err = service.MoveProduct(ctx, productID, toID, nil)
+
+		require.ErrorIs(t, err, ErrProductNotPlaced)
+	})
+}
+
+func TestCellService_GetProductLocations(t *testing.T) {
+	ctx := context.Background()
+	barcode := "4600000000001"
+	params := dto.GetProductsLocationParams{Barcode: &barcode}
+	stored, issued := dto.ProductStored, dto.ProductIssued
+	placedAt := time.Now()
+	cell := &dto.Cell{Id: openapi_types.UUID{3}, Code: "A-01", Capacity: 1}
+
+	storage := new(mockCellStorage)
+	storage.On("GetProductLocations", ctx, params).Return([]dto.ProductLocation{
+		{Product: dto.Product{Status: &stored}, Cell: cell, PlacedAt: &placedAt},
+		{Product: dto.Product{Status: &issued}, Cell: cell, PlacedAt: &placedAt},
+	}, nil)
+	service, err := NewCellService(storage)
+	require.NoError(t, err)
+
+	_, err = service.GetProductLocations(ctx, dto.GetProductsLocationParams{})
+	require.ErrorIs(t, err, ErrInvalidLocation)
+
+	locations, err := service.GetProductLocations(ctx, params)
+	require.NoError(t, err)
+	require.Len(t, locations, 2)
+	require.Equal(t, cell, locations[0].Cell)
+	require.Nil(t, locations[1].Cell, "issued product is not on shelf")
+	require.Nil(t, locations[1].PlacedAt)
+}
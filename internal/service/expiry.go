@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Arzeeq/pvz-api/internal/metrics"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+var (
+	ErrExpireProducts       = errors.New("failed to expire products")
+	ErrInvalidReturns       = errors.New("returns must contain distinct products")
+	ErrConfirmReturns       = errors.New("failed to confirm returns")
+	ErrProductsNotInList    = errors.New("products are not in return list of pvz")
+	ErrInvalidStoragePeriod = errors.New("storage period must be positive")
+)
+
+type ExpiryStorager interface {
+	ExpireProducts(ctx context.Context, productType dto.ProductType, period time.Duration) (map[string]int, error)
+	GetReturnList(ctx context.Context, pvzID openapi_types.UUID) ([]dto.Product, error)
+	// ConfirmReturns returns nil when some of products are not expired products of pvz in one of from statuses
+	ConfirmReturns(
+		ctx context.Context,
+		pvzID openapi_types.UUID,
+		productIDs []openapi_types.UUID,
+		from []dto.ProductStatus,
+	) ([]dto.Product, error)
+}
+
+type ExpiryService struct {
+	storage ExpiryStorager
+	periods map[dto.ProductType]time.Duration
+}
+
+// NewExpiryService creates service with storage period of every product type,
+// types missing in periods are kept for defaultPeriod
+func NewExpiryService(storage ExpiryStorager, periods map[dto.ProductType]time.Duration, defaultPeriod time.Duration) (*ExpiryService, error) {
+	if storage == nil {
+		return nil, ErrNilInConstruct
+	}
+	if defaultPeriod <= 0 {
+		return nil, ErrInvalidStoragePeriod
+	}
+
+	typePeriods := make(map[dto.ProductType]time.Duration, len(productTypes))
+	for _, productType := range productTypes {
+		typePeriods[productType] = defaultPeriod
+	}
+	for productType, period := range periods {
+		if !slices.Contains(productTypes, productType) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidProductType, productType)
+		}
+		if period <= 0 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidStoragePeriod, productType)
+		}
+		typePeriods[productType] = period
+	}
+
+	return &ExpiryService{storage: storage, periods: typePeriods}, nil
+}
+
+// ExpireProducts moves received and stored products with expired storage period into return lists of their pvz
+func (s *ExpiryService) ExpireProducts(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "ExpiryService.ExpireProducts")
+	defer span.End()
+
+	total := 0
+	for _, productType := range productTypes {
+		expired, err := s.storage.ExpireProducts(ctx, productType, s.periods[productType])
+		if err != nil {
+			return total, ErrExpireProducts
+		}
+		for city, count := range expired {
+			metrics.ProductsExpiredTotal.WithLabelValues(city).Add(float64(count))
+			total += count
+		}
+	}
+
+	return total, nil
+}
+
+func (s *ExpiryService) GetReturnList(ctx context.Context, pvzID openapi_types.UUID) ([]dto.Product, error) {
+	ctx, span := startSpan(ctx, "ExpiryService.GetReturnList")
+	defer span.End()
+
+	products, err := s.storage.GetReturnList(ctx, pvzID)
+	if err != nil {
+		return nil, ErrProductGet
+	}
+
+	return products, nil
+}
+
+// ConfirmReturns marks products of return list as handed over to sender, all of them or none.
+// Products are moved by transitions of expired products, as POST /products/{id}/return does
+func (s *ExpiryService) ConfirmReturns(
+	ctx context.Context,
+	pvzID openapi_types.UUID,
+	payload dto.PostPvzPvzIdReturnsConfirmJSONBody,
+) ([]dto.Product, error) {
+	ctx, span := startSpan(ctx, "ExpiryService.ConfirmReturns")
+	defer span.End()
+
+	if len(payload.ProductIds) == 0 {
+		return nil, ErrInvalidReturns
+	}
+	seen := make(map[openapi_types.UUID]struct{}, len(payload.ProductIds))
+	for _, id := range payload.ProductIds {
+		if _, ok := seen[id]; ok {
+			return nil, ErrInvalidReturns
+		}
+		seen[id] = struct{}{}
+	}
+
+	from := previousStatuses(expiredProductTransitions, dto.ProductReturnedToSender)
+	products, err := s.storage.ConfirmReturns(ctx, pvzID, payload.ProductIds, from)
+	if err != nil {
+		return nil, ErrConfirmReturns
+	}
+	if products == nil {
+		return nil, ErrProductsNotInList
+	}
+
+	return products, nil
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Arzeeq/pvz-api/internal/metrics"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockExpiryStorage struct {
+	mock.Mock
+}
+
+func (m *mockExpiryStorage) ExpireProducts(ctx context.Context, productType dto.ProductType, period time.Duration) (map[string]int, error) {
+	args := m.Called(ctx, productType, period)
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *mockExpiryStorage) GetReturnList(ctx context.Context, pvzID openapi_types.UUID) ([]dto.Product, error) {
+	args := m.Called(ctx, pvzID)
+	return args.Get(0).([]dto.Product), args.Error(1)
+}
+
+func (m *mockExpiryStorage) ConfirmReturns(
+	ctx context.Context,
+	pvzID openapi_types.UUID,
+	productIDs []openapi_types.UUID,
+	from []dto.ProductStatus,
+) ([]dto.Product, error) {
+	args := m.Called(ctx, pvzID, productIDs, from)
+	return args.Get(0).([]dto.Product), args.Error(1)
+}
+
+func TestNewExpiryService(t *testing.T) {
+	testcases := []struct {
+		name    string
+		storage ExpiryStorager
+		periods map[dto.ProductType]time.Duration
+		def     time.Duration
+		err     error
+	}{
+		{
+			name:    "success",
+			storage: new(mockExpiryStorage),
+			periods: map[dto.ProductType]time.Duration{dto.ProductTypeElectronics: time.Hour},
+			def:     time.Hour,
+		},
+		{
+			name: "nil storage",
+			def:  time.Hour,
+			err:  ErrNilInConstruct,
+		},
+		{
+			name:    "unknown product type",
+			storage: new(mockExpiryStorage),
+			periods: map[dto.ProductType]time.Duration{"мебель": time.Hour},
+			def:     time.Hour,
+			err:     ErrInvalidProductType,
+		},
+		{
+			name:    "negative period",
+			storage: new(mockExpiryStorage),
+			periods: map[dto.ProductType]time.Duration{dto.ProductTypeShoes: -time.Hour},
+			def:     time.Hour,
+			err:     ErrInvalidStoragePeriod,
+		},
+		{
+			name:    "zero default period",
+			storage: new(mockExpiryStorage),
+			err:     ErrInvalidStoragePeriod,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			service, err := NewExpiryService(testcase.storage, testcase.periods, testcase.def)
+			require.ErrorIs(t, err, testcase.err)
+			if testcase.err == nil {
+				require.NotNil(t, service)
+			} else {
+				require.Nil(t, service)
+			}
+		})
+	}
+}
+
+func TestExpiryService_ExpireProducts(t *testing.T) {
+	ctx := context.Background()
+	week, twoWeeks := 7*24*time.Hour, 14*24*time.Hour
+	city := "Казань"
+
+	t.Run("every type expires with its period", func(t *testing.T) {
+		storage := new(mockExpiryStorage)
+		storage.On("ExpireProducts", ctx, dto.ProductTypeElectronics, week).Return(map[string]int{city: 2}, nil)
+		storage.On("ExpireProducts", ctx, dto.ProductTypeClothes, twoWeeks).Return(map[string]int{}, nil)
+		storage.On("ExpireProducts", ctx, dto.ProductTypeShoes, twoWeeks).Return(map[string]int{city: 1}, nil)
+		service, err := NewExpiryService(storage, map[dto.ProductType]time.Duration{dto.ProductTypeElectronics: week}, twoWeeks)
+		require.NoError(t, err)
+		before := testutil.ToFloat64(metrics.ProductsExpiredTotal.WithLabelValues(city))
+
+		expired, err := service.ExpireProducts(ctx)
+
+		require.NoError(t, err)
+		require.Equal(t, 3, expired)
+		require.Equal(t, before+3, testutil.ToFloat64(metrics.ProductsExpiredTotal.WithLabelValues(city)))
+		storage.AssertExpectations(t)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		storage := new(mockExpiryStorage)
+		storage.On("ExpireProducts", ctx, dto.ProductTypeElectronics, twoWeeks).Return(map[string]int(nil), errors.New("error"))
+		service, err := NewExpiryService(storage, nil, twoWeeks)
+		require.NoError(t, err)
+
+		_, err = service.ExpireProducts(ctx)
+
+		require.ErrorIs(t, err, ErrExpireProducts)
+	})
+
+	t.Run("products expired before error are counted", func(t *testing.T) {
+		storage := new(mockExpiryStorage)
+		storage.On("ExpireProducts", ctx, dto.ProductTypeElectronics, twoWeeks).Return(map[string]int{city: 2}, nil)
+		storage.On("ExpireProducts", ctx, dto.ProductTypeClothes, twoWeeks).Return(map[string]int(nil), errors.New("error"))
+		service, err := NewExpiryService(storage, nil, twoWeeks)
+		require.NoError(t, err)
+
+		expired, err := service.ExpireProducts(ctx)
+
+		require.ErrorIs(t, err, ErrExpireProducts)
+		require.Equal(t, 2, expired)
+		storage.AssertExpectations(t)
+	})
+}
+
+func TestExpiryService_GetReturnList(t *testing.T) {
+	ctx := context.Background()
+	pvzID := openapi_types.UUID{1}
+	received, stored := dto.ProductReceived, dto.ProductStored
+	expiredAt := time.Now()
+	// products which were never stored expire too
+	products := []dto.Product{
+		{Id: &openapi_types.UUID{2}, Status: &received, ExpiredAt: &expiredAt},
+		{Id: &openapi_types.UUID{3}, Status: &stored, ExpiredAt: &expiredAt},
+	}
+
+	t.Run("return list", func(t *testing.T) {
+		storage := new(mockExpiryStorage)
+		storage.On("GetReturnList", ctx, pvzID).Return(products, nil)
+		service, err := NewExpiryService(storage, nil, time.Hour)
+		require.NoError(t, err)
+
+		list, err := service.GetReturnList(ctx, pvzID)
+
+		require.NoError(t, err)
+		require.Equal(t, products, list)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		storage := new(mockExpiryStorage)
+		storage.On("GetReturnList", ctx, pvzID).Return([]dto.Product(nil), errors.New("error"))
+		service, err := NewExpiryService(storage, nil, time.Hour)
+		require.NoError(t, err)
+
+		_, err = service.GetReturnList(ctx, pvzID)
+
+		require.ErrorIs(t, err, ErrProductGet)
+	})
+}
+
+func TestExpiryService_ConfirmReturns(t *testing.T) {
+	ctx := context.Background()
+	pvzID := openapi_types.UUID{1}
+	productID := openapi_types.UUID{2}
+	returned := dto.ProductReturnedToSender
+	payload := dto.PostPvzPvzIdReturnsConfirmJSONBody{ProductIds: []openapi_types.UUID{productID}}
+	products := []dto.Product{{Id: &productID, Status: &returned}}
+	// expired products are returned from pvz without issue, like by POST /products/{id}/return
+	returnFrom := []dto.ProductStatus{dto.ProductReceived, dto.ProductStored}
+
+	testcases := []struct {
+		name      string
+		payload   dto.PostPvzPvzIdReturnsConfirmJSONBody
+		mockSetup func(*mockExpiryStorage)
+		expected  []dto.Product
+		err       error
+	}{
+		{
+			name:    "returns confirmed",
+			payload: payload,
+			mockSetup: func(m *mockExpiryStorage) {
+				m.On("ConfirmReturns", ctx, pvzID, payload.ProductIds, returnFrom).Return(products, nil)
+			},
+			expected: products,
+		},
+		{
+			name:    "product is not in return list",
+			payload: payload,
+			mockSetup: func(m *mockExpiryStorage) {
+				m.On("ConfirmReturns", ctx, pvzID, payload.ProductIds, returnFrom).Return([]dto.Product(nil), nil)
+			},
+			err: ErrProductsNotInList,
+		},
+		{
+			name:      "duplicate products",
+			payload:   dto.PostPvzPvzIdReturnsConfirmJSONBody{ProductIds: []openapi_types.UUID{productID, productID}},
+			mockSetup: func(m *mockExpiryStorage) {},
+			err:       ErrInvalidReturns,
+		},
+		{
+			name:      "empty returns",
+			payload:   dto.PostPvzPvzIdReturnsConfirmJSONBody{},
+			mockSetup: func(m *mockExpiryStorage) {},
+			err:       ErrInvalidReturns,
+		},
+		{
+			name:    "storage error",
+			payload: payload,
+			mockSetup: func(m *mockExpiryStorage) {
+				m.On("ConfirmReturns", ctx, pvzID, payload.ProductIds, returnFrom).Return([]dto.Product(nil), errors.New("error"))
+			},
+			err: ErrConfirmReturns,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			storage := new(mockExpiryStorage)
+			testcase.mockSetup(storage)
+			service, err := NewExpiryService(storage, nil, time.Hour)
+			require.NoError(t, err)
+
+			// act
+			products, err := service.ConfirmReturns(ctx, pvzID, testcase.payload)
+
+			// assert
+			require.ErrorIs(t, err, testcase.err)
+			require.Equal(t, testcase.expected, products)
+			storage.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+var (
+	ErrShuttingDown        = errors.New("application is shutting down")
+	ErrDatabaseUnavailable = errors.New("database is unavailable")
+	ErrMigrationVersion    = errors.New("database migrations are not at expected version")
+)
+
+type HealthStorager interface {
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (uint, bool, error)
+}
+
+// HealthService decides whether application is ready to serve requests
+type HealthService struct {
+	storage         HealthStorager
+	expectedVersion uint
+	shuttingDown    atomic.Bool
+}
+
+func NewHealthService(storage HealthStorager, expectedVersion uint) (*HealthService, error) {
+	if storage == nil {
+		return nil, ErrNilInConstruct
+	}
+
+	return &HealthService{storage: storage, expectedVersion: expectedVersion}, nil
+}
+
+// Ready returns nil when application is not shutting down, database answers
+// and its migrations are applied up to expected version
+func (s *HealthService) Ready(ctx context.Context) error {
+	if s.shuttingDown.Load() {
+		return ErrShuttingDown
+	}
+
+	if err := s.storage.Ping(ctx); err != nil {
+		return ErrDatabaseUnavailable
+	}
+
+	version, dirty, err := s.storage.MigrationVersion(ctx)
+	if err != nil {
+		return ErrDatabaseUnavailable
+	}
+	if dirty || version != s.expectedVersion {
+		return ErrMigrationVersion
+	}
+
+	return nil
+}
+
+// SetShuttingDown makes application not ready for the rest of its life
+func (s *HealthService) SetShuttingDown() {
+	s.shuttingDown.Store(true)
+}
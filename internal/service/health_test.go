@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockHealthStorage struct {
+	mock.Mock
+}
+
+func (m *mockHealthStorage) Ping(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *mockHealthStorage) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(uint), args.Bool(1), args.Error(2)
+}
+
+func TestNewHealthService(t *testing.T) {
+	service, err := NewHealthService(nil, 1)
+	require.ErrorIs(t, err, ErrNilInConstruct)
+	require.Nil(t, service)
+
+	service, err = NewHealthService(new(mockHealthStorage), 1)
+	require.NoError(t, err)
+	require.NotNil(t, service)
+}
+
+func TestHealthService_Ready(t *testing.T) {
+	ctx := context.Background()
+	const expectedVersion uint = 15
+
+	testcases := []struct {
+		name         string
+		shuttingDown bool
+		mockSetup    func(*mockHealthStorage)
+		err          error
+	}{
+		{
+			name: "ready",
+			mockSetup: func(m *mockHealthStorage) {
+				m.On("Ping", ctx).Return(nil)
+				m.On("MigrationVersion", ctx).Return(expectedVersion, false, nil)
+			},
+		},
+		{
+			name:         "shutting down",
+			shuttingDown: true,
+			mockSetup:    func(m *mockHealthStorage) {},
+			err:          ErrShuttingDown,
+		},
+		{
+			name: "database does not answer",
+			mockSetup: func(m *mockHealthStorage) {
+				m.On("Ping", ctx).Return(errors.New("error"))
+			},
+			err: ErrDatabaseUnavailable,
+		},
+		{
+			name: "migrations behind",
+			mockSetup: func(m *mockHealthStorage) {
+				m.On("Ping", ctx).Return(nil)
+				m.On("MigrationVersion", ctx).Return(expectedVersion-1, false, nil)
+			},
+			err: ErrMigrationVersion,
+		},
+		{
+			name: "dirty migration",
+			mockSetup: func(m *mockHealthStorage) {
+				m.On("Ping", ctx).Return(nil)
+				m.On("MigrationVersion", ctx).Return(expectedVersion, true, nil)
+			},
+			err: ErrMigrationVersion,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			storage := new(mockHealthStorage)
+			testcase.mockSetup(storage)
+			service, err := NewHealthService(storage, expectedVersion)
+			require.NoError(t, err)
+			if testcase.shuttingDown {
+				service.SetShuttingDown()
+			}
+
+			// act
+			err = service.Ready(ctx)
+
+			// assert
+			require.ErrorIs(t, err, testcase.err)
+			storage.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+)
+
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used for another request")
+var ErrIdempotencyInProgress = errors.New("request with this idempotency key is still in progress")
+var ErrIdempotency = errors.New("failed to process idempotency key")
+
+type IdempotencyStorager interface {
+	// Reserve takes over key which has expired, so reservation left by crashed request ends with its lease
+	Reserve(ctx context.Context, scope string, key string, fingerprint string, leaseUntil time.Time) (*dto.IdempotencyRecord, error)
+	Complete(ctx context.Context, scope string, key string, statusCode int, body []byte, expiresAt time.Time) error
+	Release(ctx context.Context, scope string, key string) error
+	DeleteExpired(ctx context.Context) (int, error)
+}
+
+// IdempotencyService stores responses for Idempotency-Key. Keys are scoped,
+// so the same key chosen by different users does not collide
+type IdempotencyService struct {
+	storage IdempotencyStorager
+	ttl     time.Duration
+	// lease is how long key is reserved for request in progress
+	lease time.Duration
+}
+
+func NewIdempotencyService(storage IdempotencyStorager, ttl time.Duration, lease time.Duration) (*IdempotencyService, error) {
+	if storage == nil {
+		return nil, ErrNilInConstruct
+	}
+
+	return &IdempotencyService{storage: storage, ttl: ttl, lease: lease}, nil
+}
+
+// Begin reserves key of scope for request with fingerprint. Returns nil when request should be
+// processed and stored response when request with the same key and body was already done.
+func (s *IdempotencyService) Begin(ctx context.Context, scope string, key string, fingerprint string) (*dto.IdempotencyRecord, error) {
+	ctx, span := startSpan(ctx, "IdempotencyService.Begin")
+	defer span.End()
+
+	record, err := s.storage.Reserve(ctx, scope, key, fingerprint, time.Now().Add(s.lease))
+	if err != nil {
+		return nil, ErrIdempotency
+	}
+	if record == nil {
+		return nil, nil
+	}
+
+	if record.Fingerprint != fingerprint {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if record.StatusCode == 0 {
+		return nil, ErrIdempotencyInProgress
+	}
+
+	return record, nil
+}
+
+// Finish stores response for reserved key. Server errors are not stored,
+// the key is released instead so the client can retry
+func (s *IdempotencyService) Finish(ctx context.Context, scope string, key string, statusCode int, body []byte) error {
+	ctx, span := startSpan(ctx, "IdempotencyService.Finish")
+	defer span.End()
+
+	var err error
+	if statusCode >= http.StatusInternalServerError {
+		err = s.storage.Release(ctx, scope, key)
+	} else {
+		err = s.storage.Complete(ctx, scope, key, statusCode, body, time.Now().Add(s.ttl))
+	}
+	if err != nil {
+		return ErrIdempotency
+	}
+
+	return nil
+}
+
+// DeleteExpired removes expired keys and returns their number
+func (s *IdempotencyService) DeleteExpired(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "IdempotencyService.DeleteExpired")
+	defer span.End()
+
+	deleted, err := s.storage.DeleteExpired(ctx)
+	if err != nil {
+		return 0, ErrIdempotency
+	}
+
+	return deleted, nil
+}
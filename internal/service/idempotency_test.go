@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockIdempotencyStorage struct {
+	mock.Mock
+}
+
+func (m *mockIdempotencyStorage) Reserve(
+	ctx context.Context,
+	scope string,
+	key string,
+	fingerprint string,
+	leaseUntil time.Time,
+) (*dto.IdempotencyRecord, error) {
+	args := m.Called(ctx, scope, key, fingerprint, leaseUntil)
+	return args.Get(0).(*dto.IdempotencyRecord), args.Error(1)
+}
+
+func (m *mockIdempotencyStorage) Complete(
+	ctx context.Context,
+	scope string,
+	key string,
+	statusCode int,
+	body []byte,
+	expiresAt time.Time,
+) error {
+	args := m.Called(ctx, scope, key, statusCode, body, expiresAt)
+	return args.Error(0)
+}
+
+func (m *mockIdempotencyStorage) Release(ctx context.Context, scope string, key string) error {
+	args := m.Called(ctx, scope, key)
+	return args.Error(0)
+}
+
+func (m *mockIdempotencyStorage) DeleteExpired(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func TestNewIdempotencyService(t *testing.T) {
+	service, err := NewIdempotencyService(nil, time.Hour, time.Minute)
+	require.ErrorIs(t, err, ErrNilInConstruct)
+	require.Nil(t, service)
+
+	service, err = NewIdempotencyService(new(mockIdempotencyStorage), time.Hour, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, service)
+}
+
+func TestIdempotencyService_Begin(t *testing.T) {
+	ctx := context.Background()
+	key := "key"
+	fingerprint := "fingerprint"
+	completed := &dto.IdempotencyRecord{Key: key, Fingerprint: fingerprint, StatusCode: 201, Body: []byte("{}")}
+
+	testcases := []struct {
+		name     string
+		record   *dto.IdempotencyRecord
+		storeErr error
+		expected *dto.IdempotencyRecord
+		err      error
+	}{
+		{
+			name: "new key is reserved",
+		},
+		{
+			name:     "completed request is replayed",
+			record:   completed,
+			expected: completed,
+		},
+		{
+			name:   "key reused with another body",
+			record: &dto.IdempotencyRecord{Key: key, Fingerprint: "other", StatusCode: 201},
+			err:    ErrIdempotencyKeyReused,
+		},
+		{
+			name:   "request still in progress",
+			record: &dto.IdempotencyRecord{Key: key, Fingerprint: fingerprint},
+			err:    ErrIdempotencyInProgress,
+		},
+		{
+			name:     "storage error",
+			storeErr: errors.New("error"),
+			err:      ErrIdempotency,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			storage := new(mockIdempotencyStorage)
+			// reservation lasts for lease, not for ttl of stored response
+			leaseUntil := mock.MatchedBy(func(t time.Time) bool { return time.Until(t) <= time.Minute })
+			storage.On("Reserve", ctx, "user", key, fingerprint, leaseUntil).
+				Return(testcase.record, testcase.storeErr)
+			service, err := NewIdempotencyService(storage, time.Hour, time.Minute)
+			require.NoError(t, err)
+
+			// act
+			record, err := service.Begin(ctx, "user", key, fingerprint)
+
+			// assert
+			require.ErrorIs(t, err, testcase.err)
+			require.Equal(t, testcase.expected, record)
+			storage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestIdempotencyService_RetryAfterServerError(t *testing.T) {
+	ctx := context.Background()
+	key := "key"
+	fingerprint := "fingerprint"
+	body := []byte("{}")
+
+	// arrange
+	storage := new(mockIdempotencyStorage)
+	storage.On("Reserve", ctx, "user", key, fingerprint, mock.AnythingOfType("time.Time")).
+		Return((*dto.IdempotencyRecord)(nil), nil).Twice()
+	storage.On("Release", ctx, "user", key).Return(nil)
+	storage.On("Complete", ctx, "user", key, 201, body, mock.AnythingOfType("time.Time")).Return(nil)
+	service, err := NewIdempotencyService(storage, time.Hour, time.Minute)
+	require.NoError(t, err)
+
+	// act
+	_, firstErr := service.Begin(ctx, "user", key, fingerprint)
+	finishErr := service.Finish(ctx, "user", key, 503, body)
+	// released key is reserved again by retry
+	record, retryErr := service.Begin(ctx, "user", key, fingerprint)
+	completeErr := service.Finish(ctx, "user", key, 201, body)
+
+	// assert
+	require.NoError(t, firstErr)
+	require.NoError(t, finishErr)
+	require.NoError(t, retryErr)
+	require.Nil(t, record)
+	require.NoError(t, completeErr)
+	storage.AssertExpectations(t)
+}
+
+func TestIdempotencyService_Finish(t *testing.T) {
+	ctx := context.Background()
+	body := []byte("{}")
+
+	t.Run("response is stored", func(t *testing.T) {
+		storage := new(mockIdempotencyStorage)
+		expiresAt := mock.MatchedBy(func(t time.Time) bool { return time.Until(t) > time.Minute })
+		storage.On("Complete", ctx, "user", "key", 400, body, expiresAt).Return(nil)
+		service, err := NewIdempotencyService(storage, time.Hour, time.Minute)
+		require.NoError(t, err)
+
+		require.NoError(t, service.Finish(ctx, "user", "key", 400, body))
+		storage.AssertExpectations(t)
+	})
+
+	t.Run("server error releases key", func(t *testing.T) {
+		storage := new(mockIdempotencyStorage)
+		storage.On("Release", ctx, "user", "key").Return(nil)
+		service, err := NewIdempotencyService(storage, time.Hour, time.Minute)
+		require.NoError(t, err)
+
+		require.NoError(t, service.Finish(ctx, "user", "key", 500, body))
+		storage.AssertExpectations(t)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		storage := new(mockIdempotencyStorage)
+		storage.On("Complete", ctx, "user", "key", 201, body, mock.AnythingOfType("time.Time")).Return(errors.New("error"))
+		service, err := NewIdempotencyService(storage, time.Hour, time.Minute)
+		require.NoError(t, err)
+
+		require.ErrorIs(t, service.Finish(ctx, "user", "key", 201, body), ErrIdempotency)
+	})
+}
+
+func TestIdempotencyService_DeleteExpired(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("expired keys are deleted", func(t *testing.T) {
+		storage := new(mockIdempotencyStorage)
+		storage.On("DeleteExpired", ctx).Return(3, nil)
+		service, err := NewIdempotencyService(storage, time.Hour, time.Minute)
+		require.NoError(t, err)
+
+		deleted, err := service.DeleteExpired(ctx)
+
+		require.NoError(t, err)
+		require.Equal(t, 3, deleted)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		storage := new(mockIdempotencyStorage)
+		storage.On("DeleteExpired", ctx).Return(0, errors.New("error"))
+		service, err := NewIdempotencyService(storage, time.Hour, time.Minute)
+		require.NoError(t, err)
+
+		_, err = service.DeleteExpired(ctx)
+
+		require.ErrorIs(t, err, ErrIdempotency)
+	})
+}
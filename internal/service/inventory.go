@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// completeInventoryAttempts limits report rebuilds when scans arrive during completion
+const completeInventoryAttempts = 3
+
+var (
+	ErrInvalidScan        = errors.New("scan must contain product id or barcode")
+	ErrInventoryStart     = errors.New("failed to start inventory")
+	ErrInventoryActive    = errors.New("pvz already has inventory in progress")
+	ErrInventoryGet       = errors.New("failed to get inventory")
+	ErrInventoryNotFound  = errors.New("inventory not found")
+	ErrInventoryCompleted = errors.New("inventory is already completed")
+	ErrInventoryScan      = errors.New("failed to add scan")
+	ErrInventoryComplete  = errors.New("failed to complete inventory")
+)
+
+type InventoryStorager interface {
+	CreateInventory(ctx context.Context, pvzID openapi_types.UUID, startedBy *openapi_types.UUID) (*dto.Inventory, error)
+	GetInventory(ctx context.Context, inventoryID openapi_types.UUID) (*dto.Inventory, error)
+	GetPVZInventories(ctx context.Context, pvzID openapi_types.UUID) ([]dto.Inventory, error)
+	AddScan(ctx context.Context, inventoryID openapi_types.UUID, scan dto.PostInventoriesInventoryIdScansJSONBody, scannedBy *openapi_types.UUID) error
+	GetScans(ctx context.Context, inventoryID openapi_types.UUID) ([]dto.InventoryScan, error)
+	GetExpectedProducts(ctx context.Context, pvzID openapi_types.UUID) ([]dto.Product, error)
+	CompleteInventory(ctx context.Context, inventoryID openapi_types.UUID, report dto.InventoryReport, completedBy *openapi_types.UUID) (*dto.Inventory, error)
+}
+
+type InventoryService struct {
+	storage InventoryStorager
+}
+
+func NewInventoryService(storage InventoryStorager) (*InventoryService, error) {
+	if storage == nil {
+		return nil, ErrNilInConstruct
+	}
+
+	return &InventoryService{storage: storage}, nil
+}
+
+// StartInventory opens inventory in pvz, only one inventory can be in progress at a time
+func (s *InventoryService) StartInventory(ctx context.Context, pvzID openapi_types.UUID, userID *openapi_types.UUID) (*dto.Inventory, error) {
+	ctx, span := startSpan(ctx, "InventoryService.StartInventory")
+	defer span.End()
+
+	inventory, err := s.storage.CreateInventory(ctx, pvzID, userID)
+	if err != nil {
+		return nil, ErrInventoryStart
+	}
+	if inventory == nil {
+		return nil, ErrInventoryActive
+	}
+
+	return inventory, nil
+}
+
+func (s *InventoryService) GetInventory(ctx context.Context, inventoryID openapi_types.UUID) (*dto.Inventory, error) {
+	ctx, span := startSpan(ctx, "InventoryService.GetInventory")
+	defer span.End()
+
+	inventory, err := s.storage.GetInventory(ctx, inventoryID)
+	if err != nil {
+		return nil, ErrInventoryGet
+	}
+	if inventory == nil {
+		return nil, ErrInventoryNotFound
+	}
+
+	return inventory, nil
+}
+
+func (s *InventoryService) GetPVZInventories(ctx context.Context, pvzID openapi_types.UUID) ([]dto.Inventory, error) {
+	ctx, span := startSpan(ctx, "InventoryService.GetPVZInventories")
+	defer span.End()
+
+	inventories, err := s.storage.GetPVZInventories(ctx, pvzID)
+	if err != nil {
+		return nil, ErrInventoryGet
+	}
+
+	return inventories, nil
+}
+
+// Scan records product found on shelf by its id or barcode
+func (s *InventoryService) Scan(
+	ctx context.Context,
+	inventoryID openapi_types.UUID,
+	scan dto.PostInventoriesInventoryIdScansJSONBody,
+	userID *openapi_types.UUID,
+) (*dto.Inventory, error) {
+	ctx, span := startSpan(ctx, "InventoryService.Scan")
+	defer span.End()
+
+	if scan.Barcode != nil && *scan.Barcode == "" {
+		scan.Barcode = nil
+	}
+	if scan.ProductId == nil && scan.Barcode == nil {
+		return nil, ErrInvalidScan
+	}
+
+	inventory, err := s.GetInventory(ctx, inventoryID)
+	if err != nil {
+		return nil, err
+	}
+	if inventory.Status != dto.InventoryInProgress {
+		return nil, ErrInventoryCompleted
+	}
+
+	if err := s.storage.AddScan(ctx, inventoryID, scan, userID); err != nil {
+		return nil, ErrInventoryScan
+	}
+
+	inventory, err = s.GetInventory(ctx, inventoryID)
+	if err != nil {
+		return nil, err
+	}
+	// inventory was completed by concurrent request before scan
+	if inventory.Status != dto.InventoryInProgress {
+		return nil, ErrInventoryCompleted
+	}
+
+	return inventory, nil
+}
+
+// CompleteInventory compares scans with products received by pvz and not issued yet,
+// report is saved only if no scan was added while it was built
+func (s *InventoryService) CompleteInventory(ctx context.Context, inventoryID openapi_types.UUID, userID *openapi_types.UUID) (*dto.Inventory, error) {
+	ctx, span := startSpan(ctx, "InventoryService.CompleteInventory")
+	defer span.End()
+
+	for range completeInventoryAttempts {
+		inventory, err := s.GetInventory(ctx, inventoryID)
+		if err != nil {
+			return nil, err
+		}
+		if inventory.Status != dto.InventoryInProgress {
+			return nil, ErrInventoryCompleted
+		}
+
+		expected, err := s.storage.GetExpectedProducts(ctx, inventory.PvzId)
+		if err != nil {
+			return nil, ErrInventoryComplete
+		}
+		scans, err := s.storage.GetScans(ctx, inventoryID)
+		if err != nil {
+			return nil, ErrInventoryComplete
+		}
+
+		completed, err := s.storage.CompleteInventory(ctx, inventoryID, buildInventoryReport(expected, scans), userID)
+		if err != nil {
+			return nil, ErrInventoryComplete
+		}
+		if completed != nil {
+			return completed, nil
+		}
+	}
+
+	return nil, ErrInventoryComplete
+}
+
+// buildInventoryReport matches scans with expected products. Scans by id are matched first,
+// then every barcode scan takes one of remaining products with this barcode
+func buildInventoryReport(expected []dto.Product, scans []dto.InventoryScan) dto.InventoryReport {
+	found := make(map[openapi_types.UUID]bool, len(expected))
+	byBarcode := make(map[string][]openapi_types.UUID)
+	for _, product := range expected {
+		found[*product.Id] = false
+		if product.Barcode != nil {
+			byBarcode[*product.Barcode] = append(byBarcode[*product.Barcode], *product.Id)
+		}
+	}
+
+	unexpected := make([]dto.InventoryScan, 0)
+	barcodeScans := make([]dto.InventoryScan, 0)
+	for _, scan := range scans {
+		if scan.ProductId == nil {
+			barcodeScans = append(barcodeScans, scan)
+			continue
+		}
+		if matched, ok := found[*scan.ProductId]; !ok || matched {
+			unexpected = append(unexpected, scan)
+			continue
+		}
+		found[*scan.ProductId] = true
+	}
+
+	for _, scan := range barcodeScans {
+		matched := false
+		for _, id := range byBarcode[*scan.Barcode] {
+			if !found[id] {
+				found[id] = true
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unexpected = append(unexpected, scan)
+		}
+	}
+
+	missing := make([]dto.Product, 0)
+	for _, product := range expected {
+		if !found[*product.Id] {
+			missing = append(missing, product)
+		}
+	}
+
+	return dto.InventoryReport{
+		ExpectedCount: len(expected),
+		ScannedCount:  len(scans),
+		Missing:       missing,
+		Unexpected:    unexpected,
+	}
+}
@@ -0,0 +1,392 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockInventoryStorage struct {
+	mock.Mock
+}
+
+func (m *mockInventoryStorage) CreateInventory(ctx context.Context, pvzID openapi_types.UUID, startedBy *openapi_types.UUID) (*dto.Inventory, error) {
+	args := m.Called(ctx, pvzID, startedBy)
+	return args.Get(0).(*dto.Inventory), args.Error(1)
+}
+
+func (m *mockInventoryStorage) GetInventory(ctx context.Context, inventoryID openapi_types.UUID) (*dto.Inventory, error) {
+	args := m.Called(ctx, inventoryID)
+	return args.Get(0).(*dto.Inventory), args.Error(1)
+}
+
+func (m *mockInventoryStorage) GetPVZInventories(ctx context.Context, pvzID openapi_types.UUID) ([]dto.Inventory, error) {
+	args := m.Called(ctx, pvzID)
+	return args.Get(0).([]dto.Inventory), args.Error(1)
+}
+
+func (m *mockInventoryStorage) AddScan(
+	ctx context.Context,
+	inventoryID openapi_types.UUID,
+	scan dto.PostInventoriesInventoryIdScansJSONBody,
+	scannedBy *openapi_types.UUID,
+) error {
+	args := m.Called(ctx, inventoryID, scan, scannedBy)
+	return args.Error(0)
+}
+
+func (m *mockInventoryStorage) GetScans(ctx context.Context, inventoryID openapi_types.UUID) ([]dto.InventoryScan, error) {
+	args := m.Called(ctx, inventoryID)
+	return args.Get(0).([]dto.InventoryScan), args.Error(1)
+}
+
+func (m *mockInventoryStorage) GetExpectedProducts(ctx context.Context, pvzID openapi_types.UUID) ([]dto.Product, error) {
+	args := m.Called(ctx, pvzID)
+	return args.Get(0).([]dto.Product), args.Error(1)
+}
+
+func (m *mockInventoryStorage) CompleteInventory(
+	ctx context.Context,
+	inventoryID openapi_types.UUID,
+	report dto.InventoryReport,
+	completedBy *openapi_types.UUID,
+) (*dto.Inventory, error) {
+	args := m.Called(ctx, inventoryID, report, completedBy)
+	return args.Get(0).(*dto.Inventory), args.Error(1)
+}
+
+func TestNewInventoryService(t *testing.T) {
+	service, err := NewInventoryService(nil)
+	require.ErrorIs(t, err, ErrNilInConstruct)
+	require.Nil(t, service)
+
+	service, err = NewInventoryService(new(mockInventoryStorage))
+	require.NoError(t, err)
+	require.NotNil(t, service)
+}
+
+func TestInventoryService_StartInventory(t *testing.T) {
+	ctx := context.Background()
+	pvzID := openapi_types.UUID{1}
+	inventory := &dto.Inventory{Id: openapi_types.UUID{2}, PvzId: pvzID, Status: dto.InventoryInProgress}
+
+	testcases := []struct {
+		name      string
+		mockSetup func(*mockInventoryStorage)
+		expected  *dto.Inventory
+		err       error
+	}{
+		{
+			name: "inventory started",
+			mockSetup: func(m *mockInventoryStorage) {
+				m.On("CreateInventory", ctx, pvzID, (*openapi_types.UUID)(nil)).Return(inventory, nil)
+			},
+			expected: inventory,
+		},
+		{
+			name: "inventory already in progress",
+			mockSetup: func(m *mockInventoryStorage) {
+				m.On("CreateInventory", ctx, pvzID, (*openapi_types.UUID)(nil)).Return((*dto.Inventory)(nil), nil)
+			},
+			err: ErrInventoryActive,
+		},
+		{
+			name: "storage error",
+			mockSetup: func(m *mockInventoryStorage) {
+				m.On("CreateInventory", ctx, pvzID, (*openapi_types.UUID)(nil)).Return((*dto.Inventory)(nil), errors.New("error"))
+			},
+			err: ErrInventoryStart,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			storage := new(mockInventoryStorage)
+			testcase.mockSetup(storage)
+			service, err := NewInventoryService(storage)
+			require.NoError(t, err)
+
+			// act
+			inventory, err := service.StartInventory(ctx, pvzID, nil)
+
+			// assert
+			require.ErrorIs(t, err, testcase.err)
+			require.Equal(t, testcase.expected, inventory)
+			storage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestInventoryService_Scan(t *testing.T) {
+	ctx := context.Background()
+	inventoryID := openapi_types.UUID{1}
+	productID := openapi_types.UUID{2}
+	empty := ""
+	scan := dto.PostInventoriesInventoryIdScansJSONBody{ProductId: &productID}
+	inProgress := &dto.Inventory{Id: inventoryID, Status: dto.InventoryInProgress}
+	scanned := &dto.Inventory{Id: inventoryID, Status: dto.InventoryInProgress, ScannedCount: 1}
+	completed := &dto.Inventory{Id: inventoryID, Status: dto.InventoryCompleted}
+
+	testcases := []struct {
+		name      string
+		scan      dto.PostInventoriesInventoryIdScansJSONBody
+		mockSetup func(*mockInventoryStorage)
+		expected  *dto.Inventory
+		err       error
+	}{
+		{
+			name: "product scanned",
+			scan: scan,
+			mockSetup: func(m *mockInventoryStorage) {
+				m.On("GetInventory", ctx, inventoryID).Return(inProgress, nil).Once()
+				m.On("AddScan", ctx, inventoryID, scan, (*openapi_types.UUID)(nil)).Return(nil)
+				m.On("GetInventory", ctx, inventoryID).Return(scanned, nil).Once()
+			},
+			expected: scanned,
+		},
+		{
+			name:      "empty scan",
+			scan:      dto.PostInventoriesInventoryIdScansJSONBody{Barcode: &empty},
+			mockSetup: func(m *mockInventoryStorage) {},
+			err:       ErrInvalidScan,
+		},
+		{
+			name: "inventory not found",
+			scan: scan,
+			mockSetup: func(m *mockInventoryStorage) {
+				m.On("GetInventory", ctx, inventoryID).Return((*dto.Inventory)(nil), nil)
+			},
+			err: ErrInventoryNotFound,
+		},
+		{
+			name: "inventory completed",
+			scan: scan,
+			mockSetup: func(m *mockInventoryStorage) {
+				m.On("GetInventory", ctx, inventoryID).Return(completed, nil)
+			},
+			err: ErrInventoryCompleted,
+		},
+		{
+			name: "completed by concurrent request",
+			scan: scan,
+			mockSetup: func(m *mockInventoryStorage) {
+				m.On("GetInventory", ctx, inventoryID).Return(inProgress, nil).Once()
+				m.On("AddScan", ctx, inventoryID, scan, (*openapi_types.UUID)(nil)).Return(nil)
+				m.On("GetInventory", ctx, inventoryID).Return(completed, nil).Once()
+			},
+			err: ErrInventoryCompleted,
+		},
+		{
+			name: "storage error",
+			scan: scan,
+			mockSetup: func(m *mockInventoryStorage) {
+				m.On("GetInventory", ctx, inventoryID).Return(inProgress, nil)
+				m.On("AddScan", ctx, inventoryID, scan, (*openapi_types.UUID)(nil)).Return(errors.New("error"))
+			},
+			err: ErrInventoryScan,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			storage := new(mockInventoryStorage)
+			testcase.mockSetup(storage)
+			service, err := NewInventoryService(storage)
+			require.NoError(t, err)
+
+			// act
+			inventory, err := service.Scan(ctx, inventoryID, testcase.scan, nil)
+
+			// assert
+			require.ErrorIs(t, err, testcase.err)
+			require.Equal(t, testcase.expected, inventory)
+			storage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestInventoryService_CompleteInventory(t *testing.T) {
+	ctx := context.Background()
+	inventoryID, pvzID := openapi_types.UUID{1}, openapi_types.UUID{2}
+	first, second := openapi_types.UUID{3}, openapi_types.UUID{4}
+	inProgress := &dto.Inventory{Id: inventoryID, PvzId: pvzID, Status: dto.InventoryInProgress}
+	completed := &dto.Inventory{Id: inventoryID, PvzId: pvzID, Status: dto.InventoryCompleted}
+	firstScan := []dto.InventoryScan{{ProductId: &first}}
+	bothScans := []dto.InventoryScan{{ProductId: &first}, {ProductId: &second}}
+	missingSecond := dto.InventoryReport{
+		ExpectedCount: 2,
+		ScannedCount:  1,
+		Missing:       storedProducts(second),
+		Unexpected:    []dto.InventoryScan{},
+	}
+	nothingMissing := dto.InventoryReport{
+		ExpectedCount: 2,
+		ScannedCount:  2,
+		Missing:       []dto.Product{},
+		Unexpected:    []dto.InventoryScan{},
+	}
+
+	testcases := []struct {
+		name      string
+		mockSetup func(*mockInventoryStorage)
+		expected  *dto.Inventory
+		err       error
+	}{
+		{
+			name: "inventory completed",
+			mockSetup: func(m *mockInventoryStorage) {
+				m.On("GetInventory", ctx, inventoryID).Return(inProgress, nil)
+				m.On("GetExpectedProducts", ctx, pvzID).Return(storedProducts(first, second), nil)
+				m.On("GetScans", ctx, inventoryID).Return(firstScan, nil)
+				m.On("CompleteInventory", ctx, inventoryID, missingSecond, (*openapi_types.UUID)(nil)).Return(completed, nil)
+			},
+			expected: completed,
+		},
+		{
+			name: "report rebuilt after concurrent scan",
+			mockSetup: func(m *mockInventoryStorage) {
+				m.On("GetInventory", ctx, inventoryID).Return(inProgress, nil)
+				m.On("GetExpectedProducts", ctx, pvzID).Return(storedProducts(first, second), nil)
+				m.On("GetScans", ctx, inventoryID).Return(firstScan, nil).Once()
+				m.On("CompleteInventory", ctx, inventoryID, missingSecond, (*openapi_types.UUID)(nil)).Return((*dto.Inventory)(nil), nil)
+				m.On("GetScans", ctx, inventoryID).Return(bothScans, nil).Once()
+				m.On("CompleteInventory", ctx, inventoryID, nothingMissing, (*openapi_types.UUID)(nil)).Return(completed, nil)
+			},
+			expected: completed,
+		},
+		{
+			name: "already completed",
+			mockSetup: func(m *mockInventoryStorage) {
+				m.On("GetInventory", ctx, inventoryID).Return(completed, nil)
+			},
+			err: ErrInventoryCompleted,
+		},
+		{
+			name: "completed by concurrent request",
+			mockSetup: func(m *mockInventoryStorage) {
+				m.On("GetInventory", ctx, inventoryID).Return(inProgress, nil).Once()
+				m.On("GetExpectedProducts", ctx, pvzID).Return(storedProducts(first, second), nil)
+				m.On("GetScans", ctx, inventoryID).Return(firstScan, nil)
+				m.On("CompleteInventory", ctx, inventoryID, missingSecond, (*openapi_types.UUID)(nil)).Return((*dto.Inventory)(nil), nil)
+				m.On("GetInventory", ctx, inventoryID).Return(completed, nil).Once()
+			},
+			err: ErrInventoryCompleted,
+		},
+		{
+			name: "scans keep arriving during completion",
+			mockSetup: func(m *mockInventoryStorage) {
+				m.On("GetInventory", ctx, inventoryID).Return(inProgress, nil).Times(completeInventoryAttempts)
+				m.On("GetExpectedProducts", ctx, pvzID).Return(storedProducts(first, second), nil)
+				m.On("GetScans", ctx, inventoryID).Return(firstScan, nil)
+				m.On("CompleteInventory", ctx, inventoryID, missingSecond, (*openapi_types.UUID)(nil)).
+					Return((*dto.Inventory)(nil), nil).Times(completeInventoryAttempts)
+			},
+			err: ErrInventoryComplete,
+		},
+		{
+			name: "storage error",
+			mockSetup: func(m *mockInventoryStorage) {
+				m.On("GetInventory", ctx, inventoryID).Return(inProgress, nil)
+				m.On("GetExpectedProducts", ctx, pvzID).Return([]dto.Product(nil), errors.New("error"))
+			},
+			err: ErrInventoryComplete,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			storage := new(mockInventoryStorage)
+			testcase.mockSetup(storage)
+			service, err := NewInventoryService(storage)
+			require.NoError(t, err)
+
+			// act
+			inventory, err := service.CompleteInventory(ctx, inventoryID, nil)
+
+			// assert
+			require.ErrorIs(t, err, testcase.err)
+			require.Equal(t, testcase.expected, inventory)
+			storage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBuildInventoryReport(t *testing.T) {
+	first, second, third, stranger := openapi_types.UUID{1}, openapi_types.UUID{2}, openapi_types.UUID{3}, openapi_types.UUID{4}
+	barcode, unknownBarcode := "4600000000001", "4600000000002"
+	scannedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	expected := storedProducts(first, second, third)
+	expected[1].Barcode = &barcode
+	expected[2].Barcode = &barcode
+
+	scans := []dto.InventoryScan{
+		{Barcode: &barcode, ScannedAt: scannedAt},
+		{ProductId: &second, ScannedAt: scannedAt},
+		{ProductId: &stranger, ScannedAt: scannedAt},
+		{Barcode: &unknownBarcode, ScannedAt: scannedAt},
+	}
+
+	// act
+	report := buildInventoryReport(expected, scans)
+
+	// assert
+	require.Equal(t, 3, report.ExpectedCount)
+	require.Equal(t, 4, report.ScannedCount)
+	// barcode scan takes third product as second one is matched by id
+	require.Equal(t, []dto.Product{expected[0]}, report.Missing)
+	require.Equal(t, []dto.InventoryScan{scans[2], scans[3]}, report.Unexpected)
+}
+
+func TestBuildInventoryReport_SharedBarcode(t *testing.T) {
+	// arrange
+	first, second := openapi_types.UUID{1}, openapi_types.UUID{2}
+	barcode := "4600000000001"
+	scannedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	expected := storedProducts(first, second)
+	expected[0].Barcode = &barcode
+	expected[1].Barcode = &barcode
+	scan := dto.InventoryScan{Barcode: &barcode, ScannedAt: scannedAt}
+
+	// act
+	report := buildInventoryReport(expected, []dto.InventoryScan{scan, scan, scan})
+
+	// assert
+	require.Equal(t, 3, report.ScannedCount)
+	// products of different receptions with the same barcode are found by two scans
+	require.Empty(t, report.Missing)
+	require.Equal(t, []dto.InventoryScan{scan}, report.Unexpected)
+}
+
+func TestBuildInventoryReport_Empty(t *testing.T) {
+	// act
+	report := buildInventoryReport([]dto.Product{}, []dto.InventoryScan{})
+
+	// assert
+	// empty lists are reported instead of nil, so they are written as [] in json
+	require.Equal(t, dto.InventoryReport{Missing: []dto.Product{}, Unexpected: []dto.InventoryScan{}}, report)
+}
+
+func TestBuildInventoryReport_RepeatedScan(t *testing.T) {
+	// arrange
+	first, second := openapi_types.UUID{1}, openapi_types.UUID{2}
+	scannedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	expected := storedProducts(first, second)
+	scan := dto.InventoryScan{ProductId: &first, ScannedAt: scannedAt}
+
+	// act
+	report := buildInventoryReport(expected, []dto.InventoryScan{scan, scan})
+
+	// assert
+	// product is found once, its second scan does not find another product
+	require.Equal(t, []dto.Product{expected[1]}, report.Missing)
+	require.Equal(t, []dto.InventoryScan{scan}, report.Unexpected)
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Arzeeq/pvz-api/internal/metrics"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// unknownCity labels metrics of pvz whose city could not be loaded
+const unknownCity = "unknown"
+
+type MetricsStorager interface {
+	GetPVZCity(ctx context.Context, pvzID openapi_types.UUID) (dto.PVZCity, error)
+	GetProductTypeCounts(ctx context.Context, productIDs []openapi_types.UUID) (map[dto.ProductType]int, error)
+	GetOpenReceptionCounts(ctx context.Context) (map[dto.PVZCity]int, error)
+	GetClosedReceptionSizes(ctx context.Context) (map[int]int, error)
+}
+
+// BusinessMetrics records business metrics after operations are committed.
+// Nil BusinessMetrics records nothing, so services can be used without it
+type BusinessMetrics struct {
+	storage MetricsStorager
+	// cities caches city of pvz, it never changes after pvz is created
+	cities sync.Map
+	// seeded is set when histogram got receptions closed before start, they are observed only once
+	seeded atomic.Bool
+}
+
+func NewBusinessMetrics(storage MetricsStorager) (*BusinessMetrics, error) {
+	if storage == nil {
+		return nil, ErrNilInConstruct
+	}
+
+	return &BusinessMetrics{storage: storage}, nil
+}
+
+// Refresh loads metrics describing current state from storage, so they survive restarts:
+// open receptions per city and sizes of closed receptions. Sizes are observed by the first
+// successful refresh only, later receptions are observed when they are closed
+func (m *BusinessMetrics) Refresh(ctx context.Context) error {
+	ctx, span := startSpan(ctx, "BusinessMetrics.Refresh")
+	defer span.End()
+
+	open, err := m.storage.GetOpenReceptionCounts(ctx)
+	if err != nil {
+		return err
+	}
+
+	metrics.ReceptionsOpen.Reset()
+	for city, count := range open {
+		metrics.ReceptionsOpen.WithLabelValues(string(city)).Set(float64(count))
+	}
+
+	if m.seeded.Load() {
+		return nil
+	}
+	sizes, err := m.storage.GetClosedReceptionSizes(ctx)
+	if err != nil || !m.seeded.CompareAndSwap(false, true) {
+		return err
+	}
+	for size, receptions := range sizes {
+		for range receptions {
+			metrics.ReceptionProducts.Observe(float64(size))
+		}
+	}
+
+	return nil
+}
+
+func (m *BusinessMetrics) pvzCreated(pvz *dto.PVZ) {
+	if m == nil {
+		return
+	}
+
+	if pvz.Id != nil {
+		m.cities.Store(*pvz.Id, pvz.City)
+	}
+	metrics.PvzCreatedTotal.WithLabelValues(string(pvz.City)).Inc()
+}
+
+func (m *BusinessMetrics) receptionOpened(ctx context.Context, pvzID openapi_types.UUID) {
+	if m == nil {
+		return
+	}
+
+	city := m.city(ctx, pvzID)
+	metrics.ReceptionsCreatedTotal.WithLabelValues(city).Inc()
+	metrics.ReceptionsOpen.WithLabelValues(city).Inc()
+}
+
+// receptionCreatedClosed counts reception which is already closed when it is created, e.g. uploaded by node
+func (m *BusinessMetrics) receptionCreatedClosed(ctx context.Context, pvzID openapi_types.UUID) {
+	if m == nil {
+		return
+	}
+
+	metrics.ReceptionsCreatedTotal.WithLabelValues(m.city(ctx, pvzID)).Inc()
+}
+
+func (m *BusinessMetrics) receptionClosed(ctx context.Context, reception *dto.Reception, products int) {
+	if m == nil {
+		return
+	}
+
+	metrics.ReceptionsOpen.WithLabelValues(m.city(ctx, reception.PvzId)).Dec()
+	metrics.ReceptionProducts.Observe(float64(products))
+	if reception.ClosedAt != nil {
+		metrics.ReceptionDuration.Observe(reception.ClosedAt.Sub(reception.DateTime).Seconds())
+	}
+}
+
+func (m *BusinessMetrics) productsAdded(ctx context.Context, pvzID openapi_types.UUID, counts map[dto.ProductType]int) {
+	if m == nil {
+		return
+	}
+
+	city := m.city(ctx, pvzID)
+	for productType, count := range counts {
+		metrics.ProductsAddedTotal.WithLabelValues(city, string(productType)).Add(float64(count))
+	}
+}
+
+// transferReceived counts products added to destination pvz by transfer
+func (m *BusinessMetrics) transferReceived(ctx context.Context, transfer *dto.Transfer) {
+	if m == nil {
+		return
+	}
+
+	productIDs := make([]openapi_types.UUID, 0, len(transfer.Items))
+	for _, item := range transfer.Items {
+		productIDs = append(productIDs, item.ProductId)
+	}
+	counts, err := m.storage.GetProductTypeCounts(ctx, productIDs)
+	if err != nil {
+		return
+	}
+	m.productsAdded(ctx, transfer.ToPvzId, counts)
+}
+
+func (m *BusinessMetrics) city(ctx context.Context, pvzID openapi_types.UUID) string {
+	if city, ok := m.cities.Load(pvzID); ok {
+		return string(city.(dto.PVZCity))
+	}
+
+	city, err := m.storage.GetPVZCity(ctx, pvzID)
+	if err != nil || city == "" {
+		return unknownCity
+	}
+	m.cities.Store(pvzID, city)
+
+	return string(city)
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Arzeeq/pvz-api/internal/metrics"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockMetricsStorage struct {
+	mock.Mock
+}
+
+func (m *mockMetricsStorage) GetPVZCity(ctx context.Context, pvzID openapi_types.UUID) (dto.PVZCity, error) {
+	args := m.Called(ctx, pvzID)
+	return args.Get(0).(dto.PVZCity), args.Error(1)
+}
+
+func (m *mockMetricsStorage) GetProductTypeCounts(ctx context.Context, productIDs []openapi_types.UUID) (map[dto.ProductType]int, error) {
+	args := m.Called(ctx, productIDs)
+	return args.Get(0).(map[dto.ProductType]int), args.Error(1)
+}
+
+func (m *mockMetricsStorage) GetOpenReceptionCounts(ctx context.Context) (map[dto.PVZCity]int, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[dto.PVZCity]int), args.Error(1)
+}
+
+func (m *mockMetricsStorage) GetClosedReceptionSizes(ctx context.Context) (map[int]int, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[int]int), args.Error(1)
+}
+
+func TestNewBusinessMetrics(t *testing.T) {
+	businessMetrics, err := NewBusinessMetrics(nil)
+	require.ErrorIs(t, err, ErrNilInConstruct)
+	require.Nil(t, businessMetrics)
+
+	businessMetrics, err = NewBusinessMetrics(new(mockMetricsStorage))
+	require.NoError(t, err)
+	require.NotNil(t, businessMetrics)
+}
+
+func histogramSamples(t *testing.T, histogram prometheus.Histogram) uint64 {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(histogram))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	return families[0].GetMetric()[0].GetHistogram().GetSampleCount()
+}
+
+func TestBusinessMetrics_Refresh(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		// arrange
+		storage := new(mockMetricsStorage)
+		storage.On("GetOpenReceptionCounts", ctx).Return(map[dto.PVZCity]int{dto.Kazan: 2}, nil)
+		storage.On("GetClosedReceptionSizes", ctx).Return(map[int]int{3: 2}, nil).Once()
+		businessMetrics, err := NewBusinessMetrics(storage)
+		require.NoError(t, err)
+		metrics.ReceptionsOpen.WithLabelValues(string(dto.Moscow)).Set(5)
+		samples := histogramSamples(t, metrics.ReceptionProducts)
+
+		// act
+		err = businessMetrics.Refresh(ctx)
+		require.NoError(t, err)
+		err = businessMetrics.Refresh(ctx)
+
+		// assert
+		require.NoError(t, err)
+		// closed receptions are observed by the first refresh only
+		require.Equal(t, samples+2, histogramSamples(t, metrics.ReceptionProducts))
+		require.Equal(t, float64(2), testutil.ToFloat64(metrics.ReceptionsOpen.WithLabelValues(string(dto.Kazan))))
+		// cities without open receptions are dropped
+		require.Equal(t, 1, testutil.CollectAndCount(metrics.ReceptionsOpen))
+		storage.AssertExpectations(t)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		// arrange
+		storage := new(mockMetricsStorage)
+		storage.On("GetOpenReceptionCounts", ctx).Return(map[dto.PVZCity]int(nil), errors.New("db error"))
+		businessMetrics, err := NewBusinessMetrics(storage)
+		require.NoError(t, err)
+
+		// act
+		err = businessMetrics.Refresh(ctx)
+
+		// assert
+		require.Error(t, err)
+		storage.AssertExpectations(t)
+	})
+
+	t.Run("sizes are loaded again after error", func(t *testing.T) {
+		// arrange
+		storage := new(mockMetricsStorage)
+		storage.On("GetOpenReceptionCounts", ctx).Return(map[dto.PVZCity]int{}, nil)
+		storage.On("GetClosedReceptionSizes", ctx).Return(map[int]int(nil), errors.New("db error")).Once()
+		storage.On("GetClosedReceptionSizes", ctx).Return(map[int]int{1: 1}, nil).Once()
+		businessMetrics, err := NewBusinessMetrics(storage)
+		require.NoError(t, err)
+		samples := histogramSamples(t, metrics.ReceptionProducts)
+
+		// act
+		err = businessMetrics.Refresh(ctx)
+		require.Error(t, err)
+		err = businessMetrics.Refresh(ctx)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, samples+1, histogramSamples(t, metrics.ReceptionProducts))
+		storage.AssertExpectations(t)
+	})
+}
+
+func TestBusinessMetrics_City(t *testing.T) {
+	ctx := context.Background()
+	pvzID := openapi_types.UUID{1}
+	unknownPVZID := openapi_types.UUID{2}
+
+	// arrange
+	storage := new(mockMetricsStorage)
+	storage.On("GetPVZCity", ctx, pvzID).Return(dto.SaintPetersburg, nil).Once()
+	storage.On("GetPVZCity", ctx, unknownPVZID).Return(dto.PVZCity(""), errors.New("db error"))
+	businessMetrics, err := NewBusinessMetrics(storage)
+	require.NoError(t, err)
+	created := metrics.ReceptionsCreatedTotal.WithLabelValues(string(dto.SaintPetersburg))
+	before := testutil.ToFloat64(created)
+
+	// act
+	businessMetrics.receptionOpened(ctx, pvzID)
+	businessMetrics.receptionOpened(ctx, pvzID)
+	city := businessMetrics.city(ctx, unknownPVZID)
+
+	// assert
+	require.Equal(t, before+2, testutil.ToFloat64(created))
+	require.Equal(t, unknownCity, city)
+	// city of pvz is loaded once
+	storage.AssertExpectations(t)
+}
+
+func TestBusinessMetrics_Nil(t *testing.T) {
+	var businessMetrics *BusinessMetrics
+
+	require.NotPanics(t, func() {
+		businessMetrics.pvzCreated(&dto.PVZ{City: dto.Moscow})
+		businessMetrics.receptionOpened(context.Background(), openapi_types.UUID{1})
+		businessMetrics.productsAdded(context.Background(), openapi_types.UUID{1}, map[dto.ProductType]int{dto.ProductTypeShoes: 1})
+	})
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+var ErrSyncStatus = errors.New("failed to get sync status")
+var ErrSyncUpload = errors.New("failed to upload changes to central server")
+
+// namespace for batch ids, batch of the same items always gets the same id
+var syncBatchNamespace = uuid.MustParse("9b3c4f0e-6f5c-4a57-9a8e-3d0f2b7c1e64")
+
+type OutboxStorager interface {
+	GetPendingItems(ctx context.Context, limit int) ([]dto.SyncItem, error)
+	SaveItemResults(ctx context.Context, results []dto.SyncItemResult) error
+	SaveAttemptError(ctx context.Context, ids []openapi_types.UUID, message string) error
+	GetSyncStatus(ctx context.Context) (*dto.SyncStatus, error)
+}
+
+type CentralClient interface {
+	UploadBatch(ctx context.Context, batch dto.SyncBatch) (*dto.SyncBatchResult, error)
+}
+
+// NodeSyncService uploads changes recorded by offline PVZ node to the central server
+type NodeSyncService struct {
+	storage   OutboxStorager
+	client    CentralClient
+	batchSize int
+
+	mu         sync.Mutex
+	lastSyncAt *time.Time
+	lastError  *string
+}
+
+func NewNodeSyncService(storage OutboxStorager, client CentralClient, batchSize int) (*NodeSyncService, error) {
+	if storage == nil || client == nil {
+		return nil, ErrNilInConstruct
+	}
+	if batchSize <= 0 {
+		return nil, errors.New("sync batch size must be positive")
+	}
+
+	return &NodeSyncService{
+		storage:   storage,
+		client:    client,
+		batchSize: batchSize,
+	}, nil
+}
+
+// Sync uploads pending changes batch by batch until there is nothing left or upload fails
+func (s *NodeSyncService) Sync(ctx context.Context) error {
+	ctx, span := startSpan(ctx, "NodeSyncService.Sync")
+	defer span.End()
+
+	for {
+		items, err := s.storage.GetPendingItems(ctx, s.batchSize)
+		if err != nil {
+			return s.finish(err)
+		}
+		if len(items) == 0 {
+			return s.finish(nil)
+		}
+
+		ids := make([]openapi_types.UUID, 0, len(items))
+		for _, item := range items {
+			ids = append(ids, item.Id)
+		}
+
+		batch := dto.SyncBatch{BatchId: batchID(ids), Items: items}
+		result, err := s.client.UploadBatch(ctx, batch)
+		if err != nil {
+			if errSave := s.storage.SaveAttemptError(ctx, ids, err.Error()); errSave != nil {
+				return s.finish(errSave)
+			}
+			return s.finish(err)
+		}
+
+		if err := s.storage.SaveItemResults(ctx, result.Items); err != nil {
+			return s.finish(err)
+		}
+
+		if len(items) < s.batchSize {
+			return s.finish(nil)
+		}
+	}
+}
+
+func (s *NodeSyncService) GetStatus(ctx context.Context) (*dto.SyncStatus, error) {
+	ctx, span := startSpan(ctx, "NodeSyncService.GetStatus")
+	defer span.End()
+
+	status, err := s.storage.GetSyncStatus(ctx)
+	if err != nil {
+		return nil, ErrSyncStatus
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status.LastSyncAt = s.lastSyncAt
+	status.LastError = s.lastError
+
+	return status, nil
+}
+
+// finish remembers outcome of sync attempt for the status endpoint
+func (s *NodeSyncService) finish(err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		message := err.Error()
+		s.lastError = &message
+		return fmt.Errorf("%w: %s", ErrSyncUpload, message)
+	}
+
+	now := time.Now()
+	s.lastSyncAt = &now
+	s.lastError = nil
+	return nil
+}
+
+func batchID(ids []openapi_types.UUID) openapi_types.UUID {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, id.String())
+	}
+	return uuid.NewSHA1(syncBatchNamespace, []byte(strings.Join(parts, ",")))
+}
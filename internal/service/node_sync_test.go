@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockOutboxStorage struct {
+	mock.Mock
+}
+
+func (m *mockOutboxStorage) GetPendingItems(ctx context.Context, limit int) ([]dto.SyncItem, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]dto.SyncItem), args.Error(1)
+}
+
+func (m *mockOutboxStorage) SaveItemResults(ctx context.Context, results []dto.SyncItemResult) error {
+	args := m.Called(ctx, results)
+	return args.Error(0)
+}
+
+func (m *mockOutboxStorage) SaveAttemptError(ctx context.Context, ids []openapi_types.UUID, message string) error {
+	args := m.Called(ctx, ids, message)
+	return args.Error(0)
+}
+
+func (m *mockOutboxStorage) GetSyncStatus(ctx context.Context) (*dto.SyncStatus, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*dto.SyncStatus), args.Error(1)
+}
+
+type mockCentralClient struct {
+	mock.Mock
+}
+
+func (m *mockCentralClient) UploadBatch(ctx context.Context, batch dto.SyncBatch) (*dto.SyncBatchResult, error) {
+	args := m.Called(ctx, batch)
+	return args.Get(0).(*dto.SyncBatchResult), args.Error(1)
+}
+
+func TestNewNodeSyncService(t *testing.T) {
+	_, err := NewNodeSyncService(nil, new(mockCentralClient), 10)
+	require.ErrorIs(t, err, ErrNilInConstruct)
+
+	_, err = NewNodeSyncService(new(mockOutboxStorage), new(mockCentralClient), 0)
+	require.Error(t, err)
+
+	service, err := NewNodeSyncService(new(mockOutboxStorage), new(mockCentralClient), 10)
+	require.NoError(t, err)
+	require.NotNil(t, service)
+}
+
+func TestNodeSyncService_Sync(t *testing.T) {
+	ctx := context.Background()
+	items := []dto.SyncItem{
+		{Id: uuid.New(), Kind: dto.KindReceptionCreated},
+		{Id: uuid.New(), Kind: dto.KindProductAdded},
+	}
+	ids := []openapi_types.UUID{items[0].Id, items[1].Id}
+	results := []dto.SyncItemResult{
+		{Id: items[0].Id, Status: dto.SyncApplied},
+		{Id: items[1].Id, Status: dto.SyncApplied},
+	}
+
+	testcases := []struct {
+		name      string
+		mockSetup func(*mockOutboxStorage, *mockCentralClient)
+		err       error
+		lastError bool
+	}{
+		{
+			name: "nothing to sync",
+			mockSetup: func(s *mockOutboxStorage, c *mockCentralClient) {
+				s.On("GetPendingItems", ctx, 10).Return([]dto.SyncItem{}, nil)
+			},
+		},
+		{
+			name: "items uploaded",
+			mockSetup: func(s *mockOutboxStorage, c *mockCentralClient) {
+				s.On("GetPendingItems", ctx, 10).Return(items, nil)
+				c.On("UploadBatch", ctx, dto.SyncBatch{BatchId: batchID(ids), Items: items}).
+					Return(&dto.SyncBatchResult{BatchId: batchID(ids), Items: results}, nil)
+				s.On("SaveItemResults", ctx, results).Return(nil)
+			},
+		},
+		{
+			name: "central server unavailable",
+			mockSetup: func(s *mockOutboxStorage, c *mockCentralClient) {
+				s.On("GetPendingItems", ctx, 10).Return(items, nil)
+				c.On("UploadBatch", ctx, mock.Anything).
+					Return((*dto.SyncBatchResult)(nil), errors.New("connection refused"))
+				s.On("SaveAttemptError", ctx, ids, "connection refused").Return(nil)
+			},
+			err:       ErrSyncUpload,
+			lastError: true,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			storage := new(mockOutboxStorage)
+			client := new(mockCentralClient)
+			testcase.mockSetup(storage, client)
+			service, err := NewNodeSyncService(storage, client, 10)
+			require.NoError(t, err)
+
+			// act
+			err = service.Sync(ctx)
+
+			// assert
+			require.ErrorIs(t, err, testcase.err)
+			storage.On("GetSyncStatus", ctx).Return(&dto.SyncStatus{}, nil)
+			status, err := service.GetStatus(ctx)
+			require.NoError(t, err)
+			require.Equal(t, testcase.lastError, status.LastError != nil)
+			require.Equal(t, !testcase.lastError, status.LastSyncAt != nil)
+			storage.AssertExpectations(t)
+			client.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBatchID(t *testing.T) {
+	ids := []openapi_types.UUID{uuid.New(), uuid.New()}
+	require.Equal(t, batchID(ids), batchID(ids))
+	require.NotEqual(t, batchID(ids), batchID(ids[:1]))
+}
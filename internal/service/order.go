@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Arzeeq/pvz-api/pkg/auth"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// pickupCodeDigits is length of pickup code told to customer
+const pickupCodeDigits = 6
+
+var (
+	ErrInvalidOrder           = errors.New("order must have customer and distinct products")
+	ErrOrderCreate            = errors.New("failed to create order")
+	ErrOrderGet               = errors.New("failed to get order")
+	ErrOrderNotFound          = errors.New("order not found")
+	ErrOrderIssued            = errors.New("order is already issued")
+	ErrOrderIssue             = errors.New("failed to issue order")
+	ErrOrderProductsNotStored = errors.New("products of order must be stored in pvz")
+	ErrPickupCodeGenerate     = errors.New("failed to generate pickup code")
+	ErrInvalidPickupCode      = errors.New("invalid or expired pickup code")
+	ErrPickupLocked           = errors.New("too many wrong pickup codes, try again later")
+)
+
+type OrderStorager interface {
+	CreateOrder(ctx context.Context, payload dto.PostOrdersJSONBody, createdBy *openapi_types.UUID) (*dto.Order, error)
+	GetOrder(ctx context.Context, orderID openapi_types.UUID) (*dto.Order, error)
+	GetPVZProducts(ctx context.Context, pvzID openapi_types.UUID, productIDs []openapi_types.UUID) ([]dto.Product, error)
+	SetPickupCode(ctx context.Context, orderID openapi_types.UUID, codeHash string, expiresAt time.Time) error
+	// ClaimPickupAttempt counts attempt before code is compared, returns nil when verification is locked
+	ClaimPickupAttempt(
+		ctx context.Context,
+		orderID openapi_types.UUID,
+		now time.Time,
+		maxAttempts int,
+		lockUntil time.Time,
+	) (*dto.PickupCodeState, error)
+	ResetPickupAttempts(ctx context.Context, orderID openapi_types.UUID) error
+	// IssueOrder returns ErrOrderProductsNotStored when some of products are not stored anymore
+	IssueOrder(ctx context.Context, orderID openapi_types.UUID, issuedBy *openapi_types.UUID) (*dto.Order, error)
+}
+
+// PickupPolicy configures pickup codes: how long code is valid,
+// how many wrong codes in a row are allowed and for how long verification is locked after that
+type PickupPolicy struct {
+	CodeTTL     time.Duration
+	MaxAttempts int
+	Lockout     time.Duration
+}
+
+type OrderService struct {
+	storage OrderStorager
+	policy  PickupPolicy
+}
+
+func NewOrderService(storage OrderStorager, policy PickupPolicy) (*OrderService, error) {
+	if storage == nil {
+		return nil, ErrNilInConstruct
+	}
+
+	return &OrderService{storage: storage, policy: policy}, nil
+}
+
+// CreateOrder groups stored products of pvz into order for customer
+func (s *OrderService) CreateOrder(ctx context.Context, payload dto.PostOrdersJSONBody, userID *openapi_types.UUID) (*dto.Order, error) {
+	ctx, span := startSpan(ctx, "OrderService.CreateOrder")
+	defer span.End()
+
+	if payload.Customer == "" || len(payload.ProductIds) == 0 {
+		return nil, ErrInvalidOrder
+	}
+	seen := make(map[openapi_types.UUID]struct{}, len(payload.ProductIds))
+	for _, id := range payload.ProductIds {
+		if _, ok := seen[id]; ok {
+			return nil, ErrInvalidOrder
+		}
+		seen[id] = struct{}{}
+	}
+
+	if err := s.checkProductsStored(ctx, payload.PvzId, payload.ProductIds); err != nil {
+		return nil, err
+	}
+
+	order, err := s.storage.CreateOrder(ctx, payload, userID)
+	if err != nil {
+		return nil, ErrOrderCreate
+	}
+
+	return order, nil
+}
+
+func (s *OrderService) GetOrder(ctx context.Context, orderID openapi_types.UUID) (*dto.Order, error) {
+	ctx, span := startSpan(ctx, "OrderService.GetOrder")
+	defer span.End()
+
+	order, err := s.storage.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, ErrOrderGet
+	}
+	if order == nil {
+		return nil, ErrOrderNotFound
+	}
+
+	return order, nil
+}
+
+// GeneratePickupCode issues new one-time code for order, only its hash is stored
+func (s *OrderService) GeneratePickupCode(ctx context.Context, orderID openapi_types.UUID) (*dto.PickupCode, error) {
+	ctx, span := startSpan(ctx, "OrderService.GeneratePickupCode")
+	defer span.End()
+
+	order, err := s.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status == dto.OrderIssued {
+		return nil, ErrOrderIssued
+	}
+
+	code, err := auth.GenerateCode(pickupCodeDigits)
+	if err != nil {
+		return nil, ErrPickupCodeGenerate
+	}
+	codeHash, err := auth.HashPassword(code)
+	if err != nil {
+		return nil, ErrPickupCodeGenerate
+	}
+
+	expiresAt := time.Now().Add(s.policy.CodeTTL)
+	if err := s.storage.SetPickupCode(ctx, orderID, codeHash, expiresAt); err != nil {
+		return nil, ErrPickupCodeGenerate
+	}
+
+	return &dto.PickupCode{Code: code, ExpiresAt: expiresAt}, nil
+}
+
+// Pickup verifies pickup code of order at pvz and issues order products to customer
+func (s *OrderService) Pickup(
+	ctx context.Context,
+	pvzID openapi_types.UUID,
+	payload dto.PostPvzPvzIdPickupJSONBody,
+	userID *openapi_types.UUID,
+) (*dto.Order, error) {
+	ctx, span := startSpan(ctx, "OrderService.Pickup")
+	defer span.End()
+
+	order, err := s.GetOrder(ctx, payload.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	if order.PvzId != pvzID {
+		return nil, ErrOrderNotFound
+	}
+	if order.Status == dto.OrderIssued {
+		return nil, ErrOrderIssued
+	}
+
+	if err := s.verifyPickupCode(ctx, order.Id, payload.Code); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkProductsStored(ctx, pvzID, order.ProductIds); err != nil {
+		return nil, err
+	}
+
+	issued, err := s.storage.IssueOrder(ctx, order.Id, userID)
+	// products were moved by concurrent request after they were checked
+	if errors.Is(err, ErrOrderProductsNotStored) {
+		return nil, ErrOrderProductsNotStored
+	}
+	if err != nil {
+		return nil, ErrOrderIssue
+	}
+	// order was issued by concurrent request
+	if issued == nil {
+		return nil, ErrOrderIssued
+	}
+
+	return issued, nil
+}
+
+// verifyPickupCode counts every attempt before comparing the code,
+// so concurrent wrong codes can not get more than MaxAttempts tries
+func (s *OrderService) verifyPickupCode(ctx context.Context, orderID openapi_types.UUID, code string) error {
+	now := time.Now()
+	state, err := s.storage.ClaimPickupAttempt(ctx, orderID, now, s.policy.MaxAttempts, now.Add(s.policy.Lockout))
+	if err != nil {
+		return ErrOrderIssue
+	}
+	if state == nil {
+		return ErrPickupLocked
+	}
+
+	valid := state.CodeHash != nil && state.ExpiresAt != nil && now.Before(*state.ExpiresAt) &&
+		auth.ComparePasswords(*state.CodeHash, code)
+	if !valid {
+		// this attempt has used the last try
+		if state.LockedUntil != nil {
+			return ErrPickupLocked
+		}
+		return ErrInvalidPickupCode
+	}
+
+	if err := s.storage.ResetPickupAttempts(ctx, orderID); err != nil {
+		return ErrOrderIssue
+	}
+
+	return nil
+}
+
+func (s *OrderService) checkProductsStored(ctx context.Context, pvzID openapi_types.UUID, productIDs []openapi_types.UUID) error {
+	products, err := s.storage.GetPVZProducts(ctx, pvzID, productIDs)
+	if err != nil {
+		return ErrOrderGet
+	}
+	if len(products) != len(productIDs) {
+		return ErrOrderProductsNotStored
+	}
+	for _, product := range products {
+		// expired products wait for return to sender and are not issued
+		if product.Status == nil || *product.Status != dto.ProductStored || product.ExpiredAt != nil {
+			return ErrOrderProductsNotStored
+		}
+	}
+
+	return nil
+}
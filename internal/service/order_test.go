@@ -0,0 +1,456 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Arzeeq/pvz-api/pkg/auth"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockOrderStorage struct {
+	mock.Mock
+}
+
+func (m *mockOrderStorage) CreateOrder(ctx context.Context, payload dto.PostOrdersJSONBody, createdBy *openapi_types.UUID) (*dto.Order, error) {
+	args := m.Called(ctx, payload, createdBy)
+	return args.Get(0).(*dto.Order), args.Error(1)
+}
+
+func (m *mockOrderStorage) GetOrder(ctx context.Context, orderID openapi_types.UUID) (*dto.Order, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).(*dto.Order), args.Error(1)
+}
+
+func (m *mockOrderStorage) GetPVZProducts(ctx context.Context, pvzID openapi_types.UUID, productIDs []openapi_types.UUID) ([]dto.Product, error) {
+	args := m.Called(ctx, pvzID, productIDs)
+	return args.Get(0).([]dto.Product), args.Error(1)
+}
+
+func (m *mockOrderStorage) SetPickupCode(ctx context.Context, orderID openapi_types.UUID, codeHash string, expiresAt time.Time) error {
+	args := m.Called(ctx, orderID, codeHash, expiresAt)
+	return args.Error(0)
+}
+
+func (m *mockOrderStorage) ClaimPickupAttempt(
+	ctx context.Context,
+	orderID openapi_types.UUID,
+	now time.Time,
+	maxAttempts int,
+	lockUntil time.Time,
+) (*dto.PickupCodeState, error) {
+	args := m.Called(ctx, orderID, now, maxAttempts, lockUntil)
+	return args.Get(0).(*dto.PickupCodeState), args.Error(1)
+}
+
+func (m *mockOrderStorage) ResetPickupAttempts(ctx context.Context, orderID openapi_types.UUID) error {
+	args := m.Called(ctx, orderID)
+	return args.Error(0)
+}
+
+func (m *mockOrderStorage) IssueOrder(ctx context.Context, orderID openapi_types.UUID, issuedBy *openapi_types.UUID) (*dto.Order, error) {
+	args := m.Called(ctx, orderID, issuedBy)
+	return args.Get(0).(*dto.Order), args.Error(1)
+}
+
+var testPickupPolicy = PickupPolicy{CodeTTL: time.Hour, MaxAttempts: 3, Lockout: time.Minute}
+
+func storedProducts(ids ...openapi_types.UUID) []dto.Product {
+	status := dto.ProductStored
+	products := make([]dto.Product, 0, len(ids))
+	for _, id := range ids {
+		products = append(products, dto.Product{Id: &id, Type: dto.ProductTypeShoes, Status: &status})
+	}
+	return products
+}
+
+func TestNewOrderService(t *testing.T) {
+	service, err := NewOrderService(nil, testPickupPolicy)
+	require.ErrorIs(t, err, ErrNilInConstruct)
+	require.Nil(t, service)
+
+	service, err = NewOrderService(new(mockOrderStorage), testPickupPolicy)
+	require.NoError(t, err)
+	require.NotNil(t, service)
+}
+
+func TestOrderService_CreateOrder(t *testing.T) {
+	ctx := context.Background()
+	pvzID := openapi_types.UUID{1}
+	first, second := openapi_types.UUID{2}, openapi_types.UUID{3}
+	payload := dto.PostOrdersJSONBody{PvzId: pvzID, Customer: "+79990000000", ProductIds: []openapi_types.UUID{first, second}}
+	order := &dto.Order{Id: openapi_types.UUID{4}, PvzId: pvzID, Status: dto.OrderCreated, ProductIds: payload.ProductIds}
+	received := dto.ProductReceived
+
+	testcases := []struct {
+		name      string
+		payload   dto.PostOrdersJSONBody
+		mockSetup func(*mockOrderStorage)
+		expected  *dto.Order
+		err       error
+	}{
+		{
+			name:    "order created",
+			payload: payload,
+			mockSetup: func(m *mockOrderStorage) {
+				m.On("GetPVZProducts", ctx, pvzID, payload.ProductIds).Return(storedProducts(first, second), nil)
+				m.On("CreateOrder", ctx, payload, (*openapi_types.UUID)(nil)).Return(order, nil)
+			},
+			expected: order,
+		},
+		{
+			name:      "duplicate products",
+			payload:   dto.PostOrdersJSONBody{PvzId: pvzID, Customer: "customer", ProductIds: []openapi_types.UUID{first, first}},
+			mockSetup: func(m *mockOrderStorage) {},
+			err:       ErrInvalidOrder,
+		},
+		{
+			name:      "no customer",
+			payload:   dto.PostOrdersJSONBody{PvzId: pvzID, ProductIds: []openapi_types.UUID{first}},
+			mockSetup: func(m *mockOrderStorage) {},
+			err:       ErrInvalidOrder,
+		},
+		{
+			name:    "product of another pvz",
+			payload: payload,
+			mockSetup: func(m *mockOrderStorage) {
+				m.On("GetPVZProducts", ctx, pvzID, payload.ProductIds).Return(storedProducts(first), nil)
+			},
+			err: ErrOrderProductsNotStored,
+		},
+		{
+			name:    "product is not stored",
+			payload: payload,
+			mockSetup: func(m *mockOrderStorage) {
+				products := storedProducts(first, second)
+				products[1].Status = &received
+				m.On("GetPVZProducts", ctx, pvzID, payload.ProductIds).Return(products, nil)
+			},
+			err: ErrOrderProductsNotStored,
+		},
+		{
+			name:    "storage error",
+			payload: payload,
+			mockSetup: func(m *mockOrderStorage) {
+				m.On("GetPVZProducts", ctx, pvzID, payload.ProductIds).Return(storedProducts(first, second), nil)
+				m.On("CreateOrder", ctx, payload, (*openapi_types.UUID)(nil)).Return((*dto.Order)(nil), errors.New("error"))
+			},
+			err: ErrOrderCreate,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			storage := new(mockOrderStorage)
+			testcase.mockSetup(storage)
+			service, err := NewOrderService(storage, testPickupPolicy)
+			require.NoError(t, err)
+
+			// act
+			order, err := service.CreateOrder(ctx, testcase.payload, nil)
+
+			// assert
+			require.ErrorIs(t, err, testcase.err)
+			require.Equal(t, testcase.expected, order)
+			storage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOrderService_GeneratePickupCode(t *testing.T) {
+	ctx := context.Background()
+	orderID := openapi_types.UUID{1}
+
+	t.Run("code is stored hashed", func(t *testing.T) {
+		storage := new(mockOrderStorage)
+		storage.On("GetOrder", ctx, orderID).Return(&dto.Order{Id: orderID, Status: dto.OrderCreated}, nil)
+		var codeHash string
+		storage.On("SetPickupCode", ctx, orderID, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+			Run(func(args mock.Arguments) { codeHash = args.String(2) }).
+			Return(nil)
+		service, err := NewOrderService(storage, testPickupPolicy)
+		require.NoError(t, err)
+
+		code, err := service.GeneratePickupCode(ctx, orderID)
+
+		require.NoError(t, err)
+		require.Len(t, code.Code, pickupCodeDigits)
+		require.NotEqual(t, code.Code, codeHash)
+		require.True(t, auth.ComparePasswords(codeHash, code.Code))
+		storage.AssertExpectations(t)
+	})
+
+	t.Run("issued order", func(t *testing.T) {
+		storage := new(mockOrderStorage)
+		storage.On("GetOrder", ctx, orderID).Return(&dto.Order{Id: orderID, Status: dto.OrderIssued}, nil)
+		service, err := NewOrderService(storage, testPickupPolicy)
+		require.NoError(t, err)
+
+		_, err = service.GeneratePickupCode(ctx, orderID)
+
+		require.ErrorIs(t, err, ErrOrderIssued)
+	})
+
+	t.Run("order not found", func(t *testing.T) {
+		storage := new(mockOrderStorage)
+		storage.On("GetOrder", ctx, orderID).Return((*dto.Order)(nil), nil)
+		service, err := NewOrderService(storage, testPickupPolicy)
+		require.NoError(t, err)
+
+		_, err = service.GeneratePickupCode(ctx, orderID)
+
+		require.ErrorIs(t, err, ErrOrderNotFound)
+	})
+}
+
+func TestOrderService_Pickup(t *testing.T) {
+	ctx := context.Background()
+	pvzID := openapi_types.UUID{1}
+	orderID := openapi_types.UUID{2}
+	productID := openapi_types.UUID{3}
+	code := "123456"
+	codeHash, err := auth.HashPassword(code)
+	require.NoError(t, err)
+
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+	order := &dto.Order{Id: orderID, PvzId: pvzID, Status: dto.OrderCreated, ProductIds: []openapi_types.UUID{productID}}
+	issued := &dto.Order{Id: orderID, PvzId: pvzID, Status: dto.OrderIssued, ProductIds: order.ProductIds}
+	validState := &dto.PickupCodeState{CodeHash: &codeHash, ExpiresAt: &future}
+	payload := dto.PostPvzPvzIdPickupJSONBody{OrderId: orderID, Code: code}
+	wrongPayload := dto.PostPvzPvzIdPickupJSONBody{OrderId: orderID, Code: "000000"}
+
+	testcases := []struct {
+		name      string
+		pvzID     openapi_types.UUID
+		payload   dto.PostPvzPvzIdPickupJSONBody
+		mockSetup func(*mockOrderStorage)
+		expected  *dto.Order
+		err       error
+	}{
+		{
+			name:    "order issued",
+			pvzID:   pvzID,
+			payload: payload,
+			mockSetup: func(m *mockOrderStorage) {
+				m.On("GetOrder", ctx, orderID).Return(order, nil)
+				m.On("ClaimPickupAttempt", ctx, orderID, mock.AnythingOfType("time.Time"), 3, mock.AnythingOfType("time.Time")).Return(validState, nil)
+				m.On("ResetPickupAttempts", ctx, orderID).Return(nil)
+				m.On("GetPVZProducts", ctx, pvzID, order.ProductIds).Return(storedProducts(productID), nil)
+				m.On("IssueOrder", ctx, orderID, (*openapi_types.UUID)(nil)).Return(issued, nil)
+			},
+			expected: issued,
+		},
+		{
+			name:    "products issued after they were checked",
+			pvzID:   pvzID,
+			payload: payload,
+			mockSetup: func(m *mockOrderStorage) {
+				m.On("GetOrder", ctx, orderID).Return(order, nil)
+				m.On("ClaimPickupAttempt", ctx, orderID, mock.AnythingOfType("time.Time"), 3, mock.AnythingOfType("time.Time")).Return(validState, nil)
+				m.On("ResetPickupAttempts", ctx, orderID).Return(nil)
+				m.On("GetPVZProducts", ctx, pvzID, order.ProductIds).Return(storedProducts(productID), nil)
+				m.On("IssueOrder", ctx, orderID, (*openapi_types.UUID)(nil)).
+					Return((*dto.Order)(nil), fmt.Errorf("failed to issue order: %w", ErrOrderProductsNotStored))
+			},
+			err: ErrOrderProductsNotStored,
+		},
+		{
+			name:    "order of another pvz",
+			pvzID:   openapi_types.UUID{9},
+			payload: payload,
+			mockSetup: func(m *mockOrderStorage) {
+				m.On("GetOrder", ctx, orderID).Return(order, nil)
+			},
+			err: ErrOrderNotFound,
+		},
+		{
+			name:    "already issued",
+			pvzID:   pvzID,
+			payload: payload,
+			mockSetup: func(m *mockOrderStorage) {
+				m.On("GetOrder", ctx, orderID).Return(issued, nil)
+			},
+			err: ErrOrderIssued,
+		},
+		{
+			name:    "wrong code",
+			pvzID:   pvzID,
+			payload: wrongPayload,
+			mockSetup: func(m *mockOrderStorage) {
+				m.On("GetOrder", ctx, orderID).Return(order, nil)
+				m.On("ClaimPickupAttempt", ctx, orderID, mock.AnythingOfType("time.Time"), 3, mock.AnythingOfType("time.Time")).Return(validState, nil)
+			},
+			err: ErrInvalidPickupCode,
+		},
+		{
+			name:    "wrong code locks verification",
+			pvzID:   pvzID,
+			payload: wrongPayload,
+			mockSetup: func(m *mockOrderStorage) {
+				m.On("GetOrder", ctx, orderID).Return(order, nil)
+				m.On("ClaimPickupAttempt", ctx, orderID, mock.AnythingOfType("time.Time"), 3, mock.AnythingOfType("time.Time")).
+					Return(&dto.PickupCodeState{CodeHash: &codeHash, ExpiresAt: &future, FailedAttempts: 3, LockedUntil: &future}, nil)
+			},
+			err: ErrPickupLocked,
+		},
+		{
+			name:    "locked order rejects even valid code",
+			pvzID:   pvzID,
+			payload: payload,
+			mockSetup: func(m *mockOrderStorage) {
+				m.On("GetOrder", ctx, orderID).Return(order, nil)
+				m.On("ClaimPickupAttempt", ctx, orderID, mock.AnythingOfType("time.Time"), 3, mock.AnythingOfType("time.Time")).Return((*dto.PickupCodeState)(nil), nil)
+			},
+			err: ErrPickupLocked,
+		},
+		{
+			name:    "expired code",
+			pvzID:   pvzID,
+			payload: payload,
+			mockSetup: func(m *mockOrderStorage) {
+				m.On("GetOrder", ctx, orderID).Return(order, nil)
+				m.On("ClaimPickupAttempt", ctx, orderID, mock.AnythingOfType("time.Time"), 3, mock.AnythingOfType("time.Time")).Return(&dto.PickupCodeState{CodeHash: &codeHash, ExpiresAt: &past}, nil)
+			},
+			err: ErrInvalidPickupCode,
+		},
+		{
+			name:    "code was not generated",
+			pvzID:   pvzID,
+			payload: payload,
+			mockSetup: func(m *mockOrderStorage) {
+				m.On("GetOrder", ctx, orderID).Return(order, nil)
+				m.On("ClaimPickupAttempt", ctx, orderID, mock.AnythingOfType("time.Time"), 3, mock.AnythingOfType("time.Time")).Return(&dto.PickupCodeState{}, nil)
+			},
+			err: ErrInvalidPickupCode,
+		},
+		{
+			name:    "storage error on claiming attempt",
+			pvzID:   pvzID,
+			payload: payload,
+			mockSetup: func(m *mockOrderStorage) {
+				m.On("GetOrder", ctx, orderID).Return(order, nil)
+				m.On("ClaimPickupAttempt", ctx, orderID, mock.AnythingOfType("time.Time"), 3, mock.AnythingOfType("time.Time")).Return((*dto.PickupCodeState)(nil), errors.New("error"))
+			},
+			err: ErrOrderIssue,
+		},
+		{
+			name:    "product is not stored anymore",
+			pvzID:   pvzID,
+			payload: payload,
+			mockSetup: func(m *mockOrderStorage) {
+				m.On("GetOrder", ctx, orderID).Return(order, nil)
+				m.On("ClaimPickupAttempt", ctx, orderID, mock.AnythingOfType("time.Time"), 3, mock.AnythingOfType("time.Time")).Return(validState, nil)
+				m.On("ResetPickupAttempts", ctx, orderID).Return(nil)
+				m.On("GetPVZProducts", ctx, pvzID, order.ProductIds).Return([]dto.Product{}, nil)
+			},
+			err: ErrOrderProductsNotStored,
+		},
+		{
+			name:    "product is in return list",
+			pvzID:   pvzID,
+			payload: payload,
+			mockSetup: func(m *mockOrderStorage) {
+				products := storedProducts(order.ProductIds...)
+				products[0].ExpiredAt = &past
+				m.On("GetOrder", ctx, orderID).Return(order, nil)
+				m.On("ClaimPickupAttempt", ctx, orderID, mock.AnythingOfType("time.Time"), 3, mock.AnythingOfType("time.Time")).Return(validState, nil)
+				m.On("ResetPickupAttempts", ctx, orderID).Return(nil)
+				m.On("GetPVZProducts", ctx, pvzID, order.ProductIds).Return(products, nil)
+			},
+			err: ErrOrderProductsNotStored,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			storage := new(mockOrderStorage)
+			testcase.mockSetup(storage)
+			service, err := NewOrderService(storage, testPickupPolicy)
+			require.NoError(t, err)
+
+			// act
+			order, err := service.Pickup(ctx, testcase.pvzID, testcase.payload, nil)
+
+			// assert
+			require.ErrorIs(t, err, testcase.err)
+			require.Equal(t, testcase.expected, order)
+			storage.AssertExpectations(t)
+		})
+	}
+}
+
+// lockoutOrderStorage counts pickup attempts like ClaimPickupAttempt of database does
+type lockoutOrderStorage struct {
+	mockOrderStorage
+	state dto.PickupCodeState
+}
+
+func (s *lockoutOrderStorage) ClaimPickupAttempt(
+	_ context.Context,
+	_ openapi_types.UUID,
+	now time.Time,
+	maxAttempts int,
+	lockUntil time.Time,
+) (*dto.PickupCodeState, error) {
+	if s.state.LockedUntil != nil && now.Before(*s.state.LockedUntil) {
+		return nil, nil
+	}
+	if s.state.LockedUntil != nil {
+		s.state.FailedAttempts = 0
+	}
+	s.state.FailedAttempts++
+	s.state.LockedUntil = nil
+	if s.state.FailedAttempts >= maxAttempts {
+		s.state.LockedUntil = &lockUntil
+	}
+	state := s.state
+
+	return &state, nil
+}
+
+func TestOrderService_PickupAfterLockout(t *testing.T) {
+	ctx := context.Background()
+	pvzID := openapi_types.UUID{1}
+	orderID := openapi_types.UUID{2}
+	productID := openapi_types.UUID{3}
+	code := "123456"
+	codeHash, err := auth.HashPassword(code)
+	require.NoError(t, err)
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Millisecond)
+	order := &dto.Order{Id: orderID, PvzId: pvzID, Status: dto.OrderCreated, ProductIds: []openapi_types.UUID{productID}}
+	issued := &dto.Order{Id: orderID, PvzId: pvzID, Status: dto.OrderIssued, ProductIds: order.ProductIds}
+
+	// arrange
+	storage := &lockoutOrderStorage{state: dto.PickupCodeState{
+		CodeHash:       &codeHash,
+		ExpiresAt:      &future,
+		FailedAttempts: 3,
+		LockedUntil:    &past,
+	}}
+	storage.On("GetOrder", ctx, orderID).Return(order, nil)
+	storage.On("ResetPickupAttempts", ctx, orderID).Return(nil)
+	storage.On("GetPVZProducts", ctx, pvzID, order.ProductIds).Return(storedProducts(productID), nil)
+	storage.On("IssueOrder", ctx, orderID, (*openapi_types.UUID)(nil)).Return(issued, nil)
+	service, err := NewOrderService(storage, testPickupPolicy)
+	require.NoError(t, err)
+
+	// act
+	result, err := service.Pickup(ctx, pvzID, dto.PostPvzPvzIdPickupJSONBody{OrderId: orderID, Code: code}, nil)
+
+	// assert
+	// lockout has passed, counting starts again and valid code issues order
+	require.NoError(t, err)
+	require.Equal(t, issued, result)
+	require.Equal(t, 1, storage.state.FailedAttempts)
+	storage.AssertExpectations(t)
+}
@@ -3,6 +3,9 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"slices"
 
 	"github.com/Arzeeq/pvz-api/internal/dto"
 	openapi_types "github.com/oapi-codegen/runtime/types"
@@ -10,42 +13,409 @@ import (
 
 var ErrProductCreate = errors.New("failed to create product")
 var ErrDeleteProduct = errors.New("failed to delete product")
+var ErrDuplicateBarcode = errors.New("product with this barcode is already in open reception")
+var ErrInvalidBarcode = errors.New("barcode must not be empty")
+var ErrProductGet = errors.New("failed to get products")
+var ErrBatchSize = errors.New("invalid number of products in batch")
+var ErrNoActiveReception = errors.New("no open reception in pvz")
+var ErrInvalidProductType = errors.New("invalid product type")
+var ErrInvalidProductFilter = errors.New("barcode or pvz must be specified")
+var ErrInvalidTransition = errors.New("product status transition is not allowed")
+var ErrProductUpdate = errors.New("failed to update product")
+var ErrProductExpired = errors.New("product is in return list of pvz and can not be issued")
+
+// productTransitions lists statuses product can be moved to from each status,
+// lifecycle is linear: received -> stored -> issued -> returned_to_sender
+var productTransitions = map[dto.ProductStatus][]dto.ProductStatus{
+	dto.ProductReceived: {dto.ProductStored},
+	dto.ProductStored:   {dto.ProductIssued},
+	dto.ProductIssued:   {dto.ProductReturnedToSender},
+}
+
+// expiredProductTransitions are allowed besides productTransitions for products with expired
+// storage period: they are not issued and go back to sender from return list of pvz
+var expiredProductTransitions = map[dto.ProductStatus][]dto.ProductStatus{
+	dto.ProductReceived: {dto.ProductReturnedToSender},
+	dto.ProductStored:   {dto.ProductReturnedToSender},
+}
+
+// transitionAllowed tells whether product can be moved from current status to status
+func transitionAllowed(current dto.ProductStatus, status dto.ProductStatus, expired bool) bool {
+	if slices.Contains(productTransitions[current], status) {
+		return true
+	}
+	return expired && slices.Contains(expiredProductTransitions[current], status)
+}
+
+// previousStatuses returns statuses product can be moved to status from by transitions
+func previousStatuses(transitions map[dto.ProductStatus][]dto.ProductStatus, status dto.ProductStatus) []dto.ProductStatus {
+	from := make([]dto.ProductStatus, 0, 1)
+	for _, current := range slices.Sorted(maps.Keys(transitions)) {
+		if slices.Contains(transitions[current], status) {
+			from = append(from, current)
+		}
+	}
+
+	return from
+}
 
 type ProductStorager interface {
 	CreateProduct(ctx context.Context, productDto dto.PostProductsJSONBody) (*dto.Product, error)
-	DeleteProduct(ctx context.Context, productID openapi_types.UUID) error
+	// CreateProducts returns ErrNoActiveReception when pvz has no open reception
+	CreateProducts(ctx context.Context, pvzID openapi_types.UUID, items []dto.ProductBatchItem) ([]dto.Product, error)
+	DeleteProduct(ctx context.Context, productID openapi_types.UUID, deletedBy *openapi_types.UUID) error
+	DeleteReceptionProduct(
+		ctx context.Context,
+		receptionID openapi_types.UUID,
+		productID openapi_types.UUID,
+		deletedBy *openapi_types.UUID,
+	) (bool, error)
 	GetLastProduct(ctx context.Context, pvzId openapi_types.UUID) (*dto.Product, error)
 	GetReceptionProducts(ctx context.Context, receptionId openapi_types.UUID) []dto.Product
+	GetOpenReceptionProductByBarcode(ctx context.Context, pvzID openapi_types.UUID, barcode string) (*dto.Product, error)
+	GetProducts(ctx context.Context, params dto.GetProductsParams) ([]dto.Product, error)
+	GetProduct(ctx context.Context, productID openapi_types.UUID) (*dto.Product, error)
+	UpdateProductStatus(ctx context.Context, productID openapi_types.UUID, from dto.ProductStatus, to dto.ProductStatus) (*dto.Product, error)
+}
+
+// CellLister lists storage cells of pvz with their occupancy
+type CellLister interface {
+	GetCells(ctx context.Context, pvzID openapi_types.UUID) ([]dto.Cell, error)
 }
 
 type ProductService struct {
 	storage ProductStorager
+	policy  ProductPolicy
+	metrics *BusinessMetrics
+	cells   CellLister
+}
+
+// ProductPolicy returns current handling of duplicate barcodes and batch limit,
+// it is called once per call of service, so settings can be changed while service is running
+type ProductPolicy func() (returnExisting bool, batchLimit int)
+
+// productPolicy holds settings of ProductService used by one call
+type productPolicy struct {
+	// returnExisting makes repeated scan of barcode in open reception
+	// return already added product instead of ErrDuplicateBarcode
+	returnExisting bool
+	// batchLimit is max number of products in one CreateProducts call
+	batchLimit int
 }
 
-func NewProductService(productStorage ProductStorager) (*ProductService, error) {
-	if productStorage == nil {
+// NewProductService creates service, metrics may be nil.
+// Cells may be nil when storage has no cells, created products get no suggested cell then
+func NewProductService(
+	productStorage ProductStorager,
+	cells CellLister,
+	policy ProductPolicy,
+	metrics *BusinessMetrics,
+) (*ProductService, error) {
+	if productStorage == nil || policy == nil {
 		return nil, ErrNilInConstruct
 	}
 
-	return &ProductService{storage: productStorage}, nil
+	return &ProductService{
+		storage: productStorage,
+		policy:  policy,
+		metrics: metrics,
+		cells:   cells,
+	}, nil
+}
+
+func (s *ProductService) currentPolicy() *productPolicy {
+	returnExisting, batchLimit := s.policy()
+	return &productPolicy{returnExisting: returnExisting, batchLimit: batchLimit}
 }
 
-func (s *ProductService) CreateProduct(ctx context.Context, productDto dto.PostProductsJSONBody) (*dto.Product, error) {
+// CreateProduct adds product to open reception of pvz, second result is false
+// when product with the same barcode was already added and is returned instead
+func (s *ProductService) CreateProduct(ctx context.Context, productDto dto.PostProductsJSONBody) (*dto.Product, bool, error) {
+	ctx, span := startSpan(ctx, "ProductService.CreateProduct")
+	defer span.End()
+
+	if productDto.Barcode != nil {
+		if *productDto.Barcode == "" {
+			return nil, false, ErrInvalidBarcode
+		}
+
+		existing, err := s.storage.GetOpenReceptionProductByBarcode(ctx, productDto.PvzId, *productDto.Barcode)
+		if err != nil {
+			return nil, false, ErrProductCreate
+		}
+		if existing != nil {
+			return s.duplicate(existing)
+		}
+	}
+
 	product, err := s.storage.CreateProduct(ctx, productDto)
+	if err != nil || (product == nil && productDto.Barcode == nil) {
+		return nil, false, ErrProductCreate
+	}
+	// barcode was added by concurrent request after it was checked
+	if product == nil {
+		existing, err := s.storage.GetOpenReceptionProductByBarcode(ctx, productDto.PvzId, *productDto.Barcode)
+		if err != nil || existing == nil {
+			return nil, false, ErrProductCreate
+		}
+		return s.duplicate(existing)
+	}
+	s.metrics.productsAdded(ctx, productDto.PvzId, map[dto.ProductType]int{product.Type: 1})
+	s.suggestCells(ctx, productDto.PvzId, []*dto.Product{product})
+
+	return product, true, nil
+}
+
+// duplicate handles product which is already in open reception by policy
+func (s *ProductService) duplicate(existing *dto.Product) (*dto.Product, bool, error) {
+	if s.currentPolicy().returnExisting {
+		return existing, false, nil
+	}
+	return nil, false, ErrDuplicateBarcode
+}
+
+// CreateProducts adds batch of products to open reception of pvz in one transaction.
+// Invalid items and duplicate barcodes are reported per item and do not stop the batch,
+// storage failure fails the whole batch
+func (s *ProductService) CreateProducts(ctx context.Context, pvzID openapi_types.UUID, items []dto.ProductBatchItem) (*dto.ProductBatchResult, error) {
+	ctx, span := startSpan(ctx, "ProductService.CreateProducts")
+	defer span.End()
+
+	policy := s.currentPolicy()
+	if len(items) == 0 || len(items) > policy.batchLimit {
+		return nil, ErrBatchSize
+	}
+
+	results, err := s.createBatch(ctx, pvzID, items, policy)
+	// barcode was added by concurrent request after it was checked, the second check finds it
+	if errors.Is(err, errBarcodeAdded) {
+		results, err = s.createBatch(ctx, pvzID, items, policy)
+	}
+	if errors.Is(err, ErrNoActiveReception) {
+		return nil, ErrNoActiveReception
+	}
 	if err != nil {
 		return nil, ErrProductCreate
 	}
 
-	return product, nil
+	return &dto.ProductBatchResult{Results: results}, nil
+}
+
+// BatchLimit returns current maximum number of products in batch
+func (s *ProductService) BatchLimit() int {
+	return s.currentPolicy().batchLimit
 }
 
-func (s *ProductService) DeleteLastProduct(ctx context.Context, pvzID openapi_types.UUID) error {
+// errBarcodeAdded is returned by createBatch when storage rejected batch because
+// barcode of some item was added to reception after it was checked
+var errBarcodeAdded = errors.New("barcode was added to reception concurrently")
+
+func (s *ProductService) createBatch(
+	ctx context.Context,
+	pvzID openapi_types.UUID,
+	items []dto.ProductBatchItem,
+	policy *productPolicy,
+) ([]dto.ProductBatchItemResult, error) {
+	results := make([]dto.ProductBatchItemResult, len(items))
+	toCreate := make([]dto.ProductBatchItem, 0, len(items))
+	createdIndexes := make([]int, 0, len(items))
+	// firstIndex maps barcode to index of its first occurrence in batch,
+	// repeatedOf maps index of repeated scan to index of the first one
+	firstIndex := make(map[string]int)
+	repeatedOf := make(map[int]int)
+	for i, item := range items {
+		results[i] = dto.ProductBatchItemResult{Index: i}
+
+		if !slices.Contains(productTypes, item.Type) {
+			results[i].Status = dto.BatchFailed
+			results[i].Message = errorMessage(ErrInvalidProductType)
+			continue
+		}
+
+		if item.Barcode != nil {
+			if *item.Barcode == "" {
+				results[i].Status = dto.BatchFailed
+				results[i].Message = errorMessage(ErrInvalidBarcode)
+				continue
+			}
+
+			if first, ok := firstIndex[*item.Barcode]; ok {
+				// result of repeated scan is resolved after first one is created
+				repeatedOf[i] = first
+				continue
+			}
+
+			existing, err := s.storage.GetOpenReceptionProductByBarcode(ctx, pvzID, *item.Barcode)
+			if err != nil {
+				return nil, err
+			}
+			if existing != nil {
+				policy.setExisting(&results[i], existing)
+				continue
+			}
+			firstIndex[*item.Barcode] = i
+		}
+
+		toCreate = append(toCreate, item)
+		createdIndexes = append(createdIndexes, i)
+	}
+
+	if len(toCreate) != 0 {
+		products, err := s.storage.CreateProducts(ctx, pvzID, toCreate)
+		if err != nil {
+			return nil, err
+		}
+		if products == nil {
+			return nil, errBarcodeAdded
+		}
+		s.metrics.productsAdded(ctx, pvzID, countProducts(products))
+
+		created := make([]*dto.Product, 0, len(products))
+		for j, i := range createdIndexes {
+			results[i].Status = dto.BatchCreated
+			results[i].Product = &products[j]
+			created = append(created, &products[j])
+		}
+		s.suggestCells(ctx, pvzID, created)
+	}
+
+	for i, first := range repeatedOf {
+		policy.setExisting(&results[i], results[first].Product)
+	}
+
+	return results, nil
+}
+
+// suggestCells sets suggested cell of created products. Products are spread over cells of pvz
+// as if they were placed one by one into cell with the most free capacity, cells with equal
+// free capacity are taken in order of code. Suggestion is only a hint, so failure to get cells
+// leaves products without it
+func (s *ProductService) suggestCells(ctx context.Context, pvzID openapi_types.UUID, products []*dto.Product) {
+	if s.cells == nil || len(products) == 0 {
+		return
+	}
+
+	cells, err := s.cells.GetCells(ctx, pvzID)
+	if err != nil {
+		return
+	}
+	free := make([]int, len(cells))
+	for i, cell := range cells {
+		free[i] = cell.Capacity
+		if cell.Occupied != nil {
+			free[i] -= *cell.Occupied
+		}
+	}
+
+	for _, product := range products {
+		best := -1
+		for i := range cells {
+			if free[i] > 0 && (best == -1 || free[i] > free[best]) {
+				best = i
+			}
+		}
+		if best == -1 {
+			return
+		}
+		free[best]--
+		cell := cells[best]
+		product.SuggestedCell = &cell
+	}
+}
+
+func (p *productPolicy) setExisting(result *dto.ProductBatchItemResult, existing *dto.Product) {
+	if p.returnExisting {
+		result.Status = dto.BatchExisting
+		result.Product = existing
+		return
+	}
+	result.Status = dto.BatchFailed
+	result.Message = errorMessage(ErrDuplicateBarcode)
+}
+
+func errorMessage(err error) *string {
+	message := err.Error()
+	return &message
+}
+
+func (s *ProductService) GetProducts(ctx context.Context, params dto.GetProductsParams) ([]dto.Product, error) {
+	ctx, span := startSpan(ctx, "ProductService.GetProducts")
+	defer span.End()
+
+	if params.Barcode == nil && params.PvzId == nil {
+		return nil, ErrInvalidProductFilter
+	}
+	if params.Barcode != nil && *params.Barcode == "" {
+		return nil, ErrInvalidBarcode
+	}
+	dto.CorrectProductsParams(&params)
+
+	products, err := s.storage.GetProducts(ctx, params)
+	if err != nil {
+		return nil, ErrProductGet
+	}
+
+	return products, nil
+}
+
+// ChangeProductStatus moves product to status if transition from its current status is allowed
+func (s *ProductService) ChangeProductStatus(ctx context.Context, productID openapi_types.UUID, status dto.ProductStatus) (*dto.Product, error) {
+	ctx, span := startSpan(ctx, "ProductService.ChangeProductStatus")
+	defer span.End()
+
+	product, err := s.storage.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, ErrProductUpdate
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	current := dto.ProductReceived
+	if product.Status != nil {
+		current = *product.Status
+	}
+	if !transitionAllowed(current, status, product.ExpiredAt != nil) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, current, status)
+	}
+	if status == dto.ProductIssued && product.ExpiredAt != nil {
+		return nil, ErrProductExpired
+	}
+
+	updated, err := s.storage.UpdateProductStatus(ctx, productID, current, status)
+	if err != nil {
+		return nil, ErrProductUpdate
+	}
+	// status was changed or product expired by concurrent request
+	if updated == nil {
+		return nil, s.statusChangeConflict(ctx, productID, status)
+	}
+
+	return updated, nil
+}
+
+// statusChangeConflict tells why storage did not change status of product
+func (s *ProductService) statusChangeConflict(ctx context.Context, productID openapi_types.UUID, status dto.ProductStatus) error {
+	if status != dto.ProductIssued {
+		return ErrInvalidTransition
+	}
+	product, err := s.storage.GetProduct(ctx, productID)
+	if err == nil && product != nil && product.ExpiredAt != nil {
+		return ErrProductExpired
+	}
+	return ErrInvalidTransition
+}
+
+func (s *ProductService) DeleteLastProduct(ctx context.Context, pvzID openapi_types.UUID, userID *openapi_types.UUID) error {
+	ctx, span := startSpan(ctx, "ProductService.DeleteLastProduct")
+	defer span.End()
+
 	product, err := s.storage.GetLastProduct(ctx, pvzID)
 	if err != nil {
 		return ErrDeleteProduct
 	}
 
-	if err := s.storage.DeleteProduct(ctx, *product.Id); err != nil {
+	if err := s.storage.DeleteProduct(ctx, *product.Id, userID); err != nil {
 		return ErrDeleteProduct
 	}
 
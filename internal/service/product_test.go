@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -21,42 +22,96 @@ func (m *mockProductStorage) CreateProduct(ctx context.Context, productDto dto.P
 	return args.Get(0).(*dto.Product), args.Error(1)
 }
 
-func (m *mockProductStorage) DeleteProduct(ctx context.Context, productID openapi_types.UUID) error {
-	args := m.Called(ctx, productID)
+func (m *mockProductStorage) CreateProducts(ctx context.Context, pvzID openapi_types.UUID, items []dto.ProductBatchItem) ([]dto.Product, error) {
+	args := m.Called(ctx, pvzID, items)
+	return args.Get(0).([]dto.Product), args.Error(1)
+}
+
+func (m *mockProductStorage) DeleteProduct(ctx context.Context, productID openapi_types.UUID, deletedBy *openapi_types.UUID) error {
+	args := m.Called(ctx, productID, deletedBy)
 	return args.Error(0)
 }
 
+func (m *mockProductStorage) DeleteReceptionProduct(
+	ctx context.Context,
+	receptionID openapi_types.UUID,
+	productID openapi_types.UUID,
+	deletedBy *openapi_types.UUID,
+) (bool, error) {
+	args := m.Called(ctx, receptionID, productID, deletedBy)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *mockProductStorage) GetLastProduct(ctx context.Context, pvzId openapi_types.UUID) (*dto.Product, error) {
 	args := m.Called(ctx, pvzId)
 	return args.Get(0).(*dto.Product), args.Error(1)
 }
 
+func (m *mockProductStorage) GetOpenReceptionProductByBarcode(ctx context.Context, pvzID openapi_types.UUID, barcode string) (*dto.Product, error) {
+	args := m.Called(ctx, pvzID, barcode)
+	return args.Get(0).(*dto.Product), args.Error(1)
+}
+
+func (m *mockProductStorage) GetProducts(ctx context.Context, params dto.GetProductsParams) ([]dto.Product, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]dto.Product), args.Error(1)
+}
+
+func (m *mockProductStorage) GetProduct(ctx context.Context, productID openapi_types.UUID) (*dto.Product, error) {
+	args := m.Called(ctx, productID)
+	return args.Get(0).(*dto.Product), args.Error(1)
+}
+
+func (m *mockProductStorage) UpdateProductStatus(
+	ctx context.Context,
+	productID openapi_types.UUID,
+	from dto.ProductStatus,
+	to dto.ProductStatus,
+) (*dto.Product, error) {
+	args := m.Called(ctx, productID, from, to)
+	return args.Get(0).(*dto.Product), args.Error(1)
+}
+
 func (m *mockProductStorage) GetReceptionProducts(ctx context.Context, receptionId openapi_types.UUID) []dto.Product {
 	args := m.Called(ctx, receptionId)
 	return args.Get(0).([]dto.Product)
 }
 
+// staticPolicy returns policy which is never changed
+func staticPolicy(returnExisting bool, batchLimit int) ProductPolicy {
+	return func() (bool, int) { return returnExisting, batchLimit }
+}
+
 func TestNewProductService(t *testing.T) {
 	testcases := []struct {
 		name    string
 		storage ProductStorager
+		policy  ProductPolicy
 		err     error
 	}{
 		{
 			name:    "successful initialization",
 			storage: new(mockProductStorage),
+			policy:  staticPolicy(false, 10),
 			err:     nil,
 		},
 		{
 			name:    "nil storage",
 			storage: nil,
+			policy:  staticPolicy(false, 10),
+			err:     ErrNilInConstruct,
+		},
+		{
+			name:    "nil policy",
+			storage: new(mockProductStorage),
+			policy:  nil,
 			err:     ErrNilInConstruct,
 		},
 	}
 
 	for _, testcase := range testcases {
 		t.Run(testcase.name, func(t *testing.T) {
-			service, err := NewProductService(testcase.storage)
+			service, err := NewProductService(testcase.storage, nil, testcase.policy, nil)
 			require.ErrorIs(t, err, testcase.err)
 			if testcase.err != nil {
 				require.Nil(t, service)
@@ -79,13 +134,28 @@ func TestProductService_CreateProduct(t *testing.T) {
 		PvzId: pvzID,
 		Type:  dto.PostProductsJSONBodyTypeClothes,
 	}
+	barcode := "4600000000011"
+	scannedDto := dto.PostProductsJSONBody{
+		PvzId:   pvzID,
+		Type:    dto.PostProductsJSONBodyTypeClothes,
+		Barcode: &barcode,
+	}
+	emptyBarcode := ""
+	existing := &dto.Product{
+		Id:          &testUUID,
+		ReceptionId: receptionUUID,
+		Type:        productType,
+		Barcode:     &barcode,
+	}
 
 	testcases := []struct {
-		name      string
-		input     dto.PostProductsJSONBody
-		mockSetup func(*mockProductStorage)
-		expected  *dto.Product
-		err       error
+		name           string
+		input          dto.PostProductsJSONBody
+		returnExisting bool
+		mockSetup      func(*mockProductStorage)
+		expected       *dto.Product
+		created        bool
+		err            error
 	}{
 		{
 			name:  "successful product creation",
@@ -105,7 +175,74 @@ func TestProductService_CreateProduct(t *testing.T) {
 				ReceptionId: receptionUUID,
 				Type:        productType,
 			},
-			err: nil,
+			created: true,
+			err:     nil,
+		},
+		{
+			name:  "new barcode is added",
+			input: scannedDto,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetOpenReceptionProductByBarcode", ctx, pvzID, barcode).Return((*dto.Product)(nil), nil)
+				m.On("CreateProduct", ctx, scannedDto).Return(existing, nil)
+			},
+			expected: existing,
+			created:  true,
+			err:      nil,
+		},
+		{
+			name:  "duplicate barcode is rejected",
+			input: scannedDto,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetOpenReceptionProductByBarcode", ctx, pvzID, barcode).Return(existing, nil)
+			},
+			expected: nil,
+			err:      ErrDuplicateBarcode,
+		},
+		{
+			name:           "duplicate barcode returns existing product",
+			input:          scannedDto,
+			returnExisting: true,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetOpenReceptionProductByBarcode", ctx, pvzID, barcode).Return(existing, nil)
+			},
+			expected: existing,
+			created:  false,
+			err:      nil,
+		},
+		{
+			name:  "barcode added concurrently is rejected",
+			input: scannedDto,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetOpenReceptionProductByBarcode", ctx, pvzID, barcode).Return((*dto.Product)(nil), nil).Once()
+				m.On("CreateProduct", ctx, scannedDto).Return((*dto.Product)(nil), nil)
+				m.On("GetOpenReceptionProductByBarcode", ctx, pvzID, barcode).Return(existing, nil).Once()
+			},
+			expected: nil,
+			err:      ErrDuplicateBarcode,
+		},
+		{
+			name:           "barcode added concurrently returns existing product",
+			input:          scannedDto,
+			returnExisting: true,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetOpenReceptionProductByBarcode", ctx, pvzID, barcode).Return((*dto.Product)(nil), nil).Once()
+				m.On("CreateProduct", ctx, scannedDto).Return((*dto.Product)(nil), nil)
+				m.On("GetOpenReceptionProductByBarcode", ctx, pvzID, barcode).Return(existing, nil).Once()
+			},
+			expected: existing,
+			created:  false,
+			err:      nil,
+		},
+		{
+			name: "empty barcode",
+			input: dto.PostProductsJSONBody{
+				PvzId:   pvzID,
+				Type:    dto.PostProductsJSONBodyTypeClothes,
+				Barcode: &emptyBarcode,
+			},
+			mockSetup: func(m *mockProductStorage) {},
+			expected:  nil,
+			err:       ErrInvalidBarcode,
 		},
 		{
 			name:  "storage error on create",
@@ -125,20 +262,518 @@ func TestProductService_CreateProduct(t *testing.T) {
 			storage := new(mockProductStorage)
 			testcase.mockSetup(storage)
 
-			service, err := NewProductService(storage)
+			service, err := NewProductService(storage, nil, staticPolicy(testcase.returnExisting, 10), nil)
 			require.NoError(t, err)
 
 			// act
-			product, err := service.CreateProduct(ctx, testcase.input)
+			product, created, err := service.CreateProduct(ctx, testcase.input)
 
 			// assert
 			require.Equal(t, testcase.expected, product)
+			require.Equal(t, testcase.created, created)
 			require.ErrorIs(t, testcase.err, err)
 			storage.AssertExpectations(t)
 		})
 	}
 }
 
+func TestProductService_CreateProducts(t *testing.T) {
+	ctx := context.Background()
+	pvzID := openapi_types.UUID{1}
+	receptionID := openapi_types.UUID{2}
+	newBarcode := "4600000000011"
+	oldBarcode := "4600000000028"
+	emptyBarcode := ""
+
+	plain := dto.ProductBatchItem{Type: dto.ProductTypeShoes}
+	scanned := dto.ProductBatchItem{Type: dto.ProductTypeClothes, Barcode: &newBarcode}
+	rescanned := dto.ProductBatchItem{Type: dto.ProductTypeClothes, Barcode: &oldBarcode}
+	invalidType := dto.ProductBatchItem{Type: "мебель"}
+	invalidBarcode := dto.ProductBatchItem{Type: dto.ProductTypeShoes, Barcode: &emptyBarcode}
+
+	plainProduct := dto.Product{Id: &openapi_types.UUID{3}, ReceptionId: receptionID, Type: plain.Type}
+	scannedProduct := dto.Product{Id: &openapi_types.UUID{4}, ReceptionId: receptionID, Type: scanned.Type, Barcode: &newBarcode}
+	existing := &dto.Product{Id: &openapi_types.UUID{5}, ReceptionId: receptionID, Type: rescanned.Type, Barcode: &oldBarcode}
+
+	testcases := []struct {
+		name           string
+		items          []dto.ProductBatchItem
+		returnExisting bool
+		mockSetup      func(*mockProductStorage)
+		expected       []dto.ProductBatchItemResultStatus
+		err            error
+	}{
+		{
+			name:  "all products created",
+			items: []dto.ProductBatchItem{plain, scanned},
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetOpenReceptionProductByBarcode", ctx, pvzID, newBarcode).Return((*dto.Product)(nil), nil)
+				m.On("CreateProducts", ctx, pvzID, []dto.ProductBatchItem{plain, scanned}).
+					Return([]dto.Product{plainProduct, scannedProduct}, nil)
+			},
+			expected: []dto.ProductBatchItemResultStatus{dto.BatchCreated, dto.BatchCreated},
+		},
+		{
+			name:  "barcode added concurrently is found by second check",
+			items: []dto.ProductBatchItem{plain, scanned},
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetOpenReceptionProductByBarcode", ctx, pvzID, newBarcode).Return((*dto.Product)(nil), nil).Once()
+				m.On("CreateProducts", ctx, pvzID, []dto.ProductBatchItem{plain, scanned}).
+					Return([]dto.Product(nil), nil)
+				m.On("GetOpenReceptionProductByBarcode", ctx, pvzID, newBarcode).Return(&scannedProduct, nil).Once()
+				m.On("CreateProducts", ctx, pvzID, []dto.ProductBatchItem{plain}).
+					Return([]dto.Product{plainProduct}, nil)
+			},
+			expected: []dto.ProductBatchItemResultStatus{dto.BatchCreated, dto.BatchFailed},
+		},
+		{
+			name:  "barcode added concurrently twice fails batch",
+			items: []dto.ProductBatchItem{scanned},
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetOpenReceptionProductByBarcode", ctx, pvzID, newBarcode).Return((*dto.Product)(nil), nil).Twice()
+				m.On("CreateProducts", ctx, pvzID, []dto.ProductBatchItem{scanned}).
+					Return([]dto.Product(nil), nil).Twice()
+			},
+			err: ErrProductCreate,
+		},
+		{
+			name:  "invalid items do not stop batch",
+			items: []dto.ProductBatchItem{invalidType, plain, invalidBarcode},
+			mockSetup: func(m *mockProductStorage) {
+				m.On("CreateProducts", ctx, pvzID, []dto.ProductBatchItem{plain}).
+					Return([]dto.Product{plainProduct}, nil)
+			},
+			expected: []dto.ProductBatchItemResultStatus{dto.BatchFailed, dto.BatchCreated, dto.BatchFailed},
+		},
+		{
+			name:  "duplicate barcodes are rejected",
+			items: []dto.ProductBatchItem{scanned, scanned, rescanned},
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetOpenReceptionProductByBarcode", ctx, pvzID, newBarcode).Return((*dto.Product)(nil), nil)
+				m.On("GetOpenReceptionProductByBarcode", ctx, pvzID, oldBarcode).Return(existing, nil)
+				m.On("CreateProducts", ctx, pvzID, []dto.ProductBatchItem{scanned}).
+					Return([]dto.Product{scannedProduct}, nil)
+			},
+			expected: []dto.ProductBatchItemResultStatus{dto.BatchCreated, dto.BatchFailed, dto.BatchFailed},
+		},
+		{
+			name:           "duplicate barcodes return existing products",
+			items:          []dto.ProductBatchItem{scanned, scanned, rescanned},
+			returnExisting: true,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetOpenReceptionProductByBarcode", ctx, pvzID, newBarcode).Return((*dto.Product)(nil), nil)
+				m.On("GetOpenReceptionProductByBarcode", ctx, pvzID, oldBarcode).Return(existing, nil)
+				m.On("CreateProducts", ctx, pvzID, []dto.ProductBatchItem{scanned}).
+					Return([]dto.Product{scannedProduct}, nil)
+			},
+			expected: []dto.ProductBatchItemResultStatus{dto.BatchCreated, dto.BatchExisting, dto.BatchExisting},
+		},
+		{
+			name:      "empty batch",
+			items:     nil,
+			mockSetup: func(m *mockProductStorage) {},
+			err:       ErrBatchSize,
+		},
+		{
+			name:      "batch over limit",
+			items:     make([]dto.ProductBatchItem, 11),
+			mockSetup: func(m *mockProductStorage) {},
+			err:       ErrBatchSize,
+		},
+		{
+			name:  "no open reception",
+			items: []dto.ProductBatchItem{plain},
+			mockSetup: func(m *mockProductStorage) {
+				m.On("CreateProducts", ctx, pvzID, []dto.ProductBatchItem{plain}).
+					Return([]dto.Product(nil), fmt.Errorf("failed to create products: %w", ErrNoActiveReception))
+			},
+			err: ErrNoActiveReception,
+		},
+		{
+			name:  "storage error fails whole batch",
+			items: []dto.ProductBatchItem{plain},
+			mockSetup: func(m *mockProductStorage) {
+				m.On("CreateProducts", ctx, pvzID, []dto.ProductBatchItem{plain}).
+					Return([]dto.Product(nil), errors.New("storage error"))
+			},
+			err: ErrProductCreate,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			storage := new(mockProductStorage)
+			testcase.mockSetup(storage)
+
+			service, err := NewProductService(storage, nil, staticPolicy(testcase.returnExisting, 10), nil)
+			require.NoError(t, err)
+
+			// act
+			result, err := service.CreateProducts(ctx, pvzID, testcase.items)
+
+			// assert
+			require.ErrorIs(t, err, testcase.err)
+			if testcase.err == nil {
+				statuses := make([]dto.ProductBatchItemResultStatus, 0, len(result.Results))
+				for i, item := range result.Results {
+					require.Equal(t, i, item.Index)
+					require.Equal(t, item.Status != dto.BatchFailed, item.Product != nil)
+					statuses = append(statuses, item.Status)
+				}
+				require.Equal(t, testcase.expected, statuses)
+			}
+			storage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProductService_Policy(t *testing.T) {
+	ctx := context.Background()
+	pvzID := openapi_types.UUID{1}
+	barcode := "4600000000001"
+	existing := &dto.Product{Id: &openapi_types.UUID{2}, Type: dto.ProductTypeShoes, Barcode: &barcode}
+	item := dto.PostProductsJSONBody{PvzId: pvzID, Type: dto.PostProductsJSONBodyTypeShoes, Barcode: &barcode}
+
+	// arrange
+	storage := new(mockProductStorage)
+	storage.On("GetOpenReceptionProductByBarcode", ctx, pvzID, barcode).Return(existing, nil)
+	returnExisting, batchLimit := false, 10
+	service, err := NewProductService(storage, nil, func() (bool, int) { return returnExisting, batchLimit }, nil)
+	require.NoError(t, err)
+
+	// act
+	_, _, rejectErr := service.CreateProduct(ctx, item)
+	limitBefore := service.BatchLimit()
+	// policy is read on every call
+	returnExisting, batchLimit = true, 1
+	product, created, returnErr := service.CreateProduct(ctx, item)
+	_, batchErr := service.CreateProducts(ctx, pvzID, make([]dto.ProductBatchItem, 2))
+
+	// assert
+	require.ErrorIs(t, rejectErr, ErrDuplicateBarcode)
+	require.NoError(t, returnErr)
+	require.False(t, created)
+	require.Equal(t, existing, product)
+	require.ErrorIs(t, batchErr, ErrBatchSize)
+	require.Equal(t, 10, limitBefore)
+	require.Equal(t, 1, service.BatchLimit())
+	storage.AssertExpectations(t)
+}
+
+func TestProductService_SuggestedCell(t *testing.T) {
+	ctx := context.Background()
+	pvzID := openapi_types.UUID{1}
+	one, two := 1, 2
+	// A-01 has 1 free place, A-02 has 2
+	cells := []dto.Cell{
+		{Id: openapi_types.UUID{2}, PvzId: pvzID, Code: "A-01", Capacity: 2, Occupied: &one},
+		{Id: openapi_types.UUID{3}, PvzId: pvzID, Code: "A-02", Capacity: 4, Occupied: &two},
+	}
+	item := dto.ProductBatchItem{Type: dto.ProductTypeShoes}
+
+	t.Run("single product gets cell with the most free capacity", func(t *testing.T) {
+		// arrange
+		storage := new(mockProductStorage)
+		storage.On("CreateProduct", ctx, dto.PostProductsJSONBody{PvzId: pvzID, Type: dto.PostProductsJSONBodyTypeShoes}).
+			Return(&dto.Product{Type: dto.ProductTypeShoes}, nil)
+		cellStorage := new(mockCellStorage)
+		cellStorage.On("GetCells", ctx, pvzID).Return(cells, nil)
+		service, err := NewProductService(storage, cellStorage, staticPolicy(false, 10), nil)
+		require.NoError(t, err)
+
+		// act
+		product, _, err := service.CreateProduct(ctx, dto.PostProductsJSONBody{PvzId: pvzID, Type: dto.PostProductsJSONBodyTypeShoes})
+
+		// assert
+		require.NoError(t, err)
+		require.NotNil(t, product.SuggestedCell)
+		require.Equal(t, "A-02", product.SuggestedCell.Code)
+	})
+
+	t.Run("batch is spread over free places", func(t *testing.T) {
+		// arrange
+		items := []dto.ProductBatchItem{item, item, item, item}
+		storage := new(mockProductStorage)
+		storage.On("CreateProducts", ctx, pvzID, items).Return(make([]dto.Product, 4), nil)
+		cellStorage := new(mockCellStorage)
+		cellStorage.On("GetCells", ctx, pvzID).Return(cells, nil)
+		service, err := NewProductService(storage, cellStorage, staticPolicy(false, 10), nil)
+		require.NoError(t, err)
+
+		// act
+		result, err := service.CreateProducts(ctx, pvzID, items)
+
+		// assert
+		require.NoError(t, err)
+		codes := make([]string, 0, len(result.Results))
+		for _, item := range result.Results {
+			if item.Product.SuggestedCell == nil {
+				codes = append(codes, "")
+				continue
+			}
+			codes = append(codes, item.Product.SuggestedCell.Code)
+		}
+		// cells with equal free capacity are taken in order of code, the last product does not fit
+		require.Equal(t, []string{"A-02", "A-01", "A-02", ""}, codes)
+	})
+
+	t.Run("failure to get cells does not fail creation", func(t *testing.T) {
+		// arrange
+		storage := new(mockProductStorage)
+		storage.On("CreateProducts", ctx, pvzID, []dto.ProductBatchItem{item}).Return(make([]dto.Product, 1), nil)
+		cellStorage := new(mockCellStorage)
+		cellStorage.On("GetCells", ctx, pvzID).Return([]dto.Cell(nil), errors.New("storage error"))
+		service, err := NewProductService(storage, cellStorage, staticPolicy(false, 10), nil)
+		require.NoError(t, err)
+
+		// act
+		result, err := service.CreateProducts(ctx, pvzID, []dto.ProductBatchItem{item})
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, dto.BatchCreated, result.Results[0].Status)
+		require.Nil(t, result.Results[0].Product.SuggestedCell)
+	})
+}
+
+func TestProductService_GetProducts(t *testing.T) {
+	ctx := context.Background()
+	barcode := "4600000000011"
+	emptyBarcode := ""
+	pvzID := openapi_types.UUID{1}
+	status := dto.ProductStored
+	page, limit := 1, 10
+
+	testcases := []struct {
+		name      string
+		params    dto.GetProductsParams
+		mockSetup func(*mockProductStorage)
+		err       error
+	}{
+		{
+			name:   "products of pvz with status",
+			params: dto.GetProductsParams{PvzId: &pvzID, Status: &status},
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetProducts", ctx, dto.GetProductsParams{PvzId: &pvzID, Status: &status, Page: &page, Limit: &limit}).
+					Return([]dto.Product{}, nil)
+			},
+		},
+		{
+			name:   "products by barcode",
+			params: dto.GetProductsParams{Barcode: &barcode},
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetProducts", ctx, dto.GetProductsParams{Barcode: &barcode, Page: &page, Limit: &limit}).
+					Return([]dto.Product{}, nil)
+			},
+		},
+		{
+			name:      "no barcode and pvz",
+			params:    dto.GetProductsParams{Status: &status},
+			mockSetup: func(m *mockProductStorage) {},
+			err:       ErrInvalidProductFilter,
+		},
+		{
+			name:      "empty barcode",
+			params:    dto.GetProductsParams{Barcode: &emptyBarcode},
+			mockSetup: func(m *mockProductStorage) {},
+			err:       ErrInvalidBarcode,
+		},
+		{
+			name:   "storage error",
+			params: dto.GetProductsParams{PvzId: &pvzID},
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetProducts", ctx, mock.Anything).Return([]dto.Product(nil), errors.New("error"))
+			},
+			err: ErrProductGet,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			storage := new(mockProductStorage)
+			testcase.mockSetup(storage)
+			service, err := NewProductService(storage, nil, staticPolicy(false, 10), nil)
+			require.NoError(t, err)
+
+			// act
+			_, err = service.GetProducts(ctx, testcase.params)
+
+			// assert
+			require.ErrorIs(t, err, testcase.err)
+			storage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProductService_ChangeProductStatus(t *testing.T) {
+	ctx := context.Background()
+	productID := openapi_types.UUID{1}
+
+	productWithStatus := func(status dto.ProductStatus) *dto.Product {
+		return &dto.Product{Id: &productID, Type: dto.ProductTypeShoes, Status: &status}
+	}
+	expiredAt := time.Now()
+	expired := productWithStatus(dto.ProductStored)
+	expired.ExpiredAt = &expiredAt
+
+	testcases := []struct {
+		name      string
+		status    dto.ProductStatus
+		mockSetup func(*mockProductStorage)
+		expected  *dto.Product
+		err       error
+	}{
+		{
+			name:   "received product is stored",
+			status: dto.ProductStored,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetProduct", ctx, productID).Return(productWithStatus(dto.ProductReceived), nil)
+				m.On("UpdateProductStatus", ctx, productID, dto.ProductReceived, dto.ProductStored).
+					Return(productWithStatus(dto.ProductStored), nil)
+			},
+			expected: productWithStatus(dto.ProductStored),
+		},
+		{
+			name:   "stored product is issued",
+			status: dto.ProductIssued,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetProduct", ctx, productID).Return(productWithStatus(dto.ProductStored), nil)
+				m.On("UpdateProductStatus", ctx, productID, dto.ProductStored, dto.ProductIssued).
+					Return(productWithStatus(dto.ProductIssued), nil)
+			},
+			expected: productWithStatus(dto.ProductIssued),
+		},
+		{
+			name:   "received product can not be issued",
+			status: dto.ProductIssued,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetProduct", ctx, productID).Return(productWithStatus(dto.ProductReceived), nil)
+			},
+			err: ErrInvalidTransition,
+		},
+		{
+			name:   "received product can not be returned",
+			status: dto.ProductReturnedToSender,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetProduct", ctx, productID).Return(productWithStatus(dto.ProductReceived), nil)
+			},
+			err: ErrInvalidTransition,
+		},
+		{
+			name:   "stored product can not be returned before issue",
+			status: dto.ProductReturnedToSender,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetProduct", ctx, productID).Return(productWithStatus(dto.ProductStored), nil)
+			},
+			err: ErrInvalidTransition,
+		},
+		{
+			name:   "expired product is returned without issue",
+			status: dto.ProductReturnedToSender,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetProduct", ctx, productID).Return(expired, nil)
+				m.On("UpdateProductStatus", ctx, productID, dto.ProductStored, dto.ProductReturnedToSender).
+					Return(productWithStatus(dto.ProductReturnedToSender), nil)
+			},
+			expected: productWithStatus(dto.ProductReturnedToSender),
+		},
+		{
+			name:   "issued product is returned",
+			status: dto.ProductReturnedToSender,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetProduct", ctx, productID).Return(productWithStatus(dto.ProductIssued), nil)
+				m.On("UpdateProductStatus", ctx, productID, dto.ProductIssued, dto.ProductReturnedToSender).
+					Return(productWithStatus(dto.ProductReturnedToSender), nil)
+			},
+			expected: productWithStatus(dto.ProductReturnedToSender),
+		},
+		{
+			name:   "returned product is final",
+			status: dto.ProductStored,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetProduct", ctx, productID).Return(productWithStatus(dto.ProductReturnedToSender), nil)
+			},
+			err: ErrInvalidTransition,
+		},
+		{
+			name:   "status changed concurrently",
+			status: dto.ProductReturnedToSender,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetProduct", ctx, productID).Return(productWithStatus(dto.ProductIssued), nil)
+				m.On("UpdateProductStatus", ctx, productID, dto.ProductIssued, dto.ProductReturnedToSender).
+					Return((*dto.Product)(nil), nil)
+			},
+			err: ErrInvalidTransition,
+		},
+		{
+			name:   "expired product can not be issued",
+			status: dto.ProductIssued,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetProduct", ctx, productID).Return(expired, nil)
+			},
+			err: ErrProductExpired,
+		},
+		{
+			name:   "product expired concurrently can not be issued",
+			status: dto.ProductIssued,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetProduct", ctx, productID).Return(productWithStatus(dto.ProductStored), nil).Once()
+				m.On("UpdateProductStatus", ctx, productID, dto.ProductStored, dto.ProductIssued).
+					Return((*dto.Product)(nil), nil)
+				m.On("GetProduct", ctx, productID).Return(expired, nil).Once()
+			},
+			err: ErrProductExpired,
+		},
+		{
+			name:   "issued concurrently",
+			status: dto.ProductIssued,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetProduct", ctx, productID).Return(productWithStatus(dto.ProductStored), nil).Once()
+				m.On("UpdateProductStatus", ctx, productID, dto.ProductStored, dto.ProductIssued).
+					Return((*dto.Product)(nil), nil)
+				m.On("GetProduct", ctx, productID).Return(productWithStatus(dto.ProductIssued), nil).Once()
+			},
+			err: ErrInvalidTransition,
+		},
+		{
+			name:   "product not found",
+			status: dto.ProductStored,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetProduct", ctx, productID).Return((*dto.Product)(nil), nil)
+			},
+			err: ErrProductNotFound,
+		},
+		{
+			name:   "storage error",
+			status: dto.ProductStored,
+			mockSetup: func(m *mockProductStorage) {
+				m.On("GetProduct", ctx, productID).Return((*dto.Product)(nil), errors.New("error"))
+			},
+			err: ErrProductUpdate,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			storage := new(mockProductStorage)
+			testcase.mockSetup(storage)
+			service, err := NewProductService(storage, nil, staticPolicy(false, 10), nil)
+			require.NoError(t, err)
+
+			// act
+			product, err := service.ChangeProductStatus(ctx, productID, testcase.status)
+
+			// assert
+			require.ErrorIs(t, err, testcase.err)
+			require.Equal(t, testcase.expected, product)
+			storage.AssertExpectations(t)
+		})
+	}
+}
+
 func TestProductService_DeleteLastProduct(t *testing.T) {
 	ctx := context.Background()
 	pvzID := openapi_types.UUID{}
@@ -164,7 +799,7 @@ func TestProductService_DeleteLastProduct(t *testing.T) {
 						ReceptionId: receptionID,
 						Type:        productType,
 					}, nil)
-				m.On("DeleteProduct", ctx, productID).
+				m.On("DeleteProduct", ctx, productID, (*openapi_types.UUID)(nil)).
 					Return(nil)
 			},
 			err: nil,
@@ -189,7 +824,7 @@ func TestProductService_DeleteLastProduct(t *testing.T) {
 						ReceptionId: receptionID,
 						Type:        productType,
 					}, nil)
-				m.On("DeleteProduct", ctx, productID).
+				m.On("DeleteProduct", ctx, productID, (*openapi_types.UUID)(nil)).
 					Return(errors.New("delete failed"))
 			},
 			err: ErrDeleteProduct,
@@ -201,11 +836,11 @@ func TestProductService_DeleteLastProduct(t *testing.T) {
 			// arrange
 			storage := new(mockProductStorage)
 			testcase.mockSetup(storage)
-			service, err := NewProductService(storage)
+			service, err := NewProductService(storage, nil, staticPolicy(false, 10), nil)
 			require.NoError(t, err)
 
 			// act
-			err = service.DeleteLastProduct(ctx, testcase.pvzID)
+			err = service.DeleteLastProduct(ctx, testcase.pvzID, nil)
 
 			// assert
 			require.ErrorIs(t, err, testcase.err)
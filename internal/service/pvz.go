@@ -19,12 +19,15 @@ type PVZService struct {
 	pvzStorage       PVZStorager
 	receptionStorage ReceptionStorager
 	productStorage   ProductStorager
+	metrics          *BusinessMetrics
 }
 
+// NewPVZService creates service, metrics may be nil
 func NewPVZService(
 	pvzStorage PVZStorager,
 	receptionStorage ReceptionStorager,
 	productStorage ProductStorager,
+	metrics *BusinessMetrics,
 ) (*PVZService, error) {
 	if pvzStorage == nil || receptionStorage == nil || productStorage == nil {
 		return nil, ErrNilInConstruct
@@ -34,19 +37,27 @@ func NewPVZService(
 		pvzStorage:       pvzStorage,
 		receptionStorage: receptionStorage,
 		productStorage:   productStorage,
+		metrics:          metrics,
 	}, nil
 }
 
 func (s *PVZService) CreatePVZ(ctx context.Context, payload dto.PostPvzJSONRequestBody) (*dto.PVZ, error) {
+	ctx, span := startSpan(ctx, "PVZService.CreatePVZ")
+	defer span.End()
+
 	pvz, err := s.pvzStorage.CreatePVZ(ctx, payload)
 	if err != nil {
 		return nil, ErrPVZCreate
 	}
+	s.metrics.pvzCreated(pvz)
 
 	return pvz, nil
 }
 
 func (s *PVZService) GetPVZWithReceptionsFiltered(ctx context.Context, payload dto.GetPvzParams) []dto.PVZWithReceptions {
+	ctx, span := startSpan(ctx, "PVZService.GetPVZWithReceptionsFiltered")
+	defer span.End()
+
 	pvzs, err := s.pvzStorage.GetPVZs(ctx, payload)
 	if err != nil {
 		return nil
@@ -77,5 +88,8 @@ func (s *PVZService) GetPVZWithReceptionsFiltered(ctx context.Context, payload d
 }
 
 func (s *PVZService) GetPVZs(ctx context.Context) []dto.PVZ {
+	ctx, span := startSpan(ctx, "PVZService.GetPVZs")
+	defer span.End()
+
 	return s.pvzStorage.GetAllPVZs(ctx)
 }
@@ -80,6 +80,7 @@ func TestPVZService_CreatePVZ(t *testing.T) {
 				pvzStorageMock,
 				&mockReceptionStorage{},
 				&mockProductStorage{},
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -212,6 +213,7 @@ func TestPVZService_GetPVZWithReceptionsFiltered(t *testing.T) {
 				pvzStorageMock,
 				receptionStorageMock,
 				productStorageMock,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/Arzeeq/pvz-api/internal/dto"
@@ -12,39 +13,344 @@ import (
 var ErrActiveReception = errors.New("failed to create reception, there is already an active reception")
 var ErrReceptionCreate = errors.New("failed to create reception")
 var ErrReceptionClose = errors.New("failed to close reception")
+var ErrReceptionNotFound = errors.New("reception not found")
+var ErrReceptionGet = errors.New("failed to get reception")
+var ErrReceptionNotInProgress = errors.New("reception is not in progress")
+var ErrProductNotFound = errors.New("product not found in reception")
+var ErrInvalidManifest = errors.New("manifest must have non-negative count for each product type at most once and unique non-empty barcodes")
+
+// productTypes fixes order of product types in counts and reports
+var productTypes = []dto.ProductType{dto.ProductTypeElectronics, dto.ProductTypeClothes, dto.ProductTypeShoes}
 
 type ReceptionStorager interface {
-	CreateReception(ctx context.Context, pvzID openapi_types.UUID) (*dto.Reception, error)
+	CreateReception(
+		ctx context.Context,
+		pvzID openapi_types.UUID,
+		manifest *dto.ReceptionManifest,
+		openedBy *openapi_types.UUID,
+	) (*dto.Reception, error)
 	GetPVZReceptionsFiltered(ctx context.Context, pvzID openapi_types.UUID, startDate, endDate time.Time) []dto.Reception
-	CloseReception(ctx context.Context, pvzID openapi_types.UUID) (*dto.Reception, error)
+	// CloseReception closes in progress reception of pvz with report built from its products.
+	// Products are read in the closing transaction while reception is locked, so concurrently added
+	// product either gets into report or is rejected. Returns closed reception with number of its
+	// products, nil when pvz has no reception in progress
+	CloseReception(
+		ctx context.Context,
+		pvzID openapi_types.UUID,
+		closedBy *openapi_types.UUID,
+		report func(reception dto.Reception, products []dto.Product) *dto.DiscrepancyReport,
+	) (*dto.Reception, int, error)
+	GetReception(ctx context.Context, receptionID openapi_types.UUID) (*dto.Reception, error)
+	GetActiveReception(ctx context.Context, pvzID openapi_types.UUID) (*dto.Reception, error)
+	GetReceptions(ctx context.Context, pvzID openapi_types.UUID, params dto.GetPvzPvzIdReceptionsParams) ([]dto.Reception, error)
 }
 
 type ReceptionService struct {
-	storage ReceptionStorager
+	storage        ReceptionStorager
+	productStorage ProductStorager
+	metrics        *BusinessMetrics
 }
 
-func NewReceptionService(storage ReceptionStorager) (*ReceptionService, error) {
-	if storage == nil {
+// NewReceptionService creates service, metrics may be nil
+func NewReceptionService(storage ReceptionStorager, productStorage ProductStorager, metrics *BusinessMetrics) (*ReceptionService, error) {
+	if storage == nil || productStorage == nil {
 		return nil, ErrNilInConstruct
 	}
 
-	return &ReceptionService{storage: storage}, nil
+	return &ReceptionService{storage: storage, productStorage: productStorage, metrics: metrics}, nil
 }
 
-func (s *ReceptionService) CreateReception(ctx context.Context, pvzID openapi_types.UUID) (*dto.Reception, error) {
-	reception, err := s.storage.CreateReception(ctx, pvzID)
+// CreateReception opens reception in pvz with optional expected manifest,
+// userID is nil when token has no user
+func (s *ReceptionService) CreateReception(
+	ctx context.Context,
+	pvzID openapi_types.UUID,
+	manifest *dto.ReceptionManifest,
+	userID *openapi_types.UUID,
+) (*dto.Reception, error) {
+	ctx, span := startSpan(ctx, "ReceptionService.CreateReception")
+	defer span.End()
+
+	if err := validateManifest(manifest); err != nil {
+		return nil, err
+	}
+
+	reception, err := s.storage.CreateReception(ctx, pvzID, manifest, userID)
 	if err != nil {
 		return nil, ErrReceptionCreate
 	}
+	s.metrics.receptionOpened(ctx, pvzID)
 
 	return reception, nil
 }
 
-func (s *ReceptionService) CloseReception(ctx context.Context, pvzID openapi_types.UUID) (*dto.Reception, error) {
-	reception, err := s.storage.CloseReception(ctx, pvzID)
-	if err != nil {
+// CloseReception closes active reception of pvz, receptions with manifest
+// get discrepancy report comparing manifest with received products
+func (s *ReceptionService) CloseReception(ctx context.Context, pvzID openapi_types.UUID, userID *openapi_types.UUID) (*dto.Reception, error) {
+	ctx, span := startSpan(ctx, "ReceptionService.CloseReception")
+	defer span.End()
+
+	reception, products, err := s.storage.CloseReception(ctx, pvzID, userID, closingReport)
+	if err != nil || reception == nil {
 		return nil, ErrReceptionClose
 	}
+	s.metrics.receptionClosed(ctx, reception, products)
 
 	return reception, nil
 }
+
+// GetReception returns reception with its products in order of addition
+// and amount of products of every type
+func (s *ReceptionService) GetReception(ctx context.Context, receptionID openapi_types.UUID) (*dto.ReceptionDetails, error) {
+	ctx, span := startSpan(ctx, "ReceptionService.GetReception")
+	defer span.End()
+
+	reception, err := s.storage.GetReception(ctx, receptionID)
+	if err != nil {
+		return nil, ErrReceptionGet
+	}
+	if reception == nil {
+		return nil, ErrReceptionNotFound
+	}
+
+	products := s.productStorage.GetReceptionProducts(ctx, reception.Id)
+	if products == nil {
+		products = make([]dto.Product, 0)
+	}
+
+	counts := countProducts(products)
+	productCounts := make([]dto.ProductTypeCount, 0, len(productTypes))
+	for _, productType := range productTypes {
+		productCounts = append(productCounts, dto.ProductTypeCount{
+			Type:  dto.ProductTypeCountType(productType),
+			Count: counts[productType],
+		})
+	}
+
+	details := &dto.ReceptionDetails{
+		Reception:     *reception,
+		Products:      products,
+		ProductCounts: productCounts,
+	}
+	if manifest := reception.Manifest; manifest != nil {
+		if hasCounts(*manifest) {
+			progress := manifestProgress(*manifest, counts)
+			details.Progress = &progress
+		}
+		if len(manifest.Barcodes) > 0 {
+			missing, _, _ := barcodeDiscrepancy(manifest.Barcodes, products)
+			details.MissingBarcodes = &missing
+		}
+	}
+
+	return details, nil
+}
+
+func (s *ReceptionService) GetReceptions(ctx context.Context, pvzID openapi_types.UUID, params dto.GetPvzPvzIdReceptionsParams) ([]dto.Reception, error) {
+	ctx, span := startSpan(ctx, "ReceptionService.GetReceptions")
+	defer span.End()
+
+	receptions, err := s.storage.GetReceptions(ctx, pvzID, params)
+	if err != nil {
+		return nil, ErrReceptionGet
+	}
+
+	return receptions, nil
+}
+
+func (s *ReceptionService) GetActiveReception(ctx context.Context, pvzID openapi_types.UUID) (*dto.Reception, error) {
+	ctx, span := startSpan(ctx, "ReceptionService.GetActiveReception")
+	defer span.End()
+
+	reception, err := s.storage.GetActiveReception(ctx, pvzID)
+	if err != nil {
+		return nil, ErrReceptionGet
+	}
+	if reception == nil {
+		return nil, ErrReceptionNotFound
+	}
+
+	return reception, nil
+}
+
+// DeleteProduct removes any product of reception while it is in progress,
+// order of remaining products does not change
+func (s *ReceptionService) DeleteProduct(
+	ctx context.Context,
+	receptionID openapi_types.UUID,
+	productID openapi_types.UUID,
+	userID *openapi_types.UUID,
+) error {
+	ctx, span := startSpan(ctx, "ReceptionService.DeleteProduct")
+	defer span.End()
+
+	reception, err := s.storage.GetReception(ctx, receptionID)
+	if err != nil {
+		return ErrDeleteProduct
+	}
+	if reception == nil {
+		return ErrReceptionNotFound
+	}
+	if reception.Status != dto.InProgress {
+		return ErrReceptionNotInProgress
+	}
+
+	deleted, err := s.productStorage.DeleteReceptionProduct(ctx, receptionID, productID, userID)
+	if err != nil {
+		return ErrDeleteProduct
+	}
+	if !deleted {
+		return ErrProductNotFound
+	}
+
+	return nil
+}
+
+func validateManifest(manifest *dto.ReceptionManifest) error {
+	if manifest == nil {
+		return nil
+	}
+
+	seen := make(map[dto.ProductTypeCountType]bool, len(manifest.Items))
+	for _, item := range manifest.Items {
+		if item.Count < 0 || seen[item.Type] {
+			return ErrInvalidManifest
+		}
+		if !slices.Contains(productTypes, dto.ProductType(item.Type)) {
+			return ErrInvalidManifest
+		}
+		seen[item.Type] = true
+	}
+
+	seenBarcodes := make(map[string]bool, len(manifest.Barcodes))
+	for _, barcode := range manifest.Barcodes {
+		if barcode == "" || seenBarcodes[barcode] {
+			return ErrInvalidManifest
+		}
+		seenBarcodes[barcode] = true
+	}
+
+	return nil
+}
+
+// hasCounts is false for manifest listing barcodes only, its counts by product type are not compared
+func hasCounts(manifest dto.ReceptionManifest) bool {
+	return len(manifest.Items) > 0 || len(manifest.Barcodes) == 0
+}
+
+func countProducts(products []dto.Product) map[dto.ProductType]int {
+	counts := make(map[dto.ProductType]int, len(productTypes))
+	for _, product := range products {
+		counts[product.Type]++
+	}
+
+	return counts
+}
+
+func expectedCounts(manifest dto.ReceptionManifest) map[dto.ProductType]int {
+	expected := make(map[dto.ProductType]int, len(manifest.Items))
+	for _, item := range manifest.Items {
+		expected[dto.ProductType(item.Type)] = item.Count
+	}
+
+	return expected
+}
+
+func manifestProgress(manifest dto.ReceptionManifest, received map[dto.ProductType]int) []dto.ManifestProgress {
+	expected := expectedCounts(manifest)
+
+	progress := make([]dto.ManifestProgress, 0, len(expected))
+	for _, productType := range productTypes {
+		count, ok := expected[productType]
+		if !ok {
+			continue
+		}
+		progress = append(progress, dto.ManifestProgress{
+			Type:      productType,
+			Expected:  count,
+			Received:  received[productType],
+			Remaining: max(count-received[productType], 0),
+		})
+	}
+
+	return progress
+}
+
+// closingReport compares products of closed reception with its manifest, receptions without manifest get no report
+func closingReport(reception dto.Reception, products []dto.Product) *dto.DiscrepancyReport {
+	if reception.Manifest == nil {
+		return nil
+	}
+
+	return discrepancyReport(*reception.Manifest, products)
+}
+
+// discrepancyReport lists every product type which was expected or received
+// and barcodes of manifest which were not received or received products which are not in manifest.
+// Product without barcode can not match manifest of barcodes, so it is surplus too
+func discrepancyReport(manifest dto.ReceptionManifest, products []dto.Product) *dto.DiscrepancyReport {
+	report := dto.DiscrepancyReport{Items: make([]dto.DiscrepancyItem, 0, len(productTypes))}
+	if len(manifest.Barcodes) > 0 {
+		report.MissingBarcodes, report.SurplusBarcodes, report.SurplusWithoutBarcode = barcodeDiscrepancy(manifest.Barcodes, products)
+		report.HasDiscrepancies = len(report.MissingBarcodes) > 0 || len(report.SurplusBarcodes) > 0 ||
+			report.SurplusWithoutBarcode > 0
+	}
+	if !hasCounts(manifest) {
+		return &report
+	}
+
+	expected := expectedCounts(manifest)
+	received := countProducts(products)
+	for _, productType := range productTypes {
+		if expected[productType] == 0 && received[productType] == 0 {
+			continue
+		}
+
+		item := dto.DiscrepancyItem{
+			Type:     productType,
+			Expected: expected[productType],
+			Received: received[productType],
+			Missing:  max(expected[productType]-received[productType], 0),
+			Surplus:  max(received[productType]-expected[productType], 0),
+		}
+		if item.Missing > 0 || item.Surplus > 0 {
+			report.HasDiscrepancies = true
+		}
+		report.Items = append(report.Items, item)
+	}
+
+	return &report
+}
+
+// barcodeDiscrepancy returns barcodes of manifest without received product
+// and barcodes of received products which are not in manifest with number of received products without barcode
+func barcodeDiscrepancy(barcodes []string, products []dto.Product) ([]string, []string, int) {
+	received := make(map[string]bool, len(products))
+	for _, product := range products {
+		if product.Barcode != nil {
+			received[*product.Barcode] = true
+		}
+	}
+
+	expected := make(map[string]bool, len(barcodes))
+	missing := make([]string, 0)
+	for _, barcode := range barcodes {
+		expected[barcode] = true
+		if !received[barcode] {
+			missing = append(missing, barcode)
+		}
+	}
+
+	surplus := make([]string, 0)
+	var withoutBarcode int
+	for _, product := range products {
+		switch {
+		case product.Barcode == nil:
+			withoutBarcode++
+		case !expected[*product.Barcode]:
+			surplus = append(surplus, *product.Barcode)
+		}
+	}
+
+	return missing, surplus, withoutBarcode
+}
@@ -16,8 +16,13 @@ type mockReceptionStorage struct {
 	mock.Mock
 }
 
-func (m *mockReceptionStorage) CreateReception(ctx context.Context, pvzID openapi_types.UUID) (*dto.Reception, error) {
-	args := m.Called(ctx, pvzID)
+func (m *mockReceptionStorage) CreateReception(
+	ctx context.Context,
+	pvzID openapi_types.UUID,
+	manifest *dto.ReceptionManifest,
+	openedBy *openapi_types.UUID,
+) (*dto.Reception, error) {
+	args := m.Called(ctx, pvzID, manifest, openedBy)
 	return args.Get(0).(*dto.Reception), args.Error(1)
 }
 
@@ -31,33 +36,68 @@ func (m *mockReceptionStorage) GetActiveReception(ctx context.Context, pvzID ope
 	return args.Get(0).(*dto.Reception), args.Error(1)
 }
 
-func (m *mockReceptionStorage) CloseReception(ctx context.Context, pvzID openapi_types.UUID) (*dto.Reception, error) {
-	args := m.Called(ctx, pvzID)
+func (m *mockReceptionStorage) GetReception(ctx context.Context, receptionID openapi_types.UUID) (*dto.Reception, error) {
+	args := m.Called(ctx, receptionID)
 	return args.Get(0).(*dto.Reception), args.Error(1)
 }
 
+func (m *mockReceptionStorage) GetReceptions(ctx context.Context, pvzID openapi_types.UUID, params dto.GetPvzPvzIdReceptionsParams) ([]dto.Reception, error) {
+	args := m.Called(ctx, pvzID, params)
+	return args.Get(0).([]dto.Reception), args.Error(1)
+}
+
+// CloseReception returns in progress reception set up for pvz closed with report built from set up products
+func (m *mockReceptionStorage) CloseReception(
+	ctx context.Context,
+	pvzID openapi_types.UUID,
+	closedBy *openapi_types.UUID,
+	report func(reception dto.Reception, products []dto.Product) *dto.DiscrepancyReport,
+) (*dto.Reception, int, error) {
+	args := m.Called(ctx, pvzID, closedBy)
+	active := args.Get(0).(*dto.Reception)
+	products := args.Get(1).([]dto.Product)
+	if active == nil || args.Error(2) != nil {
+		return nil, 0, args.Error(2)
+	}
+
+	closed := *active
+	closed.Status = dto.Close
+	closed.ClosedBy = closedBy
+	closed.DiscrepancyReport = report(*active, products)
+	return &closed, len(products), nil
+}
+
 func TestNewReceptionService(t *testing.T) {
 	testcases := []struct {
-		name    string
-		storage ReceptionStorager
-		wantErr bool
-		err     error
+		name           string
+		storage        ReceptionStorager
+		productStorage ProductStorager
+		wantErr        bool
+		err            error
 	}{
 		{
-			name:    "success",
-			storage: new(mockReceptionStorage),
-			err:     nil,
+			name:           "success",
+			storage:        new(mockReceptionStorage),
+			productStorage: new(mockProductStorage),
+			err:            nil,
+		},
+		{
+			name:           "nil storage",
+			storage:        nil,
+			productStorage: new(mockProductStorage),
+			err:            ErrNilInConstruct,
 		},
 		{
-			name:    "nil storage",
-			storage: nil,
-			err:     ErrNilInConstruct,
+			name:           "nil product storage",
+			storage:        new(mockReceptionStorage),
+			productStorage: nil,
+			err:            ErrNilInConstruct,
 		},
 	}
 
 	for _, testcase := range testcases {
 		t.Run(testcase.name, func(t *testing.T) {
-			service, err := NewReceptionService(testcase.storage)
+			service, err := NewReceptionService(testcase.storage, testcase.productStorage, nil)
 			require.ErrorIs(t, err, testcase.err)
 			if testcase.err != nil {
 				require.Nil(t, service)
@@ -71,10 +111,12 @@ func TestNewReceptionService(t *testing.T) {
 func TestReceptionService_CreateReception(t *testing.T) {
 	ctx := context.Background()
 	testUUID := openapi_types.UUID{}
+	userID := &openapi_types.UUID{1}
 
 	testcases := []struct {
 		name      string
 		pvzID     openapi_types.UUID
+		manifest  *dto.ReceptionManifest
 		mockSetup func(*mockReceptionStorage)
 		expected  *dto.Reception
 		err       error
@@ -83,7 +125,7 @@ func TestReceptionService_CreateReception(t *testing.T) {
 			name:  "successful creation",
 			pvzID: testUUID,
 			mockSetup: func(m *mockReceptionStorage) {
-				m.On("CreateReception", ctx, testUUID).
+				m.On("CreateReception", ctx, testUUID, (*dto.ReceptionManifest)(nil), userID).
 					Return(&dto.Reception{
 						Id:     testUUID,
 						PvzId:  testUUID,
@@ -101,17 +143,36 @@ func TestReceptionService_CreateReception(t *testing.T) {
 			name:  "storage error",
 			pvzID: testUUID,
 			mockSetup: func(m *mockReceptionStorage) {
-				m.On("CreateReception", ctx, testUUID).
+				m.On("CreateReception", ctx, testUUID, (*dto.ReceptionManifest)(nil), userID).
 					Return(&dto.Reception{}, errors.New("storage error"))
 			},
 			expected: nil,
 			err:      ErrReceptionCreate,
 		},
+		{
+			name:  "invalid manifest",
+			pvzID: testUUID,
+			manifest: &dto.ReceptionManifest{Items: []dto.ProductTypeCount{
+				{Type: dto.CountShoes, Count: 1},
+				{Type: dto.CountShoes, Count: 2},
+			}},
+			mockSetup: func(m *mockReceptionStorage) {},
+			expected:  nil,
+			err:       ErrInvalidManifest,
+		},
+		{
+			name:      "manifest with repeated barcode",
+			pvzID:     testUUID,
+			manifest:  &dto.ReceptionManifest{Barcodes: []string{"111", "111"}},
+			mockSetup: func(m *mockReceptionStorage) {},
+			expected:  nil,
+			err:       ErrInvalidManifest,
+		},
 		{
 			name:  "active reception exists",
 			pvzID: testUUID,
 			mockSetup: func(m *mockReceptionStorage) {
-				m.On("CreateReception", ctx, testUUID).
+				m.On("CreateReception", ctx, testUUID, (*dto.ReceptionManifest)(nil), userID).
 					Return(&dto.Reception{}, ErrActiveReception)
 			},
 			expected: nil,
@@ -124,11 +185,11 @@ func TestReceptionService_CreateReception(t *testing.T) {
 			// arrange
 			mockStorage := new(mockReceptionStorage)
 			testcase.mockSetup(mockStorage)
-			service, err := NewReceptionService(mockStorage)
+			service, err := NewReceptionService(mockStorage, new(mockProductStorage), nil)
 			require.NoError(t, err)
 
 			// act
-			reception, err := service.CreateReception(ctx, testcase.pvzID)
+			reception, err := service.CreateReception(ctx, testcase.pvzID, testcase.manifest, userID)
 
 			// assert
 			require.Equal(t, testcase.expected, reception)
@@ -141,6 +202,35 @@ func TestReceptionService_CreateReception(t *testing.T) {
 func TestReceptionService_CloseReception(t *testing.T) {
 	ctx := context.Background()
 	testUUID := openapi_types.UUID{}
+	userID := &openapi_types.UUID{1}
+	manifest := &dto.ReceptionManifest{Items: []dto.ProductTypeCount{
+		{Type: dto.CountElectronics, Count: 2},
+		{Type: dto.CountClothes, Count: 0},
+	}}
+	report := &dto.DiscrepancyReport{
+		HasDiscrepancies: true,
+		Items: []dto.DiscrepancyItem{
+			{Type: dto.ProductTypeElectronics, Expected: 2, Received: 1, Missing: 1},
+			{Type: dto.ProductTypeShoes, Expected: 0, Received: 1, Surplus: 1},
+		},
+	}
+	expectedBarcode, surplusBarcode := "111", "333"
+	barcodeManifest := &dto.ReceptionManifest{Barcodes: []string{"111", "222"}}
+	barcodeReport := &dto.DiscrepancyReport{
+		HasDiscrepancies:      true,
+		Items:                 []dto.DiscrepancyItem{},
+		MissingBarcodes:       []string{"222"},
+		SurplusBarcodes:       []string{"333"},
+		SurplusWithoutBarcode: 1,
+	}
+	fullManifest := &dto.ReceptionManifest{Barcodes: []string{"111"}}
+	withoutBarcodeReport := &dto.DiscrepancyReport{
+		HasDiscrepancies:      true,
+		Items:                 []dto.DiscrepancyItem{},
+		MissingBarcodes:       []string{},
+		SurplusBarcodes:       []string{},
+		SurplusWithoutBarcode: 1,
+	}
 
 	tests := []struct {
 		name      string
@@ -153,17 +243,75 @@ func TestReceptionService_CloseReception(t *testing.T) {
 			name:  "successful close",
 			pvzID: testUUID,
 			mockSetup: func(m *mockReceptionStorage) {
-				m.On("CloseReception", ctx, testUUID).
-					Return(&dto.Reception{
-						Id:     testUUID,
-						PvzId:  testUUID,
-						Status: dto.Close,
+				m.On("CloseReception", ctx, testUUID, userID).
+					Return(&dto.Reception{Id: testUUID, PvzId: testUUID}, []dto.Product{{Type: dto.ProductTypeShoes}}, nil)
+			},
+			expected: &dto.Reception{
+				Id:       testUUID,
+				PvzId:    testUUID,
+				Status:   dto.Close,
+				ClosedBy: userID,
+			},
+			err: nil,
+		},
+		{
+			name:  "reception with manifest gets discrepancy report",
+			pvzID: testUUID,
+			mockSetup: func(m *mockReceptionStorage) {
+				m.On("CloseReception", ctx, testUUID, userID).
+					Return(&dto.Reception{Id: testUUID, PvzId: testUUID, Manifest: manifest}, []dto.Product{
+						{Type: dto.ProductTypeElectronics},
+						{Type: dto.ProductTypeShoes},
 					}, nil)
 			},
 			expected: &dto.Reception{
-				Id:     testUUID,
-				PvzId:  testUUID,
-				Status: dto.Close,
+				Id:                testUUID,
+				PvzId:             testUUID,
+				Status:            dto.Close,
+				ClosedBy:          userID,
+				Manifest:          manifest,
+				DiscrepancyReport: report,
+			},
+			err: nil,
+		},
+		{
+			name:  "manifest of barcodes is compared by barcode only",
+			pvzID: testUUID,
+			mockSetup: func(m *mockReceptionStorage) {
+				m.On("CloseReception", ctx, testUUID, userID).
+					Return(&dto.Reception{Id: testUUID, PvzId: testUUID, Manifest: barcodeManifest}, []dto.Product{
+						{Type: dto.ProductTypeElectronics, Barcode: &expectedBarcode},
+						{Type: dto.ProductTypeShoes, Barcode: &surplusBarcode},
+						{Type: dto.ProductTypeShoes},
+					}, nil)
+			},
+			expected: &dto.Reception{
+				Id:                testUUID,
+				PvzId:             testUUID,
+				Status:            dto.Close,
+				ClosedBy:          userID,
+				Manifest:          barcodeManifest,
+				DiscrepancyReport: barcodeReport,
+			},
+			err: nil,
+		},
+		{
+			name:  "product without barcode is surplus of barcode manifest",
+			pvzID: testUUID,
+			mockSetup: func(m *mockReceptionStorage) {
+				m.On("CloseReception", ctx, testUUID, userID).
+					Return(&dto.Reception{Id: testUUID, PvzId: testUUID, Manifest: fullManifest}, []dto.Product{
+						{Type: dto.ProductTypeElectronics, Barcode: &expectedBarcode},
+						{Type: dto.ProductTypeShoes},
+					}, nil)
+			},
+			expected: &dto.Reception{
+				Id:                testUUID,
+				PvzId:             testUUID,
+				Status:            dto.Close,
+				ClosedBy:          userID,
+				Manifest:          fullManifest,
+				DiscrepancyReport: withoutBarcodeReport,
 			},
 			err: nil,
 		},
@@ -171,8 +319,8 @@ func TestReceptionService_CloseReception(t *testing.T) {
 			name:  "storage error",
 			pvzID: testUUID,
 			mockSetup: func(m *mockReceptionStorage) {
-				m.On("CloseReception", ctx, testUUID).
-					Return(&dto.Reception{}, errors.New("storage error"))
+				m.On("CloseReception", ctx, testUUID, userID).
+					Return(&dto.Reception{}, []dto.Product(nil), errors.New("storage error"))
 			},
 			expected: nil,
 			err:      ErrReceptionClose,
@@ -181,8 +329,7 @@ func TestReceptionService_CloseReception(t *testing.T) {
 			name:  "no active reception to close",
 			pvzID: testUUID,
 			mockSetup: func(m *mockReceptionStorage) {
-				m.On("CloseReception", ctx, testUUID).
-					Return(&dto.Reception{}, errors.New("no active reception"))
+				m.On("CloseReception", ctx, testUUID, userID).Return((*dto.Reception)(nil), []dto.Product(nil), nil)
 			},
 			expected: nil,
 			err:      ErrReceptionClose,
@@ -193,17 +340,302 @@ func TestReceptionService_CloseReception(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// arrange
 			mockStorage := new(mockReceptionStorage)
+			mockProducts := new(mockProductStorage)
 			tt.mockSetup(mockStorage)
-			service, err := NewReceptionService(mockStorage)
+			service, err := NewReceptionService(mockStorage, mockProducts, nil)
 			require.NoError(t, err)
 
 			// act
-			reception, err := service.CloseReception(ctx, tt.pvzID)
+			reception, err := service.CloseReception(ctx, tt.pvzID, userID)
 
 			// assert
 			require.Equal(t, tt.expected, reception)
 			require.Equal(t, tt.err, err)
 			mockStorage.AssertExpectations(t)
+			mockProducts.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReceptionService_GetReception(t *testing.T) {
+	ctx := context.Background()
+	receptionID := openapi_types.UUID{1}
+	reception := &dto.Reception{
+		Id:     receptionID,
+		Status: dto.InProgress,
+	}
+	products := []dto.Product{
+		{Id: &openapi_types.UUID{2}, ReceptionId: receptionID, Type: dto.ProductTypeShoes},
+		{Id: &openapi_types.UUID{3}, ReceptionId: receptionID, Type: dto.ProductTypeElectronics},
+		{Id: &openapi_types.UUID{4}, ReceptionId: receptionID, Type: dto.ProductTypeShoes},
+	}
+
+	testcases := []struct {
+		name      string
+		mockSetup func(*mockReceptionStorage, *mockProductStorage)
+		expected  *dto.ReceptionDetails
+		err       error
+	}{
+		{
+			name: "success",
+			mockSetup: func(m *mockReceptionStorage, p *mockProductStorage) {
+				m.On("GetReception", ctx, receptionID).Return(reception, nil)
+				p.On("GetReceptionProducts", ctx, receptionID).Return(products)
+			},
+			expected: &dto.ReceptionDetails{
+				Reception: *reception,
+				Products:  products,
+				ProductCounts: []dto.ProductTypeCount{
+					{Type: dto.CountElectronics, Count: 1},
+					{Type: dto.CountClothes, Count: 0},
+					{Type: dto.CountShoes, Count: 2},
+				},
+			},
+			err: nil,
+		},
+		{
+			name: "empty reception",
+			mockSetup: func(m *mockReceptionStorage, p *mockProductStorage) {
+				m.On("GetReception", ctx, receptionID).Return(reception, nil)
+				p.On("GetReceptionProducts", ctx, receptionID).Return([]dto.Product(nil))
+			},
+			expected: &dto.ReceptionDetails{
+				Reception: *reception,
+				Products:  []dto.Product{},
+				ProductCounts: []dto.ProductTypeCount{
+					{Type: dto.CountElectronics, Count: 0},
+					{Type: dto.CountClothes, Count: 0},
+					{Type: dto.CountShoes, Count: 0},
+				},
+			},
+			err: nil,
+		},
+		{
+			name: "not found",
+			mockSetup: func(m *mockReceptionStorage, p *mockProductStorage) {
+				m.On("GetReception", ctx, receptionID).Return((*dto.Reception)(nil), nil)
+			},
+			expected: nil,
+			err:      ErrReceptionNotFound,
+		},
+		{
+			name: "storage error",
+			mockSetup: func(m *mockReceptionStorage, p *mockProductStorage) {
+				m.On("GetReception", ctx, receptionID).Return((*dto.Reception)(nil), errors.New("storage error"))
+			},
+			expected: nil,
+			err:      ErrReceptionGet,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			mockStorage := new(mockReceptionStorage)
+			mockProducts := new(mockProductStorage)
+			testcase.mockSetup(mockStorage, mockProducts)
+			service, err := NewReceptionService(mockStorage, mockProducts, nil)
+			require.NoError(t, err)
+
+			// act
+			details, err := service.GetReception(ctx, receptionID)
+
+			// assert
+			require.Equal(t, testcase.expected, details)
+			require.Equal(t, testcase.err, err)
+			mockStorage.AssertExpectations(t)
+			mockProducts.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReceptionService_GetActiveReception(t *testing.T) {
+	ctx := context.Background()
+	pvzID := openapi_types.UUID{1}
+
+	testcases := []struct {
+		name      string
+		mockSetup func(*mockReceptionStorage)
+		expected  *dto.Reception
+		err       error
+	}{
+		{
+			name: "success",
+			mockSetup: func(m *mockReceptionStorage) {
+				m.On("GetActiveReception", ctx, pvzID).
+					Return(&dto.Reception{PvzId: pvzID, Status: dto.InProgress}, nil)
+			},
+			expected: &dto.Reception{PvzId: pvzID, Status: dto.InProgress},
+			err:      nil,
+		},
+		{
+			name: "no active reception",
+			mockSetup: func(m *mockReceptionStorage) {
+				m.On("GetActiveReception", ctx, pvzID).Return((*dto.Reception)(nil), nil)
+			},
+			expected: nil,
+			err:      ErrReceptionNotFound,
+		},
+		{
+			name: "storage error",
+			mockSetup: func(m *mockReceptionStorage) {
+				m.On("GetActiveReception", ctx, pvzID).Return((*dto.Reception)(nil), errors.New("storage error"))
+			},
+			expected: nil,
+			err:      ErrReceptionGet,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			mockStorage := new(mockReceptionStorage)
+			testcase.mockSetup(mockStorage)
+			service, err := NewReceptionService(mockStorage, new(mockProductStorage), nil)
+			require.NoError(t, err)
+
+			// act
+			reception, err := service.GetActiveReception(ctx, pvzID)
+
+			// assert
+			require.Equal(t, testcase.expected, reception)
+			require.Equal(t, testcase.err, err)
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReceptionService_GetReceptionProgress(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	receptionID := openapi_types.UUID{1}
+	reception := &dto.Reception{
+		Id:     receptionID,
+		Status: dto.InProgress,
+		Manifest: &dto.ReceptionManifest{Items: []dto.ProductTypeCount{
+			{Type: dto.CountShoes, Count: 1},
+			{Type: dto.CountClothes, Count: 3},
+		}},
+	}
+	mockStorage := new(mockReceptionStorage)
+	mockStorage.On("GetReception", ctx, receptionID).Return(reception, nil)
+	mockProducts := new(mockProductStorage)
+	mockProducts.On("GetReceptionProducts", ctx, receptionID).Return([]dto.Product{
+		{Type: dto.ProductTypeClothes},
+		{Type: dto.ProductTypeShoes},
+		{Type: dto.ProductTypeShoes},
+	})
+	service, err := NewReceptionService(mockStorage, mockProducts, nil)
+	require.NoError(t, err)
+
+	// act
+	details, err := service.GetReception(ctx, receptionID)
+
+	// assert
+	require.NoError(t, err)
+	require.NotNil(t, details.Progress)
+	require.Equal(t, []dto.ManifestProgress{
+		{Type: dto.ProductTypeClothes, Expected: 3, Received: 1, Remaining: 2},
+		{Type: dto.ProductTypeShoes, Expected: 1, Received: 2, Remaining: 0},
+	}, *details.Progress)
+}
+
+func TestReceptionService_GetReceptionMissingBarcodes(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	receptionID := openapi_types.UUID{1}
+	barcode := "111"
+	reception := &dto.Reception{
+		Id:       receptionID,
+		Status:   dto.InProgress,
+		Manifest: &dto.ReceptionManifest{Barcodes: []string{"111", "222"}},
+	}
+	mockStorage := new(mockReceptionStorage)
+	mockStorage.On("GetReception", ctx, receptionID).Return(reception, nil)
+	mockProducts := new(mockProductStorage)
+	mockProducts.On("GetReceptionProducts", ctx, receptionID).Return([]dto.Product{
+		{Type: dto.ProductTypeClothes, Barcode: &barcode},
+	})
+	service, err := NewReceptionService(mockStorage, mockProducts, nil)
+	require.NoError(t, err)
+
+	// act
+	details, err := service.GetReception(ctx, receptionID)
+
+	// assert
+	require.NoError(t, err)
+	require.Nil(t, details.Progress)
+	require.NotNil(t, details.MissingBarcodes)
+	require.Equal(t, []string{"222"}, *details.MissingBarcodes)
+}
+
+func TestReceptionService_DeleteProduct(t *testing.T) {
+	ctx := context.Background()
+	receptionID := openapi_types.UUID{1}
+	productID := openapi_types.UUID{2}
+	userID := &openapi_types.UUID{3}
+
+	testcases := []struct {
+		name      string
+		mockSetup func(*mockReceptionStorage, *mockProductStorage)
+		err       error
+	}{
+		{
+			name: "success",
+			mockSetup: func(m *mockReceptionStorage, p *mockProductStorage) {
+				m.On("GetReception", ctx, receptionID).Return(&dto.Reception{Id: receptionID, Status: dto.InProgress}, nil)
+				p.On("DeleteReceptionProduct", ctx, receptionID, productID, userID).Return(true, nil)
+			},
+			err: nil,
+		},
+		{
+			name: "reception not found",
+			mockSetup: func(m *mockReceptionStorage, p *mockProductStorage) {
+				m.On("GetReception", ctx, receptionID).Return((*dto.Reception)(nil), nil)
+			},
+			err: ErrReceptionNotFound,
+		},
+		{
+			name: "reception is closed",
+			mockSetup: func(m *mockReceptionStorage, p *mockProductStorage) {
+				m.On("GetReception", ctx, receptionID).Return(&dto.Reception{Id: receptionID, Status: dto.Close}, nil)
+			},
+			err: ErrReceptionNotInProgress,
+		},
+		{
+			name: "product not in reception",
+			mockSetup: func(m *mockReceptionStorage, p *mockProductStorage) {
+				m.On("GetReception", ctx, receptionID).Return(&dto.Reception{Id: receptionID, Status: dto.InProgress}, nil)
+				p.On("DeleteReceptionProduct", ctx, receptionID, productID, userID).Return(false, nil)
+			},
+			err: ErrProductNotFound,
+		},
+		{
+			name: "storage error",
+			mockSetup: func(m *mockReceptionStorage, p *mockProductStorage) {
+				m.On("GetReception", ctx, receptionID).Return(&dto.Reception{Id: receptionID, Status: dto.InProgress}, nil)
+				p.On("DeleteReceptionProduct", ctx, receptionID, productID, userID).Return(false, errors.New("storage error"))
+			},
+			err: ErrDeleteProduct,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			mockStorage := new(mockReceptionStorage)
+			mockProducts := new(mockProductStorage)
+			testcase.mockSetup(mockStorage, mockProducts)
+			service, err := NewReceptionService(mockStorage, mockProducts, nil)
+			require.NoError(t, err)
+
+			// act
+			err = service.DeleteProduct(ctx, receptionID, productID, userID)
+
+			// assert
+			require.Equal(t, testcase.err, err)
+			mockStorage.AssertExpectations(t)
+			mockProducts.AssertExpectations(t)
 		})
 	}
 }
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+var ErrSyncBatch = errors.New("failed to process sync batch")
+var ErrSyncItemPayload = errors.New("sync item has no payload for its kind")
+var ErrSyncPVZForbidden = errors.New("only moderator can create pvz")
+
+type SyncStorager interface {
+	// InBatch reserves batch id and runs apply in one transaction, result returned by apply
+	// is saved with the batch. Concurrent upload of the same batch waits for the transaction
+	// and gets the result stored by it, apply is not called for already processed batch
+	InBatch(
+		ctx context.Context,
+		batchID openapi_types.UUID,
+		apply func(ctx context.Context, tx SyncTx) (*dto.SyncBatchResult, error),
+	) (*dto.SyncBatchResult, error)
+}
+
+// SyncTx changes data inside transaction of sync batch
+type SyncTx interface {
+	// Item runs changes of one item, they are rolled back alone when apply returns an error
+	Item(ctx context.Context, apply func() error) error
+	InsertPVZ(ctx context.Context, pvz dto.PVZ) (bool, error)
+	InsertReception(ctx context.Context, reception dto.Reception) (bool, error)
+	HasReception(ctx context.Context, receptionID openapi_types.UUID) (bool, error)
+	// GetActiveReception locks pvz until the end of transaction, so live requests can not
+	// open or close its receptions meanwhile, and returns in progress reception or nil
+	GetActiveReception(ctx context.Context, pvzID openapi_types.UUID) (*dto.Reception, error)
+	// CloseReceptionByID returns closed reception with number of its products,
+	// nil when reception is not in progress
+	CloseReceptionByID(
+		ctx context.Context,
+		receptionID openapi_types.UUID,
+		closedAt time.Time,
+		closedBy *openapi_types.UUID,
+		report *dto.DiscrepancyReport,
+	) (*dto.Reception, int, error)
+	// InsertProduct returns pvz of reception product was added to, nil when product already exists
+	InsertProduct(ctx context.Context, product dto.Product) (*openapi_types.UUID, error)
+	DeleteProductByID(ctx context.Context, productID openapi_types.UUID) (bool, error)
+	// UpdateProductStatusByID changes status of product only when it has one of from statuses
+	UpdateProductStatusByID(ctx context.Context, product dto.Product, from []dto.ProductStatus) (bool, error)
+	// GetProductStatus returns current status of product or nil if it does not exist
+	GetProductStatus(ctx context.Context, productID openapi_types.UUID) (*dto.ProductStatus, error)
+}
+
+// rejection is implemented by storage errors which repeat on every retry, e.g. violated constraints.
+// Other storage errors are transient, they fail the whole batch, so node uploads it again
+type rejection interface {
+	Rejected() bool
+}
+
+// rejectedItemError is item breaking rules of the central server, it is rejected the same way on every retry
+type rejectedItemError struct {
+	err error
+}
+
+func (e rejectedItemError) Error() string  { return e.err.Error() }
+func (e rejectedItemError) Unwrap() error  { return e.err }
+func (e rejectedItemError) Rejected() bool { return true }
+
+// SyncService applies batches of changes recorded by offline PVZ nodes
+type SyncService struct {
+	storage SyncStorager
+	metrics *BusinessMetrics
+}
+
+// NewSyncService creates service, metrics may be nil
+func NewSyncService(storage SyncStorager, metrics *BusinessMetrics) (*SyncService, error) {
+	if storage == nil {
+		return nil, ErrNilInConstruct
+	}
+
+	return &SyncService{storage: storage, metrics: metrics}, nil
+}
+
+// ApplyBatch applies items in order and returns result for each of them.
+// Batch is idempotent: repeated upload with the same batch id returns the stored result.
+// Pvz can be created only by batch uploaded with moderator role
+func (s *SyncService) ApplyBatch(ctx context.Context, batch dto.SyncBatch, role dto.UserRole) (*dto.SyncBatchResult, error) {
+	ctx, span := startSpan(ctx, "SyncService.ApplyBatch")
+	defer span.End()
+
+	// metrics are recorded only after changes are committed
+	var recorded []func()
+	result, err := s.storage.InBatch(ctx, batch.BatchId, func(ctx context.Context, tx SyncTx) (*dto.SyncBatchResult, error) {
+		result := dto.SyncBatchResult{
+			BatchId: batch.BatchId,
+			Items:   make([]dto.SyncItemResult, 0, len(batch.Items)),
+		}
+		for _, item := range batch.Items {
+			applier := itemApplier{tx: tx, metrics: s.metrics, role: role}
+			itemResult, err := applier.apply(ctx, item)
+			if err != nil {
+				return nil, err
+			}
+			result.Items = append(result.Items, itemResult)
+			if itemResult.Status != dto.SyncFailed {
+				recorded = append(recorded, applier.recorded...)
+			}
+		}
+		return &result, nil
+	})
+	if err != nil {
+		return nil, ErrSyncBatch
+	}
+
+	for _, record := range recorded {
+		record()
+	}
+
+	return result, nil
+}
+
+// itemApplier applies one item and collects metrics it has to record
+type itemApplier struct {
+	tx       SyncTx
+	metrics  *BusinessMetrics
+	role     dto.UserRole
+	recorded []func()
+}
+
+// apply returns error only when the whole batch has to be retried
+func (a *itemApplier) apply(ctx context.Context, item dto.SyncItem) (dto.SyncItemResult, error) {
+	if err := validateItem(item, a.role); err != nil {
+		return failedItem(item.Id, err), nil
+	}
+
+	var applied bool
+	var message string
+	err := a.tx.Item(ctx, func() error {
+		var err error
+		applied, message, err = a.change(ctx, item)
+		return err
+	})
+	var rejected rejection
+	if errors.As(err, &rejected) && rejected.Rejected() {
+		return failedItem(item.Id, err), nil
+	}
+	if err != nil {
+		return dto.SyncItemResult{}, err
+	}
+
+	result := dto.SyncItemResult{Id: item.Id, Status: dto.SyncApplied}
+	switch {
+	case message != "":
+		result.Status = dto.SyncResolved
+		result.Message = &message
+	case !applied:
+		result.Status = dto.SyncDuplicate
+	}
+
+	return result, nil
+}
+
+func validateItem(item dto.SyncItem, role dto.UserRole) error {
+	switch item.Kind {
+	case dto.KindPvzCreated:
+		if item.Pvz == nil || item.Pvz.Id == nil {
+			return ErrSyncItemPayload
+		}
+		if role != dto.UserRoleModerator {
+			return ErrSyncPVZForbidden
+		}
+	case dto.KindReceptionCreated, dto.KindReceptionClosed:
+		if item.Reception == nil {
+			return ErrSyncItemPayload
+		}
+	case dto.KindProductAdded, dto.KindProductDeleted:
+		if item.Product == nil || item.Product.Id == nil {
+			return ErrSyncItemPayload
+		}
+	case dto.KindProductStatusChanged:
+		if item.Product == nil || item.Product.Id == nil || item.Product.Status == nil {
+			return ErrSyncItemPayload
+		}
+	default:
+		return fmt.Errorf("unknown sync item kind %s", item.Kind)
+	}
+
+	return nil
+}
+
+func (a *itemApplier) change(ctx context.Context, item dto.SyncItem) (bool, string, error) {
+	switch item.Kind {
+	case dto.KindPvzCreated:
+		applied, err := a.tx.InsertPVZ(ctx, *item.Pvz)
+		if applied {
+			pvz := *item.Pvz
+			a.record(func() { a.metrics.pvzCreated(&pvz) })
+		}
+		return applied, "", err
+	case dto.KindReceptionCreated:
+		return a.createReception(ctx, *item.Reception)
+	case dto.KindReceptionClosed:
+		// nodes without reception audit do not send closing time
+		closedAt := time.Now()
+		if item.Reception.ClosedAt != nil {
+			closedAt = *item.Reception.ClosedAt
+		}
+		applied, err := a.closeReception(ctx, item.Reception.Id, closedAt, item.Reception.ClosedBy, item.Reception.DiscrepancyReport)
+		return applied, "", err
+	case dto.KindProductAdded:
+		pvzID, err := a.tx.InsertProduct(ctx, *item.Product)
+		if pvzID != nil {
+			productType := item.Product.Type
+			a.record(func() { a.metrics.productsAdded(ctx, *pvzID, map[dto.ProductType]int{productType: 1}) })
+		}
+		return pvzID != nil, "", err
+	case dto.KindProductDeleted:
+		applied, err := a.tx.DeleteProductByID(ctx, *item.Product.Id)
+		return applied, "", err
+	default:
+		applied, err := a.changeStatus(ctx, *item.Product)
+		return applied, "", err
+	}
+}
+
+// changeStatus moves product along the same lifecycle as live requests do. Product which is missing
+// or already has the status is a duplicate, any other status can not be moved to uploaded one
+func (a *itemApplier) changeStatus(ctx context.Context, product dto.Product) (bool, error) {
+	to := *product.Status
+	applied, err := a.tx.UpdateProductStatusByID(ctx, product, previousStatuses(productTransitions, to))
+	if err != nil || applied {
+		return applied, err
+	}
+
+	current, err := a.tx.GetProductStatus(ctx, *product.Id)
+	if err != nil || current == nil || *current == to {
+		return false, err
+	}
+
+	return false, rejectedItemError{err: fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, *current, to)}
+}
+
+// createReception keeps the rule "one in progress reception per PVZ" on the central server.
+// When uploaded reception clashes with another in progress one, the most recently opened
+// reception stays in progress and the other one is closed.
+func (a *itemApplier) createReception(ctx context.Context, reception dto.Reception) (bool, string, error) {
+	// replayed reception must not close anything again
+	exists, err := a.tx.HasReception(ctx, reception.Id)
+	if err != nil || exists {
+		return false, "", err
+	}
+
+	var message string
+	if reception.Status == dto.InProgress {
+		active, err := a.tx.GetActiveReception(ctx, reception.PvzId)
+		if err != nil {
+			return false, "", err
+		}
+
+		if active != nil && active.Id != reception.Id {
+			if active.DateTime.After(reception.DateTime) {
+				closedAt := time.Now()
+				reception.Status = dto.Close
+				reception.ClosedAt = &closedAt
+				message = fmt.Sprintf("uploaded reception closed, newer reception %s is in progress", active.Id)
+			} else {
+				if _, err := a.closeReception(ctx, active.Id, time.Now(), nil, nil); err != nil {
+					return false, "", err
+				}
+				message = fmt.Sprintf("reception %s closed in favour of uploaded reception", active.Id)
+			}
+		}
+	}
+
+	applied, err := a.tx.InsertReception(ctx, reception)
+	if err != nil || !applied {
+		return applied, "", err
+	}
+	a.record(func() {
+		if reception.Status == dto.InProgress {
+			a.metrics.receptionOpened(ctx, reception.PvzId)
+		} else {
+			a.metrics.receptionCreatedClosed(ctx, reception.PvzId)
+		}
+	})
+
+	return applied, message, nil
+}
+
+func (a *itemApplier) closeReception(
+	ctx context.Context,
+	receptionID openapi_types.UUID,
+	closedAt time.Time,
+	closedBy *openapi_types.UUID,
+	report *dto.DiscrepancyReport,
+) (bool, error) {
+	closed, products, err := a.tx.CloseReceptionByID(ctx, receptionID, closedAt, closedBy, report)
+	if err != nil || closed == nil {
+		return false, err
+	}
+	a.record(func() { a.metrics.receptionClosed(ctx, closed, products) })
+
+	return true, nil
+}
+
+func (a *itemApplier) record(fn func()) {
+	a.recorded = append(a.recorded, fn)
+}
+
+func failedItem(id openapi_types.UUID, err error) dto.SyncItemResult {
+	message := err.Error()
+	return dto.SyncItemResult{Id: id, Status: dto.SyncFailed, Message: &message}
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSyncStorage struct {
+	mock.Mock
+	tx *mockSyncTx
+}
+
+// InBatch returns stored result or error set up for batch, otherwise applies batch in mocked transaction
+func (m *mockSyncStorage) InBatch(
+	ctx context.Context,
+	batchID openapi_types.UUID,
+	apply func(ctx context.Context, tx SyncTx) (*dto.SyncBatchResult, error),
+) (*dto.SyncBatchResult, error) {
+	args := m.Called(ctx, batchID)
+	if stored := args.Get(0).(*dto.SyncBatchResult); stored != nil || args.Error(1) != nil {
+		return stored, args.Error(1)
+	}
+
+	return apply(ctx, m.tx)
+}
+
+type mockSyncTx struct {
+	mock.Mock
+}
+
+func (m *mockSyncTx) Item(_ context.Context, apply func() error) error {
+	return apply()
+}
+
+// rejectedErr is storage error which repeats on retry
+type rejectedErr struct{}
+
+func (rejectedErr) Error() string  { return "rejected" }
+func (rejectedErr) Rejected() bool { return true }
+
+func (m *mockSyncTx) InsertPVZ(ctx context.Context, pvz dto.PVZ) (bool, error) {
+	args := m.Called(ctx, pvz)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockSyncTx) InsertReception(ctx context.Context, reception dto.Reception) (bool, error) {
+	args := m.Called(ctx, reception)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockSyncTx) HasReception(ctx context.Context, receptionID openapi_types.UUID) (bool, error) {
+	args := m.Called(ctx, receptionID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockSyncTx) GetActiveReception(ctx context.Context, pvzID openapi_types.UUID) (*dto.Reception, error) {
+	args := m.Called(ctx, pvzID)
+	return args.Get(0).(*dto.Reception), args.Error(1)
+}
+
+func (m *mockSyncTx) CloseReceptionByID(
+	ctx context.Context,
+	receptionID openapi_types.UUID,
+	closedAt time.Time,
+	closedBy *openapi_types.UUID,
+	report *dto.DiscrepancyReport,
+) (*dto.Reception, int, error) {
+	args := m.Called(ctx, receptionID, closedAt, closedBy, report)
+	return args.Get(0).(*dto.Reception), args.Int(1), args.Error(2)
+}
+
+func (m *mockSyncTx) InsertProduct(ctx context.Context, product dto.Product) (*openapi_types.UUID, error) {
+	args := m.Called(ctx, product)
+	return args.Get(0).(*openapi_types.UUID), args.Error(1)
+}
+
+func (m *mockSyncTx) UpdateProductStatusByID(ctx context.Context, product dto.Product, from []dto.ProductStatus) (bool, error) {
+	args := m.Called(ctx, product, from)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockSyncTx) GetProductStatus(ctx context.Context, productID openapi_types.UUID) (*dto.ProductStatus, error) {
+	args := m.Called(ctx, productID)
+	return args.Get(0).(*dto.ProductStatus), args.Error(1)
+}
+
+func (m *mockSyncTx) DeleteProductByID(ctx context.Context, productID openapi_types.UUID) (bool, error) {
+	args := m.Called(ctx, productID)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestNewSyncService(t *testing.T) {
+	service, err := NewSyncService(nil, nil)
+	require.ErrorIs(t, err, ErrNilInConstruct)
+	require.Nil(t, service)
+
+	service, err = NewSyncService(new(mockSyncStorage), nil)
+	require.NoError(t, err)
+	require.NotNil(t, service)
+}
+
+func TestSyncService_ApplyBatch(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	pvzID := uuid.New()
+	activeID := uuid.New()
+	productID := uuid.New()
+
+	uploaded := dto.Reception{Id: uuid.New(), PvzId: pvzID, DateTime: now, Status: dto.InProgress}
+	product := dto.Product{Id: &productID, ReceptionId: uploaded.Id, Type: dto.ProductTypeShoes}
+	receptionItem := dto.SyncItem{Id: uuid.New(), Kind: dto.KindReceptionCreated, Reception: &uploaded}
+	productItem := dto.SyncItem{Id: uuid.New(), Kind: dto.KindProductAdded, Product: &product}
+	batch := dto.SyncBatch{BatchId: uuid.New(), Items: []dto.SyncItem{receptionItem, productItem}}
+
+	pvz := dto.PVZ{Id: &pvzID, City: dto.Moscow}
+	pvzItem := dto.SyncItem{Id: uuid.New(), Kind: dto.KindPvzCreated, Pvz: &pvz}
+	pvzBatch := dto.SyncBatch{BatchId: uuid.New(), Items: []dto.SyncItem{pvzItem}}
+
+	received, stored, issued := dto.ProductReceived, dto.ProductStored, dto.ProductIssued
+	storedProduct := dto.Product{Id: &productID, Status: &stored}
+	revivedProduct := dto.Product{Id: &productID, Status: &received}
+	statusBatch := dto.SyncBatch{BatchId: uuid.New(), Items: []dto.SyncItem{
+		{Id: uuid.New(), Kind: dto.KindProductStatusChanged, Product: &storedProduct},
+		{Id: uuid.New(), Kind: dto.KindProductStatusChanged, Product: &revivedProduct},
+	}}
+
+	testcases := []struct {
+		name      string
+		batch     dto.SyncBatch
+		role      dto.UserRole
+		mockSetup func(*mockSyncStorage, *mockSyncTx)
+		expected  []dto.SyncItemResultStatus
+		err       error
+	}{
+		{
+			name:  "replayed batch returns stored result",
+			batch: batch,
+			role:  dto.UserRoleEmployee,
+			mockSetup: func(m *mockSyncStorage, _ *mockSyncTx) {
+				m.On("InBatch", ctx, batch.BatchId).Return(&dto.SyncBatchResult{
+					BatchId: batch.BatchId,
+					Items:   []dto.SyncItemResult{{Id: receptionItem.Id, Status: dto.SyncApplied}},
+				}, nil)
+			},
+			expected: []dto.SyncItemResultStatus{dto.SyncApplied},
+		},
+		{
+			name:  "items applied without conflict",
+			batch: batch,
+			role:  dto.UserRoleEmployee,
+			mockSetup: func(m *mockSyncStorage, tx *mockSyncTx) {
+				m.On("InBatch", ctx, batch.BatchId).Return((*dto.SyncBatchResult)(nil), nil)
+				tx.On("HasReception", ctx, uploaded.Id).Return(false, nil)
+				tx.On("GetActiveReception", ctx, pvzID).Return((*dto.Reception)(nil), nil)
+				tx.On("InsertReception", ctx, uploaded).Return(true, nil)
+				tx.On("InsertProduct", ctx, product).Return((*openapi_types.UUID)(nil), nil)
+			},
+			expected: []dto.SyncItemResultStatus{dto.SyncApplied, dto.SyncDuplicate},
+		},
+		{
+			name:  "older central reception is closed",
+			batch: batch,
+			role:  dto.UserRoleEmployee,
+			mockSetup: func(m *mockSyncStorage, tx *mockSyncTx) {
+				m.On("InBatch", ctx, batch.BatchId).Return((*dto.SyncBatchResult)(nil), nil)
+				tx.On("HasReception", ctx, uploaded.Id).Return(false, nil)
+				tx.On("GetActiveReception", ctx, pvzID).Return(&dto.Reception{
+					Id: activeID, PvzId: pvzID, DateTime: now.Add(-time.Hour), Status: dto.InProgress,
+				}, nil)
+				tx.On("CloseReceptionByID", ctx, activeID, mock.Anything, (*openapi_types.UUID)(nil), (*dto.DiscrepancyReport)(nil)).
+					Return(&dto.Reception{Id: activeID, PvzId: pvzID, Status: dto.Close}, 0, nil)
+				tx.On("InsertReception", ctx, uploaded).Return(true, nil)
+				tx.On("InsertProduct", ctx, product).Return(&pvzID, nil)
+			},
+			expected: []dto.SyncItemResultStatus{dto.SyncResolved, dto.SyncApplied},
+		},
+		{
+			name:  "older uploaded reception is stored closed",
+			batch: batch,
+			role:  dto.UserRoleEmployee,
+			mockSetup: func(m *mockSyncStorage, tx *mockSyncTx) {
+				closed := mock.MatchedBy(func(r dto.Reception) bool {
+					return r.Id == uploaded.Id && r.Status == dto.Close && r.ClosedAt != nil
+				})
+				m.On("InBatch", ctx, batch.BatchId).Return((*dto.SyncBatchResult)(nil), nil)
+				tx.On("HasReception", ctx, uploaded.Id).Return(false, nil)
+				tx.On("GetActiveReception", ctx, pvzID).Return(&dto.Reception{
+					Id: activeID, PvzId: pvzID, DateTime: now.Add(time.Hour), Status: dto.InProgress,
+				}, nil)
+				tx.On("InsertReception", ctx, closed).Return(true, nil)
+				tx.On("InsertProduct", ctx, product).Return(&pvzID, nil)
+			},
+			expected: []dto.SyncItemResultStatus{dto.SyncResolved, dto.SyncApplied},
+		},
+		{
+			name:  "rejected item does not stop batch",
+			batch: batch,
+			role:  dto.UserRoleEmployee,
+			mockSetup: func(m *mockSyncStorage, tx *mockSyncTx) {
+				m.On("InBatch", ctx, batch.BatchId).Return((*dto.SyncBatchResult)(nil), nil)
+				tx.On("HasReception", ctx, uploaded.Id).Return(false, rejectedErr{})
+				tx.On("InsertProduct", ctx, product).Return(&pvzID, nil)
+			},
+			expected: []dto.SyncItemResultStatus{dto.SyncFailed, dto.SyncApplied},
+		},
+		{
+			name:  "transient error fails whole batch",
+			batch: batch,
+			role:  dto.UserRoleEmployee,
+			mockSetup: func(m *mockSyncStorage, tx *mockSyncTx) {
+				m.On("InBatch", ctx, batch.BatchId).Return((*dto.SyncBatchResult)(nil), nil)
+				tx.On("HasReception", ctx, uploaded.Id).Return(false, errors.New("error"))
+			},
+			err: ErrSyncBatch,
+		},
+		{
+			name:  "item without payload",
+			batch: dto.SyncBatch{BatchId: batch.BatchId, Items: []dto.SyncItem{{Id: uuid.New(), Kind: dto.KindProductDeleted}}},
+			role:  dto.UserRoleEmployee,
+			mockSetup: func(m *mockSyncStorage, _ *mockSyncTx) {
+				m.On("InBatch", ctx, batch.BatchId).Return((*dto.SyncBatchResult)(nil), nil)
+			},
+			expected: []dto.SyncItemResultStatus{dto.SyncFailed},
+		},
+		{
+			name:  "pvz is not created by employee",
+			batch: pvzBatch,
+			role:  dto.UserRoleEmployee,
+			mockSetup: func(m *mockSyncStorage, _ *mockSyncTx) {
+				m.On("InBatch", ctx, pvzBatch.BatchId).Return((*dto.SyncBatchResult)(nil), nil)
+			},
+			expected: []dto.SyncItemResultStatus{dto.SyncFailed},
+		},
+		{
+			name:  "pvz is created by moderator",
+			batch: pvzBatch,
+			role:  dto.UserRoleModerator,
+			mockSetup: func(m *mockSyncStorage, tx *mockSyncTx) {
+				m.On("InBatch", ctx, pvzBatch.BatchId).Return((*dto.SyncBatchResult)(nil), nil)
+				tx.On("InsertPVZ", ctx, pvz).Return(true, nil)
+			},
+			expected: []dto.SyncItemResultStatus{dto.SyncApplied},
+		},
+		{
+			name:  "status changes follow product lifecycle",
+			batch: statusBatch,
+			role:  dto.UserRoleEmployee,
+			mockSetup: func(m *mockSyncStorage, tx *mockSyncTx) {
+				m.On("InBatch", ctx, statusBatch.BatchId).Return((*dto.SyncBatchResult)(nil), nil)
+				tx.On("UpdateProductStatusByID", ctx, storedProduct, []dto.ProductStatus{dto.ProductReceived}).Return(true, nil)
+				// issued product can not be received again
+				tx.On("UpdateProductStatusByID", ctx, revivedProduct, []dto.ProductStatus{}).Return(false, nil)
+				tx.On("GetProductStatus", ctx, productID).Return(&issued, nil)
+			},
+			expected: []dto.SyncItemResultStatus{dto.SyncApplied, dto.SyncFailed},
+		},
+		{
+			name:  "repeated status change is duplicate",
+			batch: dto.SyncBatch{BatchId: statusBatch.BatchId, Items: statusBatch.Items[:1]},
+			role:  dto.UserRoleEmployee,
+			mockSetup: func(m *mockSyncStorage, tx *mockSyncTx) {
+				m.On("InBatch", ctx, statusBatch.BatchId).Return((*dto.SyncBatchResult)(nil), nil)
+				tx.On("UpdateProductStatusByID", ctx, storedProduct, []dto.ProductStatus{dto.ProductReceived}).Return(false, nil)
+				tx.On("GetProductStatus", ctx, productID).Return(&stored, nil)
+			},
+			expected: []dto.SyncItemResultStatus{dto.SyncDuplicate},
+		},
+		{
+			name:  "storage error of batch transaction",
+			batch: dto.SyncBatch{BatchId: batch.BatchId},
+			role:  dto.UserRoleEmployee,
+			mockSetup: func(m *mockSyncStorage, _ *mockSyncTx) {
+				m.On("InBatch", ctx, batch.BatchId).Return((*dto.SyncBatchResult)(nil), errors.New("error"))
+			},
+			err: ErrSyncBatch,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			tx := new(mockSyncTx)
+			storage := &mockSyncStorage{tx: tx}
+			testcase.mockSetup(storage, tx)
+			service, err := NewSyncService(storage, nil)
+			require.NoError(t, err)
+
+			// act
+			result, err := service.ApplyBatch(ctx, testcase.batch, testcase.role)
+
+			// assert
+			require.ErrorIs(t, err, testcase.err)
+			if testcase.err == nil {
+				statuses := make([]dto.SyncItemResultStatus, 0, len(result.Items))
+				for _, item := range result.Items {
+					statuses = append(statuses, item.Status)
+				}
+				require.Equal(t, testcase.expected, statuses)
+			}
+			storage.AssertExpectations(t)
+			tx.AssertExpectations(t)
+		})
+	}
+}
@@ -16,10 +16,12 @@ type JWTClaims struct {
 	Role string `json:"role"`
 }
 
-func NewJWTClaims(role string, duration time.Duration) *JWTClaims {
+// NewJWTClaims creates claims for role, subject is user id and is empty for dummy login
+func NewJWTClaims(role, subject string, duration time.Duration) *JWTClaims {
 	now := time.Now()
 	return &JWTClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
 		},
@@ -40,8 +42,8 @@ func NewTokenService(jwtSecret []byte, jwtDuration time.Duration) (*TokenService
 	return &TokenService{jwtSecret: jwtSecret, jwtDuration: jwtDuration}, nil
 }
 
-func (s *TokenService) Gen(role string) (dto.Token, error) {
-	claims := NewJWTClaims(role, s.jwtDuration)
+func (s *TokenService) Gen(role, userID string) (dto.Token, error) {
+	claims := NewJWTClaims(role, userID, s.jwtDuration)
 	token, err := auth.CreateJWT(s.jwtSecret, claims)
 	if err != nil {
 		return "", ErrTokenCreation
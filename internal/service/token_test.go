@@ -10,6 +10,7 @@ import (
 func TestNewJWTClaims(t *testing.T) {
 	testcases := []struct {
 		role     string
+		subject  string
 		duration time.Duration
 	}{
 		{
@@ -18,16 +19,18 @@ func TestNewJWTClaims(t *testing.T) {
 		},
 		{
 			role:     "employee",
+			subject:  "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
 			duration: 24 * time.Hour,
 		},
 	}
 
 	for _, testcase := range testcases {
 		t.Run(testcase.role, func(t *testing.T) {
-			claims := NewJWTClaims(testcase.role, testcase.duration)
+			claims := NewJWTClaims(testcase.role, testcase.subject, testcase.duration)
 			duration := claims.ExpiresAt.Sub(claims.IssuedAt.Time)
 
 			require.Equal(t, testcase.role, claims.Role)
+			require.Equal(t, testcase.subject, claims.Subject)
 			require.Equal(t, testcase.duration, duration)
 		})
 	}
@@ -73,6 +76,9 @@ func TestGen(t *testing.T) {
 	tokenService, err := NewTokenService([]byte("secret"), time.Hour)
 	require.NoError(t, err)
 
-	_, err = tokenService.Gen("role")
+	_, err = tokenService.Gen("role", "")
+	require.NoError(t, err)
+
+	_, err = tokenService.Gen("role", "6ba7b810-9dad-11d1-80b4-00c04fd430c8")
 	require.NoError(t, err)
 }
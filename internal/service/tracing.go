@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/Arzeeq/pvz-api/internal/service")
+
+// startSpan starts span of service method. Context is left untouched when span
+// is not recorded, e.g. when tracing is disabled
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	spanCtx, span := tracer.Start(ctx, name)
+	if !span.IsRecording() {
+		return ctx, span
+	}
+
+	return spanCtx, span
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+var (
+	ErrInvalidTransfer             = errors.New("transfer must move distinct products between different pvz")
+	ErrTransferCreate              = errors.New("failed to create transfer")
+	ErrTransferGet                 = errors.New("failed to get transfer")
+	ErrTransferNotFound            = errors.New("transfer not found")
+	ErrTransferStatus              = errors.New("transfer is not in required status")
+	ErrTransferShip                = errors.New("failed to ship transfer")
+	ErrTransferReceive             = errors.New("failed to receive transfer")
+	ErrTransferProductsUnavailable = errors.New("products must be kept in closed receptions of pvz and not be transferred already")
+	ErrTransferBarcodeConflict     = errors.New("barcodes of transfer products clash with reception of destination pvz")
+)
+
+type TransferStorager interface {
+	GetTransferableProducts(ctx context.Context, pvzID openapi_types.UUID, productIDs []openapi_types.UUID) ([]dto.Product, error)
+	GetTransferProducts(ctx context.Context, transferID openapi_types.UUID) ([]dto.Product, error)
+	CreateTransfer(ctx context.Context, payload dto.PostTransfersJSONBody, createdBy *openapi_types.UUID) (*dto.Transfer, error)
+	GetTransfer(ctx context.Context, transferID openapi_types.UUID) (*dto.Transfer, error)
+	GetPVZTransfers(ctx context.Context, pvzID openapi_types.UUID, params dto.GetPvzPvzIdTransfersParams) ([]dto.Transfer, error)
+	ShipTransfer(ctx context.Context, transferID openapi_types.UUID, shippedBy *openapi_types.UUID) (*dto.Transfer, error)
+	// ReceiveTransfer copies products into in progress reception of destination pvz and returns
+	// reception opened for them, nil when pvz already had one. Returns nil transfer when it is not
+	// in transit. When barcodes clash, nothing is received and transfer is returned in transit
+	// with clashing products set in ConflictProductId of items
+	ReceiveTransfer(
+		ctx context.Context,
+		transferID openapi_types.UUID,
+		receivedBy *openapi_types.UUID,
+	) (*dto.Transfer, *dto.Reception, error)
+	GetProductPath(ctx context.Context, productID openapi_types.UUID) ([]dto.ProductPathStep, error)
+}
+
+type TransferService struct {
+	storage TransferStorager
+	metrics *BusinessMetrics
+}
+
+// NewTransferService creates service, metrics may be nil
+func NewTransferService(storage TransferStorager, metrics *BusinessMetrics) (*TransferService, error) {
+	if storage == nil {
+		return nil, ErrNilInConstruct
+	}
+
+	return &TransferService{storage: storage, metrics: metrics}, nil
+}
+
+// CreateTransfer selects products of closed receptions at source pvz for moving to another pvz
+func (s *TransferService) CreateTransfer(
+	ctx context.Context,
+	payload dto.PostTransfersJSONBody,
+	userID *openapi_types.UUID,
+) (*dto.Transfer, error) {
+	ctx, span := startSpan(ctx, "TransferService.CreateTransfer")
+	defer span.End()
+
+	if payload.FromPvzId == payload.ToPvzId || len(payload.ProductIds) == 0 {
+		return nil, ErrInvalidTransfer
+	}
+	seen := make(map[openapi_types.UUID]struct{}, len(payload.ProductIds))
+	for _, id := range payload.ProductIds {
+		if _, ok := seen[id]; ok {
+			return nil, ErrInvalidTransfer
+		}
+		seen[id] = struct{}{}
+	}
+
+	products, err := s.storage.GetTransferableProducts(ctx, payload.FromPvzId, payload.ProductIds)
+	if err != nil {
+		return nil, ErrTransferGet
+	}
+	if len(products) != len(payload.ProductIds) {
+		return nil, ErrTransferProductsUnavailable
+	}
+
+	transfer, err := s.storage.CreateTransfer(ctx, payload, userID)
+	if err != nil {
+		return nil, ErrTransferCreate
+	}
+
+	return transfer, nil
+}
+
+func (s *TransferService) GetTransfer(ctx context.Context, transferID openapi_types.UUID) (*dto.Transfer, error) {
+	ctx, span := startSpan(ctx, "TransferService.GetTransfer")
+	defer span.End()
+
+	transfer, err := s.storage.GetTransfer(ctx, transferID)
+	if err != nil {
+		return nil, ErrTransferGet
+	}
+	if transfer == nil {
+		return nil, ErrTransferNotFound
+	}
+
+	return transfer, nil
+}
+
+func (s *TransferService) GetPVZTransfers(
+	ctx context.Context,
+	pvzID openapi_types.UUID,
+	params dto.GetPvzPvzIdTransfersParams,
+) ([]dto.Transfer, error) {
+	ctx, span := startSpan(ctx, "TransferService.GetPVZTransfers")
+	defer span.End()
+
+	transfers, err := s.storage.GetPVZTransfers(ctx, pvzID, params)
+	if err != nil {
+		return nil, ErrTransferGet
+	}
+
+	return transfers, nil
+}
+
+// ShipTransfer sends created transfer, products leave source pvz with status transferred
+func (s *TransferService) ShipTransfer(ctx context.Context, transferID openapi_types.UUID, userID *openapi_types.UUID) (*dto.Transfer, error) {
+	ctx, span := startSpan(ctx, "TransferService.ShipTransfer")
+	defer span.End()
+
+	transfer, err := s.GetTransfer(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Status != dto.TransferCreated {
+		return nil, ErrTransferStatus
+	}
+
+	products, err := s.storage.GetTransferProducts(ctx, transferID)
+	if err != nil {
+		return nil, ErrTransferGet
+	}
+	for _, product := range products {
+		if !onShelf(product) {
+			return nil, ErrTransferProductsUnavailable
+		}
+	}
+
+	shipped, err := s.storage.ShipTransfer(ctx, transferID, userID)
+	if err != nil {
+		return nil, ErrTransferShip
+	}
+	// transfer was shipped by concurrent request
+	if shipped == nil {
+		return nil, ErrTransferStatus
+	}
+
+	return shipped, nil
+}
+
+// ReceiveTransfer accepts transfer in transit into active reception of destination pvz,
+// reception is opened when pvz has none. When barcodes of products clash with products
+// of reception, transfer is returned with ErrTransferBarcodeConflict and clashing items marked
+func (s *TransferService) ReceiveTransfer(ctx context.Context, transferID openapi_types.UUID, userID *openapi_types.UUID) (*dto.Transfer, error) {
+	ctx, span := startSpan(ctx, "TransferService.ReceiveTransfer")
+	defer span.End()
+
+	transfer, err := s.GetTransfer(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Status != dto.TransferInTransit {
+		return nil, ErrTransferStatus
+	}
+
+	received, opened, err := s.storage.ReceiveTransfer(ctx, transferID, userID)
+	if err != nil {
+		return nil, ErrTransferReceive
+	}
+	// transfer was received by concurrent request
+	if received == nil {
+		return nil, ErrTransferStatus
+	}
+	if received.Status != dto.TransferReceived {
+		return received, ErrTransferBarcodeConflict
+	}
+	if opened != nil {
+		s.metrics.receptionOpened(ctx, opened.PvzId)
+	}
+	s.metrics.transferReceived(ctx, received)
+
+	return received, nil
+}
+
+// GetProductPath returns product at every pvz it passed through
+func (s *TransferService) GetProductPath(ctx context.Context, productID openapi_types.UUID) ([]dto.ProductPathStep, error) {
+	ctx, span := startSpan(ctx, "TransferService.GetProductPath")
+	defer span.End()
+
+	path, err := s.storage.GetProductPath(ctx, productID)
+	if err != nil {
+		return nil, ErrProductGet
+	}
+	if len(path) == 0 {
+		return nil, ErrProductNotFound
+	}
+
+	return path, nil
+}
@@ -0,0 +1,420 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Arzeeq/pvz-api/internal/metrics"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTransferStorage struct {
+	mock.Mock
+}
+
+func (m *mockTransferStorage) GetTransferableProducts(
+	ctx context.Context,
+	pvzID openapi_types.UUID,
+	productIDs []openapi_types.UUID,
+) ([]dto.Product, error) {
+	args := m.Called(ctx, pvzID, productIDs)
+	return args.Get(0).([]dto.Product), args.Error(1)
+}
+
+func (m *mockTransferStorage) GetTransferProducts(ctx context.Context, transferID openapi_types.UUID) ([]dto.Product, error) {
+	args := m.Called(ctx, transferID)
+	return args.Get(0).([]dto.Product), args.Error(1)
+}
+
+func (m *mockTransferStorage) CreateTransfer(ctx context.Context, payload dto.PostTransfersJSONBody, createdBy *openapi_types.UUID) (*dto.Transfer, error) {
+	args := m.Called(ctx, payload, createdBy)
+	return args.Get(0).(*dto.Transfer), args.Error(1)
+}
+
+func (m *mockTransferStorage) GetTransfer(ctx context.Context, transferID openapi_types.UUID) (*dto.Transfer, error) {
+	args := m.Called(ctx, transferID)
+	return args.Get(0).(*dto.Transfer), args.Error(1)
+}
+
+func (m *mockTransferStorage) GetPVZTransfers(
+	ctx context.Context,
+	pvzID openapi_types.UUID,
+	params dto.GetPvzPvzIdTransfersParams,
+) ([]dto.Transfer, error) {
+	args := m.Called(ctx, pvzID, params)
+	return args.Get(0).([]dto.Transfer), args.Error(1)
+}
+
+func (m *mockTransferStorage) ShipTransfer(ctx context.Context, transferID openapi_types.UUID, shippedBy *openapi_types.UUID) (*dto.Transfer, error) {
+	args := m.Called(ctx, transferID, shippedBy)
+	return args.Get(0).(*dto.Transfer), args.Error(1)
+}
+
+func (m *mockTransferStorage) ReceiveTransfer(
+	ctx context.Context,
+	transferID openapi_types.UUID,
+	receivedBy *openapi_types.UUID,
+) (*dto.Transfer, *dto.Reception, error) {
+	args := m.Called(ctx, transferID, receivedBy)
+	return args.Get(0).(*dto.Transfer), args.Get(1).(*dto.Reception), args.Error(2)
+}
+
+func (m *mockTransferStorage) GetProductPath(ctx context.Context, productID openapi_types.UUID) ([]dto.ProductPathStep, error) {
+	args := m.Called(ctx, productID)
+	return args.Get(0).([]dto.ProductPathStep), args.Error(1)
+}
+
+func TestNewTransferService(t *testing.T) {
+	service, err := NewTransferService(nil, nil)
+	require.ErrorIs(t, err, ErrNilInConstruct)
+	require.Nil(t, service)
+
+	service, err = NewTransferService(new(mockTransferStorage), nil)
+	require.NoError(t, err)
+	require.NotNil(t, service)
+}
+
+func TestTransferService_CreateTransfer(t *testing.T) {
+	ctx := context.Background()
+	from, to := openapi_types.UUID{1}, openapi_types.UUID{2}
+	first, second := openapi_types.UUID{3}, openapi_types.UUID{4}
+	payload := dto.PostTransfersJSONBody{FromPvzId: from, ToPvzId: to, ProductIds: []openapi_types.UUID{first, second}}
+	transfer := &dto.Transfer{Id: openapi_types.UUID{5}, FromPvzId: from, ToPvzId: to, Status: dto.TransferCreated}
+
+	testcases := []struct {
+		name      string
+		payload   dto.PostTransfersJSONBody
+		mockSetup func(*mockTransferStorage)
+		expected  *dto.Transfer
+		err       error
+	}{
+		{
+			name:    "transfer created",
+			payload: payload,
+			mockSetup: func(m *mockTransferStorage) {
+				m.On("GetTransferableProducts", ctx, from, payload.ProductIds).Return(storedProducts(first, second), nil)
+				m.On("CreateTransfer", ctx, payload, (*openapi_types.UUID)(nil)).Return(transfer, nil)
+			},
+			expected: transfer,
+		},
+		{
+			name:      "same pvz",
+			payload:   dto.PostTransfersJSONBody{FromPvzId: from, ToPvzId: from, ProductIds: []openapi_types.UUID{first}},
+			mockSetup: func(m *mockTransferStorage) {},
+			err:       ErrInvalidTransfer,
+		},
+		{
+			name:      "duplicate products",
+			payload:   dto.PostTransfersJSONBody{FromPvzId: from, ToPvzId: to, ProductIds: []openapi_types.UUID{first, first}},
+			mockSetup: func(m *mockTransferStorage) {},
+			err:       ErrInvalidTransfer,
+		},
+		{
+			name:    "product is not transferable",
+			payload: payload,
+			mockSetup: func(m *mockTransferStorage) {
+				m.On("GetTransferableProducts", ctx, from, payload.ProductIds).Return(storedProducts(first), nil)
+			},
+			err: ErrTransferProductsUnavailable,
+		},
+		{
+			name:    "storage error",
+			payload: payload,
+			mockSetup: func(m *mockTransferStorage) {
+				m.On("GetTransferableProducts", ctx, from, payload.ProductIds).Return(storedProducts(first, second), nil)
+				m.On("CreateTransfer", ctx, payload, (*openapi_types.UUID)(nil)).Return((*dto.Transfer)(nil), errors.New("error"))
+			},
+			err: ErrTransferCreate,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			storage := new(mockTransferStorage)
+			testcase.mockSetup(storage)
+			service, err := NewTransferService(storage, nil)
+			require.NoError(t, err)
+
+			// act
+			transfer, err := service.CreateTransfer(ctx, testcase.payload, nil)
+
+			// assert
+			require.ErrorIs(t, err, testcase.err)
+			require.Equal(t, testcase.expected, transfer)
+			storage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTransferService_ShipTransfer(t *testing.T) {
+	ctx := context.Background()
+	transferID := openapi_types.UUID{1}
+	productID := openapi_types.UUID{2}
+	created := &dto.Transfer{Id: transferID, Status: dto.TransferCreated}
+	shipped := &dto.Transfer{Id: transferID, Status: dto.TransferInTransit}
+	issued := dto.ProductIssued
+
+	testcases := []struct {
+		name      string
+		mockSetup func(*mockTransferStorage)
+		expected  *dto.Transfer
+		err       error
+	}{
+		{
+			name: "transfer shipped",
+			mockSetup: func(m *mockTransferStorage) {
+				m.On("GetTransfer", ctx, transferID).Return(created, nil)
+				m.On("GetTransferProducts", ctx, transferID).Return(storedProducts(productID), nil)
+				m.On("ShipTransfer", ctx, transferID, (*openapi_types.UUID)(nil)).Return(shipped, nil)
+			},
+			expected: shipped,
+		},
+		{
+			name: "already shipped",
+			mockSetup: func(m *mockTransferStorage) {
+				m.On("GetTransfer", ctx, transferID).Return(shipped, nil)
+			},
+			err: ErrTransferStatus,
+		},
+		{
+			name: "product was issued",
+			mockSetup: func(m *mockTransferStorage) {
+				m.On("GetTransfer", ctx, transferID).Return(created, nil)
+				m.On("GetTransferProducts", ctx, transferID).
+					Return([]dto.Product{{Id: &productID, Status: &issued}}, nil)
+			},
+			err: ErrTransferProductsUnavailable,
+		},
+		{
+			name: "shipped by concurrent request",
+			mockSetup: func(m *mockTransferStorage) {
+				m.On("GetTransfer", ctx, transferID).Return(created, nil)
+				m.On("GetTransferProducts", ctx, transferID).Return(storedProducts(productID), nil)
+				m.On("ShipTransfer", ctx, transferID, (*openapi_types.UUID)(nil)).Return((*dto.Transfer)(nil), nil)
+			},
+			err: ErrTransferStatus,
+		},
+		{
+			name: "transfer not found",
+			mockSetup: func(m *mockTransferStorage) {
+				m.On("GetTransfer", ctx, transferID).Return((*dto.Transfer)(nil), nil)
+			},
+			err: ErrTransferNotFound,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			storage := new(mockTransferStorage)
+			testcase.mockSetup(storage)
+			service, err := NewTransferService(storage, nil)
+			require.NoError(t, err)
+
+			// act
+			transfer, err := service.ShipTransfer(ctx, transferID, nil)
+
+			// assert
+			require.ErrorIs(t, err, testcase.err)
+			require.Equal(t, testcase.expected, transfer)
+			storage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTransferService_ReceiveTransfer(t *testing.T) {
+	ctx := context.Background()
+	transferID := openapi_types.UUID{1}
+	toPvzID := openapi_types.UUID{2}
+	receptionID := openapi_types.UUID{3}
+	productID := openapi_types.UUID{4}
+	conflictID := openapi_types.UUID{5}
+	inTransit := &dto.Transfer{Id: transferID, ToPvzId: toPvzID, Status: dto.TransferInTransit}
+	received := &dto.Transfer{Id: transferID, ToPvzId: toPvzID, Status: dto.TransferReceived, ReceptionId: &receptionID}
+	opened := &dto.Reception{Id: receptionID, PvzId: toPvzID, Status: dto.InProgress}
+	conflicting := &dto.Transfer{
+		Id:      transferID,
+		ToPvzId: toPvzID,
+		Status:  dto.TransferInTransit,
+		Items:   []dto.TransferItem{{ProductId: productID, ConflictProductId: &conflictID}},
+	}
+
+	testcases := []struct {
+		name      string
+		mockSetup func(*mockTransferStorage)
+		expected  *dto.Transfer
+		err       error
+	}{
+		{
+			name: "transfer received",
+			mockSetup: func(m *mockTransferStorage) {
+				m.On("GetTransfer", ctx, transferID).Return(inTransit, nil)
+				m.On("ReceiveTransfer", ctx, transferID, (*openapi_types.UUID)(nil)).Return(received, (*dto.Reception)(nil), nil)
+			},
+			expected: received,
+		},
+		{
+			name: "reception is opened for transfer",
+			mockSetup: func(m *mockTransferStorage) {
+				m.On("GetTransfer", ctx, transferID).Return(inTransit, nil)
+				m.On("ReceiveTransfer", ctx, transferID, (*openapi_types.UUID)(nil)).Return(received, opened, nil)
+			},
+			expected: received,
+		},
+		{
+			name: "transfer is not shipped",
+			mockSetup: func(m *mockTransferStorage) {
+				m.On("GetTransfer", ctx, transferID).Return(&dto.Transfer{Id: transferID, Status: dto.TransferCreated}, nil)
+			},
+			err: ErrTransferStatus,
+		},
+		{
+			name: "transfer received concurrently",
+			mockSetup: func(m *mockTransferStorage) {
+				m.On("GetTransfer", ctx, transferID).Return(inTransit, nil)
+				m.On("ReceiveTransfer", ctx, transferID, (*openapi_types.UUID)(nil)).
+					Return((*dto.Transfer)(nil), (*dto.Reception)(nil), nil)
+			},
+			err: ErrTransferStatus,
+		},
+		{
+			name: "barcodes clash with destination reception",
+			mockSetup: func(m *mockTransferStorage) {
+				m.On("GetTransfer", ctx, transferID).Return(inTransit, nil)
+				m.On("ReceiveTransfer", ctx, transferID, (*openapi_types.UUID)(nil)).Return(conflicting, (*dto.Reception)(nil), nil)
+			},
+			expected: conflicting,
+			err:      ErrTransferBarcodeConflict,
+		},
+		{
+			name: "storage error",
+			mockSetup: func(m *mockTransferStorage) {
+				m.On("GetTransfer", ctx, transferID).Return(inTransit, nil)
+				m.On("ReceiveTransfer", ctx, transferID, (*openapi_types.UUID)(nil)).
+					Return((*dto.Transfer)(nil), (*dto.Reception)(nil), errors.New("error"))
+			},
+			err: ErrTransferReceive,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			storage := new(mockTransferStorage)
+			testcase.mockSetup(storage)
+			service, err := NewTransferService(storage, nil)
+			require.NoError(t, err)
+
+			// act
+			transfer, err := service.ReceiveTransfer(ctx, transferID, nil)
+
+			// assert
+			require.ErrorIs(t, err, testcase.err)
+			require.Equal(t, testcase.expected, transfer)
+			storage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTransferService_ReceiveTransferMetrics(t *testing.T) {
+	ctx := context.Background()
+	transferID := openapi_types.UUID{1}
+	toPvzID := openapi_types.UUID{2}
+	productID := openapi_types.UUID{3}
+	receptionID := openapi_types.UUID{4}
+	received := &dto.Transfer{
+		Id:          transferID,
+		ToPvzId:     toPvzID,
+		Status:      dto.TransferReceived,
+		ReceptionId: &receptionID,
+		Items:       []dto.TransferItem{{ProductId: productID}},
+	}
+
+	// arrange
+	storage := new(mockTransferStorage)
+	storage.On("GetTransfer", ctx, transferID).Return(&dto.Transfer{Id: transferID, ToPvzId: toPvzID, Status: dto.TransferInTransit}, nil)
+	storage.On("ReceiveTransfer", ctx, transferID, (*openapi_types.UUID)(nil)).
+		Return(received, &dto.Reception{Id: receptionID, PvzId: toPvzID, Status: dto.InProgress}, nil)
+	metricsStorage := new(mockMetricsStorage)
+	metricsStorage.On("GetPVZCity", ctx, toPvzID).Return(dto.Kazan, nil)
+	metricsStorage.On("GetProductTypeCounts", ctx, []openapi_types.UUID{productID}).
+		Return(map[dto.ProductType]int{dto.ProductTypeShoes: 1}, nil)
+	businessMetrics, err := NewBusinessMetrics(metricsStorage)
+	require.NoError(t, err)
+	service, err := NewTransferService(storage, businessMetrics)
+	require.NoError(t, err)
+	open := metrics.ReceptionsOpen.WithLabelValues(string(dto.Kazan))
+	added := metrics.ProductsAddedTotal.WithLabelValues(string(dto.Kazan), string(dto.ProductTypeShoes))
+	openBefore, addedBefore := testutil.ToFloat64(open), testutil.ToFloat64(added)
+
+	// act
+	_, err = service.ReceiveTransfer(ctx, transferID, nil)
+
+	// assert
+	require.NoError(t, err)
+	// reception opened for transfer is counted as open
+	require.Equal(t, openBefore+1, testutil.ToFloat64(open))
+	require.Equal(t, addedBefore+1, testutil.ToFloat64(added))
+}
+
+func TestTransferService_GetProductPath(t *testing.T) {
+	ctx := context.Background()
+	productID := openapi_types.UUID{1}
+	firstPvzID, secondPvzID := openapi_types.UUID{2}, openapi_types.UUID{3}
+	transferID := openapi_types.UUID{4}
+	transferred, received := dto.ProductTransferred, dto.ProductReceived
+	transferStatus := dto.TransferReceived
+	// product was received in first pvz and transferred to second one
+	path := []dto.ProductPathStep{
+		{Product: dto.Product{Id: &productID, Status: &transferred}, PvzId: firstPvzID, TransferId: &transferID, TransferStatus: &transferStatus},
+		{Product: dto.Product{Id: &productID, Status: &received}, PvzId: secondPvzID},
+	}
+
+	testcases := []struct {
+		name     string
+		path     []dto.ProductPathStep
+		storeErr error
+		expected []dto.ProductPathStep
+		err      error
+	}{
+		{
+			name:     "path keeps order of pvz",
+			path:     path,
+			expected: path,
+		},
+		{
+			name: "product not found",
+			path: []dto.ProductPathStep{},
+			err:  ErrProductNotFound,
+		},
+		{
+			name:     "storage error",
+			storeErr: errors.New("error"),
+			err:      ErrProductGet,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			// arrange
+			storage := new(mockTransferStorage)
+			storage.On("GetProductPath", ctx, productID).Return(testcase.path, testcase.storeErr)
+			service, err := NewTransferService(storage, nil)
+			require.NoError(t, err)
+
+			// act
+			steps, err := service.GetProductPath(ctx, productID)
+
+			// assert
+			require.ErrorIs(t, err, testcase.err)
+			require.Equal(t, testcase.expected, steps)
+			storage.AssertExpectations(t)
+		})
+	}
+}
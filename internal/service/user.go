@@ -24,7 +24,7 @@ type UserStorager interface {
 }
 
 type TokenServicer interface {
-	Gen(role string) (dto.Token, error)
+	Gen(role, userID string) (dto.Token, error)
 }
 
 type UserService struct {
@@ -44,6 +44,9 @@ func NewUserService(storage UserStorager, tokenService TokenServicer) (*UserServ
 }
 
 func (s *UserService) RegisterUser(ctx context.Context, payload dto.PostRegisterJSONBody) (*dto.User, error) {
+	ctx, span := startSpan(ctx, "UserService.RegisterUser")
+	defer span.End()
+
 	hashedPassword, err := auth.HashPassword(payload.Password)
 	if err != nil {
 		return nil, ErrPasswordHashing
@@ -58,6 +61,9 @@ func (s *UserService) RegisterUser(ctx context.Context, payload dto.PostRegister
 }
 
 func (s *UserService) LoginUser(ctx context.Context, payload dto.PostLoginJSONBody) (dto.Token, error) {
+	ctx, span := startSpan(ctx, "UserService.LoginUser")
+	defer span.End()
+
 	hashedPassword, err := s.storage.GetUserPassword(ctx, string(payload.Email))
 	if err != nil {
 		return "", ErrUserLogin
@@ -72,7 +78,12 @@ func (s *UserService) LoginUser(ctx context.Context, payload dto.PostLoginJSONBo
 		return "", ErrUserLogin
 	}
 
-	token, err := s.tokenService.Gen(string(user.Role))
+	var userID string
+	if user.Id != nil {
+		userID = user.Id.String()
+	}
+
+	token, err := s.tokenService.Gen(string(user.Role), userID)
 	if err != nil {
 		return "", ErrTokenCreation
 	}
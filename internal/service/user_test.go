@@ -35,8 +35,8 @@ type MockTokenService struct {
 	mock.Mock
 }
 
-func (m *MockTokenService) Gen(role string) (dto.Token, error) {
-	args := m.Called(role)
+func (m *MockTokenService) Gen(role, userID string) (dto.Token, error) {
+	args := m.Called(role, userID)
 	return args.Get(0).(dto.Token), args.Error(1)
 }
 
@@ -162,10 +162,10 @@ func TestUserService_LoginUser(t *testing.T) {
 	require.NoError(t, err)
 
 	tokenService := new(MockTokenService)
-	tokenService.On("Gen", string(expectedUser.Role)).Return(generatedToken, nil)
+	tokenService.On("Gen", string(expectedUser.Role), expectedUser.Id.String()).Return(generatedToken, nil)
 
 	tokenServiceWithError := new(MockTokenService)
-	tokenServiceWithError.On("Gen", string(expectedUser.Role)).Return("", errors.New("error"))
+	tokenServiceWithError.On("Gen", string(expectedUser.Role), expectedUser.Id.String()).Return("", errors.New("error"))
 
 	for _, testcase := range []struct {
 		name         string
@@ -0,0 +1,267 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// occupiedExpr counts products of cell which are still on shelves
+const occupiedExpr = `(SELECT COUNT(*) FROM product_placements pp
+	JOIN products p ON p.id = pp.product_id
+	WHERE pp.cell_id = storage_cells.id AND p.status IN ('received', 'stored'))`
+
+// cellColumns lists cell columns in order of cellFields
+var cellColumns = []string{"id", "pvz_id", "code", "capacity", occupiedExpr + " AS occupied"}
+
+func cellFields(c *dto.Cell) []any {
+	return []any{&c.Id, &c.PvzId, &c.Code, &c.Capacity, &c.Occupied}
+}
+
+type CellStorage struct {
+	pool    *pgxpool.Pool
+	builder squirrel.StatementBuilderType
+}
+
+func NewCellStorage(pool *pgxpool.Pool) (*CellStorage, error) {
+	if pool == nil {
+		return nil, errors.New("nil values in NewCellStorage constructor")
+	}
+
+	return &CellStorage{
+		pool:    pool,
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}, nil
+}
+
+// CreateCell adds storage cell to pvz, returns nil when pvz already has cell with such code
+func (s *CellStorage) CreateCell(ctx context.Context, pvzID openapi_types.UUID, payload dto.PostPvzPvzIdCellsJSONBody) (*dto.Cell, error) {
+	query, args, err := s.builder.
+		Insert("storage_cells").
+		Columns("pvz_id", "code", "capacity").
+		Values(pvzID, payload.Code, payload.Capacity).
+		Suffix("ON CONFLICT (pvz_id, code) DO NOTHING RETURNING id, pvz_id, code, capacity").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	cell := dto.Cell{Occupied: new(int)}
+	err = s.pool.QueryRow(ctx, query, args...).Scan(&cell.Id, &cell.PvzId, &cell.Code, &cell.Capacity)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cell: %w", err)
+	}
+
+	return &cell, nil
+}
+
+func (s *CellStorage) GetCells(ctx context.Context, pvzID openapi_types.UUID) ([]dto.Cell, error) {
+	query, args, err := s.builder.
+		Select(cellColumns...).
+		From("storage_cells").
+		Where(squirrel.Eq{"pvz_id": pvzID}).
+		OrderBy("code").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	return s.queryCells(ctx, query, args)
+}
+
+// GetCell returns cell with its occupancy or nil if it does not exist
+func (s *CellStorage) GetCell(ctx context.Context, cellID openapi_types.UUID) (*dto.Cell, error) {
+	query, args, err := s.builder.
+		Select(cellColumns...).
+		From("storage_cells").
+		Where(squirrel.Eq{"id": cellID}).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	return s.queryCell(ctx, query, args)
+}
+
+// SuggestCell returns cell of pvz with the most free capacity or nil if all cells are full
+func (s *CellStorage) SuggestCell(ctx context.Context, pvzID openapi_types.UUID) (*dto.Cell, error) {
+	query, args, err := s.builder.
+		Select(cellColumns...).
+		From("storage_cells").
+		Where(squirrel.Eq{"pvz_id": pvzID}).
+		Where("capacity > "+occupiedExpr).
+		OrderBy("capacity - "+occupiedExpr+" DESC", "code").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	return s.queryCell(ctx, query, args)
+}
+
+// GetProductLocations returns products matching params with their pvz and placement
+func (s *CellStorage) GetProductLocations(ctx context.Context, params dto.GetProductsLocationParams) ([]dto.ProductLocation, error) {
+	columns := make([]string, 0, len(productColumns)+6)
+	for _, column := range productColumns {
+		columns = append(columns, "products."+column)
+	}
+	columns = append(columns, "receptions.pvz_id", "pp.placed_at", "c.id", "c.pvz_id", "c.code", "c.capacity")
+
+	builder := s.builder.
+		Select(columns...).
+		From("products").
+		Join("receptions ON receptions.id = products.reception_id").
+		LeftJoin("product_placements pp ON pp.product_id = products.id").
+		LeftJoin("storage_cells c ON c.id = pp.cell_id")
+	if params.ProductId != nil {
+		builder = builder.Where(squirrel.Eq{"products.id": *params.ProductId})
+	}
+	if params.Barcode != nil {
+		builder = builder.Where(squirrel.Eq{"products.barcode": *params.Barcode})
+	}
+
+	query, args, err := builder.OrderBy("products.date_time DESC").ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	locations := make([]dto.ProductLocation, 0)
+	for rows.Next() {
+		var location dto.ProductLocation
+		var cellID, cellPvzID *openapi_types.UUID
+		var cellCode *string
+		var cellCapacity *int
+		dest := append(productFields(&location.Product), &location.PvzId, &location.PlacedAt, &cellID, &cellPvzID, &cellCode, &cellCapacity)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan product location: %w", err)
+		}
+		if cellID != nil {
+			location.Cell = &dto.Cell{Id: *cellID, PvzId: *cellPvzID, Code: *cellCode, Capacity: *cellCapacity}
+		}
+		locations = append(locations, location)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return locations, nil
+}
+
+// PlaceProduct puts product into cell or moves it there from its current cell.
+// Cell row is locked while checking capacity, returns false when cell is full
+func (s *CellStorage) PlaceProduct(
+	ctx context.Context,
+	productID openapi_types.UUID,
+	cellID openapi_types.UUID,
+	placedBy *openapi_types.UUID,
+) (bool, error) {
+	lockQuery, lockArgs, err := s.builder.
+		Select("capacity").
+		From("storage_cells").
+		Where(squirrel.Eq{"id": cellID}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return false, ErrBuildQuery
+	}
+
+	countQuery, countArgs, err := s.builder.
+		Select("COUNT(*)").
+		From("product_placements pp").
+		Join("products p ON p.id = pp.product_id").
+		Where(squirrel.Eq{"pp.cell_id": cellID}).
+		Where(squirrel.Eq{"p.status": []dto.ProductStatus{dto.ProductReceived, dto.ProductStored}}).
+		Where(squirrel.NotEq{"pp.product_id": productID}).
+		ToSql()
+	if err != nil {
+		return false, ErrBuildQuery
+	}
+
+	placeQuery, placeArgs, err := s.builder.
+		Insert("product_placements").
+		Columns("product_id", "cell_id", "placed_at", "placed_by").
+		Values(productID, cellID, squirrel.Expr("NOW()"), placedBy).
+		Suffix("ON CONFLICT (product_id) DO UPDATE SET cell_id = EXCLUDED.cell_id, placed_at = EXCLUDED.placed_at, placed_by = EXCLUDED.placed_by").
+		ToSql()
+	if err != nil {
+		return false, ErrBuildQuery
+	}
+
+	placed := false
+	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var capacity, occupied int
+		if err := tx.QueryRow(ctx, lockQuery, lockArgs...).Scan(&capacity); err != nil {
+			return err
+		}
+		if err := tx.QueryRow(ctx, countQuery, countArgs...).Scan(&occupied); err != nil {
+			return err
+		}
+		if occupied >= capacity {
+			return nil
+		}
+
+		if _, err := tx.Exec(ctx, placeQuery, placeArgs...); err != nil {
+			return err
+		}
+		placed = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to place product: %w", err)
+	}
+
+	return placed, nil
+}
+
+func (s *CellStorage) queryCell(ctx context.Context, query string, args []any) (*dto.Cell, error) {
+	var cell dto.Cell
+	err := s.pool.QueryRow(ctx, query, args...).Scan(cellFields(&cell)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cell: %w", err)
+	}
+
+	return &cell, nil
+}
+
+func (s *CellStorage) queryCells(ctx context.Context, query string, args []any) ([]dto.Cell, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	cells := make([]dto.Cell, 0)
+	for rows.Next() {
+		var cell dto.Cell
+		if err := rows.Scan(cellFields(&cell)...); err != nil {
+			return nil, fmt.Errorf("failed to scan cell: %w", err)
+		}
+		cells = append(cells, cell)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return cells, nil
+}
@@ -0,0 +1,168 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+var errProductsNotReturnable = errors.New("not all products are in return list of pvz")
+
+// returnableStatuses lists statuses of products which are kept in pvz and can expire
+var returnableStatuses = []dto.ProductStatus{dto.ProductReceived, dto.ProductStored}
+
+type ExpiryStorage struct {
+	pool    *pgxpool.Pool
+	builder squirrel.StatementBuilderType
+}
+
+func NewExpiryStorage(pool *pgxpool.Pool) (*ExpiryStorage, error) {
+	if pool == nil {
+		return nil, errors.New("nil values in NewExpiryStorage constructor")
+	}
+
+	return &ExpiryStorage{
+		pool:    pool,
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}, nil
+}
+
+// ExpireProducts marks received and stored products of type kept longer than period as expired,
+// received products which were never stored are aged from time of reception.
+// Returns number of expired products by city of their pvz
+func (s *ExpiryStorage) ExpireProducts(ctx context.Context, productType dto.ProductType, period time.Duration) (map[string]int, error) {
+	query, args, err := s.builder.
+		Update("products").
+		Set("expired_at", squirrel.Expr("NOW()")).
+		From("receptions JOIN pvz ON pvz.id = receptions.pvz_id").
+		Where("products.reception_id = receptions.id").
+		Where(squirrel.Eq{
+			"products.type":       productType,
+			"products.status":     returnableStatuses,
+			"products.expired_at": nil,
+		}).
+		Where("COALESCE(products.stored_at, products.date_time) < ?", time.Now().Add(-period).UTC()).
+		Suffix("RETURNING pvz.city").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire products: %w", err)
+	}
+	cities, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire products: %w", err)
+	}
+
+	expired := make(map[string]int)
+	for _, city := range cities {
+		expired[city]++
+	}
+
+	return expired, nil
+}
+
+// GetReturnList returns expired products which are still kept in pvz, oldest first
+func (s *ExpiryStorage) GetReturnList(ctx context.Context, pvzID openapi_types.UUID) ([]dto.Product, error) {
+	query, args, err := s.builder.
+		Select(productColumns...).
+		From("products").
+		Where(squirrel.Eq{"status": returnableStatuses}).
+		Where(squirrel.NotEq{"expired_at": nil}).
+		Where("reception_id IN (SELECT id FROM receptions WHERE pvz_id = ?)", pvzID).
+		OrderBy("expired_at", "COALESCE(stored_at, date_time)").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]dto.Product, 0)
+	for rows.Next() {
+		var product dto.Product
+		if err := rows.Scan(productFields(&product)...); err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return products, nil
+}
+
+// ConfirmReturns marks expired products of pvz in one of from statuses as returned to sender,
+// returns nil when some of products are not in return list of pvz
+func (s *ExpiryStorage) ConfirmReturns(
+	ctx context.Context,
+	pvzID openapi_types.UUID,
+	productIDs []openapi_types.UUID,
+	from []dto.ProductStatus,
+) ([]dto.Product, error) {
+	query, args, err := s.builder.
+		Update("products").
+		Set("status", dto.ProductReturnedToSender).
+		Set("returned_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{
+			"id":     productIDs,
+			"status": from,
+		}).
+		Where(squirrel.NotEq{"expired_at": nil}).
+		Where("reception_id IN (SELECT id FROM receptions WHERE pvz_id = ?)", pvzID).
+		Suffix("RETURNING " + strings.Join(productColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	products := make([]dto.Product, 0, len(productIDs))
+	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var product dto.Product
+			if err := rows.Scan(productFields(&product)...); err != nil {
+				return err
+			}
+			products = append(products, product)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(products) != len(productIDs) {
+			return errProductsNotReturnable
+		}
+		return nil
+	})
+	if errors.Is(err, errProductsNotReturnable) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm returns: %w", err)
+	}
+
+	return products, nil
+}
@@ -0,0 +1,56 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// HealthStorage checks database availability for readiness probes
+type HealthStorage struct {
+	pool    *pgxpool.Pool
+	builder squirrel.StatementBuilderType
+}
+
+func NewHealthStorage(pool *pgxpool.Pool) (*HealthStorage, error) {
+	if pool == nil {
+		return nil, errors.New("nil values in NewHealthStorage constructor")
+	}
+
+	return &HealthStorage{
+		pool:    pool,
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}, nil
+}
+
+func (s *HealthStorage) Ping(ctx context.Context) error {
+	return s.pool.Ping(ctx)
+}
+
+// MigrationVersion returns version of last applied migration and whether it failed halfway
+func (s *HealthStorage) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	query, args, err := s.builder.
+		Select("version", "dirty").
+		From("schema_migrations").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return 0, false, ErrBuildQuery
+	}
+
+	var version int64
+	var dirty bool
+	err = s.pool.QueryRow(ctx, query, args...).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get migration version: %w", err)
+	}
+
+	return uint(version), dirty, nil
+}
@@ -0,0 +1,146 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IdempotencyStorage struct {
+	pool    *pgxpool.Pool
+	builder squirrel.StatementBuilderType
+}
+
+func NewIdempotencyStorage(pool *pgxpool.Pool) (*IdempotencyStorage, error) {
+	if pool == nil {
+		return nil, errors.New("nil values in NewIdempotencyStorage constructor")
+	}
+
+	return &IdempotencyStorage{
+		pool:    pool,
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}, nil
+}
+
+// Reserve stores key of scope for request with fingerprint until leaseUntil, expired key is taken over.
+// Returns nil when key is reserved by this call or record of earlier request with the key.
+func (s *IdempotencyStorage) Reserve(
+	ctx context.Context,
+	scope string,
+	key string,
+	fingerprint string,
+	leaseUntil time.Time,
+) (*dto.IdempotencyRecord, error) {
+	insertQuery, insertArgs, err := s.builder.
+		Insert("idempotency_keys").
+		Columns("scope", "key", "fingerprint", "expires_at").
+		Values(scope, key, fingerprint, leaseUntil.UTC()).
+		Suffix(`ON CONFLICT (scope, key) DO UPDATE SET
+			fingerprint = EXCLUDED.fingerprint,
+			status_code = 0,
+			body = NULL,
+			created_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at < ?`, time.Now().UTC()).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	selectQuery, selectArgs, err := s.builder.
+		Select("key", "fingerprint", "status_code", "body", "expires_at").
+		From("idempotency_keys").
+		Where(squirrel.Eq{"scope": scope, "key": key}).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var record *dto.IdempotencyRecord
+	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, insertQuery, insertArgs...)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 1 {
+			return nil
+		}
+
+		record = &dto.IdempotencyRecord{}
+		return tx.QueryRow(ctx, selectQuery, selectArgs...).
+			Scan(&record.Key, &record.Fingerprint, &record.StatusCode, &record.Body, &record.ExpiresAt)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	return record, nil
+}
+
+// Complete stores response of request reserved the key, it is kept until expiresAt
+func (s *IdempotencyStorage) Complete(
+	ctx context.Context,
+	scope string,
+	key string,
+	statusCode int,
+	body []byte,
+	expiresAt time.Time,
+) error {
+	query, args, err := s.builder.
+		Update("idempotency_keys").
+		Set("status_code", statusCode).
+		Set("body", body).
+		Set("expires_at", expiresAt.UTC()).
+		Where(squirrel.Eq{"scope": scope, "key": key}).
+		ToSql()
+	if err != nil {
+		return ErrBuildQuery
+	}
+
+	if _, err := s.pool.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to save idempotent response: %w", err)
+	}
+
+	return nil
+}
+
+// Release removes key, so the request can be retried
+func (s *IdempotencyStorage) Release(ctx context.Context, scope string, key string) error {
+	query, args, err := s.builder.
+		Delete("idempotency_keys").
+		Where(squirrel.Eq{"scope": scope, "key": key}).
+		ToSql()
+	if err != nil {
+		return ErrBuildQuery
+	}
+
+	if _, err := s.pool.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes expired keys and returns their number
+func (s *IdempotencyStorage) DeleteExpired(ctx context.Context) (int, error) {
+	query, args, err := s.builder.
+		Delete("idempotency_keys").
+		Where(squirrel.Lt{"expires_at": time.Now().UTC()}).
+		ToSql()
+	if err != nil {
+		return 0, ErrBuildQuery
+	}
+
+	tag, err := s.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
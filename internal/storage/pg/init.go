@@ -2,13 +2,62 @@ package pg
 
 import (
 	"context"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// return connection of pool, defer func, and error if is
-func InitDB(connStr string) (*pgxpool.Pool, func(), error) {
-	pool, err := pgxpool.New(context.Background(), connStr)
+// cancelDeadlineDelay is how long connection waits for server to stop cancelled query before it is closed
+const cancelDeadlineDelay = time.Second
+
+// PoolSettings tunes connection pool, zero values keep pgxpool defaults
+type PoolSettings struct {
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+	ConnectTimeout    time.Duration
+}
+
+// return connection of pool, defer func, and error if is.
+// Pool connects lazily, so database may still be unreachable when it is returned
+func InitDB(connStr string, settings PoolSettings) (*pgxpool.Pool, func(), error) {
+	config, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if settings.MaxConns > 0 {
+		config.MaxConns = settings.MaxConns
+	}
+	if settings.MinConns > 0 {
+		config.MinConns = settings.MinConns
+	}
+	if settings.MaxConnLifetime > 0 {
+		config.MaxConnLifetime = settings.MaxConnLifetime
+	}
+	if settings.MaxConnIdleTime > 0 {
+		config.MaxConnIdleTime = settings.MaxConnIdleTime
+	}
+	if settings.HealthCheckPeriod > 0 {
+		config.HealthCheckPeriod = settings.HealthCheckPeriod
+	}
+	if settings.ConnectTimeout > 0 {
+		config.ConnConfig.ConnectTimeout = settings.ConnectTimeout
+	}
+
+	// by default cancelled context only closes connection, while server keeps running the query,
+	// cancel request makes server stop it, e.g. when client of HTTP request disconnects
+	config.ConnConfig.BuildContextWatcherHandler = func(conn *pgconn.PgConn) ctxwatch.Handler {
+		return &pgconn.CancelRequestContextWatcherHandler{Conn: conn, DeadlineDelay: cancelDeadlineDelay}
+	}
+
+	config.ConnConfig.Tracer = queryTracer{}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
 		return nil, nil, err
 	}
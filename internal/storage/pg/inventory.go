@@ -0,0 +1,248 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+const scannedCountExpr = "(SELECT COUNT(*) FROM inventory_scans WHERE inventory_id = inventories.id)"
+
+// inventoryColumns lists inventory columns in order of inventoryFields
+var inventoryColumns = []string{
+	"id", "pvz_id", "status", "started_at", "started_by", "completed_at", "completed_by", "report",
+	scannedCountExpr + " AS scanned_count",
+}
+
+func inventoryFields(i *dto.Inventory) []any {
+	return []any{&i.Id, &i.PvzId, &i.Status, &i.StartedAt, &i.StartedBy, &i.CompletedAt, &i.CompletedBy, &i.Report, &i.ScannedCount}
+}
+
+type InventoryStorage struct {
+	pool    *pgxpool.Pool
+	builder squirrel.StatementBuilderType
+}
+
+func NewInventoryStorage(pool *pgxpool.Pool) (*InventoryStorage, error) {
+	if pool == nil {
+		return nil, errors.New("nil values in NewInventoryStorage constructor")
+	}
+
+	return &InventoryStorage{
+		pool:    pool,
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}, nil
+}
+
+// CreateInventory starts inventory in pvz, returns nil when another inventory is in progress
+func (s *InventoryStorage) CreateInventory(ctx context.Context, pvzID openapi_types.UUID, startedBy *openapi_types.UUID) (*dto.Inventory, error) {
+	query, args, err := s.builder.
+		Insert("inventories").
+		Columns("pvz_id", "started_by").
+		Values(pvzID, startedBy).
+		Suffix("ON CONFLICT (pvz_id) WHERE status = 'in_progress' DO NOTHING RETURNING " + strings.Join(inventoryColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	return s.queryInventory(ctx, query, args)
+}
+
+// GetInventory returns inventory or nil if it does not exist
+func (s *InventoryStorage) GetInventory(ctx context.Context, inventoryID openapi_types.UUID) (*dto.Inventory, error) {
+	query, args, err := s.builder.
+		Select(inventoryColumns...).
+		From("inventories").
+		Where(squirrel.Eq{"id": inventoryID}).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	return s.queryInventory(ctx, query, args)
+}
+
+func (s *InventoryStorage) GetPVZInventories(ctx context.Context, pvzID openapi_types.UUID) ([]dto.Inventory, error) {
+	query, args, err := s.builder.
+		Select(inventoryColumns...).
+		From("inventories").
+		Where(squirrel.Eq{"pvz_id": pvzID}).
+		OrderBy("started_at DESC").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	inventories := make([]dto.Inventory, 0)
+	for rows.Next() {
+		var inventory dto.Inventory
+		if err := rows.Scan(inventoryFields(&inventory)...); err != nil {
+			return nil, fmt.Errorf("failed to scan inventory: %w", err)
+		}
+		inventories = append(inventories, inventory)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return inventories, nil
+}
+
+// AddScan records scanned product while inventory is in progress. Repeated scans of product id
+// are ignored, every barcode scan is recorded, as products of different receptions can share barcode
+func (s *InventoryStorage) AddScan(
+	ctx context.Context,
+	inventoryID openapi_types.UUID,
+	scan dto.PostInventoriesInventoryIdScansJSONBody,
+	scannedBy *openapi_types.UUID,
+) error {
+	query, args, err := s.builder.
+		Insert("inventory_scans").
+		Columns("inventory_id", "product_id", "barcode", "scanned_by").
+		Select(squirrel.
+			Select("id").
+			Column(squirrel.Expr("?::uuid", scan.ProductId)).
+			Column(squirrel.Expr("?::varchar", scan.Barcode)).
+			Column(squirrel.Expr("?::uuid", scannedBy)).
+			From("inventories").
+			Where(squirrel.Eq{
+				"id":     inventoryID,
+				"status": dto.InventoryInProgress,
+			}).
+			Suffix("FOR SHARE")).
+		Suffix("ON CONFLICT DO NOTHING").
+		ToSql()
+	if err != nil {
+		return ErrBuildQuery
+	}
+
+	if _, err := s.pool.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to add scan: %w", err)
+	}
+
+	return nil
+}
+
+func (s *InventoryStorage) GetScans(ctx context.Context, inventoryID openapi_types.UUID) ([]dto.InventoryScan, error) {
+	query, args, err := s.builder.
+		Select("product_id", "barcode", "scanned_at").
+		From("inventory_scans").
+		Where(squirrel.Eq{"inventory_id": inventoryID}).
+		OrderBy("scanned_at").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	scans := make([]dto.InventoryScan, 0)
+	for rows.Next() {
+		var scan dto.InventoryScan
+		if err := rows.Scan(&scan.ProductId, &scan.Barcode, &scan.ScannedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan inventory scan: %w", err)
+		}
+		scans = append(scans, scan)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return scans, nil
+}
+
+// GetExpectedProducts returns products which are received by pvz and not issued yet
+func (s *InventoryStorage) GetExpectedProducts(ctx context.Context, pvzID openapi_types.UUID) ([]dto.Product, error) {
+	query, args, err := s.builder.
+		Select(productColumns...).
+		From("products").
+		Where(squirrel.Eq{"status": []dto.ProductStatus{dto.ProductReceived, dto.ProductStored}}).
+		Where("reception_id IN (SELECT id FROM receptions WHERE pvz_id = ?)", pvzID).
+		OrderBy("date_time").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]dto.Product, 0)
+	for rows.Next() {
+		var product dto.Product
+		if err := rows.Scan(productFields(&product)...); err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return products, nil
+}
+
+// CompleteInventory stores report and completes inventory. Report is saved only when inventory
+// is still in progress and has scannedCount scans, otherwise nil is returned
+func (s *InventoryStorage) CompleteInventory(
+	ctx context.Context,
+	inventoryID openapi_types.UUID,
+	report dto.InventoryReport,
+	completedBy *openapi_types.UUID,
+) (*dto.Inventory, error) {
+	query, args, err := s.builder.
+		Update("inventories").
+		Set("status", dto.InventoryCompleted).
+		Set("completed_at", squirrel.Expr("NOW()")).
+		Set("completed_by", completedBy).
+		Set("report", report).
+		Where(squirrel.Eq{
+			"id":     inventoryID,
+			"status": dto.InventoryInProgress,
+		}).
+		Where(scannedCountExpr+" = ?", report.ScannedCount).
+		Suffix("RETURNING " + strings.Join(inventoryColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	return s.queryInventory(ctx, query, args)
+}
+
+func (s *InventoryStorage) queryInventory(ctx context.Context, query string, args []any) (*dto.Inventory, error) {
+	var inventory dto.Inventory
+	err := s.pool.QueryRow(ctx, query, args...).Scan(inventoryFields(&inventory)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory: %w", err)
+	}
+
+	return &inventory, nil
+}
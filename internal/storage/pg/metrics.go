@@ -0,0 +1,157 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// MetricsStorage loads data for labels and startup values of business metrics
+type MetricsStorage struct {
+	pool    *pgxpool.Pool
+	builder squirrel.StatementBuilderType
+}
+
+func NewMetricsStorage(pool *pgxpool.Pool) (*MetricsStorage, error) {
+	if pool == nil {
+		return nil, errors.New("nil values in NewMetricsStorage constructor")
+	}
+
+	return &MetricsStorage{
+		pool:    pool,
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}, nil
+}
+
+// GetPVZCity returns empty city when there is no such pvz
+func (s *MetricsStorage) GetPVZCity(ctx context.Context, pvzID openapi_types.UUID) (dto.PVZCity, error) {
+	query, args, err := s.builder.
+		Select("city").
+		From("pvz").
+		Where(squirrel.Eq{"id": pvzID}).
+		ToSql()
+	if err != nil {
+		return "", ErrBuildQuery
+	}
+
+	var city dto.PVZCity
+	err = s.pool.QueryRow(ctx, query, args...).Scan(&city)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get pvz city: %w", err)
+	}
+
+	return city, nil
+}
+
+func (s *MetricsStorage) GetProductTypeCounts(ctx context.Context, productIDs []openapi_types.UUID) (map[dto.ProductType]int, error) {
+	query, args, err := s.builder.
+		Select("type", "COUNT(*)").
+		From("products").
+		Where(squirrel.Eq{"id": productIDs}).
+		GroupBy("type").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	counts := make(map[dto.ProductType]int)
+	if err := s.collectCounts(ctx, query, args, func(rows pgx.Rows) error {
+		var productType dto.ProductType
+		var count int
+		if err := rows.Scan(&productType, &count); err != nil {
+			return err
+		}
+		counts[productType] = count
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to count products by type: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetOpenReceptionCounts returns number of receptions in progress by city
+func (s *MetricsStorage) GetOpenReceptionCounts(ctx context.Context) (map[dto.PVZCity]int, error) {
+	query, args, err := s.builder.
+		Select("pvz.city", "COUNT(*)").
+		From("receptions").
+		Join("pvz ON pvz.id = receptions.pvz_id").
+		Where(squirrel.Eq{"receptions.status": dto.InProgress}).
+		GroupBy("pvz.city").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	counts := make(map[dto.PVZCity]int)
+	if err := s.collectCounts(ctx, query, args, func(rows pgx.Rows) error {
+		var city dto.PVZCity
+		var count int
+		if err := rows.Scan(&city, &count); err != nil {
+			return err
+		}
+		counts[city] = count
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to count open receptions: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetClosedReceptionSizes returns number of closed receptions by number of products in them
+func (s *MetricsStorage) GetClosedReceptionSizes(ctx context.Context) (map[int]int, error) {
+	sizes := s.builder.
+		Select("COUNT(products.id) AS size").
+		From("receptions").
+		LeftJoin("products ON products.reception_id = receptions.id").
+		Where(squirrel.Eq{"receptions.status": dto.Close}).
+		GroupBy("receptions.id")
+	query, args, err := s.builder.
+		Select("size", "COUNT(*)").
+		FromSelect(sizes, "sizes").
+		GroupBy("size").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	counts := make(map[int]int)
+	if err := s.collectCounts(ctx, query, args, func(rows pgx.Rows) error {
+		var size, count int
+		if err := rows.Scan(&size, &count); err != nil {
+			return err
+		}
+		counts[size] = count
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to count closed reception sizes: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (s *MetricsStorage) collectCounts(ctx context.Context, query string, args []interface{}, scan func(pgx.Rows) error) error {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS sync_batches (
+    id UUID PRIMARY KEY,
+    result JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
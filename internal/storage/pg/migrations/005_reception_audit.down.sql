@@ -0,0 +1,4 @@
+ALTER TABLE receptions
+    DROP COLUMN IF EXISTS closed_at,
+    DROP COLUMN IF EXISTS opened_by,
+    DROP COLUMN IF EXISTS closed_by;
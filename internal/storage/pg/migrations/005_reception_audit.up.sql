@@ -0,0 +1,4 @@
+ALTER TABLE receptions
+    ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS opened_by UUID,
+    ADD COLUMN IF NOT EXISTS closed_by UUID;
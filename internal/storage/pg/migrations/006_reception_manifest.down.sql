@@ -0,0 +1,3 @@
+ALTER TABLE receptions
+    DROP COLUMN IF EXISTS manifest,
+    DROP COLUMN IF EXISTS discrepancy_report;
@@ -0,0 +1,3 @@
+ALTER TABLE receptions
+    ADD COLUMN IF NOT EXISTS manifest JSONB,
+    ADD COLUMN IF NOT EXISTS discrepancy_report JSONB;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_products_barcode;
+DROP INDEX IF EXISTS products_reception_barcode;
+
+ALTER TABLE products DROP COLUMN IF EXISTS barcode;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_product_deletions_reception_id;
+DROP TABLE IF EXISTS product_deletions;
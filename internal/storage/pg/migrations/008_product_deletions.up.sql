@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS product_deletions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL,
+    reception_id UUID NOT NULL REFERENCES receptions(id),
+    type VARCHAR NOT NULL,
+    barcode VARCHAR,
+    added_at TIMESTAMP NOT NULL,
+    deleted_by UUID,
+    deleted_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_deletions_reception_id ON product_deletions(reception_id);
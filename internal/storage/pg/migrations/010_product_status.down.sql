@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_products_status;
+
+ALTER TABLE products DROP COLUMN IF EXISTS returned_at;
+ALTER TABLE products DROP COLUMN IF EXISTS issued_at;
+ALTER TABLE products DROP COLUMN IF EXISTS stored_at;
+ALTER TABLE products DROP COLUMN IF EXISTS status;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS orders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pvz_id UUID NOT NULL REFERENCES pvz(id),
+    customer VARCHAR NOT NULL,
+    status VARCHAR NOT NULL DEFAULT 'created' CHECK (status IN ('created', 'issued')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_by UUID,
+    pickup_code_hash VARCHAR,
+    pickup_code_expires_at TIMESTAMP,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    issued_at TIMESTAMP,
+    issued_by UUID
+);
+
+CREATE TABLE IF NOT EXISTS order_products (
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL UNIQUE REFERENCES products(id),
+    PRIMARY KEY (order_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_pvz_id ON orders(pvz_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS product_placements;
+DROP TABLE IF EXISTS storage_cells;
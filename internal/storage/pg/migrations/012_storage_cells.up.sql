@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS storage_cells (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pvz_id UUID NOT NULL REFERENCES pvz(id),
+    code VARCHAR NOT NULL,
+    capacity INTEGER NOT NULL CHECK (capacity > 0),
+    UNIQUE (pvz_id, code)
+);
+
+CREATE TABLE IF NOT EXISTS product_placements (
+    product_id UUID PRIMARY KEY REFERENCES products(id) ON DELETE CASCADE,
+    cell_id UUID NOT NULL REFERENCES storage_cells(id),
+    placed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    placed_by UUID
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_placements_cell_id ON product_placements(cell_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS transfer_products;
+DROP TABLE IF EXISTS transfers;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS transfers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    from_pvz_id UUID NOT NULL REFERENCES pvz(id),
+    to_pvz_id UUID NOT NULL REFERENCES pvz(id),
+    status VARCHAR NOT NULL DEFAULT 'created' CHECK (status IN ('created', 'in_transit', 'received')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_by UUID,
+    shipped_at TIMESTAMP,
+    shipped_by UUID,
+    received_at TIMESTAMP,
+    received_by UUID,
+    reception_id UUID REFERENCES receptions(id),
+    CHECK (from_pvz_id <> to_pvz_id)
+);
+
+-- product_id is product at source pvz, received_product_id is its copy created at destination pvz
+CREATE TABLE IF NOT EXISTS transfer_products (
+    transfer_id UUID NOT NULL REFERENCES transfers(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL UNIQUE REFERENCES products(id),
+    received_product_id UUID UNIQUE REFERENCES products(id),
+    PRIMARY KEY (transfer_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfers_from_pvz_id ON transfers(from_pvz_id);
+CREATE INDEX IF NOT EXISTS idx_transfers_to_pvz_id ON transfers(to_pvz_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_products_expired_at;
+
+ALTER TABLE products DROP COLUMN IF EXISTS expired_at;
@@ -0,0 +1,3 @@
+ALTER TABLE products ADD COLUMN expired_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_products_expired_at ON products(expired_at) WHERE expired_at IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS inventory_scans;
+DROP TABLE IF EXISTS inventories;
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS inventories (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pvz_id UUID NOT NULL REFERENCES pvz(id),
+    status VARCHAR NOT NULL DEFAULT 'in_progress' CHECK (status IN ('in_progress', 'completed')),
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    started_by UUID,
+    completed_at TIMESTAMP,
+    completed_by UUID,
+    report JSONB
+);
+
+-- only one inventory can be in progress in pvz
+CREATE UNIQUE INDEX IF NOT EXISTS inventories_pvz_in_progress
+ON inventories (pvz_id)
+WHERE status = 'in_progress';
+
+CREATE TABLE IF NOT EXISTS inventory_scans (
+    inventory_id UUID NOT NULL REFERENCES inventories(id) ON DELETE CASCADE,
+    product_id UUID,
+    barcode VARCHAR,
+    scanned_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    scanned_by UUID,
+    CHECK (product_id IS NOT NULL OR barcode IS NOT NULL)
+);
+
+-- repeated scans of the same product are ignored
+CREATE UNIQUE INDEX IF NOT EXISTS inventory_scans_product
+ON inventory_scans (inventory_id, product_id)
+WHERE product_id IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS inventory_scans_barcode
+ON inventory_scans (inventory_id, barcode)
+WHERE product_id IS NULL;
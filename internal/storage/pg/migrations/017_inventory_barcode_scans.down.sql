@@ -0,0 +1,9 @@
+DELETE FROM inventory_scans a
+USING inventory_scans b
+WHERE a.product_id IS NULL AND b.product_id IS NULL
+  AND a.inventory_id = b.inventory_id AND a.barcode = b.barcode
+  AND a.ctid > b.ctid;
+
+CREATE UNIQUE INDEX IF NOT EXISTS inventory_scans_barcode
+ON inventory_scans (inventory_id, barcode)
+WHERE product_id IS NULL;
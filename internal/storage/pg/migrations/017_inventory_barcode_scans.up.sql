@@ -0,0 +1,3 @@
+-- barcode is unique only within reception, so several products in pvz can share it
+-- and every barcode scan counts one product
+DROP INDEX IF EXISTS inventory_scans_barcode;
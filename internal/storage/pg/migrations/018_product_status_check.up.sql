@@ -0,0 +1,3 @@
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_status_check;
+ALTER TABLE products ADD CONSTRAINT products_status_check
+CHECK (status IN ('received', 'stored', 'issued', 'returned_to_sender', 'transferred'));
@@ -1,7 +1,9 @@
 package pg
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 
@@ -57,6 +59,34 @@ func (m *Migrator) Down() error {
 	return nil
 }
 
+// LatestVersion returns version of the last migration in migrations directory
+func (m *Migrator) LatestVersion() (uint, error) {
+	if _, err := os.Stat(m.migrationsDir); os.IsNotExist(err) {
+		return 0, fmt.Errorf("migrations directory does not exist: %w", err)
+	}
+
+	source, err := iofs.New(os.DirFS(m.migrationsDir), ".")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create migrations source: %w", err)
+	}
+	defer source.Close()
+
+	version, err := source.First()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	for {
+		next, err := source.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read migrations: %w", err)
+		}
+		version = next
+	}
+}
+
 func (m *Migrator) initMigrate() (*migrate.Migrate, error) {
 	if _, err := os.Stat(m.migrationsDir); os.IsNotExist(err) {
 		return nil, fmt.Errorf("migrations directory does not exist: %w", err)
@@ -0,0 +1,318 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Arzeeq/pvz-api/internal/service"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// ErrOrderProductsNotStored is error of service, so it can tell products issued meanwhile from storage failure
+var ErrOrderProductsNotStored = service.ErrOrderProductsNotStored
+
+// orderColumns lists order columns in order of orderFields
+var orderColumns = []string{
+	"id", "pvz_id", "customer", "status", "created_at", "created_by", "pickup_code_expires_at", "issued_at", "issued_by",
+}
+
+func orderFields(o *dto.Order) []any {
+	return []any{&o.Id, &o.PvzId, &o.Customer, &o.Status, &o.CreatedAt, &o.CreatedBy, &o.PickupCodeExpiresAt, &o.IssuedAt, &o.IssuedBy}
+}
+
+type OrderStorage struct {
+	pool    *pgxpool.Pool
+	builder squirrel.StatementBuilderType
+}
+
+func NewOrderStorage(pool *pgxpool.Pool) (*OrderStorage, error) {
+	if pool == nil {
+		return nil, errors.New("nil values in NewOrderStorage constructor")
+	}
+
+	return &OrderStorage{
+		pool:    pool,
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}, nil
+}
+
+func (s *OrderStorage) CreateOrder(ctx context.Context, payload dto.PostOrdersJSONBody, createdBy *openapi_types.UUID) (*dto.Order, error) {
+	orderQuery, orderArgs, err := s.builder.
+		Insert("orders").
+		Columns("pvz_id", "customer", "created_by").
+		Values(payload.PvzId, payload.Customer, createdBy).
+		Suffix("RETURNING " + strings.Join(orderColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var order dto.Order
+	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, orderQuery, orderArgs...).Scan(orderFields(&order)...); err != nil {
+			return err
+		}
+
+		insert := s.builder.
+			Insert("order_products").
+			Columns("order_id", "product_id")
+		for _, productID := range payload.ProductIds {
+			insert = insert.Values(order.Id, productID)
+		}
+		query, args, err := insert.ToSql()
+		if err != nil {
+			return ErrBuildQuery
+		}
+
+		_, err = tx.Exec(ctx, query, args...)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	order.ProductIds = payload.ProductIds
+	return &order, nil
+}
+
+// GetOrder returns order with its products or nil if it does not exist
+func (s *OrderStorage) GetOrder(ctx context.Context, orderID openapi_types.UUID) (*dto.Order, error) {
+	query, args, err := s.builder.
+		Select(orderColumns...).
+		From("orders").
+		Where(squirrel.Eq{"id": orderID}).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var order dto.Order
+	err = s.pool.QueryRow(ctx, query, args...).Scan(orderFields(&order)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	productsQuery, productsArgs, err := s.builder.
+		Select("product_id").
+		From("order_products").
+		Where(squirrel.Eq{"order_id": orderID}).
+		OrderBy("product_id").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	rows, err := s.pool.Query(ctx, productsQuery, productsArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	order.ProductIds, err = pgx.CollectRows(rows, pgx.RowTo[openapi_types.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order products: %w", err)
+	}
+
+	return &order, nil
+}
+
+// GetPVZProducts returns products with given ids received by pvz,
+// products of other pvz are skipped
+func (s *OrderStorage) GetPVZProducts(ctx context.Context, pvzID openapi_types.UUID, productIDs []openapi_types.UUID) ([]dto.Product, error) {
+	query, args, err := s.builder.
+		Select(productColumns...).
+		From("products").
+		Where(squirrel.Eq{"id": productIDs}).
+		Where("reception_id IN (SELECT id FROM receptions WHERE pvz_id = ?)", pvzID).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]dto.Product, 0, len(productIDs))
+	for rows.Next() {
+		var product dto.Product
+		if err := rows.Scan(productFields(&product)...); err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return products, nil
+}
+
+// SetPickupCode replaces pickup code of order and resets failed attempts,
+// lockout of verification is kept until it expires
+func (s *OrderStorage) SetPickupCode(ctx context.Context, orderID openapi_types.UUID, codeHash string, expiresAt time.Time) error {
+	query, args, err := s.builder.
+		Update("orders").
+		Set("pickup_code_hash", codeHash).
+		Set("pickup_code_expires_at", expiresAt.UTC()).
+		Set("failed_attempts", 0).
+		Where(squirrel.Eq{"id": orderID}).
+		ToSql()
+	if err != nil {
+		return ErrBuildQuery
+	}
+
+	if _, err := s.pool.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to set pickup code: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimPickupAttempt counts attempt to verify pickup code before the code is compared and returns
+// the code, or nil when verification is locked. Check of lockout and counting are one statement,
+// so concurrent attempts can not exceed maxAttempts. Attempt reaching maxAttempts locks verification
+// until lockUntil, first attempt after lockout starts counting again
+func (s *OrderStorage) ClaimPickupAttempt(
+	ctx context.Context,
+	orderID openapi_types.UUID,
+	now time.Time,
+	maxAttempts int,
+	lockUntil time.Time,
+) (*dto.PickupCodeState, error) {
+	attempts := "CASE WHEN locked_until IS NULL THEN failed_attempts + 1 ELSE 1 END"
+	query, args, err := s.builder.
+		Update("orders").
+		Set("failed_attempts", squirrel.Expr(attempts)).
+		Set("locked_until", squirrel.Expr("CASE WHEN "+attempts+" >= ? THEN ?::timestamp END", maxAttempts, lockUntil.UTC())).
+		Where(squirrel.Eq{"id": orderID}).
+		Where("(locked_until IS NULL OR locked_until <= ?)", now.UTC()).
+		Suffix("RETURNING pickup_code_hash, pickup_code_expires_at, failed_attempts, locked_until").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var state dto.PickupCodeState
+	err = s.pool.QueryRow(ctx, query, args...).Scan(&state.CodeHash, &state.ExpiresAt, &state.FailedAttempts, &state.LockedUntil)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim pickup attempt: %w", err)
+	}
+
+	return &state, nil
+}
+
+// ResetPickupAttempts forgets attempts counted before valid pickup code was entered
+func (s *OrderStorage) ResetPickupAttempts(ctx context.Context, orderID openapi_types.UUID) error {
+	query, args, err := s.builder.
+		Update("orders").
+		Set("failed_attempts", 0).
+		Set("locked_until", nil).
+		Where(squirrel.Eq{"id": orderID}).
+		ToSql()
+	if err != nil {
+		return ErrBuildQuery
+	}
+
+	if _, err := s.pool.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to reset pickup attempts: %w", err)
+	}
+
+	return nil
+}
+
+// IssueOrder marks order and all its stored products as issued and invalidates pickup code,
+// fails when some of products are not stored anymore or are in return list of pvz,
+// returns nil when order was already issued
+func (s *OrderStorage) IssueOrder(ctx context.Context, orderID openapi_types.UUID, issuedBy *openapi_types.UUID) (*dto.Order, error) {
+	orderQuery, orderArgs, err := s.builder.
+		Update("orders").
+		Set("status", dto.OrderIssued).
+		Set("issued_at", squirrel.Expr("NOW()")).
+		Set("issued_by", issuedBy).
+		Set("pickup_code_hash", nil).
+		Where(squirrel.Eq{
+			"id":     orderID,
+			"status": dto.OrderCreated,
+		}).
+		Suffix("RETURNING " + strings.Join(orderColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	productsQuery, productsArgs, err := s.builder.
+		Update("products").
+		Set("status", dto.ProductIssued).
+		Set("issued_at", squirrel.Expr("NOW()")).
+		Where("id IN (SELECT product_id FROM order_products WHERE order_id = ?)", orderID).
+		Where(squirrel.Eq{
+			"status":     dto.ProductStored,
+			"expired_at": nil,
+		}).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	countQuery, countArgs, err := s.builder.
+		Select("COUNT(*)").
+		From("order_products").
+		Where(squirrel.Eq{"order_id": orderID}).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var order *dto.Order
+	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var issued dto.Order
+		err := tx.QueryRow(ctx, orderQuery, orderArgs...).Scan(orderFields(&issued)...)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx, productsQuery, productsArgs...)
+		if err != nil {
+			return err
+		}
+		issued.ProductIds, err = pgx.CollectRows(rows, pgx.RowTo[openapi_types.UUID])
+		if err != nil {
+			return err
+		}
+
+		var count int
+		if err := tx.QueryRow(ctx, countQuery, countArgs...).Scan(&count); err != nil {
+			return err
+		}
+		if count != len(issued.ProductIds) {
+			return ErrOrderProductsNotStored
+		}
+
+		order = &issued
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue order: %w", err)
+	}
+
+	return order, nil
+}
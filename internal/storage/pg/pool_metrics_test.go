@@ -0,0 +1,23 @@
+package pg
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPoolCollector(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		pool := &pgxpool.Pool{}
+		collector, err := NewPoolCollector(pool)
+		require.NoError(t, err)
+		require.NotNil(t, collector)
+	})
+
+	t.Run("nil pool", func(t *testing.T) {
+		collector, err := NewPoolCollector(nil)
+		require.Error(t, err)
+		require.Nil(t, collector)
+	})
+}
@@ -4,14 +4,45 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/Arzeeq/pvz-api/internal/dto"
-	"github.com/Arzeeq/pvz-api/internal/metrics"
 	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+// productColumns lists product columns in order of productFields
+var productColumns = []string{
+	"id", "date_time", "type", "reception_id", "barcode", "status", "stored_at", "issued_at", "returned_at", "expired_at",
+}
+
+func productFields(p *dto.Product) []any {
+	return []any{
+		&p.Id, &p.DateTime, &p.Type, &p.ReceptionId, &p.Barcode, &p.Status, &p.StoredAt, &p.IssuedAt, &p.ReturnedAt, &p.ExpiredAt,
+	}
+}
+
+// statusTimeColumns maps product status to column with time of transition to it
+var statusTimeColumns = map[dto.ProductStatus]string{
+	dto.ProductStored:           "stored_at",
+	dto.ProductIssued:           "issued_at",
+	dto.ProductReturnedToSender: "returned_at",
+}
+
+// uniqueViolation is code of error returned when unique index is violated
+const uniqueViolation = "23505"
+
+// isUniqueViolation reports whether product clashed with unique index, the only one
+// products have is on barcode within reception
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
 type ProductStorage struct {
 	pool    *pgxpool.Pool
 	builder squirrel.StatementBuilderType
@@ -30,7 +61,7 @@ func NewProductStorage(pool *pgxpool.Pool) (*ProductStorage, error) {
 
 func (s *ProductStorage) GetReceptionProducts(ctx context.Context, receptionId openapi_types.UUID) []dto.Product {
 	query, args, err := s.builder.
-		Select("id", "date_time", "type", "reception_id").
+		Select(productColumns...).
 		From("products").
 		Where(squirrel.Eq{"reception_id": receptionId}).
 		OrderBy("date_time").
@@ -48,12 +79,7 @@ func (s *ProductStorage) GetReceptionProducts(ctx context.Context, receptionId o
 	var products []dto.Product
 	for rows.Next() {
 		var product dto.Product
-		if err := rows.Scan(
-			&product.Id,
-			&product.DateTime,
-			&product.Type,
-			&product.ReceptionId,
-		); err != nil {
+		if err := rows.Scan(productFields(&product)...); err != nil {
 			return nil
 		}
 		products = append(products, product)
@@ -66,6 +92,8 @@ func (s *ProductStorage) GetReceptionProducts(ctx context.Context, receptionId o
 	return products
 }
 
+// CreateProduct adds product to in progress reception of pvz,
+// returns nil when product with the same barcode is already in reception
 func (s *ProductStorage) CreateProduct(ctx context.Context, productDto dto.PostProductsJSONBody) (*dto.Product, error) {
 	receptionQuery, receptionArgs, err := s.builder.
 		Select("id").
@@ -74,40 +102,112 @@ func (s *ProductStorage) CreateProduct(ctx context.Context, productDto dto.PostP
 			"pvz_id": productDto.PvzId,
 			"status": "in_progress",
 		}).
+		Suffix("FOR UPDATE").
 		ToSql()
 	if err != nil {
 		return nil, ErrBuildQuery
 	}
 
-	var receptionID openapi_types.UUID
-	err = s.pool.QueryRow(ctx, receptionQuery, receptionArgs...).Scan(&receptionID)
+	// reception is locked, so it can not be closed before product is added
+	var product dto.Product
+	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var receptionID openapi_types.UUID
+		if err := tx.QueryRow(ctx, receptionQuery, receptionArgs...).Scan(&receptionID); err != nil {
+			return err
+		}
+
+		productQuery, productArgs, err := s.builder.
+			Insert("products").
+			Columns("type", "reception_id", "barcode").
+			Values(string(productDto.Type), receptionID, productDto.Barcode).
+			Suffix("RETURNING " + strings.Join(productColumns, ", ")).
+			ToSql()
+		if err != nil {
+			return ErrBuildQuery
+		}
+
+		return tx.QueryRow(ctx, productQuery, productArgs...).Scan(productFields(&product)...)
+	})
+	if isUniqueViolation(err) {
+		return nil, nil
+	}
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to create product: %w", err)
 	}
 
-	productQuery, productArgs, err := s.builder.
-		Insert("products").
-		Columns("type", "reception_id").
-		Values(string(productDto.Type), receptionID).
-		Suffix("RETURNING id, date_time, type, reception_id").
+	return &product, nil
+}
+
+// CreateProducts adds products to in progress reception of pvz with one multi-row insert,
+// products are returned in order of items. Returns nil and adds nothing when barcode
+// of some product is already in reception
+func (s *ProductStorage) CreateProducts(ctx context.Context, pvzID openapi_types.UUID, items []dto.ProductBatchItem) ([]dto.Product, error) {
+	receptionQuery, receptionArgs, err := s.builder.
+		Select("id").
+		From("receptions").
+		Where(squirrel.Eq{
+			"pvz_id": pvzID,
+			"status": dto.InProgress,
+		}).
+		Suffix("FOR UPDATE").
 		ToSql()
 	if err != nil {
 		return nil, ErrBuildQuery
 	}
 
-	var product dto.Product
-	err = s.pool.QueryRow(ctx, productQuery, productArgs...).Scan(
-		&product.Id,
-		&product.DateTime,
-		&product.Type,
-		&product.ReceptionId,
-	)
+	products := make([]dto.Product, 0, len(items))
+	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var receptionID openapi_types.UUID
+		err := tx.QueryRow(ctx, receptionQuery, receptionArgs...).Scan(&receptionID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNoActiveReception
+		}
+		if err != nil {
+			return err
+		}
+
+		// products of one batch get increasing date_time to keep their order in reception
+		insert := s.builder.
+			Insert("products").
+			Columns("date_time", "type", "reception_id", "barcode")
+		for i, item := range items {
+			insert = insert.Values(
+				squirrel.Expr("NOW() + ? * INTERVAL '1 microsecond'", i),
+				string(item.Type), receptionID, item.Barcode,
+			)
+		}
+		query, args, err := insert.
+			Suffix("RETURNING " + strings.Join(productColumns, ", ")).
+			ToSql()
+		if err != nil {
+			return ErrBuildQuery
+		}
+
+		rows, err := tx.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var product dto.Product
+			if err := rows.Scan(productFields(&product)...); err != nil {
+				return err
+			}
+			products = append(products, product)
+		}
+
+		return rows.Err()
+	})
+	if isUniqueViolation(err) {
+		return nil, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create product: %w", err)
+		return nil, fmt.Errorf("failed to create products: %w", err)
 	}
 
-	metrics.ProductsAddedTotal.Inc()
-	return &product, nil
+	sort.Slice(products, func(i, j int) bool { return products[i].DateTime.Before(*products[j].DateTime) })
+	return products, nil
 }
 
 func (s *ProductStorage) GetLastProduct(ctx context.Context, pvzId openapi_types.UUID) (*dto.Product, error) {
@@ -131,7 +231,7 @@ func (s *ProductStorage) GetLastProduct(ctx context.Context, pvzId openapi_types
 	}
 
 	productSelectQuery, productSelectArgs, err := s.builder.
-		Select("id", "date_time", "reception_id", "type").
+		Select(productColumns...).
 		From("products").
 		Where(squirrel.Eq{"reception_id": receptionID}).
 		OrderBy("date_time DESC").
@@ -142,12 +242,7 @@ func (s *ProductStorage) GetLastProduct(ctx context.Context, pvzId openapi_types
 	}
 
 	var product dto.Product
-	err = s.pool.QueryRow(ctx, productSelectQuery, productSelectArgs...).Scan(
-		&product.Id,
-		&product.DateTime,
-		&product.ReceptionId,
-		&product.Type,
-	)
+	err = s.pool.QueryRow(ctx, productSelectQuery, productSelectArgs...).Scan(productFields(&product)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get last product: %w", err)
 	}
@@ -155,19 +250,243 @@ func (s *ProductStorage) GetLastProduct(ctx context.Context, pvzId openapi_types
 	return &product, nil
 }
 
-func (s *ProductStorage) DeleteProduct(ctx context.Context, productID openapi_types.UUID) error {
+// DeleteProduct deletes product and records deletion in audit trail
+func (s *ProductStorage) DeleteProduct(ctx context.Context, productID openapi_types.UUID, deletedBy *openapi_types.UUID) error {
 	query, args, err := s.builder.
 		Delete("products").
 		Where(squirrel.Eq{"id": productID}).
+		Suffix("RETURNING " + strings.Join(productColumns, ", ")).
 		ToSql()
 	if err != nil {
 		return ErrBuildQuery
 	}
 
-	_, err = s.pool.Exec(ctx, query, args...)
+	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var product dto.Product
+		if err := tx.QueryRow(ctx, query, args...).Scan(productFields(&product)...); err != nil {
+			return err
+		}
+		return s.recordDeletion(ctx, tx, product, deletedBy)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to delete product: %w", err)
 	}
 
 	return nil
 }
+
+// DeleteReceptionProduct deletes product of reception only while reception is in progress,
+// returns false when there is no such product in open reception
+func (s *ProductStorage) DeleteReceptionProduct(
+	ctx context.Context,
+	receptionID openapi_types.UUID,
+	productID openapi_types.UUID,
+	deletedBy *openapi_types.UUID,
+) (bool, error) {
+	receptionQuery, receptionArgs, err := s.builder.
+		Select("id").
+		From("receptions").
+		Where(squirrel.Eq{
+			"id":     receptionID,
+			"status": dto.InProgress,
+		}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return false, ErrBuildQuery
+	}
+
+	query, args, err := s.builder.
+		Delete("products").
+		Where(squirrel.Eq{
+			"id":           productID,
+			"reception_id": receptionID,
+			"status":       dto.ProductReceived,
+		}).
+		Suffix("RETURNING " + strings.Join(productColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return false, ErrBuildQuery
+	}
+
+	// reception is locked, so it can not be closed while product is deleted
+	deleted := false
+	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var lockedID openapi_types.UUID
+		err := tx.QueryRow(ctx, receptionQuery, receptionArgs...).Scan(&lockedID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var product dto.Product
+		err = tx.QueryRow(ctx, query, args...).Scan(productFields(&product)...)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		deleted = true
+		return s.recordDeletion(ctx, tx, product, deletedBy)
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete product: %w", err)
+	}
+
+	return deleted, nil
+}
+
+func (s *ProductStorage) recordDeletion(ctx context.Context, tx pgx.Tx, product dto.Product, deletedBy *openapi_types.UUID) error {
+	query, args, err := s.builder.
+		Insert("product_deletions").
+		Columns("product_id", "reception_id", "type", "barcode", "added_at", "deleted_by").
+		Values(product.Id, product.ReceptionId, product.Type, product.Barcode, product.DateTime, deletedBy).
+		ToSql()
+	if err != nil {
+		return ErrBuildQuery
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to record product deletion: %w", err)
+	}
+
+	return nil
+}
+
+// GetOpenReceptionProductByBarcode returns product with barcode from in progress
+// reception of pvz or nil if there is none
+func (s *ProductStorage) GetOpenReceptionProductByBarcode(ctx context.Context, pvzID openapi_types.UUID, barcode string) (*dto.Product, error) {
+	query, args, err := s.builder.
+		Select(productColumns...).
+		From("products").
+		Where(squirrel.Eq{"barcode": barcode}).
+		Where("reception_id IN (SELECT id FROM receptions WHERE pvz_id = ? AND status = ?)", pvzID, dto.InProgress).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var product dto.Product
+	err = s.pool.QueryRow(ctx, query, args...).Scan(productFields(&product)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product by barcode: %w", err)
+	}
+
+	return &product, nil
+}
+
+// GetProducts returns products matching params, newest first
+func (s *ProductStorage) GetProducts(ctx context.Context, params dto.GetProductsParams) ([]dto.Product, error) {
+	offset := (*params.Page - 1) * (*params.Limit)
+	builder := s.builder.
+		Select(productColumns...).
+		From("products")
+	if params.Barcode != nil {
+		builder = builder.Where(squirrel.Eq{"barcode": *params.Barcode})
+	}
+	if params.PvzId != nil {
+		builder = builder.Where("reception_id IN (SELECT id FROM receptions WHERE pvz_id = ?)", *params.PvzId)
+	}
+	if params.Status != nil {
+		builder = builder.Where(squirrel.Eq{"status": *params.Status})
+	}
+
+	query, args, err := builder.
+		OrderBy("date_time DESC").
+		Offset(uint64(offset)).
+		Limit(uint64(*params.Limit)).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]dto.Product, 0)
+	for rows.Next() {
+		var product dto.Product
+		if err := rows.Scan(productFields(&product)...); err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return products, nil
+}
+
+// GetProduct returns product by id or nil if it does not exist
+func (s *ProductStorage) GetProduct(ctx context.Context, productID openapi_types.UUID) (*dto.Product, error) {
+	query, args, err := s.builder.
+		Select(productColumns...).
+		From("products").
+		Where(squirrel.Eq{"id": productID}).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var product dto.Product
+	err = s.pool.QueryRow(ctx, query, args...).Scan(productFields(&product)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	return &product, nil
+}
+
+// UpdateProductStatus moves product from status to another one and sets time of transition,
+// returns nil when product is not in status from anymore or when expired product is issued
+func (s *ProductStorage) UpdateProductStatus(
+	ctx context.Context,
+	productID openapi_types.UUID,
+	from dto.ProductStatus,
+	to dto.ProductStatus,
+) (*dto.Product, error) {
+	update := s.builder.
+		Update("products").
+		Set("status", to).
+		Set(statusTimeColumns[to], squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{
+			"id":     productID,
+			"status": from,
+		})
+	// expired product is in return list of pvz and must not be issued
+	if to == dto.ProductIssued {
+		update = update.Where(squirrel.Eq{"expired_at": nil})
+	}
+	query, args, err := update.
+		Suffix("RETURNING " + strings.Join(productColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var product dto.Product
+	err = s.pool.QueryRow(ctx, query, args...).Scan(productFields(&product)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update product status: %w", err)
+	}
+
+	return &product, nil
+}
@@ -4,18 +4,30 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Arzeeq/pvz-api/internal/dto"
-	"github.com/Arzeeq/pvz-api/internal/metrics"
+	"github.com/Arzeeq/pvz-api/internal/service"
 	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
-var ErrNoActiveReception = errors.New("no active receptions found in pvz")
+// ErrNoActiveReception is error of service, so it can tell missing reception from storage failure
+var ErrNoActiveReception = service.ErrNoActiveReception
 var ErrBuildQuery = errors.New("failed to build query")
 
+// receptionColumns lists reception columns in order of receptionFields
+var receptionColumns = []string{
+	"id", "date_time", "pvz_id", "status", "closed_at", "opened_by", "closed_by", "manifest", "discrepancy_report",
+}
+
+func receptionFields(r *dto.Reception) []any {
+	return []any{&r.Id, &r.DateTime, &r.PvzId, &r.Status, &r.ClosedAt, &r.OpenedBy, &r.ClosedBy, &r.Manifest, &r.DiscrepancyReport}
+}
+
 type ReceptionStorage struct {
 	pool    *pgxpool.Pool
 	builder squirrel.StatementBuilderType
@@ -32,64 +44,116 @@ func NewReceptionStorage(pool *pgxpool.Pool) (*ReceptionStorage, error) {
 	}, nil
 }
 
-func (s *ReceptionStorage) CreateReception(ctx context.Context, pvzID openapi_types.UUID) (*dto.Reception, error) {
+func (s *ReceptionStorage) CreateReception(
+	ctx context.Context,
+	pvzID openapi_types.UUID,
+	manifest *dto.ReceptionManifest,
+	openedBy *openapi_types.UUID,
+) (*dto.Reception, error) {
 	query, args, err := s.builder.
 		Insert("receptions").
-		Columns("pvz_id", "status").
-		Values(pvzID, "in_progress").
-		Suffix("RETURNING id, date_time, pvz_id, status").
+		Columns("pvz_id", "status", "manifest", "opened_by").
+		Values(pvzID, "in_progress", manifest, openedBy).
+		Suffix("RETURNING " + strings.Join(receptionColumns, ", ")).
 		ToSql()
 	if err != nil {
 		return nil, ErrBuildQuery
 	}
 
 	var reception dto.Reception
-	err = s.pool.QueryRow(ctx, query, args...).Scan(
-		&reception.Id,
-		&reception.DateTime,
-		&reception.PvzId,
-		&reception.Status,
-	)
+	err = s.pool.QueryRow(ctx, query, args...).Scan(receptionFields(&reception)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create reception: %w", err)
 	}
 
-	metrics.ReceptionsCreatedTotal.Inc()
 	return &reception, nil
 }
 
-func (s *ReceptionStorage) CloseReception(ctx context.Context, pvzID openapi_types.UUID) (*dto.Reception, error) {
-	query, args, err := s.builder.
-		Update("receptions").
-		Set("status", dto.Close).
+// CloseReception locks in progress reception of pvz, so products can not be added or deleted
+// until it is closed, and closes it with report built from its products
+func (s *ReceptionStorage) CloseReception(
+	ctx context.Context,
+	pvzID openapi_types.UUID,
+	closedBy *openapi_types.UUID,
+	report func(reception dto.Reception, products []dto.Product) *dto.DiscrepancyReport,
+) (*dto.Reception, int, error) {
+	activeQuery, activeArgs, err := s.builder.
+		Select(receptionColumns...).
+		From("receptions").
 		Where(squirrel.Eq{
 			"pvz_id": pvzID,
 			"status": dto.InProgress,
 		}).
-		Suffix("RETURNING id, date_time, pvz_id, status").
+		Suffix("FOR UPDATE").
 		ToSql()
 	if err != nil {
-		return nil, ErrBuildQuery
+		return nil, 0, ErrBuildQuery
 	}
 
-	var reception dto.Reception
-	err = s.pool.QueryRow(ctx, query, args...).Scan(
-		&reception.Id,
-		&reception.DateTime,
-		&reception.PvzId,
-		&reception.Status,
-	)
+	var reception *dto.Reception
+	var products []dto.Product
+	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var active dto.Reception
+		err := tx.QueryRow(ctx, activeQuery, activeArgs...).Scan(receptionFields(&active)...)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		productsQuery, productsArgs, err := s.builder.
+			Select(productColumns...).
+			From("products").
+			Where(squirrel.Eq{"reception_id": active.Id}).
+			OrderBy("date_time").
+			ToSql()
+		if err != nil {
+			return ErrBuildQuery
+		}
+		rows, err := tx.Query(ctx, productsQuery, productsArgs...)
+		if err != nil {
+			return err
+		}
+		products, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (dto.Product, error) {
+			var product dto.Product
+			err := row.Scan(productFields(&product)...)
+			return product, err
+		})
+		if err != nil {
+			return err
+		}
 
+		query, args, err := s.builder.
+			Update("receptions").
+			Set("status", dto.Close).
+			Set("closed_at", squirrel.Expr("NOW()")).
+			Set("closed_by", closedBy).
+			Set("discrepancy_report", report(active, products)).
+			Where(squirrel.Eq{"id": active.Id}).
+			Suffix("RETURNING " + strings.Join(receptionColumns, ", ")).
+			ToSql()
+		if err != nil {
+			return ErrBuildQuery
+		}
+
+		var closed dto.Reception
+		if err := tx.QueryRow(ctx, query, args...).Scan(receptionFields(&closed)...); err != nil {
+			return err
+		}
+		reception = &closed
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to close reception: %w", err)
+		return nil, 0, fmt.Errorf("failed to close reception: %w", err)
 	}
 
-	return &reception, nil
+	return reception, len(products), nil
 }
 
 func (s *ReceptionStorage) GetPVZReceptionsFiltered(ctx context.Context, pvzID openapi_types.UUID, startDate, endDate time.Time) []dto.Reception {
 	query, args, err := s.builder.
-		Select("id", "date_time", "pvz_id", "status").
+		Select(receptionColumns...).
 		From("receptions").
 		Where(squirrel.And{
 			squirrel.GtOrEq{"date_time": startDate},
@@ -111,7 +175,7 @@ func (s *ReceptionStorage) GetPVZReceptionsFiltered(ctx context.Context, pvzID o
 	var receptions []dto.Reception
 	for rows.Next() {
 		var r dto.Reception
-		if err := rows.Scan(&r.Id, &r.DateTime, &r.PvzId, &r.Status); err != nil {
+		if err := rows.Scan(receptionFields(&r)...); err != nil {
 			return nil
 		}
 		receptions = append(receptions, r)
@@ -123,3 +187,97 @@ func (s *ReceptionStorage) GetPVZReceptionsFiltered(ctx context.Context, pvzID o
 
 	return receptions
 }
+
+// GetReception returns reception by id or nil if it does not exist
+func (s *ReceptionStorage) GetReception(ctx context.Context, receptionID openapi_types.UUID) (*dto.Reception, error) {
+	query, args, err := s.builder.
+		Select(receptionColumns...).
+		From("receptions").
+		Where(squirrel.Eq{"id": receptionID}).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var reception dto.Reception
+	err = s.pool.QueryRow(ctx, query, args...).Scan(receptionFields(&reception)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reception: %w", err)
+	}
+
+	return &reception, nil
+}
+
+// GetActiveReception returns in progress reception of pvz or nil if there is none
+func (s *ReceptionStorage) GetActiveReception(ctx context.Context, pvzID openapi_types.UUID) (*dto.Reception, error) {
+	query, args, err := s.builder.
+		Select(receptionColumns...).
+		From("receptions").
+		Where(squirrel.Eq{
+			"pvz_id": pvzID,
+			"status": dto.InProgress,
+		}).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var reception dto.Reception
+	err = s.pool.QueryRow(ctx, query, args...).Scan(receptionFields(&reception)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active reception: %w", err)
+	}
+
+	return &reception, nil
+}
+
+func (s *ReceptionStorage) GetReceptions(ctx context.Context, pvzID openapi_types.UUID, params dto.GetPvzPvzIdReceptionsParams) ([]dto.Reception, error) {
+	offset := (*params.Page - 1) * (*params.Limit)
+	builder := s.builder.
+		Select(receptionColumns...).
+		From("receptions").
+		Where(squirrel.Eq{"pvz_id": pvzID}).
+		Where(squirrel.And{
+			squirrel.GtOrEq{"date_time": *params.StartDate},
+			squirrel.LtOrEq{"date_time": *params.EndDate},
+		})
+	if params.Status != nil {
+		builder = builder.Where(squirrel.Eq{"status": *params.Status})
+	}
+
+	query, args, err := builder.
+		OrderBy("date_time DESC").
+		Offset(uint64(offset)).
+		Limit(uint64(*params.Limit)).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	receptions := make([]dto.Reception, 0)
+	for rows.Next() {
+		var r dto.Reception
+		if err := rows.Scan(receptionFields(&r)...); err != nil {
+			return nil, fmt.Errorf("failed to scan reception: %w", err)
+		}
+		receptions = append(receptions, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return receptions, nil
+}
@@ -0,0 +1,416 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Arzeeq/pvz-api/internal/service"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// integrityViolationClass is class of postgres error codes for violated constraints
+const integrityViolationClass = "23"
+
+// SyncStorage applies changes uploaded by offline PVZ nodes
+type SyncStorage struct {
+	pool    *pgxpool.Pool
+	builder squirrel.StatementBuilderType
+}
+
+func NewSyncStorage(pool *pgxpool.Pool) (*SyncStorage, error) {
+	if pool == nil {
+		return nil, errors.New("nil values in NewSyncStorage constructor")
+	}
+
+	return &SyncStorage{
+		pool:    pool,
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}, nil
+}
+
+// InBatch reserves batch id and runs apply in one transaction. Reservation is a row of the batch,
+// so concurrent upload of the same batch waits for the transaction and then reads its result
+func (s *SyncStorage) InBatch(
+	ctx context.Context,
+	batchID openapi_types.UUID,
+	apply func(ctx context.Context, tx service.SyncTx) (*dto.SyncBatchResult, error),
+) (*dto.SyncBatchResult, error) {
+	var result *dto.SyncBatchResult
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		reserved, err := s.reserveBatch(ctx, tx, batchID)
+		if err != nil {
+			return err
+		}
+		if !reserved {
+			result, err = s.getBatchResult(ctx, tx, batchID)
+			return err
+		}
+
+		if result, err = apply(ctx, &syncTx{tx: tx, builder: s.builder}); err != nil {
+			return err
+		}
+		return s.saveBatchResult(ctx, tx, *result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *SyncStorage) reserveBatch(ctx context.Context, tx pgx.Tx, batchID openapi_types.UUID) (bool, error) {
+	query, args, err := s.builder.
+		Insert("sync_batches").
+		Columns("id", "result").
+		Values(batchID, "null").
+		Suffix("ON CONFLICT (id) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return false, ErrBuildQuery
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve sync batch: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (s *SyncStorage) getBatchResult(ctx context.Context, tx pgx.Tx, batchID openapi_types.UUID) (*dto.SyncBatchResult, error) {
+	query, args, err := s.builder.
+		Select("result").
+		From("sync_batches").
+		Where(squirrel.Eq{"id": batchID}).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var raw []byte
+	if err := tx.QueryRow(ctx, query, args...).Scan(&raw); err != nil {
+		return nil, fmt.Errorf("failed to get sync batch: %w", err)
+	}
+
+	var result dto.SyncBatchResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode sync batch result: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (s *SyncStorage) saveBatchResult(ctx context.Context, tx pgx.Tx, result dto.SyncBatchResult) error {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode sync batch result: %w", err)
+	}
+
+	query, args, err := s.builder.
+		Update("sync_batches").
+		Set("result", raw).
+		Where(squirrel.Eq{"id": result.BatchId}).
+		ToSql()
+	if err != nil {
+		return ErrBuildQuery
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to save sync batch: %w", err)
+	}
+
+	return nil
+}
+
+// syncTx applies items of sync batch inside its transaction.
+// All inserts are idempotent by entity id, so a replayed item never creates duplicates.
+type syncTx struct {
+	tx      pgx.Tx
+	builder squirrel.StatementBuilderType
+}
+
+// rejectedError is violation of constraint by sync item, applying it again fails the same way
+type rejectedError struct {
+	err error
+}
+
+func (e rejectedError) Error() string  { return e.err.Error() }
+func (e rejectedError) Unwrap() error  { return e.err }
+func (e rejectedError) Rejected() bool { return true }
+
+// Item runs apply in savepoint, so failed item does not abort transaction of the batch
+func (t *syncTx) Item(ctx context.Context, apply func() error) error {
+	savepoint, err := t.tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+
+	if err := apply(); err != nil {
+		if errRollback := savepoint.Rollback(ctx); errRollback != nil {
+			return fmt.Errorf("failed to rollback savepoint: %w", errRollback)
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && strings.HasPrefix(pgErr.Code, integrityViolationClass) {
+			return rejectedError{err: err}
+		}
+		return err
+	}
+
+	return savepoint.Commit(ctx)
+}
+
+func (t *syncTx) InsertPVZ(ctx context.Context, pvz dto.PVZ) (bool, error) {
+	query, args, err := t.builder.
+		Insert("pvz").
+		Columns("id", "registration_date", "city").
+		Values(pvz.Id, pvz.RegistrationDate, pvz.City).
+		Suffix("ON CONFLICT (id) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return false, ErrBuildQuery
+	}
+
+	tag, err := t.tx.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert PVZ: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (t *syncTx) InsertReception(ctx context.Context, reception dto.Reception) (bool, error) {
+	query, args, err := t.builder.
+		Insert("receptions").
+		Columns(receptionColumns...).
+		Values(
+			reception.Id,
+			reception.DateTime,
+			reception.PvzId,
+			reception.Status,
+			reception.ClosedAt,
+			reception.OpenedBy,
+			reception.ClosedBy,
+			reception.Manifest,
+			reception.DiscrepancyReport,
+		).
+		Suffix("ON CONFLICT (id) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return false, ErrBuildQuery
+	}
+
+	tag, err := t.tx.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert reception: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (t *syncTx) HasReception(ctx context.Context, receptionID openapi_types.UUID) (bool, error) {
+	query, args, err := t.builder.
+		Select("1").
+		From("receptions").
+		Where(squirrel.Eq{"id": receptionID}).
+		Prefix("SELECT EXISTS (").
+		Suffix(")").
+		ToSql()
+	if err != nil {
+		return false, ErrBuildQuery
+	}
+
+	var exists bool
+	if err := t.tx.QueryRow(ctx, query, args...).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check reception: %w", err)
+	}
+
+	return exists, nil
+}
+
+// GetActiveReception locks pvz, so batches of the same pvz are applied one by one,
+// and returns its in progress reception locked against closing by live requests or nil
+func (t *syncTx) GetActiveReception(ctx context.Context, pvzID openapi_types.UUID) (*dto.Reception, error) {
+	query, args, err := t.builder.
+		Select("id").
+		From("pvz").
+		Where(squirrel.Eq{"id": pvzID}).
+		Suffix("FOR NO KEY UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	if _, err := t.tx.Exec(ctx, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to lock PVZ: %w", err)
+	}
+
+	query, args, err = t.builder.
+		Select(receptionColumns...).
+		From("receptions").
+		Where(squirrel.Eq{
+			"pvz_id": pvzID,
+			"status": dto.InProgress,
+		}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var reception dto.Reception
+	err = t.tx.QueryRow(ctx, query, args...).Scan(receptionFields(&reception)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active reception: %w", err)
+	}
+
+	return &reception, nil
+}
+
+// CloseReceptionByID returns closed reception with number of its products, nil when reception is not in progress
+func (t *syncTx) CloseReceptionByID(
+	ctx context.Context,
+	receptionID openapi_types.UUID,
+	closedAt time.Time,
+	closedBy *openapi_types.UUID,
+	report *dto.DiscrepancyReport,
+) (*dto.Reception, int, error) {
+	query, args, err := t.builder.
+		Update("receptions").
+		Set("status", dto.Close).
+		Set("closed_at", closedAt).
+		Set("closed_by", closedBy).
+		Set("discrepancy_report", report).
+		Where(squirrel.Eq{
+			"id":     receptionID,
+			"status": dto.InProgress,
+		}).
+		Suffix("RETURNING " + strings.Join(receptionColumns, ", ") +
+			", (SELECT COUNT(*) FROM products WHERE products.reception_id = receptions.id)").
+		ToSql()
+	if err != nil {
+		return nil, 0, ErrBuildQuery
+	}
+
+	var reception dto.Reception
+	var products int
+	err = t.tx.QueryRow(ctx, query, args...).Scan(append(receptionFields(&reception), &products)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to close reception: %w", err)
+	}
+
+	return &reception, products, nil
+}
+
+// InsertProduct returns pvz of reception product was added to, nil when product already exists
+func (t *syncTx) InsertProduct(ctx context.Context, product dto.Product) (*openapi_types.UUID, error) {
+	// nodes without product lifecycle do not send status
+	status := dto.ProductReceived
+	if product.Status != nil {
+		status = *product.Status
+	}
+
+	query, args, err := t.builder.
+		Insert("products").
+		Columns(productColumns...).
+		Values(
+			product.Id, product.DateTime, product.Type, product.ReceptionId, product.Barcode,
+			status, product.StoredAt, product.IssuedAt, product.ReturnedAt, product.ExpiredAt,
+		).
+		Suffix("ON CONFLICT (id) DO NOTHING " +
+			"RETURNING (SELECT pvz_id FROM receptions WHERE receptions.id = products.reception_id)").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var pvzID openapi_types.UUID
+	err = t.tx.QueryRow(ctx, query, args...).Scan(&pvzID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert product: %w", err)
+	}
+
+	return &pvzID, nil
+}
+
+// UpdateProductStatusByID sets status and transition times of product as they are on node,
+// product is changed only when its status is one of from
+func (t *syncTx) UpdateProductStatusByID(ctx context.Context, product dto.Product, from []dto.ProductStatus) (bool, error) {
+	query, args, err := t.builder.
+		Update("products").
+		Set("status", product.Status).
+		Set("stored_at", product.StoredAt).
+		Set("issued_at", product.IssuedAt).
+		Set("returned_at", product.ReturnedAt).
+		Where(squirrel.Eq{
+			"id":     product.Id,
+			"status": from,
+		}).
+		ToSql()
+	if err != nil {
+		return false, ErrBuildQuery
+	}
+
+	tag, err := t.tx.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update product status: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (t *syncTx) GetProductStatus(ctx context.Context, productID openapi_types.UUID) (*dto.ProductStatus, error) {
+	query, args, err := t.builder.
+		Select("status").
+		From("products").
+		Where(squirrel.Eq{"id": productID}).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var status dto.ProductStatus
+	err = t.tx.QueryRow(ctx, query, args...).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product status: %w", err)
+	}
+
+	return &status, nil
+}
+
+func (t *syncTx) DeleteProductByID(ctx context.Context, productID openapi_types.UUID) (bool, error) {
+	query, args, err := t.builder.
+		Delete("products").
+		Where(squirrel.Eq{"id": productID}).
+		ToSql()
+	if err != nil {
+		return false, ErrBuildQuery
+	}
+
+	tag, err := t.tx.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete product: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,693 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+var errTransferProductsUnavailable = errors.New("not all products of transfer are kept in pvz")
+
+// transferColumns lists transfer columns in order of transferFields
+var transferColumns = []string{
+	"id", "from_pvz_id", "to_pvz_id", "status", "created_at", "created_by",
+	"shipped_at", "shipped_by", "received_at", "received_by", "reception_id",
+}
+
+func transferFields(t *dto.Transfer) []any {
+	return []any{
+		&t.Id, &t.FromPvzId, &t.ToPvzId, &t.Status, &t.CreatedAt, &t.CreatedBy,
+		&t.ShippedAt, &t.ShippedBy, &t.ReceivedAt, &t.ReceivedBy, &t.ReceptionId,
+	}
+}
+
+// productPathQuery walks transfer_products back to the first reception of product
+// and forward to its latest copy, depth orders steps of the path
+const productPathQuery = `
+WITH RECURSIVE back(product_id, depth) AS (
+	SELECT $1::uuid, 0
+	UNION ALL
+	SELECT tp.product_id, back.depth - 1 FROM transfer_products tp JOIN back ON tp.received_product_id = back.product_id
+), forward(product_id, depth) AS (
+	SELECT $1::uuid, 0
+	UNION ALL
+	SELECT tp.received_product_id, forward.depth + 1 FROM transfer_products tp JOIN forward ON tp.product_id = forward.product_id
+	WHERE tp.received_product_id IS NOT NULL
+), path AS (
+	SELECT product_id, depth FROM back
+	UNION
+	SELECT product_id, depth FROM forward
+)
+SELECT %s, r.pvz_id, tp.transfer_id, t.status
+FROM path
+JOIN products ON products.id = path.product_id
+JOIN receptions r ON r.id = products.reception_id
+LEFT JOIN transfer_products tp ON tp.product_id = products.id
+LEFT JOIN transfers t ON t.id = tp.transfer_id
+ORDER BY path.depth`
+
+type TransferStorage struct {
+	pool    *pgxpool.Pool
+	builder squirrel.StatementBuilderType
+}
+
+func NewTransferStorage(pool *pgxpool.Pool) (*TransferStorage, error) {
+	if pool == nil {
+		return nil, errors.New("nil values in NewTransferStorage constructor")
+	}
+
+	return &TransferStorage{
+		pool:    pool,
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}, nil
+}
+
+// GetTransferableProducts returns products with given ids which are kept in closed receptions
+// of pvz and are not included into any transfer yet
+func (s *TransferStorage) GetTransferableProducts(
+	ctx context.Context,
+	pvzID openapi_types.UUID,
+	productIDs []openapi_types.UUID,
+) ([]dto.Product, error) {
+	query, args, err := s.builder.
+		Select(productColumns...).
+		From("products").
+		Where(squirrel.Eq{
+			"id":     productIDs,
+			"status": []dto.ProductStatus{dto.ProductReceived, dto.ProductStored},
+		}).
+		Where("reception_id IN (SELECT id FROM receptions WHERE pvz_id = ? AND status = ?)", pvzID, dto.Close).
+		Where("id NOT IN (SELECT product_id FROM transfer_products)").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	return s.queryProducts(ctx, query, args)
+}
+
+// GetTransferProducts returns source products of transfer
+func (s *TransferStorage) GetTransferProducts(ctx context.Context, transferID openapi_types.UUID) ([]dto.Product, error) {
+	query, args, err := s.builder.
+		Select(productColumns...).
+		From("products").
+		Where("id IN (SELECT product_id FROM transfer_products WHERE transfer_id = ?)", transferID).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	return s.queryProducts(ctx, query, args)
+}
+
+func (s *TransferStorage) CreateTransfer(ctx context.Context, payload dto.PostTransfersJSONBody, createdBy *openapi_types.UUID) (*dto.Transfer, error) {
+	transferQuery, transferArgs, err := s.builder.
+		Insert("transfers").
+		Columns("from_pvz_id", "to_pvz_id", "created_by").
+		Values(payload.FromPvzId, payload.ToPvzId, createdBy).
+		Suffix("RETURNING " + strings.Join(transferColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var transfer dto.Transfer
+	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, transferQuery, transferArgs...).Scan(transferFields(&transfer)...); err != nil {
+			return err
+		}
+
+		insert := s.builder.
+			Insert("transfer_products").
+			Columns("transfer_id", "product_id")
+		for _, productID := range payload.ProductIds {
+			insert = insert.Values(transfer.Id, productID)
+		}
+		query, args, err := insert.ToSql()
+		if err != nil {
+			return ErrBuildQuery
+		}
+
+		_, err = tx.Exec(ctx, query, args...)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transfer: %w", err)
+	}
+
+	transfer.Items = make([]dto.TransferItem, 0, len(payload.ProductIds))
+	for _, productID := range payload.ProductIds {
+		transfer.Items = append(transfer.Items, dto.TransferItem{ProductId: productID})
+	}
+	return &transfer, nil
+}
+
+// GetTransfer returns transfer with its items or nil if it does not exist
+func (s *TransferStorage) GetTransfer(ctx context.Context, transferID openapi_types.UUID) (*dto.Transfer, error) {
+	query, args, err := s.builder.
+		Select(transferColumns...).
+		From("transfers").
+		Where(squirrel.Eq{"id": transferID}).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	transfers, err := s.queryTransfers(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(transfers) == 0 {
+		return nil, nil
+	}
+
+	return &transfers[0], nil
+}
+
+// GetPVZTransfers returns incoming or outgoing transfers of pvz, newest first
+func (s *TransferStorage) GetPVZTransfers(
+	ctx context.Context,
+	pvzID openapi_types.UUID,
+	params dto.GetPvzPvzIdTransfersParams,
+) ([]dto.Transfer, error) {
+	pvzColumn := "to_pvz_id"
+	if params.Direction != nil && *params.Direction == dto.TransferOutgoing {
+		pvzColumn = "from_pvz_id"
+	}
+
+	builder := s.builder.
+		Select(transferColumns...).
+		From("transfers").
+		Where(squirrel.Eq{pvzColumn: pvzID})
+	if params.Status != nil {
+		builder = builder.Where(squirrel.Eq{"status": *params.Status})
+	}
+
+	query, args, err := builder.OrderBy("created_at DESC").ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	return s.queryTransfers(ctx, query, args)
+}
+
+// ShipTransfer sends transfer and marks its products as transferred,
+// returns nil when transfer is not in created status
+func (s *TransferStorage) ShipTransfer(ctx context.Context, transferID openapi_types.UUID, shippedBy *openapi_types.UUID) (*dto.Transfer, error) {
+	transferQuery, transferArgs, err := s.builder.
+		Update("transfers").
+		Set("status", dto.TransferInTransit).
+		Set("shipped_at", squirrel.Expr("NOW()")).
+		Set("shipped_by", shippedBy).
+		Where(squirrel.Eq{
+			"id":     transferID,
+			"status": dto.TransferCreated,
+		}).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	productsQuery, productsArgs, err := s.builder.
+		Update("products").
+		Set("status", dto.ProductTransferred).
+		Where("id IN (SELECT product_id FROM transfer_products WHERE transfer_id = ?)", transferID).
+		Where(squirrel.Eq{"status": []dto.ProductStatus{dto.ProductReceived, dto.ProductStored}}).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	countQuery, countArgs, err := s.builder.
+		Select("COUNT(*)").
+		From("transfer_products").
+		Where(squirrel.Eq{"transfer_id": transferID}).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	shipped := false
+	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var id openapi_types.UUID
+		err := tx.QueryRow(ctx, transferQuery, transferArgs...).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		tag, err := tx.Exec(ctx, productsQuery, productsArgs...)
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.QueryRow(ctx, countQuery, countArgs...).Scan(&count); err != nil {
+			return err
+		}
+		if count != tag.RowsAffected() {
+			return errTransferProductsUnavailable
+		}
+
+		shipped = true
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to ship transfer: %w", err)
+	}
+	if !shipped {
+		return nil, nil
+	}
+
+	return s.GetTransfer(ctx, transferID)
+}
+
+// errTransferBarcodeClash rolls back receiving of transfer whose products clash by barcode
+var errTransferBarcodeClash = errors.New("barcodes of transfer products clash")
+
+// ReceiveTransfer creates copies of transferred products in active reception of destination pvz,
+// reception is opened when pvz has none and returned as the second result.
+// Returns nil when transfer is not in transit. When barcode of transferred product is already
+// in the reception or repeats in transfer, nothing is received and returned transfer stays
+// in transit with clashing product set in conflictProductId of its items
+func (s *TransferStorage) ReceiveTransfer(
+	ctx context.Context,
+	transferID openapi_types.UUID,
+	receivedBy *openapi_types.UUID,
+) (*dto.Transfer, *dto.Reception, error) {
+	transferQuery, transferArgs, err := s.builder.
+		Select("to_pvz_id").
+		From("transfers").
+		Where(squirrel.Eq{
+			"id":     transferID,
+			"status": dto.TransferInTransit,
+		}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, nil, ErrBuildQuery
+	}
+
+	var conflicts map[openapi_types.UUID]openapi_types.UUID
+	var opened *dto.Reception
+	received := false
+	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var pvzID openapi_types.UUID
+		err := tx.QueryRow(ctx, transferQuery, transferArgs...).Scan(&pvzID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		reception, isNew, err := s.destinationReception(ctx, tx, pvzID)
+		if err != nil {
+			return err
+		}
+		if isNew {
+			opened = reception
+		}
+
+		items, err := s.transferBarcodes(ctx, tx, transferID)
+		if err != nil {
+			return err
+		}
+		conflicts, err = s.barcodeConflicts(ctx, tx, reception.Id, items)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			return errTransferBarcodeClash
+		}
+
+		updateQuery, updateArgs, err := s.builder.
+			Update("transfers").
+			Set("status", dto.TransferReceived).
+			Set("received_at", squirrel.Expr("NOW()")).
+			Set("received_by", receivedBy).
+			Set("reception_id", reception.Id).
+			Where(squirrel.Eq{"id": transferID}).
+			ToSql()
+		if err != nil {
+			return ErrBuildQuery
+		}
+		if _, err := tx.Exec(ctx, updateQuery, updateArgs...); err != nil {
+			return err
+		}
+
+		// copies get increasing date_time to keep order of transfer in reception
+		for i, item := range items {
+			if err := s.receiveProduct(ctx, tx, transferID, item.productID, reception.Id, i); err != nil {
+				return err
+			}
+		}
+
+		received = true
+		return nil
+	})
+	if errors.Is(err, errTransferBarcodeClash) {
+		transfer, err := s.GetTransfer(ctx, transferID)
+		if err != nil || transfer == nil {
+			return nil, nil, err
+		}
+		for i, item := range transfer.Items {
+			if conflict, ok := conflicts[item.ProductId]; ok {
+				transfer.Items[i].ConflictProductId = &conflict
+			}
+		}
+		return transfer, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to receive transfer: %w", err)
+	}
+	if !received {
+		return nil, nil, nil
+	}
+
+	transfer, err := s.GetTransfer(ctx, transferID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return transfer, opened, nil
+}
+
+// destinationReception locks pvz, so its receptions can not be opened or closed meanwhile,
+// and returns its in progress reception, opening one when there is none
+func (s *TransferStorage) destinationReception(ctx context.Context, tx pgx.Tx, pvzID openapi_types.UUID) (*dto.Reception, bool, error) {
+	lockQuery, lockArgs, err := s.builder.
+		Select("id").
+		From("pvz").
+		Where(squirrel.Eq{"id": pvzID}).
+		Suffix("FOR NO KEY UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, false, ErrBuildQuery
+	}
+	if _, err := tx.Exec(ctx, lockQuery, lockArgs...); err != nil {
+		return nil, false, fmt.Errorf("failed to lock PVZ: %w", err)
+	}
+
+	activeQuery, activeArgs, err := s.builder.
+		Select(receptionColumns...).
+		From("receptions").
+		Where(squirrel.Eq{
+			"pvz_id": pvzID,
+			"status": dto.InProgress,
+		}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, false, ErrBuildQuery
+	}
+
+	var reception dto.Reception
+	err = tx.QueryRow(ctx, activeQuery, activeArgs...).Scan(receptionFields(&reception)...)
+	if err == nil {
+		return &reception, false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, err
+	}
+
+	openQuery, openArgs, err := s.builder.
+		Insert("receptions").
+		Columns("pvz_id", "status").
+		Values(pvzID, dto.InProgress).
+		Suffix("RETURNING " + strings.Join(receptionColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, false, ErrBuildQuery
+	}
+	if err := tx.QueryRow(ctx, openQuery, openArgs...).Scan(receptionFields(&reception)...); err != nil {
+		return nil, false, fmt.Errorf("failed to open reception: %w", err)
+	}
+
+	return &reception, true, nil
+}
+
+// transferBarcode is product of transfer with its barcode
+type transferBarcode struct {
+	productID openapi_types.UUID
+	barcode   *string
+}
+
+// transferBarcodes returns products of transfer in order they are received
+func (s *TransferStorage) transferBarcodes(ctx context.Context, tx pgx.Tx, transferID openapi_types.UUID) ([]transferBarcode, error) {
+	query, args, err := s.builder.
+		Select("tp.product_id", "p.barcode").
+		From("transfer_products tp").
+		Join("products p ON p.id = tp.product_id").
+		Where(squirrel.Eq{"tp.transfer_id": transferID}).
+		OrderBy("tp.product_id").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (transferBarcode, error) {
+		var item transferBarcode
+		err := row.Scan(&item.productID, &item.barcode)
+		return item, err
+	})
+}
+
+// barcodeConflicts maps transfer product to product of reception with the same barcode
+// or to previous product of transfer with the same barcode, barcode is unique within reception
+func (s *TransferStorage) barcodeConflicts(
+	ctx context.Context,
+	tx pgx.Tx,
+	receptionID openapi_types.UUID,
+	items []transferBarcode,
+) (map[openapi_types.UUID]openapi_types.UUID, error) {
+	barcodes := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.barcode != nil {
+			barcodes = append(barcodes, *item.barcode)
+		}
+	}
+	if len(barcodes) == 0 {
+		return nil, nil
+	}
+
+	query, args, err := s.builder.
+		Select("barcode", "id").
+		From("products").
+		Where(squirrel.Eq{
+			"reception_id": receptionID,
+			"barcode":      barcodes,
+		}).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holders := make(map[string]openapi_types.UUID)
+	for rows.Next() {
+		var barcode string
+		var productID openapi_types.UUID
+		if err := rows.Scan(&barcode, &productID); err != nil {
+			return nil, err
+		}
+		holders[barcode] = productID
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	conflicts := make(map[openapi_types.UUID]openapi_types.UUID)
+	for _, item := range items {
+		if item.barcode == nil {
+			continue
+		}
+		if holder, ok := holders[*item.barcode]; ok {
+			conflicts[item.productID] = holder
+			continue
+		}
+		holders[*item.barcode] = item.productID
+	}
+
+	return conflicts, nil
+}
+
+func (s *TransferStorage) receiveProduct(
+	ctx context.Context,
+	tx pgx.Tx,
+	transferID openapi_types.UUID,
+	productID openapi_types.UUID,
+	receptionID openapi_types.UUID,
+	position int,
+) error {
+	insertQuery, insertArgs, err := s.builder.
+		Insert("products").
+		Columns("date_time", "type", "reception_id", "barcode").
+		Select(squirrel.
+			Select().
+			Column(squirrel.Expr("NOW() + ? * INTERVAL '1 microsecond'", position)).
+			Column("type").
+			Column(squirrel.Expr("?::uuid", receptionID)).
+			Column("barcode").
+			From("products").
+			Where(squirrel.Eq{"id": productID})).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return ErrBuildQuery
+	}
+
+	var receivedID openapi_types.UUID
+	if err := tx.QueryRow(ctx, insertQuery, insertArgs...).Scan(&receivedID); err != nil {
+		return err
+	}
+
+	linkQuery, linkArgs, err := s.builder.
+		Update("transfer_products").
+		Set("received_product_id", receivedID).
+		Where(squirrel.Eq{
+			"transfer_id": transferID,
+			"product_id":  productID,
+		}).
+		ToSql()
+	if err != nil {
+		return ErrBuildQuery
+	}
+
+	_, err = tx.Exec(ctx, linkQuery, linkArgs...)
+	return err
+}
+
+// GetProductPath returns product at every pvz it passed through, from first reception to latest copy
+func (s *TransferStorage) GetProductPath(ctx context.Context, productID openapi_types.UUID) ([]dto.ProductPathStep, error) {
+	columns := make([]string, 0, len(productColumns))
+	for _, column := range productColumns {
+		columns = append(columns, "products."+column)
+	}
+
+	rows, err := s.pool.Query(ctx, fmt.Sprintf(productPathQuery, strings.Join(columns, ", ")), productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	path := make([]dto.ProductPathStep, 0)
+	for rows.Next() {
+		var step dto.ProductPathStep
+		dest := append(productFields(&step.Product), &step.PvzId, &step.TransferId, &step.TransferStatus)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan product path: %w", err)
+		}
+		path = append(path, step)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return path, nil
+}
+
+// queryTransfers runs transfers query and loads items of found transfers
+func (s *TransferStorage) queryTransfers(ctx context.Context, query string, args []any) ([]dto.Transfer, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	transfers := make([]dto.Transfer, 0)
+	index := make(map[openapi_types.UUID]int)
+	for rows.Next() {
+		var transfer dto.Transfer
+		if err := rows.Scan(transferFields(&transfer)...); err != nil {
+			return nil, fmt.Errorf("failed to scan transfer: %w", err)
+		}
+		transfer.Items = make([]dto.TransferItem, 0)
+		index[transfer.Id] = len(transfers)
+		transfers = append(transfers, transfer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	if len(transfers) == 0 {
+		return transfers, nil
+	}
+
+	ids := make([]openapi_types.UUID, 0, len(transfers))
+	for id := range index {
+		ids = append(ids, id)
+	}
+	itemsQuery, itemsArgs, err := s.builder.
+		Select("transfer_id", "product_id", "received_product_id").
+		From("transfer_products").
+		Where(squirrel.Eq{"transfer_id": ids}).
+		OrderBy("product_id").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	itemRows, err := s.pool.Query(ctx, itemsQuery, itemsArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var transferID openapi_types.UUID
+		var item dto.TransferItem
+		if err := itemRows.Scan(&transferID, &item.ProductId, &item.ReceivedProductId); err != nil {
+			return nil, fmt.Errorf("failed to scan transfer item: %w", err)
+		}
+		i := index[transferID]
+		transfers[i].Items = append(transfers[i].Items, item)
+	}
+	if err := itemRows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return transfers, nil
+}
+
+func (s *TransferStorage) queryProducts(ctx context.Context, query string, args []any) ([]dto.Product, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]dto.Product, 0)
+	for rows.Next() {
+		var product dto.Product
+		if err := rows.Scan(productFields(&product)...); err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return products, nil
+}
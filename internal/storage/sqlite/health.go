@@ -0,0 +1,55 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// HealthStorage checks database availability for readiness probes
+type HealthStorage struct {
+	db      *sql.DB
+	builder squirrel.StatementBuilderType
+}
+
+func NewHealthStorage(db *sql.DB) (*HealthStorage, error) {
+	if db == nil {
+		return nil, errors.New("nil values in NewHealthStorage constructor")
+	}
+
+	return &HealthStorage{
+		db:      db,
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+	}, nil
+}
+
+func (s *HealthStorage) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// MigrationVersion returns version of last applied migration and whether it failed halfway
+func (s *HealthStorage) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	query, args, err := s.builder.
+		Select("version", "dirty").
+		From("schema_migrations").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return 0, false, ErrBuildQuery
+	}
+
+	var version int64
+	var dirty bool
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get migration version: %w", err)
+	}
+
+	return uint(version), dirty, nil
+}
@@ -0,0 +1,38 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHealthStorage(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		storage, err := NewHealthStorage(newTestDB(t))
+		require.NoError(t, err)
+		require.NotNil(t, storage)
+	})
+
+	t.Run("nil db", func(t *testing.T) {
+		storage, err := NewHealthStorage(nil)
+		require.Error(t, err)
+		require.Nil(t, storage)
+	})
+}
+
+func TestHealthStorage_MigrationVersion(t *testing.T) {
+	ctx := context.Background()
+	storage, err := NewHealthStorage(newTestDB(t))
+	require.NoError(t, err)
+
+	require.NoError(t, storage.Ping(ctx))
+
+	expected, err := NewMigrator("migrations", "").LatestVersion()
+	require.NoError(t, err)
+
+	version, dirty, err := storage.MigrationVersion(ctx)
+	require.NoError(t, err)
+	require.False(t, dirty)
+	require.Equal(t, expected, version)
+}
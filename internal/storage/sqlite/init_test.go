@@ -0,0 +1,28 @@
+package sqlite
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDB creates migrated database in temporary directory
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "pvz.db")
+	require.NoError(t, NewMigrator("migrations", path).Up())
+
+	db, closeFn, err := InitDB(path)
+	require.NoError(t, err)
+	t.Cleanup(closeFn)
+
+	return db
+}
+
+func TestInitDB(t *testing.T) {
+	db := newTestDB(t)
+	require.NoError(t, db.Ping())
+}
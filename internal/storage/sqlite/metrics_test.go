@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMetricsStorage(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		storage, err := NewMetricsStorage(newTestDB(t))
+		require.NoError(t, err)
+		require.NotNil(t, storage)
+	})
+
+	t.Run("nil db", func(t *testing.T) {
+		storage, err := NewMetricsStorage(nil)
+		require.Error(t, err)
+		require.Nil(t, storage)
+	})
+}
+
+func TestMetricsStorage_Counts(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	pvzStorage, err := NewPVZStorage(db)
+	require.NoError(t, err)
+	receptionStorage, err := NewReceptionStorage(db)
+	require.NoError(t, err)
+	productStorage, err := NewProductStorage(db)
+	require.NoError(t, err)
+	storage, err := NewMetricsStorage(db)
+	require.NoError(t, err)
+
+	moscow, err := pvzStorage.CreatePVZ(ctx, dto.PostPvzJSONRequestBody{City: dto.Moscow})
+	require.NoError(t, err)
+	kazan, err := pvzStorage.CreatePVZ(ctx, dto.PostPvzJSONRequestBody{City: dto.Kazan})
+	require.NoError(t, err)
+
+	city, err := storage.GetPVZCity(ctx, *kazan.Id)
+	require.NoError(t, err)
+	require.Equal(t, dto.Kazan, city)
+	city, err = storage.GetPVZCity(ctx, openapi_types.UUID{1})
+	require.NoError(t, err)
+	require.Empty(t, city)
+
+	// closed reception with two products and empty closed reception in moscow
+	_, err = receptionStorage.CreateReception(ctx, *moscow.Id, nil, nil)
+	require.NoError(t, err)
+	first, err := productStorage.CreateProduct(ctx, dto.PostProductsJSONBody{PvzId: *moscow.Id, Type: dto.PostProductsJSONBodyTypeElectronics})
+	require.NoError(t, err)
+	second, err := productStorage.CreateProduct(ctx, dto.PostProductsJSONBody{PvzId: *moscow.Id, Type: dto.PostProductsJSONBodyTypeElectronics})
+	require.NoError(t, err)
+	_, _, err = receptionStorage.CloseReception(ctx, *moscow.Id, nil, noReport)
+	require.NoError(t, err)
+	_, err = receptionStorage.CreateReception(ctx, *moscow.Id, nil, nil)
+	require.NoError(t, err)
+	_, _, err = receptionStorage.CloseReception(ctx, *moscow.Id, nil, noReport)
+	require.NoError(t, err)
+
+	// open receptions in both cities
+	_, err = receptionStorage.CreateReception(ctx, *moscow.Id, nil, nil)
+	require.NoError(t, err)
+	_, err = receptionStorage.CreateReception(ctx, *kazan.Id, nil, nil)
+	require.NoError(t, err)
+	third, err := productStorage.CreateProduct(ctx, dto.PostProductsJSONBody{PvzId: *kazan.Id, Type: dto.PostProductsJSONBodyTypeShoes})
+	require.NoError(t, err)
+
+	types, err := storage.GetProductTypeCounts(ctx, []openapi_types.UUID{*first.Id, *second.Id, *third.Id})
+	require.NoError(t, err)
+	require.Equal(t, map[dto.ProductType]int{dto.ProductTypeElectronics: 2, dto.ProductTypeShoes: 1}, types)
+
+	open, err := storage.GetOpenReceptionCounts(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[dto.PVZCity]int{dto.Moscow: 1, dto.Kazan: 1}, open)
+
+	sizes, err := storage.GetClosedReceptionSizes(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[int]int{0: 1, 2: 1}, sizes)
+}
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS receptions_one_in_progress_per_pvz;
+DROP INDEX IF EXISTS idx_products_reception_id;
+DROP INDEX IF EXISTS idx_receptions_pvz_id;
+
+DROP TABLE IF EXISTS products;
+DROP TABLE IF EXISTS receptions;
+DROP TABLE IF EXISTS pvz;
+DROP TABLE IF EXISTS users;
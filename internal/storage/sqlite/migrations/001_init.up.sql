@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS users (
+    id TEXT PRIMARY KEY,
+    email TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('employee', 'moderator'))
+);
+
+CREATE TABLE IF NOT EXISTS pvz (
+    id TEXT PRIMARY KEY,
+    registration_date TIMESTAMP NOT NULL,
+    city TEXT NOT NULL CHECK (city IN ('Москва', 'Санкт-Петербург', 'Казань'))
+);
+
+CREATE TABLE IF NOT EXISTS receptions (
+    id TEXT PRIMARY KEY,
+    date_time TIMESTAMP NOT NULL,
+    pvz_id TEXT NOT NULL REFERENCES pvz(id),
+    status TEXT NOT NULL CHECK (status IN ('in_progress', 'close'))
+);
+
+CREATE TABLE IF NOT EXISTS products (
+    id TEXT PRIMARY KEY,
+    date_time TIMESTAMP NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('электроника', 'одежда', 'обувь')),
+    reception_id TEXT NOT NULL REFERENCES receptions(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_receptions_pvz_id ON receptions(pvz_id);
+CREATE INDEX IF NOT EXISTS idx_products_reception_id ON products(reception_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS receptions_one_in_progress_per_pvz
+ON receptions (pvz_id)
+WHERE status = 'in_progress';
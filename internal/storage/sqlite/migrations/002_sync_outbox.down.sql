@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_sync_outbox_status;
+DROP TABLE IF EXISTS sync_outbox;
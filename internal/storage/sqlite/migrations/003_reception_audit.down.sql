@@ -0,0 +1,3 @@
+ALTER TABLE receptions DROP COLUMN closed_by;
+ALTER TABLE receptions DROP COLUMN opened_by;
+ALTER TABLE receptions DROP COLUMN closed_at;
@@ -0,0 +1,3 @@
+ALTER TABLE receptions ADD COLUMN closed_at TIMESTAMP;
+ALTER TABLE receptions ADD COLUMN opened_by TEXT;
+ALTER TABLE receptions ADD COLUMN closed_by TEXT;
@@ -0,0 +1,2 @@
+ALTER TABLE receptions DROP COLUMN discrepancy_report;
+ALTER TABLE receptions DROP COLUMN manifest;
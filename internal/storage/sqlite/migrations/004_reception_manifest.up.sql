@@ -0,0 +1,2 @@
+ALTER TABLE receptions ADD COLUMN manifest TEXT;
+ALTER TABLE receptions ADD COLUMN discrepancy_report TEXT;
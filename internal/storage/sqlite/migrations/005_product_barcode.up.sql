@@ -0,0 +1,7 @@
+ALTER TABLE products ADD COLUMN barcode TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS products_reception_barcode
+ON products (reception_id, barcode)
+WHERE barcode IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_products_barcode ON products(barcode);
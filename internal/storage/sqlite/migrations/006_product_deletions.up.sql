@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS product_deletions (
+    id TEXT PRIMARY KEY,
+    product_id TEXT NOT NULL,
+    reception_id TEXT NOT NULL REFERENCES receptions(id),
+    type TEXT NOT NULL,
+    barcode TEXT,
+    added_at TIMESTAMP NOT NULL,
+    deleted_by TEXT,
+    deleted_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_deletions_reception_id ON product_deletions(reception_id);
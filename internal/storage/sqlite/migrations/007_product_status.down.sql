@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_products_status;
+
+ALTER TABLE products DROP COLUMN returned_at;
+ALTER TABLE products DROP COLUMN issued_at;
+ALTER TABLE products DROP COLUMN stored_at;
+ALTER TABLE products DROP COLUMN status;
@@ -0,0 +1,6 @@
+ALTER TABLE products ADD COLUMN status TEXT NOT NULL DEFAULT 'received';
+ALTER TABLE products ADD COLUMN stored_at TIMESTAMP;
+ALTER TABLE products ADD COLUMN issued_at TIMESTAMP;
+ALTER TABLE products ADD COLUMN returned_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_products_status ON products(status);
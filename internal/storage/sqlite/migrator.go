@@ -0,0 +1,105 @@
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+type Migrator struct {
+	migrationsDir string
+	path          string
+}
+
+func NewMigrator(migrationsDir, path string) *Migrator {
+	return &Migrator{migrationsDir: migrationsDir, path: path}
+}
+
+func (m *Migrator) Up() error {
+	mig, err := m.initMigrate()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		errSource, errDatabase := mig.Close()
+		if errSource != nil || errDatabase != nil {
+			log.Fatalf("error in migration close: %v, %v", errSource, errDatabase)
+		}
+	}()
+
+	if err = mig.Up(); err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	return nil
+}
+
+func (m *Migrator) Down() error {
+	mig, err := m.initMigrate()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		errSource, errDatabase := mig.Close()
+		if errSource != nil || errDatabase != nil {
+			log.Fatalf("error in migration close: %v, %v", errSource, errDatabase)
+		}
+	}()
+
+	if err := mig.Down(); err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("failed to rollback migrations: %w", err)
+	}
+
+	return nil
+}
+
+// LatestVersion returns version of the last migration in migrations directory
+func (m *Migrator) LatestVersion() (uint, error) {
+	if _, err := os.Stat(m.migrationsDir); os.IsNotExist(err) {
+		return 0, fmt.Errorf("migrations directory does not exist: %w", err)
+	}
+
+	source, err := iofs.New(os.DirFS(m.migrationsDir), ".")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create migrations source: %w", err)
+	}
+	defer source.Close()
+
+	version, err := source.First()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	for {
+		next, err := source.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read migrations: %w", err)
+		}
+		version = next
+	}
+}
+
+func (m *Migrator) initMigrate() (*migrate.Migrate, error) {
+	if _, err := os.Stat(m.migrationsDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("migrations directory does not exist: %w", err)
+	}
+
+	source, err := iofs.New(os.DirFS(m.migrationsDir), ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrations source: %w", err)
+	}
+
+	mig, err := migrate.NewWithSourceInstance("iofs", source, "sqlite://"+m.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+	return mig, nil
+}
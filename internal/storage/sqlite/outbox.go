@@ -0,0 +1,60 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+// queryRower is implemented both by *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// withTx runs fn in transaction, which is committed if fn returns no error
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// recordChange stores change in sync outbox in the same transaction as the change itself,
+// so every local change is eventually uploaded to the central server
+func recordChange(ctx context.Context, tx *sql.Tx, builder squirrel.StatementBuilderType, item dto.SyncItem) error {
+	item.Id = uuid.New()
+	item.CreatedAt = time.Now().UTC()
+
+	payload, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to encode sync item: %w", err)
+	}
+
+	query, args, err := builder.
+		Insert("sync_outbox").
+		Columns("id", "kind", "payload", "created_at").
+		Values(item.Id, item.Kind, string(payload), item.CreatedAt).
+		ToSql()
+	if err != nil {
+		return ErrBuildQuery
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to record sync item: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,459 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// productColumns lists product columns in order of productFields
+var productColumns = []string{
+	"id", "date_time", "type", "reception_id", "barcode", "status", "stored_at", "issued_at", "returned_at",
+}
+
+func productFields(p *dto.Product) []any {
+	return []any{&p.Id, &p.DateTime, &p.Type, &p.ReceptionId, &p.Barcode, &p.Status, &p.StoredAt, &p.IssuedAt, &p.ReturnedAt}
+}
+
+// statusTimeColumns maps product status to column with time of transition to it
+var statusTimeColumns = map[dto.ProductStatus]string{
+	dto.ProductStored:           "stored_at",
+	dto.ProductIssued:           "issued_at",
+	dto.ProductReturnedToSender: "returned_at",
+}
+
+// isUniqueViolation reports whether product clashed with unique index, the only one
+// products have is on barcode within reception
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
+
+type ProductStorage struct {
+	db      *sql.DB
+	builder squirrel.StatementBuilderType
+}
+
+func NewProductStorage(db *sql.DB) (*ProductStorage, error) {
+	if db == nil {
+		return nil, errors.New("nil values in NewProductStorage constructor")
+	}
+
+	return &ProductStorage{
+		db:      db,
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+	}, nil
+}
+
+func (s *ProductStorage) GetReceptionProducts(ctx context.Context, receptionId openapi_types.UUID) []dto.Product {
+	query, args, err := s.builder.
+		Select(productColumns...).
+		From("products").
+		Where(squirrel.Eq{"reception_id": receptionId}).
+		OrderBy("date_time").
+		ToSql()
+	if err != nil {
+		return nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var products []dto.Product
+	for rows.Next() {
+		var product dto.Product
+		if err := rows.Scan(productFields(&product)...); err != nil {
+			return nil
+		}
+		products = append(products, product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil
+	}
+
+	return products
+}
+
+// CreateProduct adds product to in progress reception of pvz,
+// returns nil when product with the same barcode is already in reception
+func (s *ProductStorage) CreateProduct(ctx context.Context, productDto dto.PostProductsJSONBody) (*dto.Product, error) {
+	var product dto.Product
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		receptionID, err := s.activeReceptionID(ctx, tx, productDto.PvzId)
+		if err != nil {
+			return err
+		}
+
+		productQuery, productArgs, err := s.builder.
+			Insert("products").
+			Columns("id", "date_time", "type", "reception_id", "barcode").
+			Values(uuid.New(), time.Now().UTC(), string(productDto.Type), receptionID, productDto.Barcode).
+			Suffix("RETURNING " + strings.Join(productColumns, ", ")).
+			ToSql()
+		if err != nil {
+			return ErrBuildQuery
+		}
+
+		err = tx.QueryRowContext(ctx, productQuery, productArgs...).Scan(productFields(&product)...)
+		if err != nil {
+			return fmt.Errorf("failed to create product: %w", err)
+		}
+
+		return recordChange(ctx, tx, s.builder, dto.SyncItem{Kind: dto.KindProductAdded, Product: &product})
+	})
+	if isUniqueViolation(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// CreateProducts adds products to in progress reception of pvz with one multi-row insert,
+// products are returned in order of items. Returns nil and adds nothing when barcode
+// of some product is already in reception
+func (s *ProductStorage) CreateProducts(ctx context.Context, pvzID openapi_types.UUID, items []dto.ProductBatchItem) ([]dto.Product, error) {
+	products := make([]dto.Product, 0, len(items))
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		receptionID, err := s.activeReceptionID(ctx, tx, pvzID)
+		if err != nil {
+			return err
+		}
+
+		// products of one batch get increasing date_time to keep their order in reception
+		now := time.Now().UTC()
+		insert := s.builder.
+			Insert("products").
+			Columns("id", "date_time", "type", "reception_id", "barcode")
+		for i, item := range items {
+			id := uuid.New()
+			dateTime := now.Add(time.Duration(i) * time.Microsecond)
+			status := dto.ProductReceived
+			products = append(products, dto.Product{
+				Id:          &id,
+				DateTime:    &dateTime,
+				Type:        item.Type,
+				ReceptionId: receptionID,
+				Barcode:     item.Barcode,
+				Status:      &status,
+			})
+			insert = insert.Values(id, dateTime, string(item.Type), receptionID, item.Barcode)
+		}
+
+		query, args, err := insert.ToSql()
+		if err != nil {
+			return ErrBuildQuery
+		}
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to create products: %w", err)
+		}
+
+		for i := range products {
+			item := dto.SyncItem{Kind: dto.KindProductAdded, Product: &products[i]}
+			if err := recordChange(ctx, tx, s.builder, item); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if isUniqueViolation(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (s *ProductStorage) GetLastProduct(ctx context.Context, pvzId openapi_types.UUID) (*dto.Product, error) {
+	receptionID, err := s.activeReceptionID(ctx, s.db, pvzId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active reception: %w", err)
+	}
+
+	productSelectQuery, productSelectArgs, err := s.builder.
+		Select(productColumns...).
+		From("products").
+		Where(squirrel.Eq{"reception_id": receptionID}).
+		OrderBy("date_time DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var product dto.Product
+	err = s.db.QueryRowContext(ctx, productSelectQuery, productSelectArgs...).Scan(productFields(&product)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last product: %w", err)
+	}
+
+	return &product, nil
+}
+
+// DeleteProduct deletes product and records deletion in audit trail
+func (s *ProductStorage) DeleteProduct(ctx context.Context, productID openapi_types.UUID, deletedBy *openapi_types.UUID) error {
+	query, args, err := s.builder.
+		Delete("products").
+		Where(squirrel.Eq{"id": productID}).
+		Suffix("RETURNING " + strings.Join(productColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return ErrBuildQuery
+	}
+
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var product dto.Product
+		if err := tx.QueryRowContext(ctx, query, args...).Scan(productFields(&product)...); err != nil {
+			return err
+		}
+		return s.recordDeletion(ctx, tx, product, deletedBy)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete product: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteReceptionProduct deletes product of reception only while reception is in progress,
+// returns false when there is no such product in open reception
+func (s *ProductStorage) DeleteReceptionProduct(
+	ctx context.Context,
+	receptionID openapi_types.UUID,
+	productID openapi_types.UUID,
+	deletedBy *openapi_types.UUID,
+) (bool, error) {
+	query, args, err := s.builder.
+		Delete("products").
+		Where(squirrel.Eq{
+			"id":           productID,
+			"reception_id": receptionID,
+			"status":       dto.ProductReceived,
+		}).
+		Where("reception_id IN (SELECT id FROM receptions WHERE status = ?)", dto.InProgress).
+		Suffix("RETURNING " + strings.Join(productColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return false, ErrBuildQuery
+	}
+
+	deleted := false
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var product dto.Product
+		err := tx.QueryRowContext(ctx, query, args...).Scan(productFields(&product)...)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		deleted = true
+		return s.recordDeletion(ctx, tx, product, deletedBy)
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete product: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// recordDeletion writes deleted product to audit trail and sync outbox
+func (s *ProductStorage) recordDeletion(ctx context.Context, tx *sql.Tx, product dto.Product, deletedBy *openapi_types.UUID) error {
+	query, args, err := s.builder.
+		Insert("product_deletions").
+		Columns("id", "product_id", "reception_id", "type", "barcode", "added_at", "deleted_by", "deleted_at").
+		Values(uuid.New(), product.Id, product.ReceptionId, product.Type, product.Barcode, product.DateTime, deletedBy, time.Now().UTC()).
+		ToSql()
+	if err != nil {
+		return ErrBuildQuery
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to record product deletion: %w", err)
+	}
+
+	return recordChange(ctx, tx, s.builder, dto.SyncItem{Kind: dto.KindProductDeleted, Product: &product})
+}
+
+// GetOpenReceptionProductByBarcode returns product with barcode from in progress
+// reception of pvz or nil if there is none
+func (s *ProductStorage) GetOpenReceptionProductByBarcode(ctx context.Context, pvzID openapi_types.UUID, barcode string) (*dto.Product, error) {
+	query, args, err := s.builder.
+		Select(productColumns...).
+		From("products").
+		Where(squirrel.Eq{"barcode": barcode}).
+		Where("reception_id IN (SELECT id FROM receptions WHERE pvz_id = ? AND status = ?)", pvzID, dto.InProgress).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var product dto.Product
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(productFields(&product)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product by barcode: %w", err)
+	}
+
+	return &product, nil
+}
+
+// GetProducts returns products matching params, newest first
+func (s *ProductStorage) GetProducts(ctx context.Context, params dto.GetProductsParams) ([]dto.Product, error) {
+	offset := (*params.Page - 1) * (*params.Limit)
+	builder := s.builder.
+		Select(productColumns...).
+		From("products")
+	if params.Barcode != nil {
+		builder = builder.Where(squirrel.Eq{"barcode": *params.Barcode})
+	}
+	if params.PvzId != nil {
+		builder = builder.Where("reception_id IN (SELECT id FROM receptions WHERE pvz_id = ?)", *params.PvzId)
+	}
+	if params.Status != nil {
+		builder = builder.Where(squirrel.Eq{"status": *params.Status})
+	}
+
+	query, args, err := builder.
+		OrderBy("date_time DESC").
+		Offset(uint64(offset)).
+		Limit(uint64(*params.Limit)).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]dto.Product, 0)
+	for rows.Next() {
+		var product dto.Product
+		if err := rows.Scan(productFields(&product)...); err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return products, nil
+}
+
+// GetProduct returns product by id or nil if it does not exist
+func (s *ProductStorage) GetProduct(ctx context.Context, productID openapi_types.UUID) (*dto.Product, error) {
+	query, args, err := s.builder.
+		Select(productColumns...).
+		From("products").
+		Where(squirrel.Eq{"id": productID}).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var product dto.Product
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(productFields(&product)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	return &product, nil
+}
+
+// UpdateProductStatus moves product from status to another one and sets time of transition,
+// returns nil when product is not in status from anymore
+func (s *ProductStorage) UpdateProductStatus(
+	ctx context.Context,
+	productID openapi_types.UUID,
+	from dto.ProductStatus,
+	to dto.ProductStatus,
+) (*dto.Product, error) {
+	query, args, err := s.builder.
+		Update("products").
+		Set("status", to).
+		Set(statusTimeColumns[to], time.Now().UTC()).
+		Where(squirrel.Eq{
+			"id":     productID,
+			"status": from,
+		}).
+		Suffix("RETURNING " + strings.Join(productColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var product *dto.Product
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var updated dto.Product
+		err := tx.QueryRowContext(ctx, query, args...).Scan(productFields(&updated)...)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		product = &updated
+		return recordChange(ctx, tx, s.builder, dto.SyncItem{Kind: dto.KindProductStatusChanged, Product: product})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update product status: %w", err)
+	}
+
+	return product, nil
+}
+
+func (s *ProductStorage) activeReceptionID(ctx context.Context, q queryRower, pvzID openapi_types.UUID) (openapi_types.UUID, error) {
+	query, args, err := s.builder.
+		Select("id").
+		From("receptions").
+		Where(squirrel.Eq{
+			"pvz_id": pvzID,
+			"status": dto.InProgress,
+		}).
+		ToSql()
+	if err != nil {
+		return openapi_types.UUID{}, ErrBuildQuery
+	}
+
+	var receptionID openapi_types.UUID
+	err = q.QueryRowContext(ctx, query, args...).Scan(&receptionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return openapi_types.UUID{}, ErrNoActiveReception
+	}
+	if err != nil {
+		return openapi_types.UUID{}, err
+	}
+
+	return receptionID, nil
+}
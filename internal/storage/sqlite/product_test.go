@@ -0,0 +1,263 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProductStorage(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		storage, err := NewProductStorage(newTestDB(t))
+		require.NoError(t, err)
+		require.NotNil(t, storage)
+	})
+
+	t.Run("nil db", func(t *testing.T) {
+		storage, err := NewProductStorage(nil)
+		require.Error(t, err)
+		require.Nil(t, storage)
+	})
+}
+
+func TestProductStorage_CreateAndDeleteLast(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	pvzStorage, err := NewPVZStorage(db)
+	require.NoError(t, err)
+	receptionStorage, err := NewReceptionStorage(db)
+	require.NoError(t, err)
+	storage, err := NewProductStorage(db)
+	require.NoError(t, err)
+
+	pvz, err := pvzStorage.CreatePVZ(ctx, dto.PostPvzJSONRequestBody{City: dto.Moscow})
+	require.NoError(t, err)
+
+	payload := dto.PostProductsJSONBody{PvzId: *pvz.Id, Type: dto.PostProductsJSONBodyTypeShoes}
+	_, err = storage.CreateProduct(ctx, payload)
+	require.ErrorIs(t, err, ErrNoActiveReception)
+
+	reception, err := receptionStorage.CreateReception(ctx, *pvz.Id, nil, nil)
+	require.NoError(t, err)
+
+	first, err := storage.CreateProduct(ctx, payload)
+	require.NoError(t, err)
+	second, err := storage.CreateProduct(ctx, payload)
+	require.NoError(t, err)
+	require.Equal(t, reception.Id, second.ReceptionId)
+
+	last, err := storage.GetLastProduct(ctx, *pvz.Id)
+	require.NoError(t, err)
+	require.Equal(t, second.Id, last.Id)
+
+	require.NoError(t, storage.DeleteProduct(ctx, *last.Id, nil))
+
+	products := storage.GetReceptionProducts(ctx, reception.Id)
+	require.Len(t, products, 1)
+	require.Equal(t, first.Id, products[0].Id)
+}
+
+func TestProductStorage_Barcode(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	pvzStorage, err := NewPVZStorage(db)
+	require.NoError(t, err)
+	receptionStorage, err := NewReceptionStorage(db)
+	require.NoError(t, err)
+	storage, err := NewProductStorage(db)
+	require.NoError(t, err)
+
+	pvz, err := pvzStorage.CreatePVZ(ctx, dto.PostPvzJSONRequestBody{City: dto.Moscow})
+	require.NoError(t, err)
+	_, err = receptionStorage.CreateReception(ctx, *pvz.Id, nil, nil)
+	require.NoError(t, err)
+
+	barcode := "4600000000011"
+	found, err := storage.GetOpenReceptionProductByBarcode(ctx, *pvz.Id, barcode)
+	require.NoError(t, err)
+	require.Nil(t, found)
+
+	payload := dto.PostProductsJSONBody{PvzId: *pvz.Id, Type: dto.PostProductsJSONBodyTypeShoes, Barcode: &barcode}
+	product, err := storage.CreateProduct(ctx, payload)
+	require.NoError(t, err)
+	require.Equal(t, &barcode, product.Barcode)
+
+	found, err = storage.GetOpenReceptionProductByBarcode(ctx, *pvz.Id, barcode)
+	require.NoError(t, err)
+	require.Equal(t, product.Id, found.Id)
+
+	// barcode is unique within reception
+	duplicate, err := storage.CreateProduct(ctx, payload)
+	require.NoError(t, err)
+	require.Nil(t, duplicate)
+
+	// but can be received again in the next reception
+	_, _, err = receptionStorage.CloseReception(ctx, *pvz.Id, nil, noReport)
+	require.NoError(t, err)
+	_, err = receptionStorage.CreateReception(ctx, *pvz.Id, nil, nil)
+	require.NoError(t, err)
+	again, err := storage.CreateProduct(ctx, payload)
+	require.NoError(t, err)
+
+	params := dto.GetProductsParams{Barcode: &barcode}
+	dto.CorrectProductsParams(&params)
+	products, err := storage.GetProducts(ctx, params)
+	require.NoError(t, err)
+	require.Len(t, products, 2)
+	require.Equal(t, again.Id, products[0].Id)
+}
+
+func TestProductStorage_DeleteReceptionProduct(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	pvzStorage, err := NewPVZStorage(db)
+	require.NoError(t, err)
+	receptionStorage, err := NewReceptionStorage(db)
+	require.NoError(t, err)
+	storage, err := NewProductStorage(db)
+	require.NoError(t, err)
+
+	pvz, err := pvzStorage.CreatePVZ(ctx, dto.PostPvzJSONRequestBody{City: dto.Moscow})
+	require.NoError(t, err)
+	reception, err := receptionStorage.CreateReception(ctx, *pvz.Id, nil, nil)
+	require.NoError(t, err)
+
+	payload := dto.PostProductsJSONBody{PvzId: *pvz.Id, Type: dto.PostProductsJSONBodyTypeClothes}
+	ids := make([]*openapi_types.UUID, 0, 3)
+	for range 3 {
+		product, err := storage.CreateProduct(ctx, payload)
+		require.NoError(t, err)
+		ids = append(ids, product.Id)
+	}
+
+	deletedBy := &openapi_types.UUID{1}
+	deleted, err := storage.DeleteReceptionProduct(ctx, reception.Id, *ids[1], deletedBy)
+	require.NoError(t, err)
+	require.True(t, deleted)
+
+	// remaining products keep their order
+	products := storage.GetReceptionProducts(ctx, reception.Id)
+	require.Len(t, products, 2)
+	require.Equal(t, ids[0], products[0].Id)
+	require.Equal(t, ids[2], products[1].Id)
+
+	var auditBy openapi_types.UUID
+	err = db.QueryRow("SELECT deleted_by FROM product_deletions WHERE product_id = ?", ids[1]).Scan(&auditBy)
+	require.NoError(t, err)
+	require.Equal(t, *deletedBy, auditBy)
+
+	deleted, err = storage.DeleteReceptionProduct(ctx, reception.Id, *ids[1], deletedBy)
+	require.NoError(t, err)
+	require.False(t, deleted)
+
+	// products of closed reception can not be deleted
+	_, _, err = receptionStorage.CloseReception(ctx, *pvz.Id, nil, noReport)
+	require.NoError(t, err)
+	deleted, err = storage.DeleteReceptionProduct(ctx, reception.Id, *ids[0], deletedBy)
+	require.NoError(t, err)
+	require.False(t, deleted)
+}
+
+func TestProductStorage_CreateProducts(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	pvzStorage, err := NewPVZStorage(db)
+	require.NoError(t, err)
+	receptionStorage, err := NewReceptionStorage(db)
+	require.NoError(t, err)
+	storage, err := NewProductStorage(db)
+	require.NoError(t, err)
+
+	pvz, err := pvzStorage.CreatePVZ(ctx, dto.PostPvzJSONRequestBody{City: dto.Moscow})
+	require.NoError(t, err)
+
+	barcode := "4600000000011"
+	items := []dto.ProductBatchItem{
+		{Type: dto.ProductTypeShoes},
+		{Type: dto.ProductTypeClothes, Barcode: &barcode},
+		{Type: dto.ProductTypeElectronics},
+	}
+	_, err = storage.CreateProducts(ctx, *pvz.Id, items)
+	require.ErrorIs(t, err, ErrNoActiveReception)
+
+	reception, err := receptionStorage.CreateReception(ctx, *pvz.Id, nil, nil)
+	require.NoError(t, err)
+
+	created, err := storage.CreateProducts(ctx, *pvz.Id, items)
+	require.NoError(t, err)
+	require.Len(t, created, 3)
+
+	// products keep order of batch
+	products := storage.GetReceptionProducts(ctx, reception.Id)
+	require.Len(t, products, 3)
+	for i := range items {
+		require.Equal(t, created[i].Id, products[i].Id)
+		require.Equal(t, items[i].Type, products[i].Type)
+	}
+	require.Equal(t, &barcode, products[1].Barcode)
+
+	// duplicate barcode fails whole batch
+	created, err = storage.CreateProducts(ctx, *pvz.Id, items)
+	require.NoError(t, err)
+	require.Nil(t, created)
+	require.Len(t, storage.GetReceptionProducts(ctx, reception.Id), 3)
+}
+
+func TestProductStorage_UpdateProductStatus(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	pvzStorage, err := NewPVZStorage(db)
+	require.NoError(t, err)
+	receptionStorage, err := NewReceptionStorage(db)
+	require.NoError(t, err)
+	storage, err := NewProductStorage(db)
+	require.NoError(t, err)
+
+	pvz, err := pvzStorage.CreatePVZ(ctx, dto.PostPvzJSONRequestBody{City: dto.Moscow})
+	require.NoError(t, err)
+	reception, err := receptionStorage.CreateReception(ctx, *pvz.Id, nil, nil)
+	require.NoError(t, err)
+
+	payload := dto.PostProductsJSONBody{PvzId: *pvz.Id, Type: dto.PostProductsJSONBodyTypeShoes}
+	product, err := storage.CreateProduct(ctx, payload)
+	require.NoError(t, err)
+	require.Equal(t, dto.ProductReceived, *product.Status)
+	_, err = storage.CreateProduct(ctx, payload)
+	require.NoError(t, err)
+
+	stored, err := storage.UpdateProductStatus(ctx, *product.Id, dto.ProductReceived, dto.ProductStored)
+	require.NoError(t, err)
+	require.Equal(t, dto.ProductStored, *stored.Status)
+	require.NotNil(t, stored.StoredAt)
+	require.Nil(t, stored.IssuedAt)
+
+	// product is not in expected status anymore
+	again, err := storage.UpdateProductStatus(ctx, *product.Id, dto.ProductReceived, dto.ProductStored)
+	require.NoError(t, err)
+	require.Nil(t, again)
+
+	found, err := storage.GetProduct(ctx, *product.Id)
+	require.NoError(t, err)
+	require.Equal(t, stored, found)
+
+	// stored product can not be deleted from reception
+	deleted, err := storage.DeleteReceptionProduct(ctx, reception.Id, *product.Id, nil)
+	require.NoError(t, err)
+	require.False(t, deleted)
+
+	status := dto.ProductStored
+	params := dto.GetProductsParams{PvzId: pvz.Id, Status: &status}
+	dto.CorrectProductsParams(&params)
+	products, err := storage.GetProducts(ctx, params)
+	require.NoError(t, err)
+	require.Len(t, products, 1)
+	require.Equal(t, product.Id, products[0].Id)
+
+	var kind string
+	err = db.QueryRow("SELECT kind FROM sync_outbox ORDER BY created_at DESC LIMIT 1").Scan(&kind)
+	require.NoError(t, err)
+	require.Equal(t, string(dto.KindProductStatusChanged), kind)
+}
@@ -0,0 +1,147 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+type PVZStorage struct {
+	db      *sql.DB
+	builder squirrel.StatementBuilderType
+}
+
+func NewPVZStorage(db *sql.DB) (*PVZStorage, error) {
+	if db == nil {
+		return nil, errors.New("nil values in NewPVZStorage constructor")
+	}
+
+	return &PVZStorage{
+		db:      db,
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+	}, nil
+}
+
+func (s *PVZStorage) CreatePVZ(ctx context.Context, payload dto.PostPvzJSONRequestBody) (*dto.PVZ, error) {
+	// sqlite has no defaults for uuid and time, so they are generated here
+	id := uuid.New()
+	if payload.Id != nil {
+		id = *payload.Id
+	}
+
+	registrationDate := time.Now().UTC()
+	if payload.RegistrationDate != nil {
+		registrationDate = payload.RegistrationDate.UTC()
+	}
+
+	query, args, err := s.builder.
+		Insert("pvz").
+		Columns("id", "registration_date", "city").
+		Values(id, registrationDate, payload.City).
+		Suffix("RETURNING id, registration_date, city").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var pvz dto.PVZ
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx, query, args...).Scan(&pvz.Id, &pvz.RegistrationDate, &pvz.City); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, s.builder, dto.SyncItem{Kind: dto.KindPvzCreated, Pvz: &pvz})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PVZ: %w", err)
+	}
+
+	return &pvz, nil
+}
+
+func (s *PVZStorage) GetPVZs(ctx context.Context, params dto.GetPvzParams) ([]dto.PVZ, error) {
+	offset := (*params.Page - 1) * (*params.Limit)
+	query, args, err := s.builder.
+		Select("pvz.id", "pvz.registration_date", "pvz.city").
+		From("pvz").
+		Join("receptions ON pvz.id = receptions.pvz_id").
+		Where(squirrel.And{
+			squirrel.GtOrEq{"receptions.date_time": params.StartDate.UTC()},
+			squirrel.LtOrEq{"receptions.date_time": params.EndDate.UTC()},
+		}).
+		GroupBy("pvz.id").
+		OrderBy("pvz.registration_date").
+		Offset(uint64(offset)).
+		Limit(uint64(*params.Limit)).
+		ToSql()
+
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var pvzs []dto.PVZ
+	for rows.Next() {
+		var pvz dto.PVZ
+		if err := rows.Scan(
+			&pvz.Id,
+			&pvz.RegistrationDate,
+			&pvz.City,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan PVZ: %w", err)
+		}
+		pvzs = append(pvzs, pvz)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return pvzs, nil
+}
+
+func (s *PVZStorage) GetAllPVZs(ctx context.Context) []dto.PVZ {
+	query, args, err := s.builder.
+		Select("pvz.id", "pvz.registration_date", "pvz.city").
+		From("pvz").
+		ToSql()
+
+	if err != nil {
+		return nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var pvzs []dto.PVZ
+	for rows.Next() {
+		var pvz dto.PVZ
+		if err := rows.Scan(
+			&pvz.Id,
+			&pvz.RegistrationDate,
+			&pvz.City,
+		); err != nil {
+			return nil
+		}
+		pvzs = append(pvzs, pvz)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil
+	}
+
+	return pvzs
+}
@@ -0,0 +1,56 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPVZStorage(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		storage, err := NewPVZStorage(newTestDB(t))
+		require.NoError(t, err)
+		require.NotNil(t, storage)
+	})
+
+	t.Run("nil db", func(t *testing.T) {
+		storage, err := NewPVZStorage(nil)
+		require.Error(t, err)
+		require.Nil(t, storage)
+	})
+}
+
+func TestPVZStorage_GetPVZs(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	pvzStorage, err := NewPVZStorage(db)
+	require.NoError(t, err)
+	receptionStorage, err := NewReceptionStorage(db)
+	require.NoError(t, err)
+
+	withReception, err := pvzStorage.CreatePVZ(ctx, dto.PostPvzJSONRequestBody{City: dto.Moscow})
+	require.NoError(t, err)
+	_, err = pvzStorage.CreatePVZ(ctx, dto.PostPvzJSONRequestBody{City: dto.Kazan})
+	require.NoError(t, err)
+
+	_, err = receptionStorage.CreateReception(ctx, *withReception.Id, nil, nil)
+	require.NoError(t, err)
+
+	require.Len(t, pvzStorage.GetAllPVZs(ctx), 2)
+
+	startDate := time.Now().Add(-time.Hour)
+	endDate := time.Now().Add(time.Hour)
+	page, limit := 1, 10
+	pvzs, err := pvzStorage.GetPVZs(ctx, dto.GetPvzParams{
+		StartDate: &startDate,
+		EndDate:   &endDate,
+		Page:      &page,
+		Limit:     &limit,
+	})
+	require.NoError(t, err)
+	require.Len(t, pvzs, 1)
+	require.Equal(t, withReception.Id, pvzs[0].Id)
+}
@@ -0,0 +1,299 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Arzeeq/pvz-api/internal/service"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// ErrNoActiveReception is error of service, so it can tell missing reception from storage failure
+var ErrNoActiveReception = service.ErrNoActiveReception
+var ErrBuildQuery = errors.New("failed to build query")
+
+// receptionColumns lists reception columns in order of receptionFields
+var receptionColumns = []string{
+	"id", "date_time", "pvz_id", "status", "closed_at", "opened_by", "closed_by", "manifest", "discrepancy_report",
+}
+
+func receptionFields(r *dto.Reception) []any {
+	return []any{&r.Id, &r.DateTime, &r.PvzId, &r.Status, &r.ClosedAt, &r.OpenedBy, &r.ClosedBy, &r.Manifest, &r.DiscrepancyReport}
+}
+
+type ReceptionStorage struct {
+	db      *sql.DB
+	builder squirrel.StatementBuilderType
+}
+
+func NewReceptionStorage(db *sql.DB) (*ReceptionStorage, error) {
+	if db == nil {
+		return nil, errors.New("nil values in NewReceptionStorage constructor")
+	}
+
+	return &ReceptionStorage{
+		db:      db,
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+	}, nil
+}
+
+func (s *ReceptionStorage) CreateReception(
+	ctx context.Context,
+	pvzID openapi_types.UUID,
+	manifest *dto.ReceptionManifest,
+	openedBy *openapi_types.UUID,
+) (*dto.Reception, error) {
+	query, args, err := s.builder.
+		Insert("receptions").
+		Columns("id", "date_time", "pvz_id", "status", "manifest", "opened_by").
+		Values(uuid.New(), time.Now().UTC(), pvzID, dto.InProgress, manifest, openedBy).
+		Suffix("RETURNING " + strings.Join(receptionColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var reception dto.Reception
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx, query, args...).Scan(receptionFields(&reception)...); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, s.builder, dto.SyncItem{Kind: dto.KindReceptionCreated, Reception: &reception})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reception: %w", err)
+	}
+
+	return &reception, nil
+}
+
+// CloseReception closes in progress reception of pvz with report built from its products,
+// transactions are serialized, so products can not be added or deleted meanwhile
+func (s *ReceptionStorage) CloseReception(
+	ctx context.Context,
+	pvzID openapi_types.UUID,
+	closedBy *openapi_types.UUID,
+	report func(reception dto.Reception, products []dto.Product) *dto.DiscrepancyReport,
+) (*dto.Reception, int, error) {
+	activeQuery, activeArgs, err := s.builder.
+		Select(receptionColumns...).
+		From("receptions").
+		Where(squirrel.Eq{
+			"pvz_id": pvzID,
+			"status": dto.InProgress,
+		}).
+		ToSql()
+	if err != nil {
+		return nil, 0, ErrBuildQuery
+	}
+
+	var reception *dto.Reception
+	var products []dto.Product
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var active dto.Reception
+		err := tx.QueryRowContext(ctx, activeQuery, activeArgs...).Scan(receptionFields(&active)...)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		products, err = s.receptionProducts(ctx, tx, active.Id)
+		if err != nil {
+			return err
+		}
+
+		query, args, err := s.builder.
+			Update("receptions").
+			Set("status", dto.Close).
+			Set("closed_at", time.Now().UTC()).
+			Set("closed_by", closedBy).
+			Set("discrepancy_report", report(active, products)).
+			Where(squirrel.Eq{"id": active.Id}).
+			Suffix("RETURNING " + strings.Join(receptionColumns, ", ")).
+			ToSql()
+		if err != nil {
+			return ErrBuildQuery
+		}
+
+		var closed dto.Reception
+		if err := tx.QueryRowContext(ctx, query, args...).Scan(receptionFields(&closed)...); err != nil {
+			return err
+		}
+		reception = &closed
+		return recordChange(ctx, tx, s.builder, dto.SyncItem{Kind: dto.KindReceptionClosed, Reception: &closed})
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to close reception: %w", err)
+	}
+
+	return reception, len(products), nil
+}
+
+func (s *ReceptionStorage) receptionProducts(ctx context.Context, tx *sql.Tx, receptionID openapi_types.UUID) ([]dto.Product, error) {
+	query, args, err := s.builder.
+		Select(productColumns...).
+		From("products").
+		Where(squirrel.Eq{"reception_id": receptionID}).
+		OrderBy("date_time").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []dto.Product
+	for rows.Next() {
+		var product dto.Product
+		if err := rows.Scan(productFields(&product)...); err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+
+	return products, rows.Err()
+}
+
+func (s *ReceptionStorage) GetPVZReceptionsFiltered(ctx context.Context, pvzID openapi_types.UUID, startDate, endDate time.Time) []dto.Reception {
+	query, args, err := s.builder.
+		Select(receptionColumns...).
+		From("receptions").
+		Where(squirrel.And{
+			squirrel.GtOrEq{"date_time": startDate.UTC()},
+			squirrel.LtOrEq{"date_time": endDate.UTC()},
+		}).
+		Where(squirrel.Eq{"pvz_id": pvzID}).
+		ToSql()
+
+	if err != nil {
+		return nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var receptions []dto.Reception
+	for rows.Next() {
+		var r dto.Reception
+		if err := rows.Scan(receptionFields(&r)...); err != nil {
+			return nil
+		}
+		receptions = append(receptions, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil
+	}
+
+	return receptions
+}
+
+// GetReception returns reception by id or nil if it does not exist
+func (s *ReceptionStorage) GetReception(ctx context.Context, receptionID openapi_types.UUID) (*dto.Reception, error) {
+	query, args, err := s.builder.
+		Select(receptionColumns...).
+		From("receptions").
+		Where(squirrel.Eq{"id": receptionID}).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var reception dto.Reception
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(receptionFields(&reception)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reception: %w", err)
+	}
+
+	return &reception, nil
+}
+
+// GetActiveReception returns in progress reception of pvz or nil if there is none
+func (s *ReceptionStorage) GetActiveReception(ctx context.Context, pvzID openapi_types.UUID) (*dto.Reception, error) {
+	query, args, err := s.builder.
+		Select(receptionColumns...).
+		From("receptions").
+		Where(squirrel.Eq{
+			"pvz_id": pvzID,
+			"status": dto.InProgress,
+		}).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var reception dto.Reception
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(receptionFields(&reception)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active reception: %w", err)
+	}
+
+	return &reception, nil
+}
+
+func (s *ReceptionStorage) GetReceptions(ctx context.Context, pvzID openapi_types.UUID, params dto.GetPvzPvzIdReceptionsParams) ([]dto.Reception, error) {
+	offset := (*params.Page - 1) * (*params.Limit)
+	builder := s.builder.
+		Select(receptionColumns...).
+		From("receptions").
+		Where(squirrel.Eq{"pvz_id": pvzID}).
+		Where(squirrel.And{
+			squirrel.GtOrEq{"date_time": params.StartDate.UTC()},
+			squirrel.LtOrEq{"date_time": params.EndDate.UTC()},
+		})
+	if params.Status != nil {
+		builder = builder.Where(squirrel.Eq{"status": *params.Status})
+	}
+
+	query, args, err := builder.
+		OrderBy("date_time DESC").
+		Offset(uint64(offset)).
+		Limit(uint64(*params.Limit)).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	receptions := make([]dto.Reception, 0)
+	for rows.Next() {
+		var r dto.Reception
+		if err := rows.Scan(receptionFields(&r)...); err != nil {
+			return nil, fmt.Errorf("failed to scan reception: %w", err)
+		}
+		receptions = append(receptions, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return receptions, nil
+}
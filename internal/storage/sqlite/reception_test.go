@@ -0,0 +1,156 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReceptionStorage(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		storage, err := NewReceptionStorage(newTestDB(t))
+		require.NoError(t, err)
+		require.NotNil(t, storage)
+	})
+
+	t.Run("nil db", func(t *testing.T) {
+		storage, err := NewReceptionStorage(nil)
+		require.Error(t, err)
+		require.Nil(t, storage)
+	})
+}
+
+func noReport(dto.Reception, []dto.Product) *dto.DiscrepancyReport {
+	return nil
+}
+
+func TestReceptionStorage_CreateAndClose(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	pvzStorage, err := NewPVZStorage(db)
+	require.NoError(t, err)
+	storage, err := NewReceptionStorage(db)
+	require.NoError(t, err)
+
+	pvz, err := pvzStorage.CreatePVZ(ctx, dto.PostPvzJSONRequestBody{City: dto.Moscow})
+	require.NoError(t, err)
+
+	openedBy := &openapi_types.UUID{1}
+	reception, err := storage.CreateReception(ctx, *pvz.Id, nil, openedBy)
+	require.NoError(t, err)
+	require.Equal(t, dto.InProgress, reception.Status)
+	require.Equal(t, openedBy, reception.OpenedBy)
+	require.Nil(t, reception.ClosedAt)
+
+	// only one reception per pvz can be in progress
+	_, err = storage.CreateReception(ctx, *pvz.Id, nil, nil)
+	require.Error(t, err)
+
+	closedBy := &openapi_types.UUID{2}
+	closed, products, err := storage.CloseReception(ctx, *pvz.Id, closedBy, noReport)
+	require.NoError(t, err)
+	require.Zero(t, products)
+	require.Equal(t, reception.Id, closed.Id)
+	require.Equal(t, dto.Close, closed.Status)
+	require.Equal(t, openedBy, closed.OpenedBy)
+	require.Equal(t, closedBy, closed.ClosedBy)
+	require.NotNil(t, closed.ClosedAt)
+	require.False(t, closed.ClosedAt.Before(closed.DateTime))
+
+	// nothing left to close
+	closed, _, err = storage.CloseReception(ctx, *pvz.Id, nil, noReport)
+	require.NoError(t, err)
+	require.Nil(t, closed)
+}
+
+func TestReceptionStorage_GetReceptions(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	pvzStorage, err := NewPVZStorage(db)
+	require.NoError(t, err)
+	storage, err := NewReceptionStorage(db)
+	require.NoError(t, err)
+
+	pvz, err := pvzStorage.CreatePVZ(ctx, dto.PostPvzJSONRequestBody{City: dto.Moscow})
+	require.NoError(t, err)
+
+	active, err := storage.GetActiveReception(ctx, *pvz.Id)
+	require.NoError(t, err)
+	require.Nil(t, active)
+
+	first, err := storage.CreateReception(ctx, *pvz.Id, nil, nil)
+	require.NoError(t, err)
+	_, _, err = storage.CloseReception(ctx, *pvz.Id, nil, noReport)
+	require.NoError(t, err)
+	second, err := storage.CreateReception(ctx, *pvz.Id, nil, nil)
+	require.NoError(t, err)
+
+	active, err = storage.GetActiveReception(ctx, *pvz.Id)
+	require.NoError(t, err)
+	require.Equal(t, second.Id, active.Id)
+
+	found, err := storage.GetReception(ctx, first.Id)
+	require.NoError(t, err)
+	require.Equal(t, dto.Close, found.Status)
+
+	params := dto.GetPvzPvzIdReceptionsParams{}
+	dto.CorrectReceptionsParams(&params)
+	receptions, err := storage.GetReceptions(ctx, *pvz.Id, params)
+	require.NoError(t, err)
+	require.Len(t, receptions, 2)
+	require.Equal(t, second.Id, receptions[0].Id)
+
+	status := dto.StatusFilterClose
+	params.Status = &status
+	receptions, err = storage.GetReceptions(ctx, *pvz.Id, params)
+	require.NoError(t, err)
+	require.Len(t, receptions, 1)
+	require.Equal(t, first.Id, receptions[0].Id)
+}
+
+func TestReceptionStorage_ManifestAndReport(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	pvzStorage, err := NewPVZStorage(db)
+	require.NoError(t, err)
+	storage, err := NewReceptionStorage(db)
+	require.NoError(t, err)
+	productStorage, err := NewProductStorage(db)
+	require.NoError(t, err)
+
+	pvz, err := pvzStorage.CreatePVZ(ctx, dto.PostPvzJSONRequestBody{City: dto.Kazan})
+	require.NoError(t, err)
+
+	manifest := &dto.ReceptionManifest{Items: []dto.ProductTypeCount{{Type: dto.CountClothes, Count: 2}}}
+	reception, err := storage.CreateReception(ctx, *pvz.Id, manifest, nil)
+	require.NoError(t, err)
+	require.Equal(t, manifest, reception.Manifest)
+	require.Nil(t, reception.DiscrepancyReport)
+
+	product, err := productStorage.CreateProduct(ctx, dto.PostProductsJSONBody{PvzId: *pvz.Id, Type: dto.PostProductsJSONBodyTypeClothes})
+	require.NoError(t, err)
+
+	report := &dto.DiscrepancyReport{
+		HasDiscrepancies: true,
+		Items:            []dto.DiscrepancyItem{{Type: dto.ProductTypeClothes, Expected: 2, Received: 1, Missing: 1}},
+	}
+	closed, products, err := storage.CloseReception(ctx, *pvz.Id, nil, func(active dto.Reception, received []dto.Product) *dto.DiscrepancyReport {
+		// report is built from reception and its products read in closing transaction
+		require.Equal(t, reception.Id, active.Id)
+		require.Equal(t, manifest, active.Manifest)
+		require.Len(t, received, 1)
+		require.Equal(t, product.Id, received[0].Id)
+		return report
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, products)
+	require.Equal(t, report, closed.DiscrepancyReport)
+
+	found, err := storage.GetReception(ctx, reception.Id)
+	require.NoError(t, err)
+	require.Equal(t, manifest, found.Manifest)
+	require.Equal(t, report, found.DiscrepancyReport)
+}
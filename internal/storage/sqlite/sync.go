@@ -0,0 +1,200 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Masterminds/squirrel"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// number of unsynced items returned in sync status
+const statusItemsLimit = 100
+
+// SyncStorage reads local changes from sync outbox and tracks their upload state
+type SyncStorage struct {
+	db      *sql.DB
+	builder squirrel.StatementBuilderType
+}
+
+func NewSyncStorage(db *sql.DB) (*SyncStorage, error) {
+	if db == nil {
+		return nil, errors.New("nil values in NewSyncStorage constructor")
+	}
+
+	return &SyncStorage{
+		db:      db,
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+	}, nil
+}
+
+// GetPendingItems returns oldest not uploaded changes in the order they were made
+func (s *SyncStorage) GetPendingItems(ctx context.Context, limit int) ([]dto.SyncItem, error) {
+	query, args, err := s.builder.
+		Select("payload").
+		From("sync_outbox").
+		Where(squirrel.Eq{"status": dto.OutboxPending}).
+		OrderBy("seq").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending sync items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []dto.SyncItem
+	for rows.Next() {
+		var payload string
+		if err := rows.Scan(&payload); err != nil {
+			return nil, fmt.Errorf("failed to scan sync item: %w", err)
+		}
+
+		var item dto.SyncItem
+		if err := json.Unmarshal([]byte(payload), &item); err != nil {
+			return nil, fmt.Errorf("failed to decode sync item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return items, nil
+}
+
+// SaveItemResults marks items accepted by the central server as synced and rejected ones as failed
+func (s *SyncStorage) SaveItemResults(ctx context.Context, results []dto.SyncItemResult) error {
+	now := time.Now().UTC()
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		for _, result := range results {
+			update := s.builder.
+				Update("sync_outbox").
+				Set("attempts", squirrel.Expr("attempts + 1")).
+				Where(squirrel.Eq{"id": result.Id})
+
+			if result.Status == dto.SyncFailed {
+				update = update.Set("status", dto.OutboxFailed).Set("last_error", result.Message)
+			} else {
+				update = update.Set("status", dto.OutboxSynced).Set("last_error", nil).Set("synced_at", now)
+			}
+
+			query, args, err := update.ToSql()
+			if err != nil {
+				return ErrBuildQuery
+			}
+
+			if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+				return fmt.Errorf("failed to save sync item result: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// SaveAttemptError records failed upload attempt, items stay pending to be retried
+func (s *SyncStorage) SaveAttemptError(ctx context.Context, ids []openapi_types.UUID, message string) error {
+	query, args, err := s.builder.
+		Update("sync_outbox").
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Set("last_error", message).
+		Where(squirrel.Eq{"id": ids}).
+		ToSql()
+	if err != nil {
+		return ErrBuildQuery
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to save sync attempt: %w", err)
+	}
+
+	return nil
+}
+
+// GetSyncStatus returns number of items in every state, failed items and the oldest pending ones
+func (s *SyncStorage) GetSyncStatus(ctx context.Context) (*dto.SyncStatus, error) {
+	countQuery, countArgs, err := s.builder.
+		Select("status", "COUNT(*)").
+		From("sync_outbox").
+		GroupBy("status").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	rows, err := s.db.QueryContext(ctx, countQuery, countArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sync items: %w", err)
+	}
+	defer rows.Close()
+
+	status := dto.SyncStatus{Items: make([]dto.SyncOutboxItem, 0)}
+	for rows.Next() {
+		var itemStatus dto.SyncOutboxItemStatus
+		var count int
+		if err := rows.Scan(&itemStatus, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan sync items count: %w", err)
+		}
+
+		switch itemStatus {
+		case dto.OutboxPending:
+			status.Pending = count
+		case dto.OutboxFailed:
+			status.Failed = count
+		case dto.OutboxSynced:
+			status.Synced = count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	itemsQuery, itemsArgs, err := s.builder.
+		Select("id", "kind", "status", "attempts", "last_error", "created_at").
+		From("sync_outbox").
+		Where(squirrel.NotEq{"status": dto.OutboxSynced}).
+		// items rejected by the central server are never retried, so they are listed before pending ones
+		OrderByClause("status = ? DESC, seq", dto.OutboxFailed).
+		Limit(statusItemsLimit).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	itemRows, err := s.db.QueryContext(ctx, itemsQuery, itemsArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unsynced items: %w", err)
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var item dto.SyncOutboxItem
+		if err := itemRows.Scan(
+			&item.Id,
+			&item.Kind,
+			&item.Status,
+			&item.Attempts,
+			&item.LastError,
+			&item.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan sync item: %w", err)
+		}
+		status.Items = append(status.Items, item)
+	}
+
+	if err := itemRows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return &status, nil
+}
@@ -0,0 +1,95 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSyncStorage(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		storage, err := NewSyncStorage(newTestDB(t))
+		require.NoError(t, err)
+		require.NotNil(t, storage)
+	})
+
+	t.Run("nil db", func(t *testing.T) {
+		storage, err := NewSyncStorage(nil)
+		require.Error(t, err)
+		require.Nil(t, storage)
+	})
+}
+
+func TestSyncStorage_Outbox(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	pvzStorage, err := NewPVZStorage(db)
+	require.NoError(t, err)
+	receptionStorage, err := NewReceptionStorage(db)
+	require.NoError(t, err)
+	productStorage, err := NewProductStorage(db)
+	require.NoError(t, err)
+	storage, err := NewSyncStorage(db)
+	require.NoError(t, err)
+
+	// every local change is recorded in outbox
+	pvz, err := pvzStorage.CreatePVZ(ctx, dto.PostPvzJSONRequestBody{City: dto.Kazan})
+	require.NoError(t, err)
+	_, err = receptionStorage.CreateReception(ctx, *pvz.Id, nil, nil)
+	require.NoError(t, err)
+	product, err := productStorage.CreateProduct(ctx, dto.PostProductsJSONBody{PvzId: *pvz.Id, Type: dto.PostProductsJSONBodyTypeClothes})
+	require.NoError(t, err)
+
+	items, err := storage.GetPendingItems(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	require.Equal(t, dto.KindPvzCreated, items[0].Kind)
+	require.Equal(t, dto.KindReceptionCreated, items[1].Kind)
+	require.Equal(t, dto.KindProductAdded, items[2].Kind)
+	require.Equal(t, product.Id, items[2].Product.Id)
+
+	// upload failure keeps items pending
+	require.NoError(t, storage.SaveAttemptError(ctx, []openapi_types.UUID{items[0].Id}, "connection refused"))
+
+	message := "reception not found"
+	require.NoError(t, storage.SaveItemResults(ctx, []dto.SyncItemResult{
+		{Id: items[0].Id, Status: dto.SyncApplied},
+		{Id: items[1].Id, Status: dto.SyncDuplicate},
+		{Id: items[2].Id, Status: dto.SyncFailed, Message: &message},
+	}))
+
+	pending, err := storage.GetPendingItems(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	status, err := storage.GetSyncStatus(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, status.Pending)
+	require.Equal(t, 1, status.Failed)
+	require.Equal(t, 2, status.Synced)
+	require.Len(t, status.Items, 1)
+	require.Equal(t, items[2].Id, status.Items[0].Id)
+	require.Equal(t, dto.OutboxFailed, status.Items[0].Status)
+	require.Equal(t, &message, status.Items[0].LastError)
+
+	// failed item is listed before older pending ones
+	_, err = productStorage.CreateProduct(ctx, dto.PostProductsJSONBody{PvzId: *pvz.Id, Type: dto.PostProductsJSONBodyTypeShoes})
+	require.NoError(t, err)
+	_, err = productStorage.CreateProduct(ctx, dto.PostProductsJSONBody{PvzId: *pvz.Id, Type: dto.PostProductsJSONBodyTypeShoes})
+	require.NoError(t, err)
+	pending, err = storage.GetPendingItems(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	require.NoError(t, storage.SaveItemResults(ctx, []dto.SyncItemResult{{Id: pending[1].Id, Status: dto.SyncFailed, Message: &message}}))
+
+	status, err = storage.GetSyncStatus(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, status.Pending)
+	require.Equal(t, 2, status.Failed)
+	require.Len(t, status.Items, 3)
+	require.Equal(t, []openapi_types.UUID{items[2].Id, pending[1].Id, pending[0].Id},
+		[]openapi_types.UUID{status.Items[0].Id, status.Items[1].Id, status.Items[2].Id})
+}
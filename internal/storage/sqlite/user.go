@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+type UserStorage struct {
+	db      *sql.DB
+	builder squirrel.StatementBuilderType
+}
+
+func NewUserStorage(db *sql.DB) (*UserStorage, error) {
+	if db == nil {
+		return nil, errors.New("nil values in NewUserStorage constructor")
+	}
+
+	return &UserStorage{
+		db:      db,
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+	}, nil
+}
+
+func (s *UserStorage) CreateUser(ctx context.Context, payload dto.PostRegisterJSONBody) (*dto.User, error) {
+	query, args, err := s.builder.
+		Insert("users").
+		Columns("id", "email", "password_hash", "role").
+		Values(uuid.New(), payload.Email, payload.Password, payload.Role).
+		Suffix("RETURNING id, email, role").
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var user dto.User
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(&user.Id, &user.Email, &user.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (s *UserStorage) GetUserPassword(ctx context.Context, email string) (string, error) {
+	query, args, err := s.builder.
+		Select("password_hash").
+		From("users").
+		Where(squirrel.Eq{"email": email}).
+		ToSql()
+	if err != nil {
+		return "", ErrBuildQuery
+	}
+
+	var hashedPassword string
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(&hashedPassword)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return hashedPassword, nil
+}
+
+func (s *UserStorage) GetUserByEmail(ctx context.Context, email string) (*dto.User, error) {
+	query, args, err := s.builder.
+		Select("id", "email", "role").
+		From("users").
+		Where(squirrel.Eq{"email": email}).
+		ToSql()
+	if err != nil {
+		return nil, ErrBuildQuery
+	}
+
+	var user dto.User
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(&user.Id, &user.Email, &user.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return &user, nil
+}
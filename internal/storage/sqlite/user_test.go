@@ -0,0 +1,46 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUserStorage(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		storage, err := NewUserStorage(newTestDB(t))
+		require.NoError(t, err)
+		require.NotNil(t, storage)
+	})
+
+	t.Run("nil db", func(t *testing.T) {
+		storage, err := NewUserStorage(nil)
+		require.Error(t, err)
+		require.Nil(t, storage)
+	})
+}
+
+func TestUserStorage_CreateUser(t *testing.T) {
+	ctx := context.Background()
+	storage, err := NewUserStorage(newTestDB(t))
+	require.NoError(t, err)
+
+	payload := dto.PostRegisterJSONBody{Email: "user@example.com", Password: "hash", Role: dto.Employee}
+	user, err := storage.CreateUser(ctx, payload)
+	require.NoError(t, err)
+	require.NotNil(t, user.Id)
+	require.Equal(t, dto.UserRoleEmployee, user.Role)
+
+	_, err = storage.CreateUser(ctx, payload)
+	require.Error(t, err)
+
+	password, err := storage.GetUserPassword(ctx, "user@example.com")
+	require.NoError(t, err)
+	require.Equal(t, "hash", password)
+
+	found, err := storage.GetUserByEmail(ctx, "user@example.com")
+	require.NoError(t, err)
+	require.Equal(t, user.Id, found.Id)
+}
@@ -46,16 +46,17 @@ func TestIntegrationWithTestContainers(t *testing.T) {
 	require.NoError(t, err)
 	defer deferFn()
 
-	log := logger.New(cfg.Env, cfg.LoggerFormat)
+	live := config.NewLive(cfg)
+	log := logger.New(live)
 
-	pool, closeConn, err := pg.InitDB(cfg.ConnectionStr)
+	pool, closeConn, err := pg.InitDB(cfg.ConnectionStr, pg.PoolSettings{})
 	require.NoError(t, err)
 	defer closeConn()
 
-	handlers, err := app.InitializeHandlers(pool, cfg, log)
+	handlers, err := app.InitializeHandlers(pool, cfg, live, log)
 	require.NoError(t, err)
 
-	server, err := server.NewHTTP(handlers.Auth, handlers.Pvz, handlers.Reception, handlers.Product, log, cfg)
+	server, err := server.NewHTTP(handlers.HTTPHandlers, log, cfg, live)
 	require.NoError(t, err, "Failed to create server")
 
 	t.Run("create pvz, create reception, add 50 products, close reception", func(t *testing.T) {
@@ -139,6 +140,82 @@ func TestIntegrationWithTestContainers(t *testing.T) {
 		require.Equal(t, receptionResponse.PvzId, closeResponse.PvzId)
 		require.Equal(t, dto.Close, closeResponse.Status)
 	})
+
+	t.Run("cancelled client aborts database query", func(t *testing.T) {
+		ctx := context.Background()
+		tokenModerator, err := getToken(server, dto.PostDummyLoginJSONBodyRoleModerator)
+		require.NoError(t, err)
+
+		// lock pvz table, so listing of pvz waits for the lock inside postgres
+		tx, err := pool.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+		_, err = tx.Exec(ctx, "LOCK TABLE pvz IN ACCESS EXCLUSIVE MODE")
+		require.NoError(t, err)
+
+		req, err := newRequest("GET", "http://localhost:8080/pvz", tokenModerator, nil)
+		require.NoError(t, err)
+		clientCtx, disconnect := context.WithCancel(ctx)
+		req = req.WithContext(clientCtx)
+
+		done := make(chan struct{})
+		go func() {
+			server.ServeHTTP(httptest.NewRecorder(), req)
+			close(done)
+		}()
+
+		waitingQueries := func() int {
+			var count int
+			err := pool.QueryRow(ctx,
+				"SELECT COUNT(*) FROM pg_stat_activity WHERE wait_event_type = 'Lock' AND query LIKE '%FROM pvz%'").
+				Scan(&count)
+			require.NoError(t, err)
+			return count
+		}
+		require.Eventually(t, func() bool { return waitingQueries() == 1 }, time.Second, 10*time.Millisecond)
+
+		// handler returns and postgres drops the query long before request timeout
+		disconnect()
+		select {
+		case <-done:
+		case <-time.After(cfg.RequestTimeout / 2):
+			t.Fatal("handler was not cancelled with client")
+		}
+		require.Eventually(t, func() bool { return waitingQueries() == 0 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("concurrent scans of one barcode create one product", func(t *testing.T) {
+		testConcurrentBarcode(t, pool)
+	})
+
+	t.Run("product is not deleted from reception closed meanwhile", func(t *testing.T) {
+		testDeleteReceptionProduct(t, pool)
+	})
+
+	t.Run("idempotency key is reserved by one request", func(t *testing.T) {
+		testConcurrentIdempotencyKey(t, pool)
+	})
+
+	t.Run("concurrent wrong pickup codes are limited by max attempts", func(t *testing.T) {
+		testConcurrentPickupAttempts(t, pool)
+	})
+
+	t.Run("last place of cell is taken by one product", func(t *testing.T) {
+		testConcurrentPlacement(t, pool)
+	})
+
+	t.Run("transfer is received once and kept in product path", func(t *testing.T) {
+		testTransferPath(t, pool)
+	})
+
+	t.Run("inventory report matches scans with pvz products", func(t *testing.T) {
+		testInventoryReport(t, pool)
+	})
+
+	// expires every received product left by previous subtests, so it goes last
+	t.Run("expired products are returned once and not issued", func(t *testing.T) {
+		testExpiredProducts(t, pool)
+	})
 }
 
 func createContainer(ctx context.Context) (func(), error) {
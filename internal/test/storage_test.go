@@ -0,0 +1,447 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Arzeeq/pvz-api/internal/dto"
+	"github.com/Arzeeq/pvz-api/internal/service"
+	"github.com/Arzeeq/pvz-api/internal/storage/pg"
+	"github.com/jackc/pgx/v5/pgxpool"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// concurrentCalls is number of goroutines racing for the same rows
+const concurrentCalls = 10
+
+// receivedProducts creates pvz with closed reception of count products
+func receivedProducts(t *testing.T, pool *pgxpool.Pool, count int) (openapi_types.UUID, []dto.Product) {
+	t.Helper()
+	ctx := context.Background()
+
+	pvzStorage, err := pg.NewPVZStorage(pool)
+	require.NoError(t, err)
+	receptionStorage, err := pg.NewReceptionStorage(pool)
+	require.NoError(t, err)
+	productStorage, err := pg.NewProductStorage(pool)
+	require.NoError(t, err)
+
+	pvz, err := pvzStorage.CreatePVZ(ctx, dto.PostPvzJSONRequestBody{City: dto.Moscow})
+	require.NoError(t, err)
+	_, err = receptionStorage.CreateReception(ctx, *pvz.Id, nil, nil)
+	require.NoError(t, err)
+
+	products := make([]dto.Product, 0, count)
+	for i := range count {
+		barcode := fmt.Sprintf("%s-%d", pvz.Id, i)
+		product, err := productStorage.CreateProduct(ctx, dto.PostProductsJSONBody{
+			PvzId:   *pvz.Id,
+			Type:    dto.PostProductsJSONBodyTypeElectronics,
+			Barcode: &barcode,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, product)
+		products = append(products, *product)
+	}
+
+	_, _, err = receptionStorage.CloseReception(ctx, *pvz.Id, nil, func(dto.Reception, []dto.Product) *dto.DiscrepancyReport {
+		return nil
+	})
+	require.NoError(t, err)
+
+	return *pvz.Id, products
+}
+
+// storeProducts moves received products to stored status
+func storeProducts(t *testing.T, pool *pgxpool.Pool, products []dto.Product) {
+	t.Helper()
+
+	productStorage, err := pg.NewProductStorage(pool)
+	require.NoError(t, err)
+	for _, product := range products {
+		stored, err := productStorage.UpdateProductStatus(context.Background(), *product.Id, dto.ProductReceived, dto.ProductStored)
+		require.NoError(t, err)
+		require.NotNil(t, stored)
+	}
+}
+
+// race runs call in concurrentCalls goroutines at once
+func race(call func()) {
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for range concurrentCalls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			call()
+		}()
+	}
+	close(start)
+	wg.Wait()
+}
+
+func testConcurrentBarcode(t *testing.T, pool *pgxpool.Pool) {
+	ctx := context.Background()
+	pvzStorage, err := pg.NewPVZStorage(pool)
+	require.NoError(t, err)
+	receptionStorage, err := pg.NewReceptionStorage(pool)
+	require.NoError(t, err)
+	productStorage, err := pg.NewProductStorage(pool)
+	require.NoError(t, err)
+
+	pvz, err := pvzStorage.CreatePVZ(ctx, dto.PostPvzJSONRequestBody{City: dto.Kazan})
+	require.NoError(t, err)
+	_, err = receptionStorage.CreateReception(ctx, *pvz.Id, nil, nil)
+	require.NoError(t, err)
+
+	barcode := "concurrent-barcode"
+	var mu sync.Mutex
+	created, duplicates := 0, 0
+	race(func() {
+		product, err := productStorage.CreateProduct(ctx, dto.PostProductsJSONBody{
+			PvzId:   *pvz.Id,
+			Type:    dto.PostProductsJSONBodyTypeShoes,
+			Barcode: &barcode,
+		})
+		mu.Lock()
+		defer mu.Unlock()
+		assert.NoError(t, err)
+		if product != nil {
+			created++
+		} else {
+			duplicates++
+		}
+	})
+
+	require.Equal(t, 1, created)
+	require.Equal(t, concurrentCalls-1, duplicates)
+}
+
+func testDeleteReceptionProduct(t *testing.T, pool *pgxpool.Pool) {
+	ctx := context.Background()
+	productStorage, err := pg.NewProductStorage(pool)
+	require.NoError(t, err)
+	receptionStorage, err := pg.NewReceptionStorage(pool)
+	require.NoError(t, err)
+
+	// product of closed reception is kept
+	_, closed := receivedProducts(t, pool, 1)
+	deleted, err := productStorage.DeleteReceptionProduct(ctx, closed[0].ReceptionId, *closed[0].Id, nil)
+	require.NoError(t, err)
+	require.False(t, deleted)
+
+	pvzID, _ := receivedProducts(t, pool, 0)
+	reception, err := receptionStorage.CreateReception(ctx, pvzID, nil, nil)
+	require.NoError(t, err)
+	product, err := productStorage.CreateProduct(ctx, dto.PostProductsJSONBody{
+		PvzId: pvzID,
+		Type:  dto.PostProductsJSONBodyTypeClothes,
+	})
+	require.NoError(t, err)
+
+	// closing transaction holds reception, delete waits for it
+	tx, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+	_, err = tx.Exec(ctx, "SELECT id FROM receptions WHERE id = $1 FOR UPDATE", reception.Id)
+	require.NoError(t, err)
+
+	done := make(chan bool)
+	go func() {
+		deleted, err := productStorage.DeleteReceptionProduct(ctx, reception.Id, *product.Id, nil)
+		assert.NoError(t, err)
+		done <- deleted
+	}()
+	require.Eventually(t, func() bool {
+		var waiting int
+		err := pool.QueryRow(ctx,
+			"SELECT COUNT(*) FROM pg_stat_activity WHERE wait_event_type = 'Lock' AND query LIKE '%FROM receptions%'").
+			Scan(&waiting)
+		return err == nil && waiting == 1
+	}, time.Second, 10*time.Millisecond)
+	_, err = tx.Exec(ctx, "UPDATE receptions SET status = $1 WHERE id = $2", dto.Close, reception.Id)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit(ctx))
+
+	// reception was closed first, product stays in it
+	require.False(t, <-done)
+	products := productStorage.GetReceptionProducts(ctx, reception.Id)
+	require.Len(t, products, 1)
+}
+
+func testConcurrentIdempotencyKey(t *testing.T, pool *pgxpool.Pool) {
+	ctx := context.Background()
+	idempotencyStorage, err := pg.NewIdempotencyStorage(pool)
+	require.NoError(t, err)
+
+	const scope, key = "integration", "concurrent-key"
+	var mu sync.Mutex
+	reserved, inProgress := 0, 0
+	race(func() {
+		record, err := idempotencyStorage.Reserve(ctx, scope, key, "fingerprint", time.Now().Add(time.Minute))
+		mu.Lock()
+		defer mu.Unlock()
+		assert.NoError(t, err)
+		if record == nil {
+			reserved++
+			return
+		}
+		assert.Zero(t, record.StatusCode)
+		inProgress++
+	})
+	require.Equal(t, 1, reserved)
+	require.Equal(t, concurrentCalls-1, inProgress)
+
+	require.NoError(t, idempotencyStorage.Complete(ctx, scope, key, 201, []byte(`{}`), time.Now().Add(time.Hour)))
+	record, err := idempotencyStorage.Reserve(ctx, scope, key, "other", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	require.Equal(t, 201, record.StatusCode)
+	require.Equal(t, "fingerprint", record.Fingerprint)
+
+	// key with expired lease is taken over by next request
+	const expiredKey = "expired-key"
+	record, err = idempotencyStorage.Reserve(ctx, scope, expiredKey, "fingerprint", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	require.Nil(t, record)
+	record, err = idempotencyStorage.Reserve(ctx, scope, expiredKey, "other", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Nil(t, record)
+}
+
+func testConcurrentPickupAttempts(t *testing.T, pool *pgxpool.Pool) {
+	ctx := context.Background()
+	orderStorage, err := pg.NewOrderStorage(pool)
+	require.NoError(t, err)
+
+	pvzID, products := receivedProducts(t, pool, 1)
+	storeProducts(t, pool, products)
+	order, err := orderStorage.CreateOrder(ctx, dto.PostOrdersJSONBody{
+		Customer:   "+79990000000",
+		ProductIds: []openapi_types.UUID{*products[0].Id},
+		PvzId:      pvzID,
+	}, nil)
+	require.NoError(t, err)
+	require.NoError(t, orderStorage.SetPickupCode(ctx, order.Id, "hash", time.Now().Add(time.Hour)))
+
+	const maxAttempts = 3
+	now := time.Now()
+	var mu sync.Mutex
+	claimed, locked, lockedBy := 0, 0, 0
+	race(func() {
+		state, err := orderStorage.ClaimPickupAttempt(ctx, order.Id, now, maxAttempts, now.Add(time.Minute))
+		mu.Lock()
+		defer mu.Unlock()
+		assert.NoError(t, err)
+		switch {
+		case state == nil:
+			locked++
+		case state.LockedUntil != nil:
+			lockedBy++
+			claimed++
+		default:
+			claimed++
+		}
+	})
+
+	require.Equal(t, maxAttempts, claimed)
+	require.Equal(t, 1, lockedBy)
+	require.Equal(t, concurrentCalls-maxAttempts, locked)
+
+	// first attempt after lockout starts counting again
+	after := now.Add(2 * time.Minute)
+	state, err := orderStorage.ClaimPickupAttempt(ctx, order.Id, after, maxAttempts, after.Add(time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	require.Equal(t, 1, state.FailedAttempts)
+	require.Nil(t, state.LockedUntil)
+}
+
+func testConcurrentPlacement(t *testing.T, pool *pgxpool.Pool) {
+	ctx := context.Background()
+	cellStorage, err := pg.NewCellStorage(pool)
+	require.NoError(t, err)
+
+	pvzID, products := receivedProducts(t, pool, concurrentCalls)
+	cell, err := cellStorage.CreateCell(ctx, pvzID, dto.PostPvzPvzIdCellsJSONBody{Capacity: 1, Code: "A-1"})
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	next, placed, full := 0, 0, 0
+	race(func() {
+		mu.Lock()
+		product := products[next]
+		next++
+		mu.Unlock()
+
+		ok, err := cellStorage.PlaceProduct(ctx, *product.Id, cell.Id, nil)
+		mu.Lock()
+		defer mu.Unlock()
+		assert.NoError(t, err)
+		if ok {
+			placed++
+		} else {
+			full++
+		}
+	})
+
+	require.Equal(t, 1, placed)
+	require.Equal(t, concurrentCalls-1, full)
+}
+
+func testTransferPath(t *testing.T, pool *pgxpool.Pool) {
+	ctx := context.Background()
+	transferStorage, err := pg.NewTransferStorage(pool)
+	require.NoError(t, err)
+
+	fromPvzID, products := receivedProducts(t, pool, 2)
+	toPvzID, _ := receivedProducts(t, pool, 0)
+	productIDs := []openapi_types.UUID{*products[0].Id, *products[1].Id}
+
+	transferable, err := transferStorage.GetTransferableProducts(ctx, fromPvzID, productIDs)
+	require.NoError(t, err)
+	require.Len(t, transferable, 2)
+
+	transfer, err := transferStorage.CreateTransfer(ctx, dto.PostTransfersJSONBody{
+		FromPvzId:  fromPvzID,
+		ProductIds: productIDs,
+		ToPvzId:    toPvzID,
+	}, nil)
+	require.NoError(t, err)
+	shipped, err := transferStorage.ShipTransfer(ctx, transfer.Id, nil)
+	require.NoError(t, err)
+	require.Equal(t, dto.TransferInTransit, shipped.Status)
+
+	var mu sync.Mutex
+	received, skipped := 0, 0
+	var reception *dto.Reception
+	race(func() {
+		got, opened, err := transferStorage.ReceiveTransfer(ctx, transfer.Id, nil)
+		mu.Lock()
+		defer mu.Unlock()
+		assert.NoError(t, err)
+		if got != nil {
+			received++
+			reception = opened
+		} else {
+			skipped++
+		}
+	})
+	require.Equal(t, 1, received)
+	require.Equal(t, concurrentCalls-1, skipped)
+	require.NotNil(t, reception)
+	require.Equal(t, toPvzID, reception.PvzId)
+
+	path, err := transferStorage.GetProductPath(ctx, *products[0].Id)
+	require.NoError(t, err)
+	require.Len(t, path, 2)
+	require.Equal(t, fromPvzID, path[0].PvzId)
+	require.Equal(t, *products[0].Id, *path[0].Product.Id)
+	require.Equal(t, transfer.Id, *path[0].TransferId)
+	require.Equal(t, dto.TransferReceived, *path[0].TransferStatus)
+	require.Equal(t, toPvzID, path[1].PvzId)
+	require.Equal(t, dto.ProductReceived, *path[1].Product.Status)
+	require.Nil(t, path[1].TransferId)
+
+	// path is the same from the copy of product
+	copyPath, err := transferStorage.GetProductPath(ctx, *path[1].Product.Id)
+	require.NoError(t, err)
+	require.Equal(t, path, copyPath)
+}
+
+func testExpiredProducts(t *testing.T, pool *pgxpool.Pool) {
+	ctx := context.Background()
+	expiryStorage, err := pg.NewExpiryStorage(pool)
+	require.NoError(t, err)
+	productStorage, err := pg.NewProductStorage(pool)
+	require.NoError(t, err)
+
+	pvzID, products := receivedProducts(t, pool, 2)
+	storeProducts(t, pool, products[1:])
+
+	expired, err := expiryStorage.ExpireProducts(ctx, dto.ProductTypeElectronics, time.Nanosecond)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, expired[string(dto.Moscow)], 2)
+
+	returnList, err := expiryStorage.GetReturnList(ctx, pvzID)
+	require.NoError(t, err)
+	require.Len(t, returnList, 2)
+	require.Equal(t, *products[0].Id, *returnList[0].Id)
+	require.Equal(t, *products[1].Id, *returnList[1].Id)
+
+	// expired product waits for return and is not issued
+	issued, err := productStorage.UpdateProductStatus(ctx, *products[1].Id, dto.ProductStored, dto.ProductIssued)
+	require.NoError(t, err)
+	require.Nil(t, issued)
+
+	var mu sync.Mutex
+	confirmed, missing := 0, 0
+	race(func() {
+		returned, err := expiryStorage.ConfirmReturns(ctx, pvzID, []openapi_types.UUID{*products[0].Id, *products[1].Id},
+			[]dto.ProductStatus{dto.ProductReceived, dto.ProductStored})
+		mu.Lock()
+		defer mu.Unlock()
+		assert.NoError(t, err)
+		if returned != nil {
+			confirmed++
+		} else {
+			missing++
+		}
+	})
+	require.Equal(t, 1, confirmed)
+	require.Equal(t, concurrentCalls-1, missing)
+
+	returnList, err = expiryStorage.GetReturnList(ctx, pvzID)
+	require.NoError(t, err)
+	require.Empty(t, returnList)
+}
+
+func testInventoryReport(t *testing.T, pool *pgxpool.Pool) {
+	ctx := context.Background()
+	inventoryStorage, err := pg.NewInventoryStorage(pool)
+	require.NoError(t, err)
+	inventoryService, err := service.NewInventoryService(inventoryStorage)
+	require.NoError(t, err)
+
+	pvzID, products := receivedProducts(t, pool, 3)
+	storeProducts(t, pool, products[:1])
+	inventory, err := inventoryService.StartInventory(ctx, pvzID, nil)
+	require.NoError(t, err)
+
+	unknown := "unknown-barcode"
+	scans := []dto.PostInventoriesInventoryIdScansJSONBody{
+		{ProductId: products[0].Id},
+		// repeated scan of product id is ignored
+		{ProductId: products[0].Id},
+		{Barcode: products[1].Barcode},
+		{Barcode: &unknown},
+	}
+	for _, scan := range scans {
+		require.NoError(t, inventoryStorage.AddScan(ctx, inventory.Id, scan, nil))
+	}
+
+	completed, err := inventoryService.CompleteInventory(ctx, inventory.Id, nil)
+	require.NoError(t, err)
+	require.Equal(t, dto.InventoryCompleted, completed.Status)
+	require.NotNil(t, completed.Report)
+	require.Equal(t, 3, completed.Report.ExpectedCount)
+	require.Equal(t, 3, completed.Report.ScannedCount)
+	require.Len(t, completed.Report.Missing, 1)
+	require.Equal(t, *products[2].Id, *completed.Report.Missing[0].Id)
+	require.Len(t, completed.Report.Unexpected, 1)
+	require.Equal(t, unknown, *completed.Report.Unexpected[0].Barcode)
+
+	// scans are not added to completed inventory
+	require.NoError(t, inventoryStorage.AddScan(ctx, inventory.Id, dto.PostInventoriesInventoryIdScansJSONBody{Barcode: &unknown}, nil))
+	stored, err := inventoryStorage.GetScans(ctx, inventory.Id)
+	require.NoError(t, err)
+	require.Len(t, stored, 3)
+	_, err = inventoryService.CompleteInventory(ctx, inventory.Id, nil)
+	require.ErrorIs(t, err, service.ErrInventoryCompleted)
+}
@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+// GenerateCode returns random numeric code of given length
+func GenerateCode(digits int) (string, error) {
+	var code strings.Builder
+	for range digits {
+		digit, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		code.WriteString(digit.String())
+	}
+
+	return code.String(), nil
+}
@@ -0,0 +1,17 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCode(t *testing.T) {
+	code, err := GenerateCode(6)
+
+	require.NoError(t, err)
+	require.Len(t, code, 6)
+	for _, c := range code {
+		require.True(t, c >= '0' && c <= '9')
+	}
+}